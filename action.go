@@ -0,0 +1,85 @@
+package authority
+
+import (
+	"context"
+	"errors"
+)
+
+// PermissionName returns the name of the permission allowing an action on a resource when the permission
+// was not given an action and a resource, e.g. "invoices.read" for ("read", "invoices"). it's the name
+// DefaultPermissionNamer derives from the routes
+func PermissionName(action string, resource string) string {
+	return resource + "." + action
+}
+
+// CreateActionPermission creates a permission allowing an action on a resource, named by PermissionName.
+// the existing permission of that name is given the action and the resource
+func (a *Authority) CreateActionPermission(action string, resource string) error {
+	return a.CreateActionPermissionCtx(context.Background(), action, resource)
+}
+
+// CreateActionPermissionCtx is the context-aware variant of CreateActionPermission
+func (a *Authority) CreateActionPermissionCtx(ctx context.Context, action string, resource string) error {
+	permName := PermissionName(action, resource)
+
+	return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		if err := tx.createPermission(ctx, permName, ""); err != nil {
+			return err
+		}
+
+		return tx.SetPermissionActionCtx(ctx, permName, action, resource)
+	})
+}
+
+// SetPermissionAction gives a permission the action and the resource it allows, so that Can finds it
+// whatever its name
+func (a *Authority) SetPermissionAction(permName string, action string, resource string) error {
+	return a.SetPermissionActionCtx(context.Background(), permName, action, resource)
+}
+
+// SetPermissionActionCtx is the context-aware variant of SetPermissionAction
+func (a *Authority) SetPermissionActionCtx(ctx context.Context, permName string, action string,
+	resource string) error {
+	return a.UpdatePermissionCtx(ctx, permName, PermissionUpdate{Action: &action, Resource: &resource})
+}
+
+// Can checks if a user may do an action on a resource, e.g. Can(7, "read", "invoices"). the permission is the one
+// given the action and the resource, or the one named by PermissionName when there is none, so the permissions
+// created by name keep working. it returns ErrPermissionNotFound when neither exists
+func (a *Authority) Can(userID uint, action string, resource string) (bool, error) {
+	return a.CanCtx(context.Background(), userID, action, resource)
+}
+
+// CanCtx is the context-aware variant of Can
+func (a *Authority) CanCtx(ctx context.Context, userID uint, action string, resource string) (bool, error) {
+	return a.can(ctx, userKey(userID), action, resource, "")
+}
+
+// Can checks if the user may do an action on a resource, see Authority.Can
+func (u User) Can(action string, resource string) (bool, error) {
+	return u.CanCtx(context.Background(), action, resource)
+}
+
+// CanCtx is the context-aware variant of Can
+func (u User) CanCtx(ctx context.Context, action string, resource string) (bool, error) {
+	return u.a.can(ctx, u.key, action, resource, u.tenant)
+}
+
+func (a *Authority) can(ctx context.Context, user string, action string, resource string, tenant string) (bool,
+	error) {
+	permName, err := cached(ctx, a, cacheKey("action_permission", tenant, action, resource), func() (string, error) {
+		perm, err := a.store.GetPermissionByAction(ctx, action, resource, tenant)
+		if errors.Is(err, ErrPermissionNotFound) {
+			return PermissionName(action, resource), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return perm.Name, nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return a.checkPermission(ctx, user, permName, tenant)
+}
@@ -0,0 +1,376 @@
+package authority
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// the statuses of the access requests
+const (
+	RequestPending  = "pending"
+	RequestApproved = "approved"
+	RequestDenied   = "denied"
+)
+
+// the actions recorded on the audit entries of the access requests
+const (
+	AuditAccessRequested = "request.created"
+	AuditAccessApproved  = "request.approved"
+	AuditAccessDenied    = "request.denied"
+	// AuditAccessApprovalAdded records an approval of a request that needs more approvals
+	AuditAccessApprovalAdded = "request.approval_added"
+)
+
+// DefaultApprovalTokenTTL is how long the approval tokens are valid when ApprovalOptions.TokenTTL is zero
+const DefaultApprovalTokenTTL = 7 * 24 * time.Hour
+
+var (
+	ErrAccessRequestNotFound = errors.New("access request not found")
+	ErrAccessRequestDecided  = errors.New("access request already decided")
+	ErrInvalidApprovalToken  = errors.New("invalid approval token")
+	ErrApprovalTokenExpired  = errors.New("approval token expired")
+	ErrNoApprovalSecret      = errors.New("approval tokens need a secret")
+	ErrNotApprover           = errors.New("not an approver of the role")
+	ErrApprovalAlreadyGiven  = errors.New("access request already approved by the approver")
+)
+
+// ApprovalTokens are the tokens deciding an access request, e.g. embedded in the buttons of the message a Slack or
+// Teams bot posts to the approvers. they are signed with the secret of the options so the bot keeps no state,
+// it passes the token of the clicked button to FinalizeRequest
+type ApprovalTokens struct {
+	Approve   string
+	Deny      string
+	ExpiresAt time.Time
+}
+
+// ApprovalOptions has the options of the access requests
+type ApprovalOptions struct {
+	// Secret signs the approval tokens, there are no tokens when it's empty
+	Secret []byte
+	// TokenTTL is how long the tokens are valid, DefaultApprovalTokenTTL is used when it's zero
+	TokenTTL time.Duration
+	// OnRequest is called with every new request and its tokens, e.g. to post it to the approvers
+	OnRequest func(ctx context.Context, req AccessRequest, tokens ApprovalTokens)
+	// Thresholds are the approvals needed by the requests of the high-risk roles, by role name.
+	// the requests of the other roles are decided by a single approver
+	Thresholds map[string]ApprovalThreshold
+}
+
+// ApprovalThreshold requires several approvals to grant a role, e.g. 2 of the members of the security team.
+// the approvers are user keys and a single denial denies the request
+type ApprovalThreshold struct {
+	// Approvals is the number of distinct approvers needed
+	Approvals int
+	// ApproverRoles are the roles whose holders, in the tenant of the request, can approve or deny the
+	// requests, any approver can when it's empty
+	ApproverRoles []string
+}
+
+// RequestRole records the request of a user for a role, the role is assigned once the request is approved
+// with ApproveRequest or FinalizeRequest
+func (a *Authority) RequestRole(userID uint, roleName string, reason string) (*AccessRequest, error) {
+	return a.RequestRoleCtx(context.Background(), userID, roleName, reason)
+}
+
+// RequestRoleCtx is the context-aware variant of RequestRole
+func (a *Authority) RequestRoleCtx(ctx context.Context, userID uint, roleName string, reason string) (
+	*AccessRequest, error) {
+	return a.requestRole(ctx, userKey(userID), roleName, "", reason)
+}
+
+// RequestRole records the request of the user for a role in its tenant, see Authority.RequestRole
+func (u User) RequestRole(roleName string, reason string) (*AccessRequest, error) {
+	return u.RequestRoleCtx(context.Background(), roleName, reason)
+}
+
+// RequestRoleCtx is the context-aware variant of RequestRole
+func (u User) RequestRoleCtx(ctx context.Context, roleName string, reason string) (*AccessRequest, error) {
+	return u.a.requestRole(ctx, u.key, roleName, u.tenant, reason)
+}
+
+func (a *Authority) requestRole(ctx context.Context, user string, roleName string, tenant string, reason string) (
+	*AccessRequest, error) {
+	// make sure the role exists
+	if _, err := a.getTenantRole(ctx, roleName, tenant); err != nil {
+		return nil, err
+	}
+
+	req := &AccessRequest{
+		UserKey: user, Role: roleName, Tenant: tenant, Reason: reason, Status: RequestPending, RequestedAt: a.now(),
+	}
+	if err := a.store.CreateAccessRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	err := a.changed(ctx, AuditEntry{
+		Action: AuditAccessRequested, UserKey: user, Role: roleName, Tenant: tenant, Detail: reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if a.approvals.OnRequest != nil {
+		tokens, err := a.ApprovalTokens(req)
+		if err != nil && !errors.Is(err, ErrNoApprovalSecret) {
+			return nil, err
+		}
+		a.approvals.OnRequest(ctx, *req, tokens)
+	}
+
+	return req, nil
+}
+
+// GetAccessRequest returns an access request by id
+func (a *Authority) GetAccessRequest(id uint) (*AccessRequest, error) {
+	return a.GetAccessRequestCtx(context.Background(), id)
+}
+
+// GetAccessRequestCtx is the context-aware variant of GetAccessRequest
+func (a *Authority) GetAccessRequestCtx(ctx context.Context, id uint) (*AccessRequest, error) {
+	return a.store.GetAccessRequest(ctx, id)
+}
+
+// GetPendingRequests returns the requests waiting for a decision, the oldest first
+func (a *Authority) GetPendingRequests() ([]AccessRequest, error) {
+	return a.GetPendingRequestsCtx(context.Background())
+}
+
+// GetPendingRequestsCtx is the context-aware variant of GetPendingRequests
+func (a *Authority) GetPendingRequestsCtx(ctx context.Context) ([]AccessRequest, error) {
+	return a.store.ListAccessRequests(ctx, "", RequestPending)
+}
+
+// GetUserRequests returns the requests of a user, the oldest first
+func (a *Authority) GetUserRequests(userID uint) ([]AccessRequest, error) {
+	return a.GetUserRequestsCtx(context.Background(), userID)
+}
+
+// GetUserRequestsCtx is the context-aware variant of GetUserRequests
+func (a *Authority) GetUserRequestsCtx(ctx context.Context, userID uint) ([]AccessRequest, error) {
+	return a.store.ListAccessRequests(ctx, userKey(userID), "")
+}
+
+// ApproveRequest approves a pending request and assigns the role, the approver is recorded as the actor
+// of the assignment. it returns ErrAccessRequestDecided if the request was already approved or denied.
+// when the role has a threshold the approval is recorded and the request stays pending until it has enough
+// approvals. it returns ErrNotApprover if the approver is empty, is the requester or can't approve the role
+func (a *Authority) ApproveRequest(id uint, approver string) (*AccessRequest, error) {
+	return a.ApproveRequestCtx(context.Background(), id, approver)
+}
+
+// ApproveRequestCtx is the context-aware variant of ApproveRequest
+func (a *Authority) ApproveRequestCtx(ctx context.Context, id uint, approver string) (*AccessRequest, error) {
+	return a.decide(ctx, id, true, approver)
+}
+
+// DenyRequest denies a pending request, it returns ErrAccessRequestDecided if the request was already approved or
+// denied and ErrNotApprover like ApproveRequest
+func (a *Authority) DenyRequest(id uint, approver string) (*AccessRequest, error) {
+	return a.DenyRequestCtx(context.Background(), id, approver)
+}
+
+// DenyRequestCtx is the context-aware variant of DenyRequest
+func (a *Authority) DenyRequestCtx(ctx context.Context, id uint, approver string) (*AccessRequest, error) {
+	return a.decide(ctx, id, false, approver)
+}
+
+// GetRequestApprovals returns the approvals of a request needing several approvals, the oldest first
+func (a *Authority) GetRequestApprovals(id uint) ([]RequestApproval, error) {
+	return a.GetRequestApprovalsCtx(context.Background(), id)
+}
+
+// GetRequestApprovalsCtx is the context-aware variant of GetRequestApprovals
+func (a *Authority) GetRequestApprovalsCtx(ctx context.Context, id uint) ([]RequestApproval, error) {
+	return a.store.GetRequestApprovals(ctx, id)
+}
+
+// FinalizeRequest approves or denies the request of an approval token depending on the token,
+// it returns ErrInvalidApprovalToken or ErrApprovalTokenExpired if the token can't be used
+func (a *Authority) FinalizeRequest(token string, approver string) (*AccessRequest, error) {
+	return a.FinalizeRequestCtx(context.Background(), token, approver)
+}
+
+// FinalizeRequestCtx is the context-aware variant of FinalizeRequest
+func (a *Authority) FinalizeRequestCtx(ctx context.Context, token string, approver string) (*AccessRequest, error) {
+	id, approve, err := a.verifyApprovalToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.decide(ctx, id, approve, approver)
+}
+
+// decide stores the decision on a request and assigns the role of an approved request atomically
+func (a *Authority) decide(ctx context.Context, id uint, approve bool, approver string) (*AccessRequest, error) {
+	// the approver is the actor of the decision and of the assignment
+	md, _ := RequestMetadataFromContext(ctx)
+	md.Actor = approver
+	ctx = WithRequestMetadata(ctx, md)
+
+	var req *AccessRequest
+	err := a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		var err error
+		if req, err = tx.store.GetAccessRequest(ctx, id); err != nil {
+			return err
+		}
+
+		if req.Status != RequestPending {
+			return ErrAccessRequestDecided
+		}
+
+		threshold := tx.approvals.Thresholds[req.Role]
+		if err = tx.checkApprover(ctx, req, threshold, approver); err != nil {
+			return err
+		}
+
+		if approve && threshold.Approvals > 1 {
+			var approvals int
+			approvals, err = tx.store.AddRequestApproval(ctx, &RequestApproval{
+				RequestID: req.ID, Approver: approver, ApprovedAt: tx.now(),
+			})
+			if err != nil {
+				return err
+			}
+
+			if approvals < threshold.Approvals {
+				return tx.changed(ctx, AuditEntry{
+					Action: AuditAccessApprovalAdded, UserKey: req.UserKey, Role: req.Role, Tenant: req.Tenant,
+					Detail: fmt.Sprintf("%d: %d of %d", req.ID, approvals, threshold.Approvals),
+				})
+			}
+		}
+
+		action := AuditAccessDenied
+		req.Status, req.DecidedBy, req.DecidedAt = RequestDenied, approver, tx.now()
+		if approve {
+			action = AuditAccessApproved
+			req.Status = RequestApproved
+		}
+
+		if err = tx.store.DecideAccessRequest(ctx, req); err != nil {
+			return err
+		}
+
+		if approve {
+			err = tx.assignRole(ctx, req.Role, UserRole{UserKey: req.UserKey, Tenant: req.Tenant})
+			if err != nil && !errors.Is(err, ErrRoleAlreadyAssigned) {
+				return err
+			}
+		}
+
+		return tx.changed(ctx, AuditEntry{
+			Action: action, UserKey: req.UserKey, Role: req.Role, Tenant: req.Tenant, Detail: strconv.Itoa(int(req.ID)),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+// checkApprover returns ErrNotApprover if the approver can't decide a request: the approver must be given and
+// can't be the requester, and it must hold one of the approver roles of the threshold of the role, if any
+func (a *Authority) checkApprover(ctx context.Context, req *AccessRequest, threshold ApprovalThreshold,
+	approver string) error {
+	if approver == "" || approver == req.UserKey {
+		return ErrNotApprover
+	}
+
+	if len(threshold.ApproverRoles) == 0 {
+		return nil
+	}
+
+	// the approvers are checked against the current assignments
+	for _, roleName := range threshold.ApproverRoles {
+		granted, err := a.checkRole(NoCache(ctx), approver, roleName, req.Tenant)
+		if err != nil && !errors.Is(err, ErrRoleNotFound) {
+			return err
+		}
+		if granted {
+			return nil
+		}
+	}
+
+	return ErrNotApprover
+}
+
+// ApprovalTokens returns the tokens approving and denying a request,
+// it returns ErrNoApprovalSecret if the options have no secret
+func (a *Authority) ApprovalTokens(req *AccessRequest) (ApprovalTokens, error) {
+	if len(a.approvals.Secret) == 0 {
+		return ApprovalTokens{}, ErrNoApprovalSecret
+	}
+
+	ttl := a.approvals.TokenTTL
+	if ttl <= 0 {
+		ttl = DefaultApprovalTokenTTL
+	}
+	expiresAt := a.now().Add(ttl).Truncate(time.Second)
+
+	return ApprovalTokens{
+		Approve:   a.signApprovalToken(req.ID, RequestApproved, expiresAt),
+		Deny:      a.signApprovalToken(req.ID, RequestDenied, expiresAt),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// signApprovalToken returns a token made of the id of the request, the decision and the expiry with their mac
+func (a *Authority) signApprovalToken(id uint, decision string, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%d.%s.%d", id, decision, expiresAt.Unix())
+
+	return payload + "." + base64.RawURLEncoding.EncodeToString(a.approvalMAC(payload))
+}
+
+// verifyApprovalToken returns the request and the decision of a token
+func (a *Authority) verifyApprovalToken(token string) (uint, bool, error) {
+	if len(a.approvals.Secret) == 0 {
+		return 0, false, ErrNoApprovalSecret
+	}
+
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return 0, false, ErrInvalidApprovalToken
+	}
+
+	payload := token[:i]
+	mac, err := base64.RawURLEncoding.DecodeString(token[i+1:])
+	if err != nil || !hmac.Equal(mac, a.approvalMAC(payload)) {
+		return 0, false, ErrInvalidApprovalToken
+	}
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 {
+		return 0, false, ErrInvalidApprovalToken
+	}
+
+	id, err := strconv.ParseUint(parts[0], 10, 0)
+	if err != nil {
+		return 0, false, ErrInvalidApprovalToken
+	}
+
+	var expiry int64
+	if expiry, err = strconv.ParseInt(parts[2], 10, 64); err != nil {
+		return 0, false, ErrInvalidApprovalToken
+	}
+
+	if !a.now().Before(time.Unix(expiry, 0)) {
+		return 0, false, ErrApprovalTokenExpired
+	}
+
+	return uint(id), parts[1] == RequestApproved, nil
+}
+
+func (a *Authority) approvalMAC(payload string) []byte {
+	mac := hmac.New(sha256.New, a.approvals.Secret)
+	mac.Write([]byte(payload))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,165 @@
+package authority_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"authority"
+)
+
+func TestApproveRequest(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	setupRole(t, a, "editor", "articles.write")
+
+	req, err := a.RequestRole(1, "editor", "writing the release notes")
+	must(t, err)
+	if req.Status != authority.RequestPending {
+		t.Fatalf("the status of a new request = %q, want pending", req.Status)
+	}
+
+	// the requesters can't decide their own requests, and the approvers must be known
+	for _, approver := range []string{"1", ""} {
+		if _, err = a.ApproveRequest(req.ID, approver); !errors.Is(err, authority.ErrNotApprover) {
+			t.Errorf("ApproveRequest by %q = %v, want ErrNotApprover", approver, err)
+		}
+		if _, err = a.DenyRequest(req.ID, approver); !errors.Is(err, authority.ErrNotApprover) {
+			t.Errorf("DenyRequest by %q = %v, want ErrNotApprover", approver, err)
+		}
+	}
+	if granted, err := a.CheckRole(1, "editor"); err != nil || granted {
+		t.Fatalf("CheckRole after the rejected approvals = %v, %v, want false", granted, err)
+	}
+
+	if req, err = a.ApproveRequest(req.ID, "2"); err != nil || req.Status != authority.RequestApproved ||
+		req.DecidedBy != "2" {
+		t.Fatalf("ApproveRequest = %+v, %v, want approved by 2", req, err)
+	}
+	if granted, err := a.CheckRole(1, "editor"); err != nil || !granted {
+		t.Errorf("CheckRole after the approval = %v, %v, want true", granted, err)
+	}
+
+	if _, err = a.DenyRequest(req.ID, "3"); !errors.Is(err, authority.ErrAccessRequestDecided) {
+		t.Errorf("DenyRequest of an approved request = %v, want ErrAccessRequestDecided", err)
+	}
+}
+
+func TestFinalizeRequest(t *testing.T) {
+	now := time.Now()
+	var posted []authority.ApprovalTokens
+	a := newAuthority(t, authority.Options{Now: func() time.Time { return now }, Approvals: authority.ApprovalOptions{
+		Secret: []byte("secret"), TokenTTL: time.Hour,
+		OnRequest: func(_ context.Context, _ authority.AccessRequest, tokens authority.ApprovalTokens) {
+			posted = append(posted, tokens)
+		},
+	}})
+	setupRole(t, a, "editor", "articles.write")
+
+	req, err := a.RequestRole(1, "editor", "")
+	must(t, err)
+	if len(posted) != 1 || posted[0].Approve == "" || posted[0].Deny == "" {
+		t.Fatalf("the tokens posted to the approvers = %+v", posted)
+	}
+	tokens := posted[0]
+	if want := now.Add(time.Hour).Truncate(time.Second); !tokens.ExpiresAt.Equal(want) {
+		t.Errorf("the expiry of the tokens = %v, want %v", tokens.ExpiresAt, want)
+	}
+
+	// the tokens can't be forged or tampered with
+	other := newAuthority(t, authority.Options{Approvals: authority.ApprovalOptions{Secret: []byte("other secret")}})
+	forged, err := other.ApprovalTokens(req)
+	must(t, err)
+	tampered := strings.Replace(tokens.Deny, authority.RequestDenied, authority.RequestApproved, 1)
+	for _, token := range []string{"", "garbage", tokens.Approve + "x", forged.Approve, tampered} {
+		if _, err = a.FinalizeRequest(token, "2"); !errors.Is(err, authority.ErrInvalidApprovalToken) {
+			t.Errorf("FinalizeRequest of %q = %v, want ErrInvalidApprovalToken", token, err)
+		}
+	}
+
+	// the requesters can't use the tokens
+	if _, err = a.FinalizeRequest(tokens.Approve, "1"); !errors.Is(err, authority.ErrNotApprover) {
+		t.Errorf("FinalizeRequest by the requester = %v, want ErrNotApprover", err)
+	}
+
+	if req, err = a.FinalizeRequest(tokens.Deny, "2"); err != nil || req.Status != authority.RequestDenied {
+		t.Fatalf("FinalizeRequest of the deny token = %+v, %v, want denied", req, err)
+	}
+	if _, err = a.FinalizeRequest(tokens.Approve, "2"); !errors.Is(err, authority.ErrAccessRequestDecided) {
+		t.Errorf("FinalizeRequest of a denied request = %v, want ErrAccessRequestDecided", err)
+	}
+
+	// the tokens expire
+	_, err = a.RequestRole(1, "editor", "")
+	must(t, err)
+	now = now.Add(2 * time.Hour)
+	if _, err = a.FinalizeRequest(posted[1].Approve, "2"); !errors.Is(err, authority.ErrApprovalTokenExpired) {
+		t.Errorf("FinalizeRequest of an expired token = %v, want ErrApprovalTokenExpired", err)
+	}
+
+	// and there are none without a secret
+	unsigned := newAuthority(t, authority.Options{})
+	if _, err = unsigned.ApprovalTokens(req); !errors.Is(err, authority.ErrNoApprovalSecret) {
+		t.Errorf("ApprovalTokens without a secret = %v, want ErrNoApprovalSecret", err)
+	}
+	if _, err = unsigned.FinalizeRequest(tokens.Approve, "2"); !errors.Is(err, authority.ErrNoApprovalSecret) {
+		t.Errorf("FinalizeRequest without a secret = %v, want ErrNoApprovalSecret", err)
+	}
+}
+
+func TestApprovalThresholds(t *testing.T) {
+	a := newAuthority(t, authority.Options{Approvals: authority.ApprovalOptions{
+		Thresholds: map[string]authority.ApprovalThreshold{
+			"admin": {Approvals: 2, ApproverRoles: []string{"security"}},
+		},
+	}})
+	setupRole(t, a, "admin", "users.delete")
+	setupRole(t, a, "security", "audit.read")
+	for _, userID := range []uint{2, 3, 4} {
+		must(t, a.AssignRole(userID, "security"))
+	}
+
+	req, err := a.RequestRole(1, "admin", "")
+	must(t, err)
+
+	// only the holders of the approver roles approve
+	if _, err = a.ApproveRequest(req.ID, "5"); !errors.Is(err, authority.ErrNotApprover) {
+		t.Errorf("ApproveRequest by a user without an approver role = %v, want ErrNotApprover", err)
+	}
+
+	if req, err = a.ApproveRequest(req.ID, "2"); err != nil || req.Status != authority.RequestPending {
+		t.Fatalf("ApproveRequest 1 of 2 = %+v, %v, want pending", req, err)
+	}
+	if _, err = a.ApproveRequest(req.ID, "2"); !errors.Is(err, authority.ErrApprovalAlreadyGiven) {
+		t.Errorf("ApproveRequest twice by the same approver = %v, want ErrApprovalAlreadyGiven", err)
+	}
+	if granted, err := a.CheckRole(1, "admin"); err != nil || granted {
+		t.Fatalf("CheckRole with 1 approval of 2 = %v, %v, want false", granted, err)
+	}
+
+	if req, err = a.ApproveRequest(req.ID, "3"); err != nil || req.Status != authority.RequestApproved {
+		t.Fatalf("ApproveRequest 2 of 2 = %+v, %v, want approved", req, err)
+	}
+	if granted, err := a.CheckRole(1, "admin"); err != nil || !granted {
+		t.Errorf("CheckRole with 2 approvals of 2 = %v, %v, want true", granted, err)
+	}
+
+	approvals, err := a.GetRequestApprovals(req.ID)
+	must(t, err)
+	if len(approvals) != 2 || approvals[0].Approver != "2" || approvals[1].Approver != "3" {
+		t.Errorf("GetRequestApprovals = %+v, want the approvals of 2 and 3", approvals)
+	}
+
+	// a single denial denies the request
+	req, err = a.RequestRole(6, "admin", "")
+	must(t, err)
+	_, err = a.ApproveRequest(req.ID, "2")
+	must(t, err)
+	if req, err = a.DenyRequest(req.ID, "4"); err != nil || req.Status != authority.RequestDenied {
+		t.Fatalf("DenyRequest after an approval = %+v, %v, want denied", req, err)
+	}
+	if granted, err := a.CheckRole(6, "admin"); err != nil || granted {
+		t.Errorf("CheckRole of a denied request = %v, %v, want false", granted, err)
+	}
+}
@@ -0,0 +1,165 @@
+package authority
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// the actions recorded on audit entries
+const (
+	AuditRoleCreated            = "role.created"
+	AuditRoleDeleted            = "role.deleted"
+	AuditPermissionCreated      = "permission.created"
+	AuditPermissionDeleted      = "permission.deleted"
+	AuditPermissionConditionSet = "permission.condition_set"
+	AuditPermissionAssigned     = "permission.assigned"
+	AuditPermissionRevoked      = "permission.revoked"
+	AuditRoleAssigned           = "role.assigned"
+	AuditRoleRevoked            = "role.revoked"
+	AuditCompositeRoleAdded     = "composite.added"
+	AuditCompositeRoleRemoved   = "composite.removed"
+	AuditRoleParentSet          = "parent.set"
+	AuditRoleParentRemoved      = "parent.removed"
+	AuditPolicyDefined          = "policy.defined"
+	AuditPolicyDeleted          = "policy.deleted"
+)
+
+// RequestMetadata describes the request that triggered a change, it's recorded on the audit entries
+type RequestMetadata struct {
+	// Actor identifies who made the change, e.g. the id or email of an admin
+	Actor     string
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type requestMetadataKey struct{}
+
+// WithRequestMetadata returns a copy of the context carrying the request metadata,
+// changes made with the context-aware methods record it on their audit entries
+func WithRequestMetadata(ctx context.Context, md RequestMetadata) context.Context {
+	return context.WithValue(ctx, requestMetadataKey{}, md)
+}
+
+// RequestMetadataFromContext returns the request metadata carried by the context
+func RequestMetadataFromContext(ctx context.Context) (RequestMetadata, bool) {
+	md, ok := ctx.Value(requestMetadataKey{}).(RequestMetadata)
+	return md, ok
+}
+
+// HTTPMetadataOptions has the options of HTTPRequestMetadataWith
+type HTTPMetadataOptions struct {
+	// TrustedProxies are the networks of the proxies in front of the server, e.g. a load balancer, the
+	// X-Forwarded-For header is only read from them since any client can set it
+	TrustedProxies []netip.Prefix
+}
+
+// HTTPRequestMetadata returns the metadata of an http request, the ip is the remote address of the connection
+// and the request id the X-Request-Id header. use HTTPRequestMetadataWith behind proxies
+func HTTPRequestMetadata(r *http.Request) RequestMetadata {
+	return HTTPRequestMetadataWith(r, HTTPMetadataOptions{})
+}
+
+// HTTPRequestMetadataWith is like HTTPRequestMetadata, the ip of a request coming from a trusted proxy is the
+// right-most X-Forwarded-For address that isn't a trusted proxy, the addresses on its left may be forged
+func HTTPRequestMetadataWith(r *http.Request, opts HTTPMetadataOptions) RequestMetadata {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	if opts.trusted(ip) {
+		forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+		for i := len(forwarded) - 1; i >= 0; i-- {
+			addr := strings.TrimSpace(forwarded[i])
+			if _, err := netip.ParseAddr(addr); err != nil {
+				break
+			}
+
+			ip = addr
+			if !opts.trusted(addr) {
+				break
+			}
+		}
+	}
+
+	return RequestMetadata{
+		IP:        ip,
+		UserAgent: r.UserAgent(),
+		RequestID: r.Header.Get("X-Request-Id"),
+	}
+}
+
+// trusted reports whether the address is one of a trusted proxy
+func (o HTTPMetadataOptions) trusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	for _, prefix := range o.TrustedProxies {
+		if prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// changed is called after every change, it forgets the checks memoized by the request, drops the cached lookups,
+// bumps the policy version, calls the validators, records the change and notifies the hooks
+func (a *Authority) changed(ctx context.Context, entry AuditEntry) error {
+	forgetChecks(ctx)
+
+	if err := a.purgeCache(ctx); err != nil {
+		return err
+	}
+
+	if err := a.bumpPolicyVersion(ctx, entry); err != nil {
+		return err
+	}
+
+	entry = a.describe(ctx, entry)
+	if err := a.validated(ctx, entry); err != nil {
+		return err
+	}
+
+	if err := a.audit(ctx, entry); err != nil {
+		return err
+	}
+
+	a.notify(ctx, entry)
+
+	return nil
+}
+
+// describe completes an entry with the user id or key and the request metadata of the context
+func (a *Authority) describe(ctx context.Context, entry AuditEntry) AuditEntry {
+	if entry.UserKey == "" && entry.UserID != 0 {
+		entry.UserKey = userKey(entry.UserID)
+	}
+	if entry.UserID == 0 {
+		entry.UserID = userIDOf(entry.UserKey)
+	}
+
+	md, _ := RequestMetadataFromContext(ctx)
+	entry.Actor = md.Actor
+	entry.IP = md.IP
+	entry.UserAgent = md.UserAgent
+	entry.RequestID = md.RequestID
+	entry.CreatedAt = a.now()
+
+	return entry
+}
+
+// audit records a change when auditing is enabled for it
+func (a *Authority) audit(ctx context.Context, entry AuditEntry) error {
+	if !a.audits(entry) {
+		return nil
+	}
+
+	return a.store.CreateAuditEntry(ctx, &entry)
+}
@@ -0,0 +1,49 @@
+package authority_test
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"authority"
+)
+
+func TestHTTPRequestMetadata(t *testing.T) {
+	proxies := authority.HTTPMetadataOptions{TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}}
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded []string
+		opts      authority.HTTPMetadataOptions
+		want      string
+	}{
+		{name: "direct", remote: "203.0.113.7:4000", want: "203.0.113.7"},
+		{name: "forged without proxies", remote: "203.0.113.7:4000", forwarded: []string{"198.51.100.1"},
+			want: "203.0.113.7"},
+		{name: "forged by an untrusted client", remote: "203.0.113.7:4000", forwarded: []string{"198.51.100.1"},
+			opts: proxies, want: "203.0.113.7"},
+		{name: "through a proxy", remote: "10.0.0.2:4000", forwarded: []string{"203.0.113.7"}, opts: proxies,
+			want: "203.0.113.7"},
+		{name: "forged through a proxy", remote: "10.0.0.2:4000", forwarded: []string{"198.51.100.1, 203.0.113.7"},
+			opts: proxies, want: "203.0.113.7"},
+		{name: "through proxies", remote: "10.0.0.2:4000", forwarded: []string{"198.51.100.1, 203.0.113.7",
+			"10.0.0.3"}, opts: proxies, want: "203.0.113.7"},
+		{name: "invalid address", remote: "10.0.0.2:4000", forwarded: []string{"203.0.113.7, unknown"},
+			opts: proxies, want: "10.0.0.2"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tc.remote
+			for _, forwarded := range tc.forwarded {
+				r.Header.Add("X-Forwarded-For", forwarded)
+			}
+
+			if md := authority.HTTPRequestMetadataWith(r, tc.opts); md.IP != tc.want {
+				t.Errorf("the ip = %q, want %q", md.IP, tc.want)
+			}
+		})
+	}
+}
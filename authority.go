@@ -2,104 +2,328 @@ package authority
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/uptrace/bun"
 )
 
 // Authority helps deal with permissions
 type Authority struct {
+	// DB is the database of the default store, it's nil when a custom store is used
 	DB *bun.DB
 
-	TableRole     string
-	TablePerm     string
-	TableRolePerm string
-	TableUserRole string
+	store        Store
+	auditEnabled bool
+	lockTimeout  time.Duration
+	now          func() time.Time
+	conditions   *conditions
+	policies     *policySet
+	checks       *checkLimiter
+	quotas       QuotaProvider
+	cache        CacheBackend
+	cacheTTL     time.Duration
+	stale        *staleness
+	hooks        Hooks
+	validators   []Validator
+	metrics      MetricsSink
+	logger       *slog.Logger
+	logChecks    bool
+	// notifications is nil when no notifier is set
+	notifications *notifications
+	approvals     ApprovalOptions
+	capabilities  map[string]Capability
+	superRole     string
+	defaultRoles  []string
+	// bursts is nil when the detection of the denial bursts is disabled
+	bursts *denialBursts
+	// pending has the changes of a transaction to notify once it's committed
+	pending *[]AuditEntry
+	// changeFeedDelay is how old the changes returned by GetChangesSince are
+	changeFeedDelay time.Duration
 }
 
 // Options has the options for initiating the package
 type Options struct {
 	DB           *bun.DB
 	TablesPrefix string
+	// Store replaces the default bun store, DB and TablesPrefix are ignored when it's set
+	Store Store
+	// CaseInsensitiveNames makes the default store compare the names of the roles and the permissions
+	// without case, see BunStoreOptions
+	CaseInsensitiveNames bool
+	// Namespace scopes the roles and the permissions of the default store to an application, for the
+	// applications sharing the tables, see BunStoreOptions
+	Namespace string
+	// ColumnTypes sets the types of some columns of the tables of the default store
+	ColumnTypes ColumnTypes
+	// CheckFunctions creates the SQL functions checking the permissions, see BunStoreOptions
+	CheckFunctions bool
+	// ReportingViews creates the views of the effective permissions for reporting, see BunStoreOptions
+	ReportingViews bool
+	// Replication adds the metadata making the tables of the default store safe to replicate across regions
+	Replication ReplicationOptions
+	// IndexName names the indexes of the foreign keys of the default store, see BunStoreOptions
+	IndexName func(table string, columns []string) string
+	// SkipMigration makes New leave the schema alone, for the environments whose schema is managed by external
+	// migration tools, see GenerateMigrationSQL
+	SkipMigration bool
+	// Audit records every change on an audit entry
+	Audit bool
+	// LockTimeout is how long the exclusive admin operations wait for another instance running the same
+	// operation, they fail with ErrOperationLocked right away when it's zero
+	LockTimeout time.Duration
+	// Now returns the current time, scheduled assignments become active when it passes their start time
+	// and time-bound ones stop at their expiry. it defaults to time.Now
+	Now func() time.Time
+	// MaxConcurrentChecks limits the checks running at the same time, the others wait for a free slot.
+	// a check holds one connection of the pool at a time, so keeping the limit below the size of the pool
+	// leaves connections for the rest of the application. there is no limit when it's zero
+	MaxConcurrentChecks int
+	// Quotas limits the number of users holding a role in a tenant, e.g. by the plan of the tenant
+	Quotas QuotaProvider
+	// CacheTTL enables an in-process cache of the role and permission lookups and the check results,
+	// the entries are kept for the ttl at most and every change made through the instance drops them.
+	// changes made by other instances are seen once the entries expire. there is no cache when it's zero
+	// and CacheBackend is nil
+	CacheTTL time.Duration
+	// CacheSize is the number of entries the in-process cache keeps, DefaultCacheSize is used when it's zero
+	CacheSize int
+	// CacheBackend replaces the in-process cache, e.g. with a cache shared by the instances that also sees
+	// their changes. DefaultCacheTTL is used when CacheTTL is zero
+	CacheBackend CacheBackend
+	// StaleWhileRevalidate keeps the cached check results for the duration after their ttl, a check finding
+	// an expired result returns it right away and refreshes it in the background. the expired results are
+	// reloaded before returning when it's zero
+	StaleWhileRevalidate time.Duration
+	// PermissionClass returns the sensitivity class of a permission, e.g. ClassPrivileged for "billing.refund"
+	PermissionClass func(permName string) string
+	// SensitivityClasses configures the caching, the auditing and the approval of the permissions by class
+	SensitivityClasses map[string]SensitivityClass
+	// Hooks is notified of the changes made through the instance
+	Hooks Hooks
+	// Validators are called before and after the changes made through the instance and can veto them
+	Validators []Validator
+	// Metrics receives the counters and the timers of the checks, the cache and the changes, e.g. the sinks of
+	// the statsd and prometheus packages. the failed queries are counted by adding MetricsQueryHook to the DB
+	Metrics MetricsSink
+	// Logger receives the log records of the instance: the denied checks and the committed changes at the info
+	// level, the errors the instance goes on after, e.g. of the cache backend, at the warn level and the failed
+	// checks at the error level. the granted checks are logged with LogChecks
+	Logger *slog.Logger
+	// LogChecks logs every check at the debug level with the fields of the decision, e.g. for log-based
+	// analytics: the kind, the decision, the user, the tenant, the perm or the role, the role_matched granting
+	// it, the cache_hit and the latency. the role granting a permission is looked up for the record, with
+	// the cache of the lookups
+	LogChecks bool
+	// Notifications notifies the users when they gain or lose a role
+	Notifications NotificationOptions
+	// DenialBursts detects the bursts of denied permission checks of a user
+	DenialBursts DenialBurstOptions
+	// Approvals configures the tokens of the access requests
+	Approvals ApprovalOptions
+	// Capabilities maps the capabilities of the manifests returned by BuildCapabilityManifest by name
+	Capabilities map[string]Capability
+	// ChangeFeedDelay is how old the changes must be to be returned by GetChangesSince, it should exceed the
+	// duration of the longest transaction. DefaultChangeFeedDelay is used when it's zero
+	ChangeFeedDelay time.Duration
+	// SuperRole is a role, e.g. "root", whose holders pass every CheckRole, CheckPermission and CheckPolicy
+	// without assignments of the permissions, their denials and conditions included, and have every permission
+	// in GetUserPermissions. the default store grants it within the check query, a custom store implementing
+	// PermissionChecker must grant it as well
+	SuperRole string
+	// DefaultRoles are roles, e.g. "user", the checks consider assigned to every user without storing the
+	// assignments, the default store considers them within the check query. see MaterializeDefaultRoles
+	DefaultRoles []string
 }
 
 var (
-	ErrPermissionInUse        = errors.New("cannot delete assigned permission")
-	ErrPermissionNotFound     = errors.New("permission not found")
-	ErrRoleAlreadyAssigned    = errors.New("this role is already assigned to the user")
-	ErrRoleInUse              = errors.New("cannot delete assigned role")
-	ErrRoleNotFound           = errors.New("role not found")
-	ErrRolePermissionNotFound = errors.New("permission for a role not found")
-	ErrUserRoleNotFound       = errors.New("role for a user not found")
-	ErrRoleExists             = errors.New("role exists")
+	ErrPermissionInUse           = errors.New("cannot delete assigned permission")
+	ErrPermissionNotFound        = errors.New("permission not found")
+	ErrRoleAlreadyAssigned       = errors.New("this role is already assigned to the user")
+	ErrPermissionAlreadyAssigned = errors.New("this permission is already assigned to the role")
+	ErrRoleInUse                 = errors.New("cannot delete assigned role")
+	ErrRoleNotFound              = errors.New("role not found")
+	ErrRolePermissionNotFound    = errors.New("permission for a role not found")
+	ErrUserRoleNotFound          = errors.New("role for a user not found")
+	ErrRoleExists                = errors.New("role exists")
+	ErrCompositeCycle            = errors.New("composite role cannot include itself")
+	ErrHierarchyCycle            = errors.New("role cannot inherit from itself")
+	ErrNotSupported              = errors.New("operation not supported by the store")
+	ErrOperationLocked           = errors.New("operation is running on another instance")
 )
 
-var auth *Authority
+// auth is the last initiated instance returned by Resolve
+var (
+	authMu sync.RWMutex
+	auth   *Authority
+)
 
-// New initiates authority
+// New initiates authority, it panics if the storage can't be migrated. NewE returns the error instead
 func New(opts Options) *Authority {
-	auth = &Authority{
-		DB:            opts.DB,
-		TableRole:     opts.TablesPrefix + "roles AS role",
-		TablePerm:     opts.TablesPrefix + "permissions AS perm",
-		TableRolePerm: opts.TablesPrefix + "role_permissions AS rp",
-		TableUserRole: opts.TablesPrefix + "user_roles AS ur",
+	a, err := NewE(opts)
+	if err != nil {
+		panic(err)
 	}
 
-	if err := migrateTables(&opts); err != nil {
-		panic(err)
+	return a
+}
+
+// NewE initiates authority and migrates the storage, it returns the error of the migration
+// so services can retry or shut down gracefully
+func NewE(opts Options) (*Authority, error) {
+	a := &Authority{
+		store:        opts.Store,
+		auditEnabled: opts.Audit,
+		lockTimeout:  opts.LockTimeout,
+		now:          opts.Now,
+		conditions:   &conditions{},
+		policies:     &policySet{},
+		checks:       newCheckLimiter(opts.MaxConcurrentChecks),
+		quotas:       opts.Quotas,
+		stale:        newStaleness(opts),
+		hooks:        opts.Hooks,
+		validators:   opts.Validators,
+		metrics:      opts.Metrics,
+		logger:       opts.Logger,
+		logChecks:    opts.LogChecks,
+		approvals:    opts.Approvals,
+		capabilities: opts.Capabilities,
+		superRole:    opts.SuperRole,
+		defaultRoles: opts.DefaultRoles,
+
+		changeFeedDelay: opts.ChangeFeedDelay,
+	}
+	if a.now == nil {
+		a.now = time.Now
+	}
+	a.bursts = newDenialBursts(opts.DenialBursts, a.now)
+	if a.changeFeedDelay <= 0 {
+		a.changeFeedDelay = DefaultChangeFeedDelay
+	}
+	a.cache, a.cacheTTL = opts.CacheBackend, opts.CacheTTL
+	if a.cache == nil && a.cacheTTL > 0 {
+		a.cache = newLRUCache(opts.CacheSize, a.now)
+	} else if a.cache != nil && a.cacheTTL <= 0 {
+		a.cacheTTL = DefaultCacheTTL
+	}
+	if opts.Notifications.Notifier != nil || len(opts.Notifications.Roles) > 0 {
+		var err error
+		if a.notifications, err = newNotifications(opts.Notifications); err != nil {
+			return nil, err
+		}
+	}
+	if a.store == nil {
+		a.DB = opts.DB
+		a.store = NewBunStoreWith(opts.DB, BunStoreOptions{
+			TablesPrefix: opts.TablesPrefix, Namespace: opts.Namespace, CaseInsensitiveNames: opts.CaseInsensitiveNames,
+			SuperRole: opts.SuperRole, DefaultRoles: opts.DefaultRoles, ColumnTypes: opts.ColumnTypes,
+			CheckFunctions: opts.CheckFunctions, ReportingViews: opts.ReportingViews, Replication: opts.Replication,
+			IndexName: opts.IndexName,
+		})
 	}
 
-	return auth
+	// instances starting together wait for each other instead of migrating concurrently
+	if !opts.SkipMigration {
+		migrate := func(ctx context.Context) error { return a.store.Migrate(ctx) }
+		if err := a.exclusive(context.Background(), "migrate", -1, migrate); err != nil {
+			return nil, err
+		}
+	}
+
+	authMu.Lock()
+	auth = a
+	authMu.Unlock()
+
+	return a, nil
 }
 
-// Resolve returns the initiated instance
+// Resolve returns the last initiated instance.
+//
+// Deprecated: it's ambiguous when several instances are initiated, keep the instance returned by New
+// or register the instances in a Registry
 func Resolve() *Authority {
+	authMu.RLock()
+	defer authMu.RUnlock()
+
 	return auth
 }
 
 // CreateRole stores a role in the database it accepts the role name.
 // it returns an error in case of any
 func (a *Authority) CreateRole(roleName string) error {
+	return a.CreateRoleCtx(context.Background(), roleName)
+}
+
+// CreateRoleCtx is the context-aware variant of CreateRole
+func (a *Authority) CreateRoleCtx(ctx context.Context, roleName string) error {
+	return a.createRole(ctx, Role{Name: roleName})
+}
+
+func (a *Authority) createRole(ctx context.Context, newRole Role) error {
 	var err error
-	ctx := context.Background()
 
-	var exists bool
-	if exists, err = a.DB.NewSelect().Model((*Role)(nil)).ModelTableExpr(a.TableRole).
-		Where("name = ?", roleName).Exists(ctx); err != nil {
+	var role *Role
+	if role, err = a.getTenantRole(ctx, newRole.Name, newRole.Tenant); err != nil && !errors.Is(err, ErrRoleNotFound) {
 		return err
 	}
 
-	if !exists {
-		if _, err = a.DB.NewInsert().Model(&Role{Name: roleName}).ModelTableExpr(a.TableRole).Exec(ctx); err != nil {
-			return err
-		}
+	// a global role with the same name doesn't prevent defining the role in a tenant
+	if err == nil && role.Tenant == newRole.Tenant {
+		return nil
 	}
 
-	return nil
+	entry := AuditEntry{Action: AuditRoleCreated, Role: newRole.Name, Tenant: newRole.Tenant}
+	if err = a.validate(ctx, entry); err != nil {
+		return err
+	}
+
+	if err = a.store.CreateRole(ctx, &newRole); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, entry)
 }
 
 // CreatePermission stores a permission in the database it accepts the permission name.
 // it returns an error in case of any
 func (a *Authority) CreatePermission(permName string) error {
+	return a.CreatePermissionCtx(context.Background(), permName)
+}
+
+// CreatePermissionCtx is the context-aware variant of CreatePermission
+func (a *Authority) CreatePermissionCtx(ctx context.Context, permName string) error {
+	return a.createPermission(ctx, permName, "")
+}
+
+func (a *Authority) createPermission(ctx context.Context, permName string, tenant string) error {
 	var err error
-	ctx := context.Background()
 
-	var exists bool
-	if exists, err = a.DB.NewSelect().Model((*Permission)(nil)).ModelTableExpr(a.TablePerm).
-		Where("name = ?", permName).Exists(ctx); err != nil {
+	var perm *Permission
+	if perm, err = a.getTenantPermission(ctx, permName, tenant); err != nil && !errors.Is(err, ErrPermissionNotFound) {
 		return err
 	}
 
-	if !exists {
-		if _, err = a.DB.NewInsert().Model(&Permission{Name: permName}).ModelTableExpr(a.TablePerm).Exec(ctx); err != nil {
-			return err
-		}
+	// a global permission with the same name doesn't prevent defining the permission in a tenant
+	if err == nil && perm.Tenant == tenant {
+		return nil
 	}
 
-	return nil
+	entry := AuditEntry{Action: AuditPermissionCreated, Permission: permName, Tenant: tenant}
+	if err = a.validate(ctx, entry); err != nil {
+		return err
+	}
+
+	if err = a.store.CreatePermission(ctx, &Permission{Name: permName, Tenant: tenant}); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, entry)
 }
 
 // AssignPermissions assigns a group of permissions to a given role it accepts in the first parameter the role name,
@@ -108,33 +332,74 @@ func (a *Authority) CreatePermission(permName string) error {
 // if any of these permissions doesn't have a matching record in the database the operations stops, changes reverted
 // and error is returned in case of success nothing is returned
 func (a *Authority) AssignPermissions(roleName string, permNames []string) error {
+	return a.AssignPermissionsCtx(context.Background(), roleName, permNames)
+}
+
+// AssignPermissionsCtx is the context-aware variant of AssignPermissions
+func (a *Authority) AssignPermissionsCtx(ctx context.Context, roleName string, permNames []string) error {
+	return a.assignPermissions(ctx, roleName, permNames, RolePermission{}, "")
+}
+
+// AssignPermissionsBetween assigns permissions to a role for a period, e.g. from next monday until the end of a
+// campaign. a zero start grants them right away and a zero end until they are revoked
+func (a *Authority) AssignPermissionsBetween(roleName string, permNames []string, startAt, endAt time.Time) error {
+	return a.AssignPermissionsBetweenCtx(context.Background(), roleName, permNames, startAt, endAt)
+}
+
+// AssignPermissionsBetweenCtx is the context-aware variant of AssignPermissionsBetween
+func (a *Authority) AssignPermissionsBetweenCtx(ctx context.Context, roleName string, permNames []string,
+	startAt, endAt time.Time) error {
+	return a.assignPermissions(ctx, roleName, permNames, RolePermission{StartsAt: startAt, ExpiresAt: endAt}, "")
+}
+
+// assignPermissions assigns permissions to a role for the period of the given assignment
+// assignPermissions assigns all the permissions or none
+func (a *Authority) assignPermissions(ctx context.Context, roleName string, permNames []string,
+	period RolePermission, tenant string) error {
+	return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		return tx.assignRolePermissions(ctx, roleName, permNames, period, tenant)
+	})
+}
+
+func (a *Authority) assignRolePermissions(ctx context.Context, roleName string, permNames []string,
+	period RolePermission, tenant string) error {
 	var err error
-	ctx := context.Background()
 
 	// get the role id
 	var role *Role
-	if role, err = a.getRole(roleName); err != nil {
+	if role, err = a.getTenantRole(ctx, roleName, tenant); err != nil {
 		return err
 	}
 
 	var perms []*Permission
 	for _, permName := range permNames {
 		var perm *Permission
-		if perm, err = a.getPermission(permName); err != nil {
+		if perm, err = a.getTenantPermission(ctx, permName, tenant); err != nil {
 			return err
 		}
 		perms = append(perms, perm)
 	}
 
-	// insert data into RolePermissions table
+	// insert data into RolePermissions table, the insert skips any assigned permission
 	for _, perm := range perms {
-		// ignore any assigned permission
-		if _, err = a.getRolePermission(role.ID, perm.ID); err != nil {
-			// assign the record
-			if _, err = a.DB.NewInsert().Model(&RolePermission{RoleID: role.ID, PermissionID: perm.ID}).
-				ModelTableExpr(a.TableRolePerm).Exec(ctx); err != nil {
-				return err
-			}
+		entry := AuditEntry{
+			Action: AuditPermissionAssigned, Role: roleName, Permission: perm.Name, Tenant: tenant,
+			Detail: periodDetail(period.StartsAt, period.ExpiresAt),
+		}
+		if err = a.validate(ctx, entry); err != nil {
+			return err
+		}
+
+		rolePerm := period
+		rolePerm.RoleID, rolePerm.PermissionID = role.ID, perm.ID
+		if err = a.store.AssignPermission(ctx, &rolePerm); errors.Is(err, ErrPermissionAlreadyAssigned) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if err = a.changed(ctx, entry); err != nil {
+			return err
 		}
 	}
 
@@ -145,25 +410,109 @@ func (a *Authority) AssignPermissions(roleName string, permNames []string) error
 // if the role name doesn't have a matching record in the data base an error is returned
 // if the user have already a role assigned to him an error is returned
 func (a *Authority) AssignRole(userID uint, roleName string) error {
+	return a.AssignRoleCtx(context.Background(), userID, roleName)
+}
+
+// AssignRoleCtx is the context-aware variant of AssignRole
+func (a *Authority) AssignRoleCtx(ctx context.Context, userID uint, roleName string) error {
+	return a.assignRole(ctx, roleName, UserRole{UserKey: userKey(userID)})
+}
+
+// AssignRoleFrom assigns a role to a user starting at the given time, e.g. the start date of a new employee,
+// the checks ignore the assignment until then
+func (a *Authority) AssignRoleFrom(userID uint, roleName string, startAt time.Time) error {
+	return a.AssignRoleFromCtx(context.Background(), userID, roleName, startAt)
+}
+
+// AssignRoleFromCtx is the context-aware variant of AssignRoleFrom
+func (a *Authority) AssignRoleFromCtx(ctx context.Context, userID uint, roleName string, startAt time.Time) error {
+	return a.assignRole(ctx, roleName, UserRole{UserKey: userKey(userID), StartsAt: startAt})
+}
+
+// AssignRoleUntil assigns a role to a user until the given time, e.g. the end of a contract,
+// the checks ignore the assignment after then
+func (a *Authority) AssignRoleUntil(userID uint, roleName string, expiresAt time.Time) error {
+	return a.AssignRoleUntilCtx(context.Background(), userID, roleName, expiresAt)
+}
+
+// AssignRoleUntilCtx is the context-aware variant of AssignRoleUntil
+func (a *Authority) AssignRoleUntilCtx(ctx context.Context, userID uint, roleName string, expiresAt time.Time) error {
+	return a.assignRole(ctx, roleName, UserRole{UserKey: userKey(userID), ExpiresAt: expiresAt})
+}
+
+// assignRole assigns a role to the user of the assignment, in its tenant and for its period
+func (a *Authority) assignRole(ctx context.Context, roleName string, userRole UserRole) error {
 	var err error
-	ctx := context.Background()
 
 	// make sure the role exist
 	var role *Role
-	if role, err = a.getRole(roleName); err != nil {
+	if role, err = a.getTenantRole(ctx, roleName, userRole.Tenant); err != nil {
 		return err
 	}
 
-	// check if the role is already assigned
-	if _, err = a.getUserRole(userID, role.ID); err == nil {
-		//found a record, this role is already assigned to the same user
-		return ErrRoleAlreadyAssigned
+	// a retired role is kept for the history only
+	if role.stateOrDefault() == RoleStateRetired {
+		return ErrRoleRetired
+	}
+
+	// check if the role is already assigned, before the quota counts the user. the insert below skips the
+	// assignment made concurrently since this check
+	if _, err = a.store.GetUserRole(ctx, userRole.UserKey, role.ID, userRole.Tenant); err == nil {
+		return a.alreadyAssigned(ctx, role.ID, userRole)
+	}
+
+	// make sure the tenant has a seat left
+	if err = a.checkQuota(ctx, role, userRole.Tenant, 1); err != nil {
+		return err
+	}
+
+	entry := AuditEntry{
+		Action: AuditRoleAssigned, UserKey: userRole.UserKey, Role: roleName, Tenant: userRole.Tenant,
+		Detail: periodDetail(userRole.StartsAt, userRole.ExpiresAt),
+	}
+	if err = a.validate(ctx, entry); err != nil {
+		return err
 	}
 
 	// assign the role
-	_, err = a.DB.NewInsert().Model(&UserRole{UserID: userID, RoleID: role.ID}).ModelTableExpr(a.TableUserRole).Exec(ctx)
+	md, _ := RequestMetadataFromContext(ctx)
+	userRole.UserID = userIDOf(userRole.UserKey)
+	userRole.RoleID = role.ID
+	userRole.GrantedBy = md.Actor
+	if err = a.store.AssignRole(ctx, &userRole); errors.Is(err, ErrRoleAlreadyAssigned) {
+		return a.alreadyAssigned(ctx, role.ID, userRole)
+	} else if err != nil {
+		return err
+	}
+
+	if err = a.claim(ctx, []UserRole{userRole}); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, entry)
+}
+
+// alreadyAssigned records the claim of the source on an assignment the user already has
+// and returns ErrRoleAlreadyAssigned
+func (a *Authority) alreadyAssigned(ctx context.Context, roleID uint, userRole UserRole) error {
+	if err := a.claim(ctx, []UserRole{{UserKey: userRole.UserKey, RoleID: roleID, Tenant: userRole.Tenant}}); err != nil {
+		return err
+	}
+
+	return ErrRoleAlreadyAssigned
+}
+
+// periodDetail describes the period of an assignment on its audit entry
+func periodDetail(start, end time.Time) string {
+	var period []string
+	if !start.IsZero() {
+		period = append(period, "starts at "+start.Format(time.RFC3339))
+	}
+	if !end.IsZero() {
+		period = append(period, "expires at "+end.Format(time.RFC3339))
+	}
 
-	return err
+	return strings.Join(period, ", ")
 }
 
 // CheckRole checks if a role is assigned to a user
@@ -171,143 +520,321 @@ func (a *Authority) AssignRole(userID uint, roleName string) error {
 // the role as the second parameter
 // it returns an error if the role is not present in database
 func (a *Authority) CheckRole(userID uint, roleName string) (bool, error) {
-	var err error
+	return a.CheckRoleCtx(context.Background(), userID, roleName)
+}
+
+// CheckRoleCtx is the context-aware variant of CheckRole
+func (a *Authority) CheckRoleCtx(ctx context.Context, userID uint, roleName string) (bool, error) {
+	return a.checkRole(ctx, userKey(userID), roleName, "")
+}
+
+func (a *Authority) checkRole(ctx context.Context, user string, roleName string, tenant string) (
+	granted bool, err error) {
+	c := &checkLog{kind: CheckKindRole, user: user, tenant: tenant, name: roleName, start: time.Now()}
+	defer func() { c.granted, c.err = granted, err; a.logCheck(ctx, c) }()
+	defer a.measureCheck(CheckKindRole, "", c.start, &granted, &err)
+
+	// the request may have made the check already
+	key := cacheKey("check_role", tenant, user, roleName)
+	if memo, ok := memoized(ctx, key); ok {
+		return memo.Granted, nil
+	}
+	defer func() {
+		if err == nil {
+			memoize(ctx, key, permissionGrant{Granted: granted})
+		}
+	}()
+
+	release, err := a.checks.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer release()
 
 	// find the role
 	var role *Role
-	if role, err = a.getRole(roleName); err != nil {
+	if role, err = a.getTenantRole(ctx, roleName, tenant); err != nil {
 		return false, err
 	}
 
-	// check if the role is assigned
-	if _, err = a.getUserRole(userID, role.ID); err != nil {
-		if errors.Is(err, ErrUserRoleNotFound) {
-			return false, nil
+	// the role may be assigned directly or included in one of the user's roles
+	return cachedStale(ctx, a, key, cachePolicy{window: a.stale.window}, func(ctx context.Context) (bool, error) {
+		c.loaded()
+
+		roleIDs, err := a.userRoleIDs(ctx, user, tenant)
+		if err != nil {
+			return false, err
 		}
 
-		return false, err
-	}
+		if roleIDs, err = a.grantingRoles(ctx, roleIDs); err != nil {
+			return false, err
+		}
 
-	return true, nil
+		for _, id := range roleIDs {
+			if id == role.ID {
+				return true, nil
+			}
+		}
+
+		return a.holdsSuperRole(ctx, roleIDs, tenant)
+	})
 }
 
 // CheckPermission checks if a permission is assigned to the role that's assigned to the user.
 // it accepts the user id as the first parameter the permission as the second parameter
 // it returns an error if the permission is not present in the database
+// a denial of the permission to the user or to one of its roles takes precedence over the roles granting it
 func (a *Authority) CheckPermission(userID uint, permName string) (bool, error) {
-	var err error
-	ctx := context.Background()
-	// the user role
-	var userRoles []UserRole
-	if err = a.DB.NewSelect().Model(&userRoles).ModelTableExpr(a.TableUserRole).
-		Where("user_id = ?", userID).Scan(ctx); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return false, nil
+	return a.CheckPermissionCtx(context.Background(), userID, permName)
+}
+
+// CheckPermissionCtx is the context-aware variant of CheckPermission
+func (a *Authority) CheckPermissionCtx(ctx context.Context, userID uint, permName string) (bool, error) {
+	return a.checkPermission(ctx, userKey(userID), permName, "")
+}
+
+func (a *Authority) checkPermission(ctx context.Context, user string, permName string, tenant string) (
+	granted bool, err error) {
+	c := &checkLog{kind: CheckKindPermission, user: user, tenant: tenant, name: permName, start: time.Now()}
+	defer func() { c.granted, c.err = granted, err; a.logCheck(ctx, c) }()
+	defer a.measureCheck(CheckKindPermission, permName, c.start, &granted, &err)
+
+	// the request may have found the role permission already, the condition is evaluated on every check since
+	// it depends on the context
+	key := cacheKey("check_permission", tenant, user, permName)
+	grant, ok := memoized(ctx, key)
+	if !ok {
+		var release func()
+		if release, err = a.checks.acquire(ctx); err != nil {
+			return false, err
 		}
+		defer release()
 
+		if grant, err = a.cachedGrant(ctx, user, permName, tenant, c); err != nil {
+			return false, err
+		}
+		memoize(ctx, key, grant)
+	}
+
+	// a conditional permission is granted only when its condition holds for the attributes of the context
+	granted = grant.Granted
+	if granted && grant.Condition != "" {
+		if granted, err = a.conditions.eval(ctx, grant.Condition, user); err != nil {
+			return false, err
+		}
+	}
+
+	if err = a.auditCheck(ctx, user, permName, tenant, granted); err != nil {
 		return false, err
 	}
 
-	//prepare an array of role ids
-	var roleIDs []uint
-	for _, r := range userRoles {
-		roleIDs = append(roleIDs, r.RoleID)
+	if !granted {
+		a.bursts.denied(ctx, a, user, permName)
+	}
+
+	return granted, nil
+}
+
+// permissionGrant is whether the roles of a user grant a permission, the fields are exported for the cache
+type permissionGrant struct {
+	Granted   bool
+	Condition string
+}
+
+// cachedGrant is checkGrant with the cache of the check results, the loads are counted on the log of the check
+func (a *Authority) cachedGrant(ctx context.Context, user string, permName string, tenant string, c *checkLog) (
+	permissionGrant, error) {
+	key := cacheKey("check_permission", tenant, user, permName)
+	return cachedStale(ctx, a, key, a.stale.checkPolicy(permName), func(ctx context.Context) (permissionGrant, error) {
+		c.loaded()
+		return a.checkGrant(ctx, user, permName, tenant)
+	})
+}
+
+// checkGrant checks whether the roles of a user grant a permission and no denial overrides them, with a single
+// query when the store is a PermissionChecker
+func (a *Authority) checkGrant(ctx context.Context, user string, permName string, tenant string) (
+	permissionGrant, error) {
+	if checker, ok := a.store.(PermissionChecker); ok {
+		granted, condition, err := checker.CheckUserPermission(ctx, user, permName, tenant, a.now())
+		return permissionGrant{Granted: granted, Condition: condition}, err
 	}
 
 	// find the permission
-	var perm *Permission
-	if perm, err = a.getPermission(permName); err != nil {
-		return false, err
+	perm, err := a.getTenantPermission(ctx, permName, tenant)
+	if err != nil {
+		return permissionGrant{}, err
+	}
+
+	// the user role
+	var roleIDs []uint
+	if roleIDs, err = a.userRoleIDs(ctx, user, tenant); err != nil {
+		return permissionGrant{}, err
 	}
 
-	// find the role permission
-	var rolePermission RolePermission
-	if err = a.DB.NewSelect().Model(&rolePermission).ModelTableExpr(a.TableRolePerm).
-		Where("role_id IN (?)", bun.In(roleIDs)).Where("permission_id = ?", perm.ID).
-		Scan(ctx); err != nil {
-		return false, nil
+	// include the roles of composite roles
+	if roleIDs, err = a.grantingRoles(ctx, roleIDs); err != nil {
+		return permissionGrant{}, err
 	}
 
-	return true, nil
+	// the super role grants every permission unconditionally
+	var super bool
+	if super, err = a.holdsSuperRole(ctx, roleIDs, tenant); err != nil || super {
+		return permissionGrant{Granted: super}, err
+	}
+
+	granted, err := a.store.RolesHavePermission(ctx, roleIDs, perm.ID, a.now())
+	if err != nil || !granted {
+		return permissionGrant{Condition: perm.Condition}, err
+	}
+
+	// a denial to the user or to one of its roles overrides the grant
+	denied, err := a.store.IsPermissionDenied(ctx, perm.ID, user, tenant, roleIDs)
+
+	return permissionGrant{Granted: !denied, Condition: perm.Condition}, err
 }
 
 // CheckRolePermission checks if a role has the permission assigned it accepts the role as the first parameter
 // it accepts the permission as the second parameter it returns an error if the role is not present in database
-// it returns an error if the permission is not present in database
+// it returns an error if the permission is not present in database, a denial of the permission to the role
+// takes precedence
 func (a *Authority) CheckRolePermission(roleName string, permName string) (bool, error) {
-	var err error
+	return a.CheckRolePermissionCtx(context.Background(), roleName, permName)
+}
+
+// CheckRolePermissionCtx is the context-aware variant of CheckRolePermission
+func (a *Authority) CheckRolePermissionCtx(ctx context.Context, roleName string, permName string) (bool, error) {
+	return a.checkRolePermission(ctx, roleName, permName, "")
+}
+
+func (a *Authority) checkRolePermission(ctx context.Context, roleName string, permName string, tenant string) (
+	bool, error) {
+	release, err := a.checks.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer release()
 
 	// find the role
 	var role *Role
-	if role, err = a.getRole(roleName); err != nil {
+	if role, err = a.getTenantRole(ctx, roleName, tenant); err != nil {
 		return false, err
 	}
 
 	// find the permission
 	var perm *Permission
-	if perm, err = a.getPermission(permName); err != nil {
+	if perm, err = a.getTenantPermission(ctx, permName, tenant); err != nil {
 		return false, err
 	}
 
-	// find the rolePermission
-	if _, err = a.getRolePermission(role.ID, perm.ID); err != nil {
-		if errors.Is(err, ErrRolePermissionNotFound) {
-			return false, nil
-		}
+	// include the roles of a composite role
+	var roleIDs []uint
+	if roleIDs, err = a.grantingRoles(ctx, []uint{role.ID}); err != nil {
+		return false, err
+	}
+
+	var super bool
+	if super, err = a.holdsSuperRole(ctx, roleIDs, tenant); err != nil || super {
+		return super, err
+	}
 
+	// find the rolePermission
+	var granted bool
+	if granted, err = a.store.RolesHavePermission(ctx, roleIDs, perm.ID, a.now()); err != nil || !granted {
 		return false, err
 	}
 
-	return true, nil
+	// a denial to the role or to the roles it includes overrides the grant
+	denied, err := a.store.IsPermissionDenied(ctx, perm.ID, "", tenant, roleIDs)
+
+	return !denied, err
 }
 
 // RevokeRole revokes a user's role
 // it returns a error in case of any
 func (a *Authority) RevokeRole(userID uint, roleName string) error {
+	return a.RevokeRoleCtx(context.Background(), userID, roleName)
+}
+
+// RevokeRoleCtx is the context-aware variant of RevokeRole
+func (a *Authority) RevokeRoleCtx(ctx context.Context, userID uint, roleName string) error {
+	return a.revokeRole(ctx, userKey(userID), roleName, "")
+}
+
+func (a *Authority) revokeRole(ctx context.Context, user string, roleName string, tenant string) error {
 	var err error
-	ctx := context.Background()
 
 	// find the role
 	var role *Role
-	if role, err = a.getRole(roleName); err != nil {
+	if role, err = a.getTenantRole(ctx, roleName, tenant); err != nil {
+		return err
+	}
+
+	entry := AuditEntry{Action: AuditRoleRevoked, UserKey: user, Role: roleName, Tenant: tenant}
+	if err = a.validate(ctx, entry); err != nil {
 		return err
 	}
 
 	// revoke the role
-	_, err = a.DB.NewDelete().Model((*UserRole)(nil)).ModelTableExpr(a.TableUserRole).
-		Where("user_id = ?", userID).Where("role_id = ?", role.ID).Exec(ctx)
+	if err = a.store.RevokeRole(ctx, user, role.ID, tenant); err != nil {
+		return err
+	}
 
-	return err
+	return a.changed(ctx, entry)
 }
 
 // RevokePermission revokes a permission from the user's assigned role
 // it returns an error in case of any
 func (a *Authority) RevokePermission(userID uint, permName string) error {
+	return a.RevokePermissionCtx(context.Background(), userID, permName)
+}
+
+// RevokePermissionCtx is the context-aware variant of RevokePermission
+func (a *Authority) RevokePermissionCtx(ctx context.Context, userID uint, permName string) error {
+	// the permission is revoked from all the roles or none
+	return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		return tx.revokePermission(ctx, userID, permName)
+	})
+}
+
+func (a *Authority) revokePermission(ctx context.Context, userID uint, permName string) error {
 	var err error
-	ctx := context.Background()
 	// revoke the permission from all roles of the user find the user roles
 	var userRoles []UserRole
-	if err = a.DB.NewSelect().Model(&userRoles).ModelTableExpr(a.TableUserRole).
-		Where("user_id = ?", userID).Scan(ctx); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil
-		}
-
+	if userRoles, err = a.store.GetUserRoles(ctx, userKey(userID)); err != nil {
 		return err
 	}
 
 	// find the permission
 	var perm *Permission
-	if perm, err = a.getPermission(permName); err != nil {
+	if perm, err = a.getPermission(ctx, permName); err != nil {
 		return err
 	}
 
 	for _, r := range userRoles {
+		var roles []Role
+		if roles, err = a.store.GetRolesByID(ctx, []uint{r.RoleID}); err != nil {
+			return err
+		}
+
+		for _, role := range roles {
+			if err = a.validate(ctx, AuditEntry{
+				Action: AuditPermissionRevoked, Role: role.Name, Permission: permName,
+			}); err != nil {
+				return err
+			}
+		}
+
 		// revoke the permission
-		if _, err = a.DB.NewDelete().Model((*RolePermission)(nil)).ModelTableExpr(a.TableRolePerm).
-			Where("role_id = ?", r.RoleID).Where("permission_id = ?", perm.ID).Exec(ctx); err != nil {
+		if err = a.store.RevokePermission(ctx, r.RoleID, perm.ID); err != nil {
 			return err
 		}
+
+		for _, role := range roles {
+			if err = a.changed(ctx, AuditEntry{Action: AuditPermissionRevoked, Role: role.Name, Permission: permName}); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -316,32 +843,47 @@ func (a *Authority) RevokePermission(userID uint, permName string) error {
 // RevokeRolePermission revokes a permission from a given role
 // it returns an error in case of any
 func (a *Authority) RevokeRolePermission(roleName string, permName string) error {
+	return a.RevokeRolePermissionCtx(context.Background(), roleName, permName)
+}
+
+// RevokeRolePermissionCtx is the context-aware variant of RevokeRolePermission
+func (a *Authority) RevokeRolePermissionCtx(ctx context.Context, roleName string, permName string) error {
 	var err error
-	ctx := context.Background()
 
 	// find the role
 	var role *Role
-	if role, err = a.getRole(roleName); err != nil {
+	if role, err = a.getRole(ctx, roleName); err != nil {
 		return err
 	}
 
 	// find the permission
 	var perm *Permission
-	if perm, err = a.getPermission(permName); err != nil {
+	if perm, err = a.getPermission(ctx, permName); err != nil {
+		return err
+	}
+
+	entry := AuditEntry{Action: AuditPermissionRevoked, Role: roleName, Permission: permName}
+	if err = a.validate(ctx, entry); err != nil {
 		return err
 	}
 
 	// revoke the permission
-	_, err = a.DB.NewDelete().Model((*RolePermission)(nil)).ModelTableExpr(a.TableRolePerm).
-		Where("role_id = ?", role.ID).Where("permission_id = ?", perm.ID).Exec(ctx)
+	if err = a.store.RevokePermission(ctx, role.ID, perm.ID); err != nil {
+		return err
+	}
 
-	return nil
+	return a.changed(ctx, entry)
 }
 
 // GetRoles returns all stored roles
 func (a *Authority) GetRoles() ([]string, error) {
-	var roles []Role
-	if err := a.DB.NewSelect().Model(&roles).ModelTableExpr(a.TableRole).Scan(context.Background()); err != nil {
+	return a.GetRolesCtx(context.Background())
+}
+
+// GetRolesCtx is the context-aware variant of GetRoles
+func (a *Authority) GetRolesCtx(ctx context.Context) ([]string, error) {
+	roles, err := a.store.ListRoles(ctx)
+	if err != nil {
 		return nil, err
 	}
 
@@ -353,33 +895,248 @@ func (a *Authority) GetRoles() ([]string, error) {
 	return result, nil
 }
 
+// GetRolesDetailed is like GetRoles but returns the stored roles, including their ids and titles
+func (a *Authority) GetRolesDetailed() ([]Role, error) {
+	return a.GetRolesDetailedCtx(context.Background())
+}
+
+// GetRolesDetailedCtx is the context-aware variant of GetRolesDetailed
+func (a *Authority) GetRolesDetailedCtx(ctx context.Context) ([]Role, error) {
+	return a.store.ListRoles(ctx)
+}
+
+// GetRole returns the stored global role with the given name or ErrRoleNotFound
+func (a *Authority) GetRole(roleName string) (*Role, error) {
+	return a.GetRoleCtx(context.Background(), roleName)
+}
+
+// GetRoleCtx is the context-aware variant of GetRole
+func (a *Authority) GetRoleCtx(ctx context.Context, roleName string) (*Role, error) {
+	return a.getRole(ctx, roleName)
+}
+
+// GetRoleByID returns the stored role with the given id or ErrRoleNotFound
+func (a *Authority) GetRoleByID(roleID uint) (*Role, error) {
+	return a.GetRoleByIDCtx(context.Background(), roleID)
+}
+
+// GetRoleByIDCtx is the context-aware variant of GetRoleByID
+func (a *Authority) GetRoleByIDCtx(ctx context.Context, roleID uint) (*Role, error) {
+	roles, err := a.store.GetRolesByID(ctx, []uint{roleID})
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		return nil, fmt.Errorf("%w: id %d", ErrRoleNotFound, roleID)
+	}
+
+	return &roles[0], nil
+}
+
+// GetRoleUsers returns a page of the ids of the users the role is directly assigned to, e.g. to find who has
+// the admin role. the users identified by a string are listed by GetRoleUserKeys
+func (a *Authority) GetRoleUsers(roleName string, page Page) ([]uint, error) {
+	return a.GetRoleUsersCtx(context.Background(), roleName, page)
+}
+
+// GetRoleUsersCtx is the context-aware variant of GetRoleUsers
+func (a *Authority) GetRoleUsersCtx(ctx context.Context, roleName string, page Page) ([]uint, error) {
+	users, err := a.roleUsers(ctx, roleName, "", true, page)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]uint, 0, len(users))
+	for _, user := range users {
+		result = append(result, userIDOf(user))
+	}
+
+	return result, nil
+}
+
+// GetRoleUserKeys is like GetRoleUsers but returns the keys of all the users, including the ones
+// identified by a string
+func (a *Authority) GetRoleUserKeys(roleName string, page Page) ([]string, error) {
+	return a.GetRoleUserKeysCtx(context.Background(), roleName, page)
+}
+
+// GetRoleUserKeysCtx is the context-aware variant of GetRoleUserKeys
+func (a *Authority) GetRoleUserKeysCtx(ctx context.Context, roleName string, page Page) ([]string, error) {
+	return a.roleUsers(ctx, roleName, "", false, page)
+}
+
+func (a *Authority) roleUsers(ctx context.Context, roleName string, tenant string, numeric bool, page Page) (
+	[]string, error) {
+	role, err := a.getTenantRole(ctx, roleName, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.store.GetRoleUsers(ctx, role.ID, tenant, a.now(), numeric, page)
+}
+
 // GetUserRoles returns all user assigned roles
 func (a *Authority) GetUserRoles(userID uint) ([]string, error) {
-	ctx := context.Background()
-	var userRoles []UserRole
-	if err := a.DB.NewSelect().Model(&userRoles).ModelTableExpr(a.TableUserRole).
-		Where("user_id = ?", userID).Scan(ctx); err != nil {
+	return a.GetUserRolesCtx(context.Background(), userID)
+}
+
+// GetUserRolesCtx is the context-aware variant of GetUserRoles
+func (a *Authority) GetUserRolesCtx(ctx context.Context, userID uint) ([]string, error) {
+	return a.getUserRoles(ctx, userKey(userID), "")
+}
+
+// GetUserRolesFull is like GetUserRoles but returns the stored roles, including their titles
+func (a *Authority) GetUserRolesFull(userID uint) ([]Role, error) {
+	return a.GetUserRolesFullCtx(context.Background(), userID)
+}
+
+// GetUserRolesFullCtx is the context-aware variant of GetUserRolesFull
+func (a *Authority) GetUserRolesFullCtx(ctx context.Context, userID uint) ([]Role, error) {
+	return a.store.GetAssignedRoles(ctx, userKey(userID), "", a.now())
+}
+
+func (a *Authority) getUserRoles(ctx context.Context, user string, tenant string) ([]string, error) {
+	roles, err := a.store.GetAssignedRoles(ctx, user, tenant, a.now())
+	if err != nil {
 		return nil, err
 	}
 
-	result := make([]string, 0, len(userRoles))
-	for _, r := range userRoles {
-		var role Role
-		// for every user role get the role name
-		if err := a.DB.NewSelect().Model(&role).ModelTableExpr(a.TableRole).
-			Where("id = ?", r.RoleID).Scan(ctx); err == nil {
-			result = append(result, role.Name)
+	result := make([]string, 0, len(roles))
+	for _, role := range roles {
+		result = append(result, role.Name)
+	}
+
+	return result, nil
+}
+
+// GetUserPermissions returns the names of the permissions a user has through all of its roles,
+// including the composite and inherited roles. every permission is listed once and the permissions denied to the
+// user or to its roles are left out, the holders of Options.SuperRole have every permission
+func (a *Authority) GetUserPermissions(userID uint) ([]string, error) {
+	return a.GetUserPermissionsCtx(context.Background(), userID)
+}
+
+// GetUserPermissionsCtx is the context-aware variant of GetUserPermissions
+func (a *Authority) GetUserPermissionsCtx(ctx context.Context, userID uint) ([]string, error) {
+	perms, err := a.userPermissions(ctx, userKey(userID), "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(perms))
+	for _, perm := range perms {
+		result = append(result, perm.Name)
+	}
+
+	return result, nil
+}
+
+// GetUserPermissionDetails is like GetUserPermissions but returns the stored permissions
+func (a *Authority) GetUserPermissionDetails(userID uint) ([]Permission, error) {
+	return a.GetUserPermissionDetailsCtx(context.Background(), userID)
+}
+
+// GetUserPermissionDetailsCtx is the context-aware variant of GetUserPermissionDetails
+func (a *Authority) GetUserPermissionDetailsCtx(ctx context.Context, userID uint) ([]Permission, error) {
+	return a.userPermissions(ctx, userKey(userID), "")
+}
+
+// userPermissions returns the permissions of the roles a user has in the tenant sorted by name, without the
+// permissions denied to the user or to its roles. the holders of the super role have every permission
+func (a *Authority) userPermissions(ctx context.Context, user string, tenant string) ([]Permission, error) {
+	var err error
+
+	var roleIDs []uint
+	if roleIDs, err = a.userRoleIDs(ctx, user, tenant); err != nil {
+		return nil, err
+	}
+
+	if roleIDs, err = a.grantingRoles(ctx, roleIDs); err != nil {
+		return nil, err
+	}
+
+	// the super role has every permission, whatever the denials, like in the checks
+	var super bool
+	if super, err = a.holdsSuperRole(ctx, roleIDs, tenant); err != nil {
+		return nil, err
+	}
+	if super {
+		return a.tenantPermissions(ctx, tenant)
+	}
+
+	var rolePerms []RolePermission
+	if rolePerms, err = a.activeRolePermissions(ctx, roleIDs); err != nil {
+		return nil, err
+	}
+
+	// many roles may have the same permission
+	seen := make(map[uint]bool, len(rolePerms))
+	permIDs := make([]uint, 0, len(rolePerms))
+	for _, rp := range rolePerms {
+		if !seen[rp.PermissionID] {
+			seen[rp.PermissionID] = true
+			permIDs = append(permIDs, rp.PermissionID)
+		}
+	}
+
+	var perms []Permission
+	if perms, err = a.store.GetPermissionsByID(ctx, permIDs); err != nil {
+		return nil, err
+	}
+
+	// a denial to the user or to one of its roles overrides the grants, like in the checks
+	n := 0
+	for _, perm := range perms {
+		var denied bool
+		if denied, err = a.store.IsPermissionDenied(ctx, perm.ID, user, tenant, roleIDs); err != nil {
+			return nil, err
+		}
+
+		if !denied {
+			perms[n] = perm
+			n++
+		}
+	}
+	perms = perms[:n]
+
+	sort.Slice(perms, func(i, j int) bool { return perms[i].Name < perms[j].Name })
+
+	return perms, nil
+}
+
+// tenantPermissions returns the permissions visible in the tenant sorted by name, a permission defined in the
+// tenant takes the place of the global one with the same name
+func (a *Authority) tenantPermissions(ctx context.Context, tenant string) ([]Permission, error) {
+	perms, err := a.store.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]Permission, len(perms))
+	for _, perm := range perms {
+		if perm.Tenant == tenant || perm.Tenant == "" && byName[perm.Name].Tenant == "" {
+			byName[perm.Name] = perm
 		}
 	}
 
+	result := make([]Permission, 0, len(byName))
+	for _, perm := range byName {
+		result = append(result, perm)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
 	return result, nil
 }
 
 // GetPermissions returns all stored permissions
 func (a *Authority) GetPermissions() ([]string, error) {
-	var perms []Permission
-	if err := a.DB.NewSelect().Model(&perms).ModelTableExpr(a.TablePerm).
-		Scan(context.Background()); err != nil {
+	return a.GetPermissionsCtx(context.Background())
+}
+
+// GetPermissionsCtx is the context-aware variant of GetPermissions
+func (a *Authority) GetPermissionsCtx(ctx context.Context) ([]string, error) {
+	perms, err := a.store.ListPermissions(ctx)
+	if err != nil {
 		return nil, err
 	}
 
@@ -391,151 +1148,320 @@ func (a *Authority) GetPermissions() ([]string, error) {
 	return result, nil
 }
 
+// GetPermissionsDetailed is like GetPermissions but returns the stored permissions, including their ids, titles
+// and conditions
+func (a *Authority) GetPermissionsDetailed() ([]Permission, error) {
+	return a.GetPermissionsDetailedCtx(context.Background())
+}
+
+// GetPermissionsDetailedCtx is the context-aware variant of GetPermissionsDetailed
+func (a *Authority) GetPermissionsDetailedCtx(ctx context.Context) ([]Permission, error) {
+	return a.store.ListPermissions(ctx)
+}
+
+// GetPermission returns the stored global permission with the given name or ErrPermissionNotFound
+func (a *Authority) GetPermission(permName string) (*Permission, error) {
+	return a.GetPermissionCtx(context.Background(), permName)
+}
+
+// GetPermissionCtx is the context-aware variant of GetPermission
+func (a *Authority) GetPermissionCtx(ctx context.Context, permName string) (*Permission, error) {
+	return a.getPermission(ctx, permName)
+}
+
+// GetPermissionByID returns the stored permission with the given id or ErrPermissionNotFound
+func (a *Authority) GetPermissionByID(permID uint) (*Permission, error) {
+	return a.GetPermissionByIDCtx(context.Background(), permID)
+}
+
+// GetPermissionByIDCtx is the context-aware variant of GetPermissionByID
+func (a *Authority) GetPermissionByIDCtx(ctx context.Context, permID uint) (*Permission, error) {
+	perms, err := a.store.GetPermissionsByID(ctx, []uint{permID})
+	if err != nil {
+		return nil, err
+	}
+	if len(perms) == 0 {
+		return nil, fmt.Errorf("%w: id %d", ErrPermissionNotFound, permID)
+	}
+
+	return &perms[0], nil
+}
+
 // DeleteRole deletes a given role
 // if the role is assigned to a user it returns an error
 func (a *Authority) DeleteRole(roleName string) error {
+	return a.DeleteRoleCtx(context.Background(), roleName)
+}
+
+// DeleteRoleCtx is the context-aware variant of DeleteRole
+func (a *Authority) DeleteRoleCtx(ctx context.Context, roleName string) error {
+	// the permissions of the role are revoked only when it is deleted
+	return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		return tx.deleteRole(ctx, roleName)
+	})
+}
+
+func (a *Authority) deleteRole(ctx context.Context, roleName string) error {
 	var err error
-	ctx := context.Background()
 
 	// find the role
 	var role *Role
-	if role, err = a.getRole(roleName); err != nil {
+	if role, err = a.getRole(ctx, roleName); err != nil {
 		return err
 	}
 
 	// check if the role is assigned to a user
-	var userRole UserRole
-	if err = a.DB.NewSelect().Model(&userRole).ModelTableExpr(a.TableUserRole).
-		Where("role_id = ?", role.ID).Scan(ctx); err == nil {
-		// role is assigned
+	var assigned bool
+	if assigned, err = a.store.RoleAssigned(ctx, role.ID); err != nil {
+		return err
+	}
+
+	if assigned {
 		return ErrRoleInUse
 	}
 
+	entry := AuditEntry{Action: AuditRoleDeleted, Role: roleName}
+	if err = a.validate(ctx, entry); err != nil {
+		return err
+	}
+
 	// revoke the assignment of permissions before deleting the role
-	if _, err = a.DB.NewSelect().Model((*RolePermission)(nil)).ModelTableExpr(a.TableRolePerm).
-		Where("role_id = ?", role.ID).Exec(ctx); err != nil {
+	if err = a.store.RevokeRolePermissions(ctx, role.ID); err != nil {
 		return err
 	}
 
 	// delete the role
-	if _, err = a.DB.NewDelete().Model((*Role)(nil)).ModelTableExpr(a.TableRole).
-		Where("name = ?", roleName).Exec(ctx); err != nil {
+	if err = a.store.DeleteRole(ctx, role.ID); err != nil {
 		return err
 	}
 
-	return nil
+	return a.changed(ctx, entry)
 }
 
 // DeletePermission deletes a given permission
 // if the permission is assigned to a role it returns an error
 func (a *Authority) DeletePermission(permName string) error {
+	return a.DeletePermissionCtx(context.Background(), permName)
+}
+
+// DeletePermissionCtx is the context-aware variant of DeletePermission
+func (a *Authority) DeletePermissionCtx(ctx context.Context, permName string) error {
+	// the permission is deleted only when it is not assigned
+	return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		return tx.deletePermission(ctx, permName)
+	})
+}
+
+func (a *Authority) deletePermission(ctx context.Context, permName string) error {
 	var err error
-	ctx := context.Background()
 
 	// find the permission
 	var perm *Permission
-	if perm, err = a.getPermission(permName); err != nil {
+	if perm, err = a.getPermission(ctx, permName); err != nil {
 		return err
 	}
 
 	// check if the permission is assigned to a role
-	var rolePermission RolePermission
-	if err = a.DB.NewSelect().Model(&rolePermission).ModelTableExpr(a.TableRolePerm).
-		Where("permission_id = ?", perm.ID).Scan(ctx); err == nil {
-		// role is assigned
+	var assigned bool
+	if assigned, err = a.store.PermissionAssigned(ctx, perm.ID); err != nil {
+		return err
+	}
+
+	if assigned {
 		return ErrPermissionInUse
 	}
 
+	entry := AuditEntry{Action: AuditPermissionDeleted, Permission: permName}
+	if err = a.validate(ctx, entry); err != nil {
+		return err
+	}
+
 	// delete the permission
-	if _, err = a.DB.NewDelete().Model((*Permission)(nil)).ModelTableExpr(a.TablePerm).
-		Where("name = ?", permName).Exec(ctx); err != nil {
+	if err = a.store.DeletePermission(ctx, perm.ID); err != nil {
 		return err
 	}
 
-	return nil
+	return a.changed(ctx, entry)
+}
+
+func (a *Authority) getRole(ctx context.Context, roleName string) (*Role, error) {
+	return a.getTenantRole(ctx, roleName, "")
 }
 
-func (a *Authority) getRole(roleName string) (*Role, error) {
-	ctx := context.Background()
-	var role Role
-	if err := a.DB.NewSelect().Model(&role).Where("name = ?", roleName).ModelTableExpr(a.TableRole).Scan(ctx); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrRoleNotFound
+// getTenantRole returns the role defined in the tenant or globally, a missing role is an error wrapping
+// ErrRoleNotFound with its name whatever the store returned, never a role without an id, and the other errors
+// of the store are returned as is
+func (a *Authority) getTenantRole(ctx context.Context, roleName string, tenant string) (*Role, error) {
+	// the callers get a copy of the cached role
+	role, err := cached(ctx, a, cacheKey("role", tenant, roleName), func() (Role, error) {
+		role, err := a.store.GetRole(ctx, roleName, tenant)
+		if err != nil {
+			return Role{}, err
+		}
+		if role == nil {
+			return Role{}, nil
 		}
+		return *role, nil
+	})
+	if errors.Is(err, ErrRoleNotFound) || err == nil && role.ID == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrRoleNotFound, roleName)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	return &role, nil
 }
 
-func (a *Authority) getPermission(permName string) (*Permission, error) {
-	ctx := context.Background()
-	var perm Permission
-	if err := a.DB.NewSelect().Model(&perm).Where("name = ?", permName).
-		ModelTableExpr(a.TablePerm).Scan(ctx); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrPermissionNotFound
+func (a *Authority) getPermission(ctx context.Context, permName string) (*Permission, error) {
+	return a.getTenantPermission(ctx, permName, "")
+}
+
+// getTenantPermission returns the permission defined in the tenant or globally, like getTenantRole a missing
+// permission is an error wrapping ErrPermissionNotFound with its name
+func (a *Authority) getTenantPermission(ctx context.Context, permName string, tenant string) (*Permission, error) {
+	// the callers get a copy of the cached permission
+	perm, err := cached(ctx, a, cacheKey("permission", tenant, permName), func() (Permission, error) {
+		perm, err := a.store.GetPermission(ctx, permName, tenant)
+		if err != nil {
+			return Permission{}, err
+		}
+		if perm == nil {
+			return Permission{}, nil
 		}
+		return *perm, nil
+	})
+	if errors.Is(err, ErrPermissionNotFound) || err == nil && perm.ID == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrPermissionNotFound, permName)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	return &perm, nil
 }
 
-func (a *Authority) getRolePermission(roleID, permID uint) (*RolePermission, error) {
-	var rolePerm RolePermission
-	if err := a.DB.NewSelect().Model(&rolePerm).ModelTableExpr(a.TableRolePerm).
-		Where("role_id = ?", roleID).Where("permission_id =?", permID).
-		Scan(context.Background()); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrRolePermissionNotFound
-		}
-
+// activeRolePermissions returns the permission assignments of the roles in effect now
+func (a *Authority) activeRolePermissions(ctx context.Context, roleIDs []uint) ([]RolePermission, error) {
+	rolePerms, err := a.store.GetRolePermissions(ctx, roleIDs)
+	if err != nil {
 		return nil, err
 	}
 
-	return &rolePerm, nil
+	now := a.now()
+	n := 0
+	for _, rp := range rolePerms {
+		if rp.activeAt(now) {
+			rolePerms[n] = rp
+			n++
+		}
+	}
+
+	return rolePerms[:n], nil
 }
 
-func (a *Authority) getUserRole(userID, roleID uint) (*UserRole, error) {
-	var userRole UserRole
-	if err := a.DB.NewSelect().Model(&userRole).ModelTableExpr(a.TableUserRole).
-		Where("user_id = ?", userID).Where("role_id = ?", roleID).
-		Scan(context.Background()); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, ErrUserRoleNotFound
+// userRoleIDs returns the ids of the roles assigned to a user in the tenant, the global assignments apply
+// in every tenant, the scheduled ones once they start and the time-bound ones until they expire.
+// the roles of the groups of the user and the default roles are included
+func (a *Authority) userRoleIDs(ctx context.Context, user string, tenant string) ([]uint, error) {
+	userRoles, err := a.store.GetUserRoles(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	now := a.now()
+	roleIDs := make([]uint, 0, len(userRoles)+len(a.defaultRoles))
+	for _, r := range userRoles {
+		if (r.Tenant == "" || r.Tenant == tenant) && r.activeAt(now) {
+			roleIDs = append(roleIDs, r.RoleID)
 		}
+	}
+
+	// the members of the groups have their roles
+	groupRoleIDs, err := a.store.GetUserGroupRoleIDs(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	roleIDs = append(roleIDs, groupRoleIDs...)
+
+	// every user has the default roles
+	defaults, err := a.defaultRoleIDs(ctx, tenant)
+	if err != nil {
 		return nil, err
 	}
+	roleIDs = append(roleIDs, defaults...)
 
-	return &userRole, nil
+	// and the roles granted to the segments of the users it belongs to
+	segments, err := a.segmentRoleIDs(ctx, user, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(roleIDs, segments...), nil
 }
 
-func migrateTables(opts *Options) error {
-	ctx := context.Background()
+// expandRoles returns the given role ids followed by the ids of all the roles they include as composite roles
+// and all the roles they inherit from through the hierarchy
+func (a *Authority) expandRoles(ctx context.Context, roleIDs []uint) ([]uint, error) {
+	return a.walkRoles(ctx, roleIDs, true, false)
+}
 
-	if _, err := opts.DB.NewCreateTable().IfNotExists().Model((*Role)(nil)).
-		ModelTableExpr(opts.TablesPrefix + "roles").Exec(ctx); err != nil {
-		return err
+// walkRoles returns the given role ids followed by the ids of the roles they include as composite roles,
+// the parents of the roles are followed as well when inherit is set and only the active roles when active is set
+func (a *Authority) walkRoles(ctx context.Context, roleIDs []uint, inherit bool, active bool) ([]uint, error) {
+	seen := make(map[uint]bool, len(roleIDs))
+	result := make([]uint, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
 	}
 
-	if _, err := opts.DB.NewCreateTable().IfNotExists().Model((*Permission)(nil)).
-		ModelTableExpr(opts.TablesPrefix + "permissions").Exec(ctx); err != nil {
-		return err
+	if active {
+		var err error
+		if result, err = a.activeRoleIDs(ctx, result); err != nil {
+			return nil, err
+		}
 	}
 
-	roleFk1 := fmt.Sprintf(`("role_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, opts.TablesPrefix+"roles")
-	roleFk2 := fmt.Sprintf(`("permission_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, opts.TablesPrefix+"permissions")
-	if _, err := opts.DB.NewCreateTable().IfNotExists().Model((*RolePermission)(nil)).
-		ModelTableExpr(opts.TablesPrefix + "role_permissions").
-		ForeignKey(roleFk1).ForeignKey(roleFk2).Exec(ctx); err != nil {
-		return err
-	}
+	frontier := result
+	for len(frontier) > 0 {
+		composites, err := a.store.GetRoleComposites(ctx, frontier)
+		if err != nil {
+			return nil, err
+		}
 
-	userFk1 := fmt.Sprintf(`("role_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, opts.TablesPrefix+"roles")
-	if _, err := opts.DB.NewCreateTable().IfNotExists().Model((*UserRole)(nil)).
-		ModelTableExpr(opts.TablesPrefix + "user_roles").
-		ForeignKey(userFk1).Exec(ctx); err != nil {
-		return err
+		var parents []RoleParent
+		if inherit {
+			if parents, err = a.store.GetRoleParents(ctx, frontier); err != nil {
+				return nil, err
+			}
+		}
+
+		next := make([]uint, 0, len(composites)+len(parents))
+		for _, c := range composites {
+			next = append(next, c.MemberID)
+		}
+		for _, p := range parents {
+			next = append(next, p.ParentID)
+		}
+
+		frontier = nil
+		for _, id := range next {
+			if !seen[id] {
+				seen[id] = true
+				frontier = append(frontier, id)
+			}
+		}
+
+		if active {
+			if frontier, err = a.activeRoleIDs(ctx, frontier); err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, frontier...)
 	}
 
-	return nil
+	return result, nil
 }
@@ -0,0 +1,82 @@
+package authority_test
+
+import (
+	"testing"
+
+	"authority"
+	"authority/authoritytest"
+)
+
+// newAuthority returns an instance using a memory store, the options may set everything but the store
+func newAuthority(t *testing.T, opts authority.Options) *authority.Authority {
+	t.Helper()
+
+	opts.Store = authoritytest.NewMemoryStore()
+	a, err := authority.NewE(opts)
+	if err != nil {
+		t.Fatalf("NewE: %v", err)
+	}
+
+	return a
+}
+
+// must fails the test on an error
+func must(t *testing.T, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupRole creates a role with the permissions, creating the missing permissions
+func setupRole(t *testing.T, a *authority.Authority, roleName string, permNames ...string) {
+	t.Helper()
+
+	must(t, a.CreateRole(roleName))
+	for _, permName := range permNames {
+		must(t, a.CreatePermission(permName))
+	}
+	must(t, a.AssignPermissions(roleName, permNames))
+}
+
+// equalStrings reports whether the slices have the same strings in the same order
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestCheckPermission(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	setupRole(t, a, "editor", "articles.read", "articles.write")
+	must(t, a.CreatePermission("articles.delete"))
+	must(t, a.AssignRole(1, "editor"))
+
+	for _, tt := range []struct {
+		perm string
+		want bool
+	}{
+		{"articles.read", true},
+		{"articles.write", true},
+		{"articles.delete", false},
+	} {
+		got, err := a.CheckPermission(1, tt.perm)
+		must(t, err)
+		if got != tt.want {
+			t.Errorf("CheckPermission(1, %q) = %v, want %v", tt.perm, got, tt.want)
+		}
+	}
+
+	if _, err := a.CheckPermission(1, "missing"); err == nil {
+		t.Error("CheckPermission of a missing permission: want an error")
+	}
+}
@@ -0,0 +1,166 @@
+// Package authoritypb provides the protobuf types of the policy snapshots and the audit events of authority,
+// so services written in other languages can consume the exports and the streams of events, and the grpc
+// service serving the instances to them with its JSON gateway over http
+package authoritypb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative authority.proto service.proto
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"time"
+
+	"authority"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// FromRole converts a role
+func FromRole(r authority.Role) *Role {
+	return &Role{Id: uint64(r.ID), Name: r.Name, Title: r.Title, Tenant: r.Tenant}
+}
+
+// FromPermission converts a permission
+func FromPermission(p authority.Permission) *Permission {
+	return &Permission{Id: uint64(p.ID), Name: p.Name, Title: p.Title, Tenant: p.Tenant, Condition: p.Condition}
+}
+
+// FromRolePermission converts the assignment of a permission to a role
+func FromRolePermission(rp authority.RolePermission) *RolePermission {
+	return &RolePermission{
+		RoleId: uint64(rp.RoleID), PermissionId: uint64(rp.PermissionID),
+		StartsAt: timestamp(rp.StartsAt), ExpiresAt: timestamp(rp.ExpiresAt),
+	}
+}
+
+// FromUserRole converts the assignment of a role to a user
+func FromUserRole(ur authority.UserRole) *UserRole {
+	return &UserRole{
+		UserKey: ur.UserKey, RoleId: uint64(ur.RoleID), Tenant: ur.Tenant,
+		StartsAt: timestamp(ur.StartsAt), ExpiresAt: timestamp(ur.ExpiresAt), CreatedAt: timestamp(ur.CreatedAt),
+		GrantedBy: ur.GrantedBy,
+	}
+}
+
+// FromRoleComposite converts the inclusion of a role in a composite role
+func FromRoleComposite(rc authority.RoleComposite) *RoleComposite {
+	return &RoleComposite{RoleId: uint64(rc.RoleID), MemberId: uint64(rc.MemberID)}
+}
+
+// FromRoleParent converts the parent of a role
+func FromRoleParent(rp authority.RoleParent) *RoleParent {
+	return &RoleParent{RoleId: uint64(rp.RoleID), ParentId: uint64(rp.ParentID)}
+}
+
+// FromPolicy converts a named policy
+func FromPolicy(p authority.Policy) *Policy {
+	return &Policy{Name: p.Name, Expression: p.Expression}
+}
+
+// FromAuditEntry converts an audit entry
+func FromAuditEntry(e authority.AuditEntry) *AuditEvent {
+	return &AuditEvent{
+		Id: uint64(e.ID), Action: e.Action, UserKey: e.UserKey, Role: e.Role, Permission: e.Permission,
+		Detail: e.Detail, Tenant: e.Tenant, Actor: e.Actor, Ip: e.IP, UserAgent: e.UserAgent,
+		RequestId: e.RequestID, CreatedAt: timestamp(e.CreatedAt),
+	}
+}
+
+// FromAccessRequest converts an access request
+func FromAccessRequest(r authority.AccessRequest) *AccessRequest {
+	return &AccessRequest{
+		Id: uint64(r.ID), UserKey: r.UserKey, Role: r.Role, Tenant: r.Tenant, Reason: r.Reason, Status: r.Status,
+		RequestedAt: timestamp(r.RequestedAt), DecidedBy: r.DecidedBy, DecidedAt: timestamp(r.DecidedAt),
+	}
+}
+
+// FromRelationTuple converts a relation tuple
+func FromRelationTuple(t authority.RelationTuple) *RelationTuple {
+	return &RelationTuple{
+		ObjectType: t.ObjectType, ObjectId: t.ObjectID, Relation: t.Relation, SubjectType: t.SubjectType,
+		SubjectId: t.SubjectID, SubjectRelation: t.SubjectRelation,
+	}
+}
+
+// toTuple converts a relation tuple of a request
+func toTuple(t *RelationTuple) authority.RelationTuple {
+	return authority.RelationTuple{
+		ObjectType: t.GetObjectType(), ObjectID: t.GetObjectId(), Relation: t.GetRelation(),
+		SubjectType: t.GetSubjectType(), SubjectID: t.GetSubjectId(), SubjectRelation: t.GetSubjectRelation(),
+	}
+}
+
+// toTuples converts the relation tuples of a request
+func toTuples(tuples []*RelationTuple) []authority.RelationTuple {
+	result := make([]authority.RelationTuple, 0, len(tuples))
+	for _, t := range tuples {
+		result = append(result, toTuple(t))
+	}
+
+	return result
+}
+
+// timestamp converts a time, the zero time is left unset
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
+
+// WriteDelimited writes a message prefixed with its size as a varint, the framing of the streams of events
+// that most protobuf libraries read as delimited messages
+func WriteDelimited(w io.Writer, m proto.Message) error {
+	b, err := proto.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	if _, err = w.Write(protowire.AppendVarint(nil, uint64(len(b)))); err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+
+	return err
+}
+
+// ReadDelimited reads a message written by WriteDelimited, it returns io.EOF at the end of the stream
+func ReadDelimited(r *bufio.Reader, m proto.Message) error {
+	size, err := readVarint(r)
+	if err != nil {
+		return err
+	}
+
+	b := make([]byte, size)
+	if _, err = io.ReadFull(r, b); err != nil {
+		return err
+	}
+
+	return proto.Unmarshal(b, m)
+}
+
+// readVarint reads the size prefix of a message
+func readVarint(r *bufio.Reader) (uint64, error) {
+	var x uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		b, err := r.ReadByte()
+		if err != nil {
+			if shift > 0 && errors.Is(err, io.EOF) {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+
+		x |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return x, nil
+		}
+	}
+
+	return 0, errors.New("authoritypb: size prefix overflows")
+}
@@ -0,0 +1,126 @@
+package authoritypb
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// GatewayPrefix is the path prefix of the RPCs served by NewGateway, e.g. POST /v1/CheckPermission
+const GatewayPrefix = "/v1/"
+
+// maxGatewayBody is the maximum size of the body of a request to the gateway
+const maxGatewayBody = 4 << 20
+
+// NewGateway returns a REST gateway to the service for the clients without grpc: every RPC is served at
+// POST GatewayPrefix + its name with the JSON mapping of protobuf, e.g.
+//
+//	curl -d '{"userKey": "42", "permission": "articles.write"}' localhost:8080/v1/CheckPermission
+//
+// an empty body is an empty request. the errors are answered with the http status of their grpc code and
+// a {"code", "message"} body. srv is usually NewServer, the interceptors are not applied
+func NewGateway(srv AuthorityServiceServer) http.Handler {
+	methods := make(map[string]grpc.MethodDesc, len(AuthorityService_ServiceDesc.Methods))
+	for _, m := range AuthorityService_ServiceDesc.Methods {
+		methods[m.MethodName] = m
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m, ok := methods[strings.TrimPrefix(r.URL.Path, GatewayPrefix)]
+		if !ok || !strings.HasPrefix(r.URL.Path, GatewayPrefix) {
+			writeGatewayError(w, status.Error(codes.NotFound, "unknown method "+r.URL.Path))
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			writeGatewayStatus(w, http.StatusMethodNotAllowed, status.New(codes.Unimplemented,
+				"the methods are served with POST"))
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxGatewayBody))
+		if err != nil {
+			writeGatewayError(w, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+
+		dec := func(in interface{}) error {
+			if len(body) == 0 {
+				return nil
+			}
+			if err := protojson.Unmarshal(body, in.(proto.Message)); err != nil {
+				return status.Error(codes.InvalidArgument, err.Error())
+			}
+			return nil
+		}
+
+		out, err := m.Handler(srv, r.Context(), dec, nil)
+		if err != nil {
+			writeGatewayError(w, err)
+			return
+		}
+
+		var data []byte
+		if data, err = protojson.Marshal(out.(proto.Message)); err != nil {
+			writeGatewayError(w, status.Error(codes.Internal, err.Error()))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	})
+}
+
+// writeGatewayError answers an error with the http status of its grpc code
+func writeGatewayError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	writeGatewayStatus(w, httpStatus(st.Code()), st)
+}
+
+func writeGatewayStatus(w http.ResponseWriter, code int, st *status.Status) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}{st.Code().String(), st.Message()})
+}
+
+// httpStatus maps a grpc code to an http status, like grpc-gateway does
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
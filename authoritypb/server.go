@@ -0,0 +1,532 @@
+package authoritypb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"authority"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// server implements AuthorityServiceServer with an authority instance
+type server struct {
+	UnimplementedAuthorityServiceServer
+
+	a *authority.Authority
+}
+
+// NewServer returns the implementation of the service backed by the instance, register it with
+// RegisterAuthorityServiceServer
+func NewServer(a *authority.Authority) AuthorityServiceServer {
+	return &server{a: a}
+}
+
+func (s *server) CreateRole(ctx context.Context, req *CreateRoleRequest) (*emptypb.Empty, error) {
+	return empty(s.a.CreateRoleInTenantCtx(ctx, req.GetName(), req.GetTenant()))
+}
+
+func (s *server) DeleteRole(ctx context.Context, req *DeleteRoleRequest) (*emptypb.Empty, error) {
+	return empty(s.a.DeleteRoleCtx(ctx, req.GetName()))
+}
+
+func (s *server) ListRoles(ctx context.Context, _ *emptypb.Empty) (*NamesResponse, error) {
+	return names(s.a.GetRolesCtx(ctx))
+}
+
+func (s *server) GetRole(ctx context.Context, req *RoleRequest) (*Role, error) {
+	role, err := s.a.GetRoleCtx(ctx, req.GetRole())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return FromRole(*role), nil
+}
+
+func (s *server) CreatePermission(ctx context.Context, req *CreatePermissionRequest) (*emptypb.Empty, error) {
+	return empty(s.a.CreatePermissionInTenantCtx(ctx, req.GetName(), req.GetTenant()))
+}
+
+func (s *server) DeletePermission(ctx context.Context, req *DeletePermissionRequest) (*emptypb.Empty, error) {
+	return empty(s.a.DeletePermissionCtx(ctx, req.GetName()))
+}
+
+func (s *server) ListPermissions(ctx context.Context, _ *emptypb.Empty) (*NamesResponse, error) {
+	return names(s.a.GetPermissionsCtx(ctx))
+}
+
+func (s *server) GetPermission(ctx context.Context, req *PermissionRequest) (*Permission, error) {
+	perm, err := s.a.GetPermissionCtx(ctx, req.GetPermission())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return FromPermission(*perm), nil
+}
+
+func (s *server) SetPermissionCondition(ctx context.Context, req *SetPermissionConditionRequest) (*emptypb.Empty,
+	error) {
+	return empty(s.a.SetPermissionConditionCtx(ctx, req.GetPermission(), req.GetCondition()))
+}
+
+func (s *server) AssignPermissions(ctx context.Context, req *AssignPermissionsRequest) (*emptypb.Empty, error) {
+	return empty(s.a.AssignPermissionsInTenantCtx(ctx, req.GetRole(), req.GetPermissions(), req.GetTenant()))
+}
+
+func (s *server) RevokeRolePermission(ctx context.Context, req *RevokeRolePermissionRequest) (*emptypb.Empty, error) {
+	return empty(s.a.RevokeRolePermissionCtx(ctx, req.GetRole(), req.GetPermission()))
+}
+
+func (s *server) CheckRolePermission(ctx context.Context, req *CheckRolePermissionRequest) (*CheckResponse, error) {
+	return check(s.a.CheckRolePermissionCtx(ctx, req.GetRole(), req.GetPermission()))
+}
+
+func (s *server) CreateDraftRole(ctx context.Context, req *RoleRequest) (*emptypb.Empty, error) {
+	return empty(s.a.CreateDraftRoleCtx(ctx, req.GetRole()))
+}
+
+func (s *server) ActivateRole(ctx context.Context, req *RoleRequest) (*emptypb.Empty, error) {
+	return empty(s.a.ActivateRoleCtx(ctx, req.GetRole()))
+}
+
+func (s *server) RetireRole(ctx context.Context, req *RoleRequest) (*emptypb.Empty, error) {
+	return empty(s.a.RetireRoleCtx(ctx, req.GetRole()))
+}
+
+func (s *server) GetRoleState(ctx context.Context, req *RoleRequest) (*RoleStateResponse, error) {
+	state, err := s.a.GetRoleStateCtx(ctx, req.GetRole())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &RoleStateResponse{State: state}, nil
+}
+
+func (s *server) AddCompositeRoles(ctx context.Context, req *AddCompositeRolesRequest) (*emptypb.Empty, error) {
+	return empty(s.a.AddCompositeRolesCtx(ctx, req.GetRole(), req.GetMembers()))
+}
+
+func (s *server) RemoveCompositeRole(ctx context.Context, req *RemoveCompositeRoleRequest) (*emptypb.Empty, error) {
+	return empty(s.a.RemoveCompositeRoleCtx(ctx, req.GetRole(), req.GetMember()))
+}
+
+func (s *server) GetCompositeRoles(ctx context.Context, req *RoleRequest) (*NamesResponse, error) {
+	return names(s.a.GetCompositeRolesCtx(ctx, req.GetRole()))
+}
+
+func (s *server) SetRoleParent(ctx context.Context, req *SetRoleParentRequest) (*emptypb.Empty, error) {
+	return empty(s.a.SetRoleParentCtx(ctx, req.GetRole(), req.GetParent()))
+}
+
+func (s *server) RemoveRoleParent(ctx context.Context, req *RoleRequest) (*emptypb.Empty, error) {
+	return empty(s.a.RemoveRoleParentCtx(ctx, req.GetRole()))
+}
+
+func (s *server) GetRoleChildren(ctx context.Context, req *RoleRequest) (*NamesResponse, error) {
+	return names(s.a.GetRoleChildrenCtx(ctx, req.GetRole()))
+}
+
+func (s *server) AssignRole(ctx context.Context, req *AssignRoleRequest) (*emptypb.Empty, error) {
+	user := s.a.User(req.GetUserKey()).InTenant(req.GetTenant())
+
+	switch {
+	case req.StartsAt != nil && req.ExpiresAt != nil:
+		return nil, status.Error(codes.InvalidArgument, "starts_at and expires_at can't be both set")
+	case req.StartsAt != nil:
+		return empty(user.AssignRoleFromCtx(ctx, req.GetRole(), req.GetStartsAt().AsTime()))
+	case req.ExpiresAt != nil:
+		return empty(user.AssignRoleUntilCtx(ctx, req.GetRole(), req.GetExpiresAt().AsTime()))
+	default:
+		return empty(user.AssignRoleCtx(ctx, req.GetRole()))
+	}
+}
+
+func (s *server) RevokeRole(ctx context.Context, req *RevokeRoleRequest) (*emptypb.Empty, error) {
+	return empty(s.a.User(req.GetUserKey()).InTenant(req.GetTenant()).RevokeRoleCtx(ctx, req.GetRole()))
+}
+
+func (s *server) GetUserRoles(ctx context.Context, req *GetUserRolesRequest) (*NamesResponse, error) {
+	return names(s.a.User(req.GetUserKey()).InTenant(req.GetTenant()).GetRolesCtx(ctx))
+}
+
+func (s *server) GetUserPermissions(ctx context.Context, req *GetUserPermissionsRequest) (*NamesResponse, error) {
+	return names(s.a.User(req.GetUserKey()).InTenant(req.GetTenant()).GetPermissionsCtx(ctx))
+}
+
+func (s *server) GetRoleUsers(ctx context.Context, req *GetRoleUsersRequest) (*NamesResponse, error) {
+	page := authority.Page{Offset: int(req.GetOffset()), Limit: int(req.GetLimit())}
+	return names(s.a.GetRoleUserKeysCtx(ctx, req.GetRole(), page))
+}
+
+func (s *server) SweepExpiredAssignments(ctx context.Context, _ *emptypb.Empty) (*CountResponse, error) {
+	n, err := s.a.SweepExpiredAssignmentsCtx(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &CountResponse{Count: int64(n)}, nil
+}
+
+func (s *server) CheckRole(ctx context.Context, req *CheckRoleRequest) (*CheckResponse, error) {
+	return check(s.a.User(req.GetUserKey()).InTenant(req.GetTenant()).CheckRoleCtx(ctx, req.GetRole()))
+}
+
+func (s *server) CheckPermission(ctx context.Context, req *CheckPermissionRequest) (*CheckResponse, error) {
+	ctx = withAttributes(ctx, req.GetAttributes())
+	return check(s.a.User(req.GetUserKey()).InTenant(req.GetTenant()).CheckPermissionCtx(ctx, req.GetPermission()))
+}
+
+func (s *server) CheckPermissions(ctx context.Context, req *CheckPermissionsRequest) (*CheckPermissionsResponse,
+	error) {
+	ctx = withAttributes(ctx, req.GetAttributes())
+	allowed, err := s.a.User(req.GetUserKey()).InTenant(req.GetTenant()).CheckPermissionsCtx(ctx, req.GetPermissions())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &CheckPermissionsResponse{Allowed: allowed}, nil
+}
+
+func (s *server) CheckPermissionDetailed(ctx context.Context, req *CheckPermissionRequest) (*DecisionResponse,
+	error) {
+	ctx = withAttributes(ctx, req.GetAttributes())
+	d, err := s.a.User(req.GetUserKey()).InTenant(req.GetTenant()).CheckPermissionDetailedCtx(ctx, req.GetPermission())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &DecisionResponse{
+		Allowed: d.Granted, Reason: d.Reason, Permission: d.Permission, Tenant: d.Tenant, Role: d.Role,
+		Roles: d.Roles, Condition: d.Condition,
+	}, nil
+}
+
+func (s *server) Can(ctx context.Context, req *CanRequest) (*CheckResponse, error) {
+	return check(s.a.User(req.GetUserKey()).InTenant(req.GetTenant()).CanCtx(ctx, req.GetAction(), req.GetResource()))
+}
+
+func (s *server) CheckPolicy(ctx context.Context, req *CheckPolicyRequest) (*CheckResponse, error) {
+	ctx = withAttributes(ctx, req.GetAttributes())
+	return check(s.a.User(req.GetUserKey()).InTenant(req.GetTenant()).CheckPolicyCtx(ctx, req.GetPolicy()))
+}
+
+func (s *server) DefinePolicy(ctx context.Context, req *DefinePolicyRequest) (*emptypb.Empty, error) {
+	return empty(s.a.DefinePolicyCtx(ctx, req.GetName(), req.GetExpression()))
+}
+
+func (s *server) DeletePolicy(ctx context.Context, req *PolicyRequest) (*emptypb.Empty, error) {
+	return empty(s.a.DeletePolicyCtx(ctx, req.GetName()))
+}
+
+func (s *server) GetPolicyVersion(ctx context.Context, _ *emptypb.Empty) (*PolicyVersionResponse, error) {
+	version, err := s.a.PolicyVersionCtx(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &PolicyVersionResponse{Version: version}, nil
+}
+
+func (s *server) DenyPermission(ctx context.Context, req *UserPermissionRequest) (*emptypb.Empty, error) {
+	return empty(s.a.User(req.GetUserKey()).InTenant(req.GetTenant()).DenyPermissionCtx(ctx, req.GetPermission()))
+}
+
+func (s *server) RevokePermissionDenial(ctx context.Context, req *UserPermissionRequest) (*emptypb.Empty, error) {
+	user := s.a.User(req.GetUserKey()).InTenant(req.GetTenant())
+	return empty(user.RevokePermissionDenialCtx(ctx, req.GetPermission()))
+}
+
+func (s *server) DenyRolePermission(ctx context.Context, req *RolePermissionRequest) (*emptypb.Empty, error) {
+	return empty(s.a.DenyRolePermissionCtx(ctx, req.GetRole(), req.GetPermission()))
+}
+
+func (s *server) RevokeRolePermissionDenial(ctx context.Context, req *RolePermissionRequest) (*emptypb.Empty,
+	error) {
+	return empty(s.a.RevokeRolePermissionDenialCtx(ctx, req.GetRole(), req.GetPermission()))
+}
+
+func (s *server) CreateGroup(ctx context.Context, req *GroupRequest) (*emptypb.Empty, error) {
+	return empty(s.a.CreateGroupCtx(ctx, req.GetGroup()))
+}
+
+func (s *server) DeleteGroup(ctx context.Context, req *GroupRequest) (*emptypb.Empty, error) {
+	return empty(s.a.DeleteGroupCtx(ctx, req.GetGroup()))
+}
+
+func (s *server) AddUserToGroup(ctx context.Context, req *GroupMemberRequest) (*emptypb.Empty, error) {
+	return empty(s.a.User(req.GetUserKey()).AddToGroupCtx(ctx, req.GetGroup()))
+}
+
+func (s *server) RemoveUserFromGroup(ctx context.Context, req *GroupMemberRequest) (*emptypb.Empty, error) {
+	return empty(s.a.User(req.GetUserKey()).RemoveFromGroupCtx(ctx, req.GetGroup()))
+}
+
+func (s *server) AssignRoleToGroup(ctx context.Context, req *GroupRoleRequest) (*emptypb.Empty, error) {
+	return empty(s.a.AssignRoleToGroupCtx(ctx, req.GetGroup(), req.GetRole()))
+}
+
+func (s *server) RevokeRoleFromGroup(ctx context.Context, req *GroupRoleRequest) (*emptypb.Empty, error) {
+	return empty(s.a.RevokeRoleFromGroupCtx(ctx, req.GetGroup(), req.GetRole()))
+}
+
+func (s *server) GetGroupRoles(ctx context.Context, req *GroupRequest) (*NamesResponse, error) {
+	return names(s.a.GetGroupRolesCtx(ctx, req.GetGroup()))
+}
+
+func (s *server) GetGroupMembers(ctx context.Context, req *GroupRequest) (*NamesResponse, error) {
+	return names(s.a.GetGroupMembersCtx(ctx, req.GetGroup()))
+}
+
+func (s *server) GetUserGroups(ctx context.Context, req *UserRequest) (*NamesResponse, error) {
+	return names(s.a.User(req.GetUserKey()).GetGroupsCtx(ctx))
+}
+
+func (s *server) GrantPermissionOnResource(ctx context.Context, req *ResourcePermissionRequest) (*emptypb.Empty,
+	error) {
+	if err := oneGrantee(req); err != nil {
+		return nil, err
+	}
+
+	if req.GetRole() != "" {
+		return empty(s.a.GrantRolePermissionOnResourceCtx(ctx, req.GetRole(), req.GetPermission(),
+			req.GetResourceType(), req.GetResourceId()))
+	}
+
+	return empty(s.a.User(req.GetUserKey()).GrantPermissionOnResourceCtx(ctx, req.GetPermission(),
+		req.GetResourceType(), req.GetResourceId()))
+}
+
+func (s *server) RevokePermissionOnResource(ctx context.Context, req *ResourcePermissionRequest) (*emptypb.Empty,
+	error) {
+	if err := oneGrantee(req); err != nil {
+		return nil, err
+	}
+
+	if req.GetRole() != "" {
+		return empty(s.a.RevokeRolePermissionOnResourceCtx(ctx, req.GetRole(), req.GetPermission(),
+			req.GetResourceType(), req.GetResourceId()))
+	}
+
+	return empty(s.a.User(req.GetUserKey()).RevokePermissionOnResourceCtx(ctx, req.GetPermission(),
+		req.GetResourceType(), req.GetResourceId()))
+}
+
+func (s *server) CheckPermissionOnResource(ctx context.Context, req *ResourcePermissionRequest) (*CheckResponse,
+	error) {
+	if req.GetRole() != "" {
+		return nil, status.Error(codes.InvalidArgument, "the checks on a resource are of a user")
+	}
+
+	ctx = withAttributes(ctx, req.GetAttributes())
+	return check(s.a.User(req.GetUserKey()).InTenant(req.GetTenant()).CheckPermissionOnResourceCtx(ctx,
+		req.GetPermission(), req.GetResourceType(), req.GetResourceId()))
+}
+
+func (s *server) RequestRole(ctx context.Context, req *RequestRoleRequest) (*AccessRequest, error) {
+	user := s.a.User(req.GetUserKey()).InTenant(req.GetTenant())
+	return accessRequest(user.RequestRoleCtx(ctx, req.GetRole(), req.GetReason()))
+}
+
+func (s *server) GetAccessRequest(ctx context.Context, req *AccessRequestRequest) (*AccessRequest, error) {
+	return accessRequest(s.a.GetAccessRequestCtx(ctx, uint(req.GetId())))
+}
+
+func (s *server) GetPendingRequests(ctx context.Context, _ *emptypb.Empty) (*AccessRequestsResponse, error) {
+	reqs, err := s.a.GetPendingRequestsCtx(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &AccessRequestsResponse{Requests: make([]*AccessRequest, 0, len(reqs))}
+	for _, req := range reqs {
+		resp.Requests = append(resp.Requests, FromAccessRequest(req))
+	}
+
+	return resp, nil
+}
+
+func (s *server) ApproveRequest(ctx context.Context, req *DecideRequestRequest) (*AccessRequest, error) {
+	return accessRequest(s.a.ApproveRequestCtx(ctx, uint(req.GetId()), req.GetApprover()))
+}
+
+func (s *server) DenyRequest(ctx context.Context, req *DecideRequestRequest) (*AccessRequest, error) {
+	return accessRequest(s.a.DenyRequestCtx(ctx, uint(req.GetId()), req.GetApprover()))
+}
+
+func (s *server) FinalizeRequest(ctx context.Context, req *FinalizeRequestRequest) (*AccessRequest, error) {
+	return accessRequest(s.a.FinalizeRequestCtx(ctx, req.GetToken(), req.GetApprover()))
+}
+
+func (s *server) OffboardUser(ctx context.Context, req *OffboardUserRequest) (*emptypb.Empty, error) {
+	opts := authority.OffboardOptions{Tombstone: req.GetTombstone(), Reason: req.GetReason()}
+	return empty(s.a.User(req.GetUserKey()).OffboardCtx(ctx, opts))
+}
+
+func (s *server) RevokeAllRoles(ctx context.Context, req *UserRequest) (*emptypb.Empty, error) {
+	return empty(s.a.User(req.GetUserKey()).RevokeAllRolesCtx(ctx))
+}
+
+func (s *server) ForgetUser(ctx context.Context, req *UserRequest) (*emptypb.Empty, error) {
+	return empty(s.a.User(req.GetUserKey()).ForgetCtx(ctx))
+}
+
+func (s *server) WriteTuples(ctx context.Context, req *RelationTuples) (*emptypb.Empty, error) {
+	return empty(s.a.WriteTuplesCtx(ctx, toTuples(req.GetTuples())))
+}
+
+func (s *server) DeleteTuples(ctx context.Context, req *RelationTuples) (*emptypb.Empty, error) {
+	return empty(s.a.DeleteTuplesCtx(ctx, toTuples(req.GetTuples())))
+}
+
+func (s *server) ReadTuples(ctx context.Context, _ *emptypb.Empty) (*RelationTuples, error) {
+	tuples, err := s.a.ReadTuplesCtx(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	resp := &RelationTuples{Tuples: make([]*RelationTuple, 0, len(tuples))}
+	for _, t := range tuples {
+		resp.Tuples = append(resp.Tuples, FromRelationTuple(t))
+	}
+
+	return resp, nil
+}
+
+func (s *server) CheckTuple(ctx context.Context, req *RelationTuple) (*CheckResponse, error) {
+	return check(s.a.CheckTupleCtx(ctx, toTuple(req)))
+}
+
+func (s *server) Export(ctx context.Context, _ *emptypb.Empty) (*SnapshotResponse, error) {
+	snapshot, err := s.a.ExportCtx(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	var data []byte
+	if data, err = json.Marshal(snapshot); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &SnapshotResponse{Snapshot: data}, nil
+}
+
+func (s *server) Import(ctx context.Context, req *ImportRequest) (*emptypb.Empty, error) {
+	var snapshot authority.PolicySnapshot
+	if err := json.Unmarshal(req.GetSnapshot(), &snapshot); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid snapshot: "+err.Error())
+	}
+
+	mode := authority.ImportMerge
+	if req.GetReplace() {
+		mode = authority.ImportReplace
+	}
+
+	return empty(s.a.ImportCtx(ctx, &snapshot, mode))
+}
+
+func (s *server) GenerateAccessReport(ctx context.Context, req *AccessReportRequest) (*AccessReportResponse,
+	error) {
+	var buf bytes.Buffer
+	if err := s.a.GenerateAccessReportCtx(ctx, &buf, authority.ReportFormat(req.GetFormat())); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &AccessReportResponse{Content: buf.Bytes()}, nil
+}
+
+// withAttributes adds the attributes of a check to the context, for the conditions of the permissions
+func withAttributes(ctx context.Context, attributes *structpb.Struct) context.Context {
+	if attributes == nil {
+		return ctx
+	}
+
+	return authority.WithAttributes(ctx, attributes.AsMap())
+}
+
+// oneGrantee makes sure a grant on a resource is to either a user or a role
+func oneGrantee(req *ResourcePermissionRequest) error {
+	if (req.GetUserKey() == "") == (req.GetRole() == "") {
+		return status.Error(codes.InvalidArgument, "exactly one of user_key and role must be set")
+	}
+
+	return nil
+}
+
+func accessRequest(req *authority.AccessRequest, err error) (*AccessRequest, error) {
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return FromAccessRequest(*req), nil
+}
+
+func empty(err error) (*emptypb.Empty, error) {
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func names(names []string, err error) (*NamesResponse, error) {
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &NamesResponse{Names: names}, nil
+}
+
+func check(allowed bool, err error) (*CheckResponse, error) {
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &CheckResponse{Allowed: allowed}, nil
+}
+
+// statusError maps the errors of authority to the grpc codes
+func statusError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, authority.ErrRoleNotFound), errors.Is(err, authority.ErrPermissionNotFound),
+		errors.Is(err, authority.ErrRolePermissionNotFound), errors.Is(err, authority.ErrUserRoleNotFound),
+		errors.Is(err, authority.ErrPolicyNotFound), errors.Is(err, authority.ErrGroupNotFound),
+		errors.Is(err, authority.ErrAccessRequestNotFound):
+		code = codes.NotFound
+	case errors.Is(err, authority.ErrRoleExists), errors.Is(err, authority.ErrRoleAlreadyAssigned),
+		errors.Is(err, authority.ErrPermissionAlreadyAssigned), errors.Is(err, authority.ErrDuplicate):
+		code = codes.AlreadyExists
+	case errors.Is(err, authority.ErrRoleInUse), errors.Is(err, authority.ErrPermissionInUse),
+		errors.Is(err, authority.ErrCompositeCycle), errors.Is(err, authority.ErrHierarchyCycle),
+		errors.Is(err, authority.ErrMissingReference):
+		code = codes.FailedPrecondition
+	case errors.Is(err, authority.ErrInvalidPolicy), errors.Is(err, authority.ErrUnknownPolicyReference),
+		errors.Is(err, authority.ErrInvalidCondition), errors.Is(err, authority.ErrInvalidTuple),
+		errors.Is(err, authority.ErrUnsupportedTuple), errors.Is(err, authority.ErrUnknownReportFormat),
+		errors.Is(err, authority.ErrUnknownSnapshotVersion), errors.Is(err, authority.ErrInvalidApprovalToken):
+		code = codes.InvalidArgument
+	case errors.Is(err, authority.ErrAccessRequestDecided), errors.Is(err, authority.ErrApprovalAlreadyGiven),
+		errors.Is(err, authority.ErrInvalidRoleTransition), errors.Is(err, authority.ErrRoleRetired),
+		errors.Is(err, authority.ErrApprovalTokenExpired):
+		code = codes.FailedPrecondition
+	case errors.Is(err, authority.ErrNotApprover):
+		code = codes.PermissionDenied
+	case errors.Is(err, authority.ErrOperationLocked):
+		code = codes.Aborted
+	case errors.Is(err, authority.ErrQuotaExceeded):
+		code = codes.ResourceExhausted
+	case errors.Is(err, authority.ErrNotSupported):
+		code = codes.Unimplemented
+	case errors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case errors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	}
+
+	return status.Error(code, err.Error())
+}
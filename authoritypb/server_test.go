@@ -0,0 +1,219 @@
+package authoritypb_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"authority"
+	"authority/authoritypb"
+	"authority/authoritytest"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// newClient serves an instance using a memory store in memory and returns a client of the service
+func newClient(t *testing.T) (*authority.Authority, authoritypb.AuthorityServiceClient) {
+	t.Helper()
+
+	a, err := authority.NewE(authority.Options{Store: authoritytest.NewMemoryStore()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	authoritypb.RegisterAuthorityServiceServer(srv, authoritypb.NewServer(a))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return a, authoritypb.NewAuthorityServiceClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	a, client := newClient(t)
+	ctx := context.Background()
+
+	for _, step := range []error{
+		a.CreateRole("editor"),
+		a.CreatePermission("articles.write"),
+		a.CreatePermission("payments.approve"),
+		a.AssignPermissions("editor", []string{"articles.write", "payments.approve"}),
+		a.SetPermissionCondition("payments.approve", "attrs.amount < 1000"),
+		a.DefinePolicy("can_publish", "articles.write AND editor"),
+		a.CreateGroup("team"),
+		a.AssignRoleToGroup("team", "editor"),
+	} {
+		if step != nil {
+			t.Fatal(step)
+		}
+	}
+
+	// the users are identified by any key, the policies included
+	alice := "alice@example.com"
+	if _, err := client.AddUserToGroup(ctx, &authoritypb.GroupMemberRequest{Group: "team", UserKey: alice}); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.CheckPolicy(ctx, &authoritypb.CheckPolicyRequest{UserKey: alice, Policy: "can_publish"})
+	if err != nil || !resp.GetAllowed() {
+		t.Errorf("CheckPolicy of %s = %v, %v", alice, resp, err)
+	}
+
+	attributes, err := structpb.NewStruct(map[string]interface{}{"amount": 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks, err := client.CheckPermissions(ctx, &authoritypb.CheckPermissionsRequest{
+		UserKey: alice, Permissions: []string{"articles.write", "payments.approve"}, Attributes: attributes,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if allowed := checks.GetAllowed(); !allowed["articles.write"] || !allowed["payments.approve"] {
+		t.Errorf("CheckPermissions of %s = %v", alice, allowed)
+	}
+
+	decision, err := client.CheckPermissionDetailed(ctx, &authoritypb.CheckPermissionRequest{
+		UserKey: alice, Permission: "payments.approve",
+	})
+	if err != nil || decision.GetAllowed() || decision.GetReason() != authority.ReasonConditionFailed {
+		t.Errorf("CheckPermissionDetailed without the attributes = %v, %v", decision, err)
+	}
+
+	// the grants on the resources go to either a user or a role
+	grant := &authoritypb.ResourcePermissionRequest{
+		UserKey: "bob", Permission: "articles.write", ResourceType: "article", ResourceId: "42",
+	}
+	if _, err = client.GrantPermissionOnResource(ctx, grant); err != nil {
+		t.Fatal(err)
+	}
+	if resp, err = client.CheckPermissionOnResource(ctx, grant); err != nil || !resp.GetAllowed() {
+		t.Errorf("CheckPermissionOnResource of bob = %v, %v", resp, err)
+	}
+	grant.Role = "editor"
+	if _, err = client.GrantPermissionOnResource(ctx, grant); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GrantPermissionOnResource to a user and a role = %v, want InvalidArgument", err)
+	}
+
+	if _, err = client.OffboardUser(ctx, &authoritypb.OffboardUserRequest{UserKey: alice}); err != nil {
+		t.Fatal(err)
+	}
+	groups, err := client.GetUserGroups(ctx, &authoritypb.UserRequest{UserKey: alice})
+	if err != nil || len(groups.GetNames()) != 0 {
+		t.Errorf("GetUserGroups after offboarding = %v, %v", groups, err)
+	}
+
+	// the snapshots are exchanged in the JSON format of the exports
+	snapshot, err := client.Export(ctx, &emptypb.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var exported authority.PolicySnapshot
+	if err = json.Unmarshal(snapshot.GetSnapshot(), &exported); err != nil {
+		t.Fatal(err)
+	}
+	if len(exported.Roles) != 1 || exported.Roles[0].Name != "editor" {
+		t.Errorf("the roles of the export = %+v", exported.Roles)
+	}
+	_, err = client.Import(ctx, &authoritypb.ImportRequest{Snapshot: []byte("{")})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Import of an invalid snapshot = %v, want InvalidArgument", err)
+	}
+
+	// the errors of authority are mapped to the codes
+	if _, err = client.GetRole(ctx, &authoritypb.RoleRequest{Role: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetRole of a missing role = %v, want NotFound", err)
+	}
+	_, err = client.DefinePolicy(ctx, &authoritypb.DefinePolicyRequest{Name: "p", Expression: "editor AND"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("DefinePolicy of an invalid expression = %v, want InvalidArgument", err)
+	}
+}
+
+func TestGateway(t *testing.T) {
+	a, err := authority.NewE(authority.Options{Store: authoritytest.NewMemoryStore()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(authoritypb.NewGateway(authoritypb.NewServer(a)))
+	defer srv.Close()
+
+	post := func(method string, body string) (int, map[string]interface{}) {
+		t.Helper()
+
+		resp, err := http.Post(srv.URL+authoritypb.GatewayPrefix+method, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+
+		var result map[string]interface{}
+		if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			t.Fatal(err)
+		}
+
+		return resp.StatusCode, result
+	}
+
+	for _, step := range []struct{ method, body string }{
+		{"CreateRole", `{"name": "editor"}`},
+		{"CreatePermission", `{"name": "articles.write"}`},
+		{"AssignPermissions", `{"role": "editor", "permissions": ["articles.write"]}`},
+		{"AssignRole", `{"userKey": "alice", "role": "editor"}`},
+	} {
+		if code, result := post(step.method, step.body); code != http.StatusOK {
+			t.Fatalf("%s = %d %v", step.method, code, result)
+		}
+	}
+
+	code, result := post("CheckPermission", `{"userKey": "alice", "permission": "articles.write"}`)
+	if code != http.StatusOK || result["allowed"] != true {
+		t.Errorf("CheckPermission = %d %v", code, result)
+	}
+	if code, result = post("ListRoles", ""); code != http.StatusOK || fmt.Sprint(result["names"]) != "[editor]" {
+		t.Errorf("ListRoles = %d %v", code, result)
+	}
+
+	// the errors are answered with the http status of their code
+	for _, tc := range []struct {
+		method, body string
+		code         int
+	}{
+		{"GetRole", `{"role": "missing"}`, http.StatusNotFound},
+		{"DeleteRole", `{"name": "editor"}`, http.StatusBadRequest},
+		{"CheckPermission", `{"userKey": 42}`, http.StatusBadRequest},
+		{"Missing", `{}`, http.StatusNotFound},
+	} {
+		if code, result = post(tc.method, tc.body); code != tc.code || result["message"] == "" {
+			t.Errorf("%s of %s = %d %v, want %d", tc.method, tc.body, code, result, tc.code)
+		}
+	}
+
+	resp, err := http.Get(srv.URL + authoritypb.GatewayPrefix + "ListRoles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET ListRoles = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,76 @@
+// Package authoritytest provides helpers to test the code using authority, e.g. a manual clock to inject with
+// authority.Options.Now so that the time-bound assignments and grants expire without sleeping
+package authoritytest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"authority"
+)
+
+// Epoch is the time of the clocks created without a start time
+var Epoch = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Clock is a clock whose time only changes with Set and Advance, it's safe for concurrent use
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock returns a clock set to start, or to Epoch when it's zero, e.g.
+//
+//	clock := authoritytest.NewClock(time.Time{})
+//	auth, err := authority.NewE(authority.Options{DB: db, Now: clock.Now})
+func NewClock(start time.Time) *Clock {
+	if start.IsZero() {
+		start = Epoch
+	}
+
+	return &Clock{now: start}
+}
+
+// Now returns the time of the clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Set sets the time of the clock
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	c.now = t
+	c.mu.Unlock()
+}
+
+// Advance moves the clock forward by d and returns its new time
+func (c *Clock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	return c.now
+}
+
+// FastForward advances the clock by d then sweeps the assignments expired at its new time with
+// SweepExpiredAssignments, the authority must use the clock. it returns the number of revoked assignments
+func FastForward(ctx context.Context, a *authority.Authority, c *Clock, d time.Duration) (int, error) {
+	c.Advance(d)
+
+	return a.SweepExpiredAssignmentsCtx(ctx)
+}
+
+// FastForwardTo is like FastForward up to the time t, the clock is left alone when it's already past t
+func FastForwardTo(ctx context.Context, a *authority.Authority, c *Clock, t time.Time) (int, error) {
+	c.mu.Lock()
+	if c.now.Before(t) {
+		c.now = t
+	}
+	c.mu.Unlock()
+
+	return a.SweepExpiredAssignmentsCtx(ctx)
+}
@@ -0,0 +1,85 @@
+package authoritytest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"authority"
+	"authority/authoritytest"
+)
+
+func TestClock(t *testing.T) {
+	clock := authoritytest.NewClock(time.Time{})
+	if !clock.Now().Equal(authoritytest.Epoch) {
+		t.Fatalf("Now = %v, want the epoch", clock.Now())
+	}
+
+	if now := clock.Advance(time.Hour); !now.Equal(authoritytest.Epoch.Add(time.Hour)) {
+		t.Fatalf("Advance = %v, want an hour after the epoch", now)
+	}
+
+	start := time.Date(2030, time.June, 1, 0, 0, 0, 0, time.UTC)
+	clock.Set(start)
+	if !clock.Now().Equal(start) {
+		t.Fatalf("Now = %v after Set, want %v", clock.Now(), start)
+	}
+}
+
+func TestFastForward(t *testing.T) {
+	clock := authoritytest.NewClock(time.Time{})
+	a, err := authority.NewE(authority.Options{Store: authoritytest.NewMemoryStore(), Now: clock.Now})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, roleName := range []string{"oncall", "auditor"} {
+		if err = a.CreateRole(roleName); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = a.AssignRoleUntil(1, "oncall", clock.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err = a.AssignRoleFrom(1, "auditor", clock.Now().Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(roleName string, want bool) {
+		t.Helper()
+
+		ok, err := a.CheckRole(1, roleName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok != want {
+			t.Fatalf("CheckRole(%s) = %t at %v, want %t", roleName, ok, clock.Now(), want)
+		}
+	}
+
+	check("oncall", true)
+	check("auditor", false)
+
+	ctx := context.Background()
+	revoked, err := authoritytest.FastForward(ctx, a, clock, 90*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked != 1 {
+		t.Fatalf("FastForward revoked %d assignments, want 1", revoked)
+	}
+	check("oncall", false)
+	check("auditor", false)
+
+	// the clock doesn't go back
+	if _, err = authoritytest.FastForwardTo(ctx, a, clock, authoritytest.Epoch); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = authoritytest.FastForwardTo(ctx, a, clock, authoritytest.Epoch.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if !clock.Now().Equal(authoritytest.Epoch.Add(2 * time.Hour)) {
+		t.Fatalf("Now = %v after FastForwardTo", clock.Now())
+	}
+	check("auditor", true)
+}
@@ -0,0 +1,525 @@
+package authoritytest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"authority"
+)
+
+// TestStore checks that a store implements the behaviour of authority.Store, e.g. for the stores of the
+// applications and the tests of this module running it against MemoryStore and the bun store. newStore returns
+// an empty migrated store, it's called once per subtest
+func TestStore(t *testing.T, newStore func(t *testing.T) authority.Store) {
+	for _, test := range []struct {
+		name string
+		run  func(t *testing.T, ctx context.Context, store authority.Store)
+	}{
+		{"Roles", testRoles},
+		{"TenantRoles", testTenantRoles},
+		{"Permissions", testPermissions},
+		{"RolePermissions", testRolePermissions},
+		{"UserRoles", testUserRoles},
+		{"RoleGraph", testRoleGraph},
+		{"Segments", testSegments},
+		{"Denials", testDenials},
+		{"Groups", testGroups},
+		{"Policies", testPolicies},
+		{"ImportBatches", testImportBatches},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			test.run(t, context.Background(), newStore(t))
+		})
+	}
+}
+
+// fatal fails the test on an error
+func fatal(t *testing.T, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// createRole stores a global role
+func createRole(t *testing.T, ctx context.Context, store authority.Store, roleName string) *authority.Role {
+	t.Helper()
+
+	role := &authority.Role{Name: roleName}
+	fatal(t, store.CreateRole(ctx, role))
+	if role.ID == 0 {
+		t.Fatalf("CreateRole didn't set the id of %s", roleName)
+	}
+
+	return role
+}
+
+// createPermission stores a global permission
+func createPermission(t *testing.T, ctx context.Context, store authority.Store, permName string) *authority.Permission {
+	t.Helper()
+
+	perm := &authority.Permission{Name: permName}
+	fatal(t, store.CreatePermission(ctx, perm))
+	if perm.ID == 0 {
+		t.Fatalf("CreatePermission didn't set the id of %s", permName)
+	}
+
+	return perm
+}
+
+func testRoles(t *testing.T, ctx context.Context, store authority.Store) {
+	if _, err := store.GetRole(ctx, "editor", ""); !errors.Is(err, authority.ErrRoleNotFound) {
+		t.Fatalf("GetRole of a missing role = %v, want ErrRoleNotFound", err)
+	}
+
+	editor := createRole(t, ctx, store, "editor")
+	reader := createRole(t, ctx, store, "reader")
+
+	role, err := store.GetRole(ctx, "editor", "")
+	fatal(t, err)
+	if role.ID != editor.ID || role.Name != "editor" {
+		t.Fatalf("GetRole = %+v, want %+v", role, editor)
+	}
+
+	roles, err := store.GetRolesByID(ctx, []uint{reader.ID})
+	fatal(t, err)
+	if len(roles) != 1 || roles[0].Name != "reader" {
+		t.Fatalf("GetRolesByID = %+v, want the reader", roles)
+	}
+
+	if roles, err = store.GetRolesByName(ctx, []string{"editor", "reader", "missing"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 2 {
+		t.Fatalf("GetRolesByName = %+v, want the editor and the reader", roles)
+	}
+
+	editor.Title, editor.Description = "Editor", "edits the articles"
+	fatal(t, store.UpdateRole(ctx, editor))
+	if role, err = store.GetRole(ctx, "editor", ""); err != nil {
+		t.Fatal(err)
+	}
+	if role.Title != "Editor" || role.Description != "edits the articles" {
+		t.Fatalf("GetRole = %+v after UpdateRole", role)
+	}
+
+	fatal(t, store.DeleteRole(ctx, reader.ID))
+	if roles, err = store.ListRoles(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 1 || roles[0].Name != "editor" {
+		t.Fatalf("ListRoles = %+v after DeleteRole, want the editor", roles)
+	}
+}
+
+func testTenantRoles(t *testing.T, ctx context.Context, store authority.Store) {
+	global := createRole(t, ctx, store, "editor")
+	acme := &authority.Role{Name: "editor", Tenant: "acme"}
+	fatal(t, store.CreateRole(ctx, acme))
+
+	for _, tc := range []struct {
+		tenant string
+		id     uint
+	}{
+		{"", global.ID},
+		{"acme", acme.ID},
+		{"globex", global.ID},
+	} {
+		role, err := store.GetRole(ctx, "editor", tc.tenant)
+		fatal(t, err)
+		if role.ID != tc.id {
+			t.Errorf("GetRole in %q = %d, want %d", tc.tenant, role.ID, tc.id)
+		}
+	}
+
+	if _, err := store.GetRole(ctx, "reader", "acme"); !errors.Is(err, authority.ErrRoleNotFound) {
+		t.Fatalf("GetRole of a missing role = %v, want ErrRoleNotFound", err)
+	}
+}
+
+func testPermissions(t *testing.T, ctx context.Context, store authority.Store) {
+	if _, err := store.GetPermission(ctx, "articles.read", ""); !errors.Is(err, authority.ErrPermissionNotFound) {
+		t.Fatalf("GetPermission of a missing permission = %v, want ErrPermissionNotFound", err)
+	}
+
+	read := createPermission(t, ctx, store, "articles.read")
+	write := &authority.Permission{Name: "articles.write", Action: "write", Resource: "articles"}
+	fatal(t, store.CreatePermission(ctx, write))
+
+	perm, err := store.GetPermission(ctx, "articles.read", "")
+	fatal(t, err)
+	if perm.ID != read.ID {
+		t.Fatalf("GetPermission = %+v, want %+v", perm, read)
+	}
+
+	if perm, err = store.GetPermissionByAction(ctx, "write", "articles", ""); err != nil {
+		t.Fatal(err)
+	}
+	if perm.ID != write.ID {
+		t.Fatalf("GetPermissionByAction = %+v, want %+v", perm, write)
+	}
+
+	read.Condition = `user.department == "news"`
+	fatal(t, store.UpdatePermission(ctx, read))
+	if perm, err = store.GetPermission(ctx, "articles.read", ""); err != nil {
+		t.Fatal(err)
+	}
+	if perm.Condition != read.Condition {
+		t.Fatalf("the condition is %q after UpdatePermission, want %q", perm.Condition, read.Condition)
+	}
+
+	fatal(t, store.DeletePermission(ctx, write.ID))
+	perms, err := store.ListPermissions(ctx)
+	fatal(t, err)
+	if len(perms) != 1 || perms[0].ID != read.ID {
+		t.Fatalf("ListPermissions = %+v after DeletePermission, want articles.read", perms)
+	}
+}
+
+func testRolePermissions(t *testing.T, ctx context.Context, store authority.Store) {
+	role := createRole(t, ctx, store, "editor")
+	read := createPermission(t, ctx, store, "articles.read")
+	write := createPermission(t, ctx, store, "articles.write")
+
+	if _, err := store.GetRolePermission(ctx, role.ID, read.ID); !errors.Is(err, authority.ErrRolePermissionNotFound) {
+		t.Fatalf("GetRolePermission = %v, want ErrRolePermissionNotFound", err)
+	}
+
+	now := time.Now()
+	fatal(t, store.AssignPermission(ctx, &authority.RolePermission{RoleID: role.ID, PermissionID: read.ID}))
+	fatal(t, store.AssignPermission(ctx, &authority.RolePermission{
+		RoleID: role.ID, PermissionID: write.ID, ExpiresAt: now.Add(time.Hour),
+	}))
+
+	err := store.AssignPermission(ctx, &authority.RolePermission{RoleID: role.ID, PermissionID: read.ID})
+	if !errors.Is(err, authority.ErrPermissionAlreadyAssigned) {
+		t.Fatalf("AssignPermission twice = %v, want ErrPermissionAlreadyAssigned", err)
+	}
+
+	for _, tc := range []struct {
+		permID uint
+		at     time.Time
+		has    bool
+	}{
+		{read.ID, now, true},
+		{write.ID, now, true},
+		{write.ID, now.Add(2 * time.Hour), false},
+	} {
+		has, err := store.RolesHavePermission(ctx, []uint{role.ID}, tc.permID, tc.at)
+		fatal(t, err)
+		if has != tc.has {
+			t.Errorf("RolesHavePermission of %d at %v = %t, want %t", tc.permID, tc.at, has, tc.has)
+		}
+	}
+
+	assigned, err := store.PermissionAssigned(ctx, read.ID)
+	fatal(t, err)
+	if !assigned {
+		t.Fatal("PermissionAssigned = false for an assigned permission")
+	}
+
+	fatal(t, store.RevokePermission(ctx, role.ID, read.ID))
+	rolePerms, err := store.GetRolePermissions(ctx, []uint{role.ID})
+	fatal(t, err)
+	if len(rolePerms) != 1 || rolePerms[0].PermissionID != write.ID {
+		t.Fatalf("GetRolePermissions = %+v after RevokePermission, want articles.write", rolePerms)
+	}
+
+	fatal(t, store.RevokeRolePermissions(ctx, role.ID))
+	if rolePerms, err = store.GetRolePermissions(ctx, []uint{role.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if len(rolePerms) != 0 {
+		t.Fatalf("GetRolePermissions = %+v after RevokeRolePermissions", rolePerms)
+	}
+}
+
+func testUserRoles(t *testing.T, ctx context.Context, store authority.Store) {
+	editor := createRole(t, ctx, store, "editor")
+	reader := createRole(t, ctx, store, "reader")
+
+	now := time.Now()
+	fatal(t, store.AssignRole(ctx, &authority.UserRole{UserID: 1, UserKey: "1", RoleID: editor.ID}))
+	fatal(t, store.AssignRole(ctx, &authority.UserRole{UserKey: "1", RoleID: reader.ID, Tenant: "acme"}))
+	fatal(t, store.AssignRole(ctx, &authority.UserRole{
+		UserKey: "alice", RoleID: reader.ID, ExpiresAt: now.Add(time.Hour),
+	}))
+
+	err := store.AssignRole(ctx, &authority.UserRole{UserID: 1, UserKey: "1", RoleID: editor.ID})
+	if !errors.Is(err, authority.ErrRoleAlreadyAssigned) {
+		t.Fatalf("AssignRole twice = %v, want ErrRoleAlreadyAssigned", err)
+	}
+
+	for _, tc := range []struct {
+		user   string
+		tenant string
+		at     time.Time
+		roles  []string
+	}{
+		{"1", "", now, []string{"editor"}},
+		{"1", "acme", now, []string{"editor", "reader"}},
+		{"alice", "", now, []string{"reader"}},
+		{"alice", "", now.Add(2 * time.Hour), nil},
+	} {
+		roles, err := store.GetAssignedRoles(ctx, tc.user, tc.tenant, tc.at)
+		fatal(t, err)
+
+		names := make([]string, 0, len(roles))
+		for _, role := range roles {
+			names = append(names, role.Name)
+		}
+		sort.Strings(names)
+
+		if len(names) != len(tc.roles) || len(names) > 0 && !equal(names, tc.roles) {
+			t.Errorf("GetAssignedRoles of %s in %q = %v, want %v", tc.user, tc.tenant, names, tc.roles)
+		}
+	}
+
+	// the batches skip the assignments the users have
+	fatal(t, store.AssignRoles(ctx, []authority.UserRole{
+		{UserKey: "alice", RoleID: reader.ID}, {UserKey: "bob", RoleID: reader.ID},
+	}))
+	users, err := store.GetRoleUsers(ctx, reader.ID, "", now, false, authority.Page{})
+	fatal(t, err)
+	if !equal(users, []string{"alice", "bob"}) {
+		t.Fatalf("GetRoleUsers = %v, want alice and bob", users)
+	}
+
+	expired, err := store.GetExpiredUserRoles(ctx, now.Add(2*time.Hour))
+	fatal(t, err)
+	if len(expired) != 1 || expired[0].UserKey != "alice" {
+		t.Fatalf("GetExpiredUserRoles = %+v, want the assignment of alice", expired)
+	}
+
+	fatal(t, store.RevokeRole(ctx, "1", editor.ID, ""))
+	if _, err = store.GetUserRole(ctx, "1", editor.ID, ""); !errors.Is(err, authority.ErrUserRoleNotFound) {
+		t.Fatalf("GetUserRole after RevokeRole = %v, want ErrUserRoleNotFound", err)
+	}
+
+	userRoles, err := store.GetUserRoles(ctx, "1")
+	fatal(t, err)
+	if len(userRoles) != 1 || userRoles[0].Tenant != "acme" {
+		t.Fatalf("GetUserRoles = %+v, want the assignment in acme", userRoles)
+	}
+}
+
+func testRoleGraph(t *testing.T, ctx context.Context, store authority.Store) {
+	staff := createRole(t, ctx, store, "staff")
+	billing := createRole(t, ctx, store, "billing")
+	support := createRole(t, ctx, store, "support")
+
+	fatal(t, store.AddRoleComposite(ctx, staff.ID, billing.ID))
+	fatal(t, store.AddRoleComposite(ctx, staff.ID, billing.ID))
+	composites, err := store.GetRoleComposites(ctx, []uint{staff.ID})
+	fatal(t, err)
+	if len(composites) != 1 || composites[0].MemberID != billing.ID {
+		t.Fatalf("GetRoleComposites = %+v, want billing once", composites)
+	}
+
+	fatal(t, store.SetRoleParent(ctx, billing.ID, staff.ID))
+	fatal(t, store.SetRoleParent(ctx, billing.ID, support.ID))
+	parents, err := store.GetRoleParents(ctx, []uint{billing.ID})
+	fatal(t, err)
+	if len(parents) != 1 || parents[0].ParentID != support.ID {
+		t.Fatalf("GetRoleParents = %+v, want support replacing staff", parents)
+	}
+
+	children, err := store.GetRoleChildren(ctx, support.ID)
+	fatal(t, err)
+	if len(children) != 1 || children[0].RoleID != billing.ID {
+		t.Fatalf("GetRoleChildren = %+v, want billing", children)
+	}
+
+	fatal(t, store.RemoveRoleParent(ctx, billing.ID))
+	fatal(t, store.RemoveRoleComposite(ctx, staff.ID, billing.ID))
+	if parents, err = store.GetRoleParents(ctx, []uint{billing.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if composites, err = store.GetRoleComposites(ctx, []uint{staff.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if len(parents) != 0 || len(composites) != 0 {
+		t.Fatalf("the parents %+v and the composites %+v are left", parents, composites)
+	}
+}
+
+func testSegments(t *testing.T, ctx context.Context, store authority.Store) {
+	beta := createRole(t, ctx, store, "beta")
+	createRole(t, ctx, store, "editor")
+
+	fatal(t, store.SetRoleSegment(ctx, beta.ID, 20, "reports"))
+	roles, err := store.GetSegmentRoles(ctx)
+	fatal(t, err)
+	if len(roles) != 1 || roles[0].ID != beta.ID || roles[0].SegmentPercent != 20 || roles[0].SegmentSeed != "reports" {
+		t.Fatalf("GetSegmentRoles = %+v, want the beta role in the segment of 20%%", roles)
+	}
+
+	fatal(t, store.SetRoleSegment(ctx, beta.ID, 0, ""))
+	if roles, err = store.GetSegmentRoles(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 0 {
+		t.Fatalf("GetSegmentRoles = %+v after removing the segment", roles)
+	}
+}
+
+func testDenials(t *testing.T, ctx context.Context, store authority.Store) {
+	role := createRole(t, ctx, store, "editor")
+	perm := createPermission(t, ctx, store, "articles.delete")
+
+	toUser := &authority.PermissionDenial{PermissionID: perm.ID, UserKey: "alice", Tenant: "acme"}
+	toRole := &authority.PermissionDenial{PermissionID: perm.ID, RoleID: role.ID}
+	fatal(t, store.DenyPermission(ctx, toUser))
+	fatal(t, store.DenyPermission(ctx, toUser))
+	fatal(t, store.DenyPermission(ctx, toRole))
+
+	for _, tc := range []struct {
+		user    string
+		tenant  string
+		roleIDs []uint
+		denied  bool
+	}{
+		{"alice", "acme", nil, true},
+		{"alice", "", nil, false},
+		{"bob", "acme", nil, false},
+		{"bob", "", []uint{role.ID}, true},
+		{"", "", []uint{role.ID}, true},
+	} {
+		denied, err := store.IsPermissionDenied(ctx, perm.ID, tc.user, tc.tenant, tc.roleIDs)
+		fatal(t, err)
+		if denied != tc.denied {
+			t.Errorf("IsPermissionDenied to %q in %q with %v = %t, want %t", tc.user, tc.tenant, tc.roleIDs,
+				denied, tc.denied)
+		}
+	}
+
+	fatal(t, store.RevokePermissionDenial(ctx, toRole))
+	denials, err := store.GetPermissionDenials(ctx, perm.ID)
+	fatal(t, err)
+	if len(denials) != 1 || denials[0].UserKey != "alice" {
+		t.Fatalf("GetPermissionDenials = %+v, want the denial to alice", denials)
+	}
+
+	denials, err = store.GetUserPermissionDenials(ctx, "alice")
+	fatal(t, err)
+	if len(denials) != 1 || denials[0].PermissionID != perm.ID || denials[0].Tenant != "acme" {
+		t.Fatalf("GetUserPermissionDenials = %+v, want the denial to alice", denials)
+	}
+}
+
+func testGroups(t *testing.T, ctx context.Context, store authority.Store) {
+	if _, err := store.GetGroup(ctx, "team"); !errors.Is(err, authority.ErrGroupNotFound) {
+		t.Fatalf("GetGroup of a missing group = %v, want ErrGroupNotFound", err)
+	}
+
+	role := createRole(t, ctx, store, "editor")
+	group := &authority.Group{Name: "team"}
+	fatal(t, store.CreateGroup(ctx, group))
+
+	for _, user := range []string{"bob", "alice", "alice"} {
+		fatal(t, store.AddGroupMember(ctx, &authority.GroupMember{GroupID: group.ID, UserKey: user}))
+	}
+	fatal(t, store.AssignGroupRole(ctx, &authority.GroupRole{GroupID: group.ID, RoleID: role.ID}))
+	fatal(t, store.AssignGroupRole(ctx, &authority.GroupRole{GroupID: group.ID, RoleID: role.ID}))
+
+	members, err := store.GetGroupMembers(ctx, group.ID)
+	fatal(t, err)
+	if !equal(members, []string{"alice", "bob"}) {
+		t.Fatalf("GetGroupMembers = %v, want alice and bob", members)
+	}
+
+	roleIDs, err := store.GetUserGroupRoleIDs(ctx, "alice")
+	fatal(t, err)
+	if len(roleIDs) != 1 || roleIDs[0] != role.ID {
+		t.Fatalf("GetUserGroupRoleIDs = %v, want the editor", roleIDs)
+	}
+
+	fatal(t, store.RemoveGroupMember(ctx, group.ID, "alice"))
+	if roleIDs, err = store.GetUserGroupRoleIDs(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if len(roleIDs) != 0 {
+		t.Fatalf("GetUserGroupRoleIDs = %v after RemoveGroupMember", roleIDs)
+	}
+
+	fatal(t, store.DeleteGroup(ctx, group.ID))
+	groups, err := store.GetUserGroups(ctx, "bob")
+	fatal(t, err)
+	if len(groups) != 0 {
+		t.Fatalf("GetUserGroups = %+v after DeleteGroup", groups)
+	}
+}
+
+func testPolicies(t *testing.T, ctx context.Context, store authority.Store) {
+	if _, err := store.GetPolicy(ctx, "publish"); !errors.Is(err, authority.ErrPolicyNotFound) {
+		t.Fatalf("GetPolicy of a missing policy = %v, want ErrPolicyNotFound", err)
+	}
+
+	fatal(t, store.SavePolicy(ctx, &authority.Policy{Name: "publish", Expression: "editor"}))
+	fatal(t, store.SavePolicy(ctx, &authority.Policy{Name: "publish", Expression: "editor AND articles.publish"}))
+
+	policy, err := store.GetPolicy(ctx, "publish")
+	fatal(t, err)
+	if policy.Expression != "editor AND articles.publish" {
+		t.Fatalf("GetPolicy = %+v, want the saved expression", policy)
+	}
+
+	fatal(t, store.DeletePolicy(ctx, "publish"))
+	policies, err := store.ListPolicies(ctx)
+	fatal(t, err)
+	if len(policies) != 0 {
+		t.Fatalf("ListPolicies = %+v after DeletePolicy", policies)
+	}
+}
+
+func testImportBatches(t *testing.T, ctx context.Context, store authority.Store) {
+	if _, err := store.GetImportBatch(ctx, "job-1"); !errors.Is(err, authority.ErrImportNotFound) {
+		t.Fatalf("GetImportBatch of a missing key = %v, want ErrImportNotFound", err)
+	}
+
+	started := time.Now().UTC().Truncate(time.Second)
+	batch := &authority.ImportBatch{
+		Key: "job-1", Kind: authority.ImportKindAssignRoles, Status: authority.ImportFailed, StartedAt: started,
+	}
+	created, err := store.CreateImportBatch(ctx, batch)
+	fatal(t, err)
+	if !created {
+		t.Fatal("CreateImportBatch didn't store the first batch of the key")
+	}
+
+	again := *batch
+	if created, err = store.CreateImportBatch(ctx, &again); err != nil {
+		t.Fatal(err)
+	}
+	if created {
+		t.Fatal("CreateImportBatch stored a second batch with the same key")
+	}
+
+	batch.Status, batch.Items = authority.ImportCompleted, 3
+	fatal(t, store.SaveImportBatch(ctx, batch))
+
+	saved, err := store.GetImportBatch(ctx, "job-1")
+	fatal(t, err)
+	if saved.Status != authority.ImportCompleted || saved.Items != 3 || !saved.StartedAt.Equal(started) {
+		t.Fatalf("GetImportBatch = %+v, want the saved batch", saved)
+	}
+}
+
+// equal reports whether the slices have the same strings in the same order
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
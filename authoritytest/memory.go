@@ -0,0 +1,1559 @@
+package authoritytest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"authority"
+)
+
+// MemoryStore is an authority.Store keeping the roles, the permissions and their assignments in memory, e.g. to
+// unit test the authorization logic of an application without a database:
+//
+//	auth, err := authority.NewE(authority.Options{Store: authoritytest.NewMemoryStore(), Now: clock.Now})
+//
+// it enforces the unique and the foreign keys of the bun store, the rows referencing a deleted row are deleted
+// with it. it implements authority.Transactor, a transaction works on a copy of the data replacing them once it's
+// committed and the other changes wait for it. it's safe for concurrent use
+type MemoryStore struct {
+	// mu guards the data, a transaction has its own
+	mu   *sync.Mutex
+	data *memoryData
+}
+
+var (
+	_ authority.Store      = (*MemoryStore)(nil)
+	_ authority.Transactor = (*MemoryStore)(nil)
+)
+
+// memoryData are the tables of the store, the rows are ordered by id
+type memoryData struct {
+	// ids are the last ids allocated by table
+	ids map[string]uint
+
+	roles         []authority.Role
+	perms         []authority.Permission
+	rolePerms     []authority.RolePermission
+	resourcePerms []authority.ResourcePermission
+	denials       []authority.PermissionDenial
+	tombstones    []authority.UserTombstone
+	groups        []authority.Group
+	members       []authority.GroupMember
+	groupRoles    []authority.GroupRole
+	userRoles     []authority.UserRole
+	claims        []authority.AssignmentClaim
+	composites    []authority.RoleComposite
+	parents       []authority.RoleParent
+	policies      []authority.Policy
+	audit         []authority.AuditEntry
+	requests      []authority.AccessRequest
+	approvals     []authority.RequestApproval
+	imports       []authority.ImportBatch
+}
+
+// NewMemoryStore returns an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{mu: &sync.Mutex{}, data: &memoryData{ids: make(map[string]uint)}}
+}
+
+// lock locks the data until the returned function is called
+func (s *MemoryStore) lock() func() {
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// nextID allocates the next id of a table
+func (d *memoryData) nextID(table string) uint {
+	d.ids[table]++
+	return d.ids[table]
+}
+
+// clone copies the tables, the rows are values and their maps and slices are never changed in place
+func (d *memoryData) clone() *memoryData {
+	ids := make(map[string]uint, len(d.ids))
+	for table, id := range d.ids {
+		ids[table] = id
+	}
+
+	return &memoryData{
+		ids:           ids,
+		roles:         clone(d.roles),
+		perms:         clone(d.perms),
+		rolePerms:     clone(d.rolePerms),
+		resourcePerms: clone(d.resourcePerms),
+		denials:       clone(d.denials),
+		tombstones:    clone(d.tombstones),
+		groups:        clone(d.groups),
+		members:       clone(d.members),
+		groupRoles:    clone(d.groupRoles),
+		userRoles:     clone(d.userRoles),
+		claims:        clone(d.claims),
+		composites:    clone(d.composites),
+		parents:       clone(d.parents),
+		policies:      clone(d.policies),
+		audit:         clone(d.audit),
+		requests:      clone(d.requests),
+		approvals:     clone(d.approvals),
+		imports:       clone(d.imports),
+	}
+}
+
+func clone[T any](rows []T) []T {
+	return append([]T(nil), rows...)
+}
+
+// filter returns the rows kept by keep, in order
+func filter[T any](rows []T, keep func(T) bool) []T {
+	var result []T
+	for _, row := range rows {
+		if keep(row) {
+			result = append(result, row)
+		}
+	}
+
+	return result
+}
+
+// remove deletes the rows matched by match, in place
+func remove[T any](rows *[]T, match func(T) bool) {
+	kept := (*rows)[:0]
+	for _, row := range *rows {
+		if !match(row) {
+			kept = append(kept, row)
+		}
+	}
+	*rows = kept
+}
+
+// find returns the index of the first row matched by match, -1 when there is none
+func find[T any](rows []T, match func(T) bool) int {
+	for i, row := range rows {
+		if match(row) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// containsID reports whether the ids include the id
+func containsID(ids []uint, id uint) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsKey reports whether the keys include the key
+func containsKey(keys []string, key string) bool {
+	for _, v := range keys {
+		if v == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inTenant reports whether a row of the tenant applies in the given tenant, the global rows apply in every tenant
+func inTenant(rowTenant string, tenant string) bool {
+	return rowTenant == "" || rowTenant == tenant
+}
+
+// inPeriod reports whether the time is within the period, a zero start or end leaves the period open
+func inPeriod(start, end, at time.Time) bool {
+	return (start.IsZero() || !start.After(at)) && (end.IsZero() || at.Before(end))
+}
+
+// copyMetadata copies the metadata so that the caller and the store don't share them
+func copyMetadata(md map[string]interface{}) map[string]interface{} {
+	if md == nil {
+		return nil
+	}
+
+	result := make(map[string]interface{}, len(md))
+	for k, v := range md {
+		result[k] = v
+	}
+
+	return result
+}
+
+func copyRole(role authority.Role) authority.Role {
+	role.Metadata = copyMetadata(role.Metadata)
+	return role
+}
+
+func copyPermission(perm authority.Permission) authority.Permission {
+	perm.Metadata = copyMetadata(perm.Metadata)
+	return perm
+}
+
+func copyRoles(roles []authority.Role) []authority.Role {
+	for i := range roles {
+		roles[i] = copyRole(roles[i])
+	}
+
+	return roles
+}
+
+func copyPermissions(perms []authority.Permission) []authority.Permission {
+	for i := range perms {
+		perms[i] = copyPermission(perms[i])
+	}
+
+	return perms
+}
+
+// duplicate returns the error of a unique key violation
+func duplicate(entity string, name string) error {
+	return &authority.ConstraintError{Kind: authority.ErrDuplicate, Entity: entity, Name: name}
+}
+
+// missing returns the error of a foreign key violation
+func missing(entity string, name string) error {
+	return &authority.ConstraintError{Kind: authority.ErrMissingReference, Entity: entity, Name: name}
+}
+
+func (d *memoryData) roleExists(roleID uint) bool {
+	return find(d.roles, func(r authority.Role) bool { return r.ID == roleID }) >= 0
+}
+
+func (d *memoryData) permissionExists(permID uint) bool {
+	return find(d.perms, func(p authority.Permission) bool { return p.ID == permID }) >= 0
+}
+
+func (d *memoryData) groupExists(groupID uint) bool {
+	return find(d.groups, func(g authority.Group) bool { return g.ID == groupID }) >= 0
+}
+
+// Migrate implements authority.Store, there is nothing to prepare
+func (s *MemoryStore) Migrate(context.Context) error {
+	return nil
+}
+
+// InTx implements authority.Transactor
+func (s *MemoryStore) InTx(ctx context.Context, fn func(ctx context.Context, store authority.Store) error) error {
+	defer s.lock()()
+
+	tx := &MemoryStore{mu: &sync.Mutex{}, data: s.data.clone()}
+	if err := fn(ctx, tx); err != nil {
+		return err
+	}
+
+	defer tx.lock()()
+	s.data = tx.data
+
+	return nil
+}
+
+// GetRole implements authority.Store
+func (s *MemoryStore) GetRole(_ context.Context, roleName string, tenant string) (*authority.Role, error) {
+	defer s.lock()()
+
+	var found *authority.Role
+	for _, role := range s.data.roles {
+		if role.Name == roleName && inTenant(role.Tenant, tenant) && (found == nil || role.Tenant != "") {
+			role := copyRole(role)
+			found = &role
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s", authority.ErrRoleNotFound, roleName)
+	}
+
+	return found, nil
+}
+
+// GetRolesByID implements authority.Store
+func (s *MemoryStore) GetRolesByID(_ context.Context, roleIDs []uint) ([]authority.Role, error) {
+	defer s.lock()()
+
+	return copyRoles(filter(s.data.roles, func(r authority.Role) bool { return containsID(roleIDs, r.ID) })), nil
+}
+
+// GetRolesByName implements authority.Store
+func (s *MemoryStore) GetRolesByName(_ context.Context, roleNames []string) ([]authority.Role, error) {
+	defer s.lock()()
+
+	return copyRoles(filter(s.data.roles, func(r authority.Role) bool { return containsKey(roleNames, r.Name) })), nil
+}
+
+// ListRoles implements authority.Store
+func (s *MemoryStore) ListRoles(context.Context) ([]authority.Role, error) {
+	defer s.lock()()
+
+	return copyRoles(clone(s.data.roles)), nil
+}
+
+// FindRoles implements authority.Store
+func (s *MemoryStore) FindRoles(_ context.Context, opts authority.ListOptions) ([]authority.Role, int, error) {
+	defer s.lock()()
+
+	roles, total := list(s.data.roles, opts, func(r authority.Role) (string, uint) { return r.Name, r.ID })
+
+	return copyRoles(roles), total, nil
+}
+
+// list applies the filter, the order and the page of the options to the rows, it returns the page and the number
+// of rows matching the filter
+func list[T any](rows []T, opts authority.ListOptions, key func(T) (string, uint)) ([]T, int) {
+	result := filter(rows, func(row T) bool {
+		name, _ := key(row)
+		return strings.HasPrefix(name, opts.Prefix)
+	})
+
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := i, j
+		if opts.Desc {
+			a, b = j, i
+		}
+
+		nameA, idA := key(result[a])
+		nameB, idB := key(result[b])
+		if opts.Sort != authority.SortByID && nameA != nameB {
+			return nameA < nameB
+		}
+
+		return idA < idB
+	})
+
+	total := len(result)
+	if opts.Offset > 0 {
+		if opts.Offset > len(result) {
+			opts.Offset = len(result)
+		}
+		result = result[opts.Offset:]
+	}
+	if opts.Limit > 0 && opts.Limit < len(result) {
+		result = result[:opts.Limit]
+	}
+
+	return append([]T{}, result...), total
+}
+
+// CreateRole implements authority.Store
+func (s *MemoryStore) CreateRole(_ context.Context, role *authority.Role) error {
+	defer s.lock()()
+
+	if find(s.data.roles, func(r authority.Role) bool { return r.Name == role.Name && r.Tenant == role.Tenant }) >= 0 {
+		return duplicate(authority.EntityRole, role.Name)
+	}
+
+	role.ID = s.data.nextID("roles")
+	if role.State == "" {
+		role.State = authority.RoleStateActive
+	}
+	s.data.roles = append(s.data.roles, copyRole(*role))
+
+	return nil
+}
+
+// SetRoleState implements authority.Store
+func (s *MemoryStore) SetRoleState(_ context.Context, roleID uint, state string) error {
+	defer s.lock()()
+
+	if i := find(s.data.roles, func(r authority.Role) bool { return r.ID == roleID }); i >= 0 {
+		s.data.roles[i].State = state
+	}
+
+	return nil
+}
+
+// SetRoleSegment implements authority.Store
+func (s *MemoryStore) SetRoleSegment(_ context.Context, roleID uint, percent int, seed string) error {
+	defer s.lock()()
+
+	if i := find(s.data.roles, func(r authority.Role) bool { return r.ID == roleID }); i >= 0 {
+		s.data.roles[i].SegmentPercent, s.data.roles[i].SegmentSeed = percent, seed
+	}
+
+	return nil
+}
+
+// GetSegmentRoles implements authority.Store
+func (s *MemoryStore) GetSegmentRoles(_ context.Context) ([]authority.Role, error) {
+	defer s.lock()()
+
+	var roles []authority.Role
+	for _, role := range s.data.roles {
+		if role.SegmentPercent > 0 {
+			roles = append(roles, copyRole(role))
+		}
+	}
+
+	return roles, nil
+}
+
+// UpdateRole implements authority.Store
+func (s *MemoryStore) UpdateRole(_ context.Context, role *authority.Role) error {
+	defer s.lock()()
+
+	i := find(s.data.roles, func(r authority.Role) bool { return r.ID == role.ID })
+	if i < 0 {
+		return nil
+	}
+
+	stored := &s.data.roles[i]
+	if find(s.data.roles, func(r authority.Role) bool {
+		return r.ID != role.ID && r.Name == role.Name && r.Tenant == stored.Tenant
+	}) >= 0 {
+		return duplicate(authority.EntityRole, role.Name)
+	}
+
+	stored.Name, stored.Title, stored.Description = role.Name, role.Title, role.Description
+	stored.Metadata = copyMetadata(role.Metadata)
+
+	return nil
+}
+
+// DeleteRole implements authority.Store, the rows referencing the role are deleted with it
+func (s *MemoryStore) DeleteRole(_ context.Context, roleID uint) error {
+	defer s.lock()()
+
+	d := s.data
+	remove(&d.roles, func(r authority.Role) bool { return r.ID == roleID })
+	remove(&d.rolePerms, func(rp authority.RolePermission) bool { return rp.RoleID == roleID })
+	remove(&d.composites, func(rc authority.RoleComposite) bool { return rc.RoleID == roleID || rc.MemberID == roleID })
+	remove(&d.parents, func(rp authority.RoleParent) bool { return rp.RoleID == roleID || rp.ParentID == roleID })
+	remove(&d.userRoles, func(ur authority.UserRole) bool { return ur.RoleID == roleID })
+	remove(&d.claims, func(ac authority.AssignmentClaim) bool { return ac.RoleID == roleID })
+	remove(&d.resourcePerms, func(g authority.ResourcePermission) bool { return g.RoleID == roleID })
+	remove(&d.denials, func(pd authority.PermissionDenial) bool { return pd.RoleID == roleID })
+	remove(&d.groupRoles, func(gr authority.GroupRole) bool { return gr.RoleID == roleID })
+
+	return nil
+}
+
+// RoleAssigned implements authority.Store
+func (s *MemoryStore) RoleAssigned(_ context.Context, roleID uint) (bool, error) {
+	defer s.lock()()
+
+	return find(s.data.userRoles, func(ur authority.UserRole) bool { return ur.RoleID == roleID }) >= 0, nil
+}
+
+// CountRoleUsers implements authority.Store
+func (s *MemoryStore) CountRoleUsers(_ context.Context, roleID uint, tenant string, at time.Time) (int, error) {
+	defer s.lock()()
+
+	users := make(map[string]bool)
+	for _, ur := range s.data.userRoles {
+		if ur.RoleID == roleID && ur.Tenant == tenant && (ur.ExpiresAt.IsZero() || ur.ExpiresAt.After(at)) {
+			users[ur.UserKey] = true
+		}
+	}
+
+	return len(users), nil
+}
+
+// GetPermission implements authority.Store
+func (s *MemoryStore) GetPermission(_ context.Context, permName string, tenant string) (*authority.Permission,
+	error) {
+	defer s.lock()()
+
+	return s.data.tenantPermission(func(p authority.Permission) bool { return p.Name == permName }, tenant, permName)
+}
+
+// GetPermissionByAction implements authority.Store
+func (s *MemoryStore) GetPermissionByAction(_ context.Context, action string, resource string, tenant string) (
+	*authority.Permission, error) {
+	defer s.lock()()
+
+	return s.data.tenantPermission(func(p authority.Permission) bool {
+		return p.Action == action && p.Resource == resource
+	}, tenant, action+" on "+resource)
+}
+
+// tenantPermission returns the first permission matched by match defined in the tenant, or the first global one,
+// the error of a missing permission is described by name
+func (d *memoryData) tenantPermission(match func(authority.Permission) bool, tenant string, name string) (
+	*authority.Permission, error) {
+	var found *authority.Permission
+	for _, perm := range d.perms {
+		if !match(perm) || !inTenant(perm.Tenant, tenant) {
+			continue
+		}
+		if found == nil || found.Tenant == "" && perm.Tenant != "" {
+			perm := copyPermission(perm)
+			found = &perm
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s", authority.ErrPermissionNotFound, name)
+	}
+
+	return found, nil
+}
+
+// GetPermissionsByID implements authority.Store
+func (s *MemoryStore) GetPermissionsByID(_ context.Context, permIDs []uint) ([]authority.Permission, error) {
+	defer s.lock()()
+
+	return copyPermissions(filter(s.data.perms, func(p authority.Permission) bool {
+		return containsID(permIDs, p.ID)
+	})), nil
+}
+
+// ListPermissions implements authority.Store
+func (s *MemoryStore) ListPermissions(context.Context) ([]authority.Permission, error) {
+	defer s.lock()()
+
+	return copyPermissions(clone(s.data.perms)), nil
+}
+
+// FindPermissions implements authority.Store
+func (s *MemoryStore) FindPermissions(_ context.Context, opts authority.ListOptions) ([]authority.Permission, int,
+	error) {
+	defer s.lock()()
+
+	perms, total := list(s.data.perms, opts, func(p authority.Permission) (string, uint) { return p.Name, p.ID })
+
+	return copyPermissions(perms), total, nil
+}
+
+// CreatePermission implements authority.Store
+func (s *MemoryStore) CreatePermission(_ context.Context, perm *authority.Permission) error {
+	defer s.lock()()
+
+	if find(s.data.perms, func(p authority.Permission) bool {
+		return p.Name == perm.Name && p.Tenant == perm.Tenant
+	}) >= 0 {
+		return duplicate(authority.EntityPermission, perm.Name)
+	}
+
+	perm.ID = s.data.nextID("permissions")
+	s.data.perms = append(s.data.perms, copyPermission(*perm))
+
+	return nil
+}
+
+// UpdatePermission implements authority.Store
+func (s *MemoryStore) UpdatePermission(_ context.Context, perm *authority.Permission) error {
+	defer s.lock()()
+
+	i := find(s.data.perms, func(p authority.Permission) bool { return p.ID == perm.ID })
+	if i < 0 {
+		return nil
+	}
+
+	stored := &s.data.perms[i]
+	if find(s.data.perms, func(p authority.Permission) bool {
+		return p.ID != perm.ID && p.Name == perm.Name && p.Tenant == stored.Tenant
+	}) >= 0 {
+		return duplicate(authority.EntityPermission, perm.Name)
+	}
+
+	stored.Name, stored.Title, stored.Condition, stored.Description = perm.Name, perm.Title, perm.Condition,
+		perm.Description
+	stored.Metadata, stored.Action, stored.Resource = copyMetadata(perm.Metadata), perm.Action, perm.Resource
+
+	return nil
+}
+
+// DeletePermission implements authority.Store, the rows referencing the permission are deleted with it
+func (s *MemoryStore) DeletePermission(_ context.Context, permID uint) error {
+	defer s.lock()()
+
+	d := s.data
+	remove(&d.perms, func(p authority.Permission) bool { return p.ID == permID })
+	remove(&d.rolePerms, func(rp authority.RolePermission) bool { return rp.PermissionID == permID })
+	remove(&d.resourcePerms, func(g authority.ResourcePermission) bool { return g.PermissionID == permID })
+	remove(&d.denials, func(pd authority.PermissionDenial) bool { return pd.PermissionID == permID })
+
+	return nil
+}
+
+// PermissionAssigned implements authority.Store
+func (s *MemoryStore) PermissionAssigned(_ context.Context, permID uint) (bool, error) {
+	defer s.lock()()
+
+	return find(s.data.rolePerms, func(rp authority.RolePermission) bool { return rp.PermissionID == permID }) >= 0,
+		nil
+}
+
+// GetRolePermission implements authority.Store
+func (s *MemoryStore) GetRolePermission(_ context.Context, roleID, permID uint) (*authority.RolePermission, error) {
+	defer s.lock()()
+
+	i := find(s.data.rolePerms, func(rp authority.RolePermission) bool {
+		return rp.RoleID == roleID && rp.PermissionID == permID
+	})
+	if i < 0 {
+		return nil, fmt.Errorf("%w: permission %d of the role %d", authority.ErrRolePermissionNotFound, permID, roleID)
+	}
+
+	rolePerm := s.data.rolePerms[i]
+
+	return &rolePerm, nil
+}
+
+// GetRolePermissions implements authority.Store
+func (s *MemoryStore) GetRolePermissions(_ context.Context, roleIDs []uint) ([]authority.RolePermission, error) {
+	defer s.lock()()
+
+	return filter(s.data.rolePerms, func(rp authority.RolePermission) bool { return containsID(roleIDs, rp.RoleID) }),
+		nil
+}
+
+// RolesHavePermission implements authority.Store
+func (s *MemoryStore) RolesHavePermission(_ context.Context, roleIDs []uint, permID uint, at time.Time) (bool,
+	error) {
+	defer s.lock()()
+
+	return find(s.data.rolePerms, func(rp authority.RolePermission) bool {
+		return containsID(roleIDs, rp.RoleID) && rp.PermissionID == permID && inPeriod(rp.StartsAt, rp.ExpiresAt, at)
+	}) >= 0, nil
+}
+
+// AssignPermission implements authority.Store
+func (s *MemoryStore) AssignPermission(_ context.Context, rolePerm *authority.RolePermission) error {
+	defer s.lock()()
+
+	if !s.data.roleExists(rolePerm.RoleID) || !s.data.permissionExists(rolePerm.PermissionID) {
+		return missing(authority.EntityRolePermission, fmt.Sprintf("%d/%d", rolePerm.RoleID, rolePerm.PermissionID))
+	}
+
+	if find(s.data.rolePerms, func(rp authority.RolePermission) bool {
+		return rp.RoleID == rolePerm.RoleID && rp.PermissionID == rolePerm.PermissionID
+	}) >= 0 {
+		return authority.ErrPermissionAlreadyAssigned
+	}
+
+	rolePerm.ID = s.data.nextID("role_permissions")
+	s.data.rolePerms = append(s.data.rolePerms, *rolePerm)
+
+	return nil
+}
+
+// RevokePermission implements authority.Store
+func (s *MemoryStore) RevokePermission(_ context.Context, roleID, permID uint) error {
+	defer s.lock()()
+
+	remove(&s.data.rolePerms, func(rp authority.RolePermission) bool {
+		return rp.RoleID == roleID && rp.PermissionID == permID
+	})
+
+	return nil
+}
+
+// RevokeRolePermissions implements authority.Store
+func (s *MemoryStore) RevokeRolePermissions(_ context.Context, roleID uint) error {
+	defer s.lock()()
+
+	remove(&s.data.rolePerms, func(rp authority.RolePermission) bool { return rp.RoleID == roleID })
+
+	return nil
+}
+
+// GetResourcePermissions implements authority.Store
+func (s *MemoryStore) GetResourcePermissions(_ context.Context, resourceType string, resourceID string) (
+	[]authority.ResourcePermission, error) {
+	defer s.lock()()
+
+	return filter(s.data.resourcePerms, func(g authority.ResourcePermission) bool {
+		return g.ResourceType == resourceType && g.ResourceID == resourceID
+	}), nil
+}
+
+// HasResourcePermission implements authority.Store
+func (s *MemoryStore) HasResourcePermission(_ context.Context, permID uint, resourceType string, resourceID string,
+	userKey string, tenant string, roleIDs []uint) (bool, error) {
+	defer s.lock()()
+
+	return find(s.data.resourcePerms, func(g authority.ResourcePermission) bool {
+		return g.PermissionID == permID && g.ResourceType == resourceType && g.ResourceID == resourceID &&
+			(g.UserKey == userKey && inTenant(g.Tenant, tenant) || containsID(roleIDs, g.RoleID))
+	}) >= 0, nil
+}
+
+// GetUserResourcePermissions implements authority.Store
+func (s *MemoryStore) GetUserResourcePermissions(_ context.Context, userKey string) ([]authority.ResourcePermission,
+	error) {
+	defer s.lock()()
+
+	return filter(s.data.resourcePerms, func(g authority.ResourcePermission) bool {
+		return g.UserKey == userKey && g.RoleID == 0
+	}), nil
+}
+
+// GrantResourcePermission implements authority.Store
+func (s *MemoryStore) GrantResourcePermission(_ context.Context, grant *authority.ResourcePermission) error {
+	defer s.lock()()
+
+	if !s.data.permissionExists(grant.PermissionID) || grant.RoleID != 0 && !s.data.roleExists(grant.RoleID) {
+		return missing(authority.EntityResourcePermission, grant.ResourceType+":"+grant.ResourceID)
+	}
+
+	if find(s.data.resourcePerms, func(g authority.ResourcePermission) bool { return sameGrant(g, *grant) }) >= 0 {
+		return nil
+	}
+
+	grant.ID = s.data.nextID("resource_permissions")
+	if grant.CreatedAt.IsZero() {
+		grant.CreatedAt = time.Now()
+	}
+	s.data.resourcePerms = append(s.data.resourcePerms, *grant)
+
+	return nil
+}
+
+// RevokeResourcePermission implements authority.Store
+func (s *MemoryStore) RevokeResourcePermission(_ context.Context, grant *authority.ResourcePermission) error {
+	defer s.lock()()
+
+	remove(&s.data.resourcePerms, func(g authority.ResourcePermission) bool { return sameGrant(g, *grant) })
+
+	return nil
+}
+
+// sameGrant reports whether the grants are the same, by the unique key of the grants
+func sameGrant(a, b authority.ResourcePermission) bool {
+	return a.PermissionID == b.PermissionID && a.ResourceType == b.ResourceType && a.ResourceID == b.ResourceID &&
+		a.UserKey == b.UserKey && a.Tenant == b.Tenant && a.RoleID == b.RoleID
+}
+
+// SaveUserTombstone implements authority.Store
+func (s *MemoryStore) SaveUserTombstone(_ context.Context, tombstone *authority.UserTombstone) error {
+	defer s.lock()()
+
+	tombstone.ID = s.data.nextID("user_tombstones")
+
+	stored := *tombstone
+	stored.Roles, stored.Grants = clone(tombstone.Roles), clone(tombstone.Grants)
+	s.data.tombstones = append(s.data.tombstones, stored)
+
+	return nil
+}
+
+// GetUserTombstones implements authority.Store
+func (s *MemoryStore) GetUserTombstones(_ context.Context, userKey string) ([]authority.UserTombstone, error) {
+	defer s.lock()()
+
+	tombstones := filter(s.data.tombstones, func(t authority.UserTombstone) bool { return t.UserKey == userKey })
+	sort.SliceStable(tombstones, func(i, j int) bool {
+		return tombstones[i].OffboardedAt.Before(tombstones[j].OffboardedAt)
+	})
+	for i := range tombstones {
+		tombstones[i].Roles, tombstones[i].Grants = clone(tombstones[i].Roles), clone(tombstones[i].Grants)
+	}
+
+	return tombstones, nil
+}
+
+// GetGroup implements authority.Store
+func (s *MemoryStore) GetGroup(_ context.Context, groupName string) (*authority.Group, error) {
+	defer s.lock()()
+
+	i := find(s.data.groups, func(g authority.Group) bool { return g.Name == groupName })
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", authority.ErrGroupNotFound, groupName)
+	}
+
+	group := s.data.groups[i]
+
+	return &group, nil
+}
+
+// CreateGroup implements authority.Store
+func (s *MemoryStore) CreateGroup(_ context.Context, group *authority.Group) error {
+	defer s.lock()()
+
+	if find(s.data.groups, func(g authority.Group) bool { return g.Name == group.Name }) >= 0 {
+		return duplicate(authority.EntityGroup, group.Name)
+	}
+
+	group.ID = s.data.nextID("groups")
+	if group.CreatedAt.IsZero() {
+		group.CreatedAt = time.Now()
+	}
+	s.data.groups = append(s.data.groups, *group)
+
+	return nil
+}
+
+// DeleteGroup implements authority.Store, its members and its roles are deleted with it
+func (s *MemoryStore) DeleteGroup(_ context.Context, groupID uint) error {
+	defer s.lock()()
+
+	remove(&s.data.groups, func(g authority.Group) bool { return g.ID == groupID })
+	remove(&s.data.members, func(m authority.GroupMember) bool { return m.GroupID == groupID })
+	remove(&s.data.groupRoles, func(gr authority.GroupRole) bool { return gr.GroupID == groupID })
+
+	return nil
+}
+
+// AddGroupMember implements authority.Store
+func (s *MemoryStore) AddGroupMember(_ context.Context, member *authority.GroupMember) error {
+	defer s.lock()()
+
+	if !s.data.groupExists(member.GroupID) {
+		return missing(authority.EntityGroup, fmt.Sprint(member.GroupID))
+	}
+
+	if find(s.data.members, func(m authority.GroupMember) bool {
+		return m.GroupID == member.GroupID && m.UserKey == member.UserKey
+	}) >= 0 {
+		return nil
+	}
+
+	member.ID = s.data.nextID("group_members")
+	if member.CreatedAt.IsZero() {
+		member.CreatedAt = time.Now()
+	}
+	s.data.members = append(s.data.members, *member)
+
+	return nil
+}
+
+// RemoveGroupMember implements authority.Store
+func (s *MemoryStore) RemoveGroupMember(_ context.Context, groupID uint, userKey string) error {
+	defer s.lock()()
+
+	remove(&s.data.members, func(m authority.GroupMember) bool { return m.GroupID == groupID && m.UserKey == userKey })
+
+	return nil
+}
+
+// GetGroupMembers implements authority.Store
+func (s *MemoryStore) GetGroupMembers(_ context.Context, groupID uint) ([]string, error) {
+	defer s.lock()()
+
+	var users []string
+	for _, m := range s.data.members {
+		if m.GroupID == groupID {
+			users = append(users, m.UserKey)
+		}
+	}
+	sort.Strings(users)
+
+	return users, nil
+}
+
+// GetUserGroups implements authority.Store
+func (s *MemoryStore) GetUserGroups(_ context.Context, userKey string) ([]authority.Group, error) {
+	defer s.lock()()
+
+	groups := filter(s.data.groups, func(g authority.Group) bool {
+		return find(s.data.members, func(m authority.GroupMember) bool {
+			return m.GroupID == g.ID && m.UserKey == userKey
+		}) >= 0
+	})
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+
+	return groups, nil
+}
+
+// AssignGroupRole implements authority.Store
+func (s *MemoryStore) AssignGroupRole(_ context.Context, groupRole *authority.GroupRole) error {
+	defer s.lock()()
+
+	if !s.data.groupExists(groupRole.GroupID) || !s.data.roleExists(groupRole.RoleID) {
+		return missing(authority.EntityGroup, fmt.Sprintf("%d/%d", groupRole.GroupID, groupRole.RoleID))
+	}
+
+	if find(s.data.groupRoles, func(gr authority.GroupRole) bool {
+		return gr.GroupID == groupRole.GroupID && gr.RoleID == groupRole.RoleID
+	}) >= 0 {
+		return nil
+	}
+
+	groupRole.ID = s.data.nextID("group_roles")
+	s.data.groupRoles = append(s.data.groupRoles, *groupRole)
+
+	return nil
+}
+
+// RevokeGroupRole implements authority.Store
+func (s *MemoryStore) RevokeGroupRole(_ context.Context, groupID uint, roleID uint) error {
+	defer s.lock()()
+
+	remove(&s.data.groupRoles, func(gr authority.GroupRole) bool { return gr.GroupID == groupID && gr.RoleID == roleID })
+
+	return nil
+}
+
+// GetGroupRoleIDs implements authority.Store
+func (s *MemoryStore) GetGroupRoleIDs(_ context.Context, groupID uint) ([]uint, error) {
+	defer s.lock()()
+
+	var roleIDs []uint
+	for _, gr := range s.data.groupRoles {
+		if gr.GroupID == groupID {
+			roleIDs = append(roleIDs, gr.RoleID)
+		}
+	}
+	sort.Slice(roleIDs, func(i, j int) bool { return roleIDs[i] < roleIDs[j] })
+
+	return roleIDs, nil
+}
+
+// GetUserGroupRoleIDs implements authority.Store
+func (s *MemoryStore) GetUserGroupRoleIDs(_ context.Context, userKey string) ([]uint, error) {
+	defer s.lock()()
+
+	var roleIDs []uint
+	for _, gr := range s.data.groupRoles {
+		member := find(s.data.members, func(m authority.GroupMember) bool {
+			return m.GroupID == gr.GroupID && m.UserKey == userKey
+		}) >= 0
+		if member && !containsID(roleIDs, gr.RoleID) {
+			roleIDs = append(roleIDs, gr.RoleID)
+		}
+	}
+
+	return roleIDs, nil
+}
+
+// ForgetUser implements authority.Store
+func (s *MemoryStore) ForgetUser(_ context.Context, userKey string) error {
+	// the grants and the denials to the roles have an empty user key
+	if userKey == "" {
+		return nil
+	}
+
+	defer s.lock()()
+
+	d := s.data
+	remove(&d.userRoles, func(ur authority.UserRole) bool { return ur.UserKey == userKey })
+	remove(&d.claims, func(ac authority.AssignmentClaim) bool { return ac.UserKey == userKey })
+	remove(&d.resourcePerms, func(g authority.ResourcePermission) bool { return g.UserKey == userKey })
+	remove(&d.denials, func(pd authority.PermissionDenial) bool { return pd.UserKey == userKey })
+	remove(&d.members, func(m authority.GroupMember) bool { return m.UserKey == userKey })
+	remove(&d.tombstones, func(t authority.UserTombstone) bool { return t.UserKey == userKey })
+
+	var requestIDs []uint
+	for _, req := range d.requests {
+		if req.UserKey == userKey {
+			requestIDs = append(requestIDs, req.ID)
+		}
+	}
+	remove(&d.requests, func(req authority.AccessRequest) bool { return req.UserKey == userKey })
+	remove(&d.approvals, func(a authority.RequestApproval) bool { return containsID(requestIDs, a.RequestID) })
+
+	for i := range d.audit {
+		if d.audit[i].UserKey == userKey {
+			d.audit[i].UserKey, d.audit[i].UserID = "", 0
+		}
+	}
+
+	return nil
+}
+
+// GetPermissionDenials implements authority.Store
+func (s *MemoryStore) GetPermissionDenials(_ context.Context, permID uint) ([]authority.PermissionDenial, error) {
+	defer s.lock()()
+
+	return filter(s.data.denials, func(pd authority.PermissionDenial) bool { return pd.PermissionID == permID }), nil
+}
+
+// GetUserPermissionDenials implements authority.Store
+func (s *MemoryStore) GetUserPermissionDenials(_ context.Context, userKey string) ([]authority.PermissionDenial,
+	error) {
+	defer s.lock()()
+
+	return filter(s.data.denials, func(pd authority.PermissionDenial) bool { return pd.UserKey == userKey }), nil
+}
+
+// IsPermissionDenied implements authority.Store
+func (s *MemoryStore) IsPermissionDenied(_ context.Context, permID uint, userKey string, tenant string,
+	roleIDs []uint) (bool, error) {
+	defer s.lock()()
+
+	return find(s.data.denials, func(pd authority.PermissionDenial) bool {
+		return pd.PermissionID == permID &&
+			(userKey != "" && pd.UserKey == userKey && inTenant(pd.Tenant, tenant) || containsID(roleIDs, pd.RoleID))
+	}) >= 0, nil
+}
+
+// DenyPermission implements authority.Store
+func (s *MemoryStore) DenyPermission(_ context.Context, denial *authority.PermissionDenial) error {
+	defer s.lock()()
+
+	if !s.data.permissionExists(denial.PermissionID) || denial.RoleID != 0 && !s.data.roleExists(denial.RoleID) {
+		return missing(authority.EntityPermissionDenial, fmt.Sprint(denial.PermissionID))
+	}
+
+	if find(s.data.denials, func(pd authority.PermissionDenial) bool { return sameDenial(pd, *denial) }) >= 0 {
+		return nil
+	}
+
+	denial.ID = s.data.nextID("permission_denials")
+	if denial.CreatedAt.IsZero() {
+		denial.CreatedAt = time.Now()
+	}
+	s.data.denials = append(s.data.denials, *denial)
+
+	return nil
+}
+
+// RevokePermissionDenial implements authority.Store
+func (s *MemoryStore) RevokePermissionDenial(_ context.Context, denial *authority.PermissionDenial) error {
+	defer s.lock()()
+
+	remove(&s.data.denials, func(pd authority.PermissionDenial) bool { return sameDenial(pd, *denial) })
+
+	return nil
+}
+
+// sameDenial reports whether the denials are the same, by the unique key of the denials
+func sameDenial(a, b authority.PermissionDenial) bool {
+	return a.PermissionID == b.PermissionID && a.UserKey == b.UserKey && a.Tenant == b.Tenant && a.RoleID == b.RoleID
+}
+
+// GetUserRole implements authority.Store
+func (s *MemoryStore) GetUserRole(_ context.Context, userKey string, roleID uint, tenant string) (
+	*authority.UserRole, error) {
+	defer s.lock()()
+
+	i := find(s.data.userRoles, func(ur authority.UserRole) bool {
+		return ur.UserKey == userKey && ur.RoleID == roleID && ur.Tenant == tenant
+	})
+	if i < 0 {
+		return nil, fmt.Errorf("%w: role %d of the user %s", authority.ErrUserRoleNotFound, roleID, userKey)
+	}
+
+	userRole := s.data.userRoles[i]
+
+	return &userRole, nil
+}
+
+// GetUserRoles implements authority.Store
+func (s *MemoryStore) GetUserRoles(_ context.Context, userKey string) ([]authority.UserRole, error) {
+	defer s.lock()()
+
+	return filter(s.data.userRoles, func(ur authority.UserRole) bool { return ur.UserKey == userKey }), nil
+}
+
+// GetAssignedRoles implements authority.Store
+func (s *MemoryStore) GetAssignedRoles(_ context.Context, userKey string, tenant string, at time.Time) (
+	[]authority.Role, error) {
+	defer s.lock()()
+
+	var roles []authority.Role
+	for _, ur := range s.data.userRoles {
+		if ur.UserKey != userKey || !inTenant(ur.Tenant, tenant) || !inPeriod(ur.StartsAt, ur.ExpiresAt, at) {
+			continue
+		}
+
+		if i := find(s.data.roles, func(r authority.Role) bool { return r.ID == ur.RoleID }); i >= 0 {
+			roles = append(roles, copyRole(s.data.roles[i]))
+		}
+	}
+
+	return roles, nil
+}
+
+// GetUsersRoles implements authority.Store
+func (s *MemoryStore) GetUsersRoles(_ context.Context, userKeys []string) ([]authority.UserRole, error) {
+	defer s.lock()()
+
+	return filter(s.data.userRoles, func(ur authority.UserRole) bool { return containsKey(userKeys, ur.UserKey) }), nil
+}
+
+// GetRoleUsers implements authority.Store
+func (s *MemoryStore) GetRoleUsers(_ context.Context, roleID uint, tenant string, at time.Time, numeric bool,
+	page authority.Page) ([]string, error) {
+	defer s.lock()()
+
+	type roleUser struct {
+		id  uint
+		key string
+	}
+
+	seen := make(map[roleUser]bool)
+	var users []roleUser
+	for _, ur := range s.data.userRoles {
+		u := roleUser{ur.UserID, ur.UserKey}
+		if ur.RoleID != roleID || ur.Tenant != tenant || !inPeriod(ur.StartsAt, ur.ExpiresAt, at) ||
+			numeric && ur.UserID == 0 || seen[u] {
+			continue
+		}
+		seen[u] = true
+		users = append(users, u)
+	}
+
+	// the users without an id are last
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].id != users[j].id {
+			return users[j].id == 0 || users[i].id != 0 && users[i].id < users[j].id
+		}
+
+		return users[i].key < users[j].key
+	})
+
+	if page.Offset > 0 {
+		if page.Offset > len(users) {
+			page.Offset = len(users)
+		}
+		users = users[page.Offset:]
+	}
+	if page.Limit > 0 && page.Limit < len(users) {
+		users = users[:page.Limit]
+	}
+
+	keys := make([]string, 0, len(users))
+	for _, u := range users {
+		keys = append(keys, u.key)
+	}
+
+	return keys, nil
+}
+
+// ListUserRoles implements authority.Store
+func (s *MemoryStore) ListUserRoles(context.Context) ([]authority.UserRole, error) {
+	defer s.lock()()
+
+	// the assignments of the users without an id are last
+	userRoles := clone(s.data.userRoles)
+	sort.SliceStable(userRoles, func(i, j int) bool {
+		a, b := userRoles[i].UserID, userRoles[j].UserID
+
+		return a != b && (b == 0 || a != 0 && a < b)
+	})
+
+	return userRoles, nil
+}
+
+// AssignRole implements authority.Store
+func (s *MemoryStore) AssignRole(_ context.Context, userRole *authority.UserRole) error {
+	defer s.lock()()
+
+	return s.data.assignRole(userRole)
+}
+
+// AssignRoles implements authority.Store
+func (s *MemoryStore) AssignRoles(_ context.Context, userRoles []authority.UserRole) error {
+	defer s.lock()()
+
+	for i := range userRoles {
+		if err := s.data.assignRole(&userRoles[i]); err != nil && err != authority.ErrRoleAlreadyAssigned {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// assignRole stores an assignment unless the user has the role in the tenant
+func (d *memoryData) assignRole(userRole *authority.UserRole) error {
+	if !d.roleExists(userRole.RoleID) {
+		return missing(authority.EntityUserRole, fmt.Sprintf("%s/%d", userRole.UserKey, userRole.RoleID))
+	}
+
+	if find(d.userRoles, func(ur authority.UserRole) bool {
+		return ur.UserKey == userRole.UserKey && ur.RoleID == userRole.RoleID && ur.Tenant == userRole.Tenant
+	}) >= 0 {
+		return authority.ErrRoleAlreadyAssigned
+	}
+
+	userRole.ID = d.nextID("user_roles")
+	if userRole.CreatedAt.IsZero() {
+		userRole.CreatedAt = time.Now()
+	}
+	d.userRoles = append(d.userRoles, *userRole)
+
+	return nil
+}
+
+// GetTimeBoundUserRoles implements authority.Store
+func (s *MemoryStore) GetTimeBoundUserRoles(_ context.Context, roleID uint, tenant string, userKeys []string) (
+	[]authority.UserRole, error) {
+	defer s.lock()()
+
+	return filter(s.data.userRoles, func(ur authority.UserRole) bool {
+		return !ur.ExpiresAt.IsZero() && ur.Tenant == tenant && (roleID == 0 || ur.RoleID == roleID) &&
+			(len(userKeys) == 0 || containsKey(userKeys, ur.UserKey))
+	}), nil
+}
+
+// GetExpiredUserRoles implements authority.Store
+func (s *MemoryStore) GetExpiredUserRoles(_ context.Context, at time.Time) ([]authority.UserRole, error) {
+	defer s.lock()()
+
+	return filter(s.data.userRoles, func(ur authority.UserRole) bool {
+		return !ur.ExpiresAt.IsZero() && !ur.ExpiresAt.After(at)
+	}), nil
+}
+
+// SetUserRolesExpiry implements authority.Store
+func (s *MemoryStore) SetUserRolesExpiry(_ context.Context, userRoleIDs []uint, expiresAt time.Time) error {
+	defer s.lock()()
+
+	for i := range s.data.userRoles {
+		if containsID(userRoleIDs, s.data.userRoles[i].ID) {
+			s.data.userRoles[i].ExpiresAt = expiresAt
+		}
+	}
+
+	return nil
+}
+
+// RevokeRole implements authority.Store
+func (s *MemoryStore) RevokeRole(_ context.Context, userKey string, roleID uint, tenant string) error {
+	defer s.lock()()
+
+	remove(&s.data.userRoles, func(ur authority.UserRole) bool {
+		return ur.UserKey == userKey && ur.RoleID == roleID && ur.Tenant == tenant
+	})
+	remove(&s.data.claims, func(ac authority.AssignmentClaim) bool {
+		return ac.UserKey == userKey && ac.RoleID == roleID && ac.Tenant == tenant
+	})
+
+	return nil
+}
+
+// ClaimAssignments implements authority.Store
+func (s *MemoryStore) ClaimAssignments(_ context.Context, claims []authority.AssignmentClaim) error {
+	defer s.lock()()
+
+	for _, claim := range claims {
+		if !s.data.roleExists(claim.RoleID) {
+			return missing(authority.EntityUserRole, fmt.Sprintf("%s/%d", claim.UserKey, claim.RoleID))
+		}
+
+		i := find(s.data.claims, func(ac authority.AssignmentClaim) bool {
+			return ac.UserKey == claim.UserKey && ac.RoleID == claim.RoleID && ac.Tenant == claim.Tenant &&
+				ac.Source == claim.Source
+		})
+		if i >= 0 {
+			s.data.claims[i].ClaimedAt = claim.ClaimedAt
+			continue
+		}
+
+		claim.ID = s.data.nextID("assignment_claims")
+		s.data.claims = append(s.data.claims, claim)
+	}
+
+	return nil
+}
+
+// ListConflictingClaims implements authority.Store
+func (s *MemoryStore) ListConflictingClaims(context.Context) ([]authority.AssignmentClaim, error) {
+	defer s.lock()()
+
+	type assignment struct {
+		userKey string
+		roleID  uint
+		tenant  string
+	}
+
+	sources := make(map[assignment]map[string]bool)
+	for _, ac := range s.data.claims {
+		key := assignment{ac.UserKey, ac.RoleID, ac.Tenant}
+		if sources[key] == nil {
+			sources[key] = make(map[string]bool)
+		}
+		sources[key][ac.Source] = true
+	}
+
+	claims := filter(s.data.claims, func(ac authority.AssignmentClaim) bool {
+		return len(sources[assignment{ac.UserKey, ac.RoleID, ac.Tenant}]) > 1
+	})
+	sort.Slice(claims, func(i, j int) bool {
+		a, b := claims[i], claims[j]
+		switch {
+		case a.Tenant != b.Tenant:
+			return a.Tenant < b.Tenant
+		case a.UserKey != b.UserKey:
+			return a.UserKey < b.UserKey
+		case a.RoleID != b.RoleID:
+			return a.RoleID < b.RoleID
+		default:
+			return a.Source < b.Source
+		}
+	})
+
+	return claims, nil
+}
+
+// GetRoleComposites implements authority.Store
+func (s *MemoryStore) GetRoleComposites(_ context.Context, roleIDs []uint) ([]authority.RoleComposite, error) {
+	defer s.lock()()
+
+	return filter(s.data.composites, func(rc authority.RoleComposite) bool { return containsID(roleIDs, rc.RoleID) }),
+		nil
+}
+
+// AddRoleComposite implements authority.Store
+func (s *MemoryStore) AddRoleComposite(_ context.Context, roleID, memberID uint) error {
+	defer s.lock()()
+
+	if find(s.data.composites, func(rc authority.RoleComposite) bool {
+		return rc.RoleID == roleID && rc.MemberID == memberID
+	}) >= 0 {
+		return nil
+	}
+
+	if !s.data.roleExists(roleID) || !s.data.roleExists(memberID) {
+		return missing(authority.EntityRoleComposite, fmt.Sprintf("%d/%d", roleID, memberID))
+	}
+
+	s.data.composites = append(s.data.composites, authority.RoleComposite{
+		ID: s.data.nextID("role_composites"), RoleID: roleID, MemberID: memberID,
+	})
+
+	return nil
+}
+
+// RemoveRoleComposite implements authority.Store
+func (s *MemoryStore) RemoveRoleComposite(_ context.Context, roleID, memberID uint) error {
+	defer s.lock()()
+
+	remove(&s.data.composites, func(rc authority.RoleComposite) bool {
+		return rc.RoleID == roleID && rc.MemberID == memberID
+	})
+
+	return nil
+}
+
+// GetRoleParents implements authority.Store
+func (s *MemoryStore) GetRoleParents(_ context.Context, roleIDs []uint) ([]authority.RoleParent, error) {
+	defer s.lock()()
+
+	return filter(s.data.parents, func(rp authority.RoleParent) bool { return containsID(roleIDs, rp.RoleID) }), nil
+}
+
+// GetRoleChildren implements authority.Store
+func (s *MemoryStore) GetRoleChildren(_ context.Context, parentID uint) ([]authority.RoleParent, error) {
+	defer s.lock()()
+
+	return filter(s.data.parents, func(rp authority.RoleParent) bool { return rp.ParentID == parentID }), nil
+}
+
+// SetRoleParent implements authority.Store
+func (s *MemoryStore) SetRoleParent(_ context.Context, roleID, parentID uint) error {
+	defer s.lock()()
+
+	if !s.data.roleExists(roleID) || !s.data.roleExists(parentID) {
+		return missing(authority.EntityRoleParent, fmt.Sprintf("%d/%d", roleID, parentID))
+	}
+
+	if i := find(s.data.parents, func(rp authority.RoleParent) bool { return rp.RoleID == roleID }); i >= 0 {
+		s.data.parents[i].ParentID = parentID
+		return nil
+	}
+
+	s.data.parents = append(s.data.parents, authority.RoleParent{
+		ID: s.data.nextID("role_parents"), RoleID: roleID, ParentID: parentID,
+	})
+
+	return nil
+}
+
+// RemoveRoleParent implements authority.Store
+func (s *MemoryStore) RemoveRoleParent(_ context.Context, roleID uint) error {
+	defer s.lock()()
+
+	remove(&s.data.parents, func(rp authority.RoleParent) bool { return rp.RoleID == roleID })
+
+	return nil
+}
+
+// GetPolicy implements authority.Store
+func (s *MemoryStore) GetPolicy(_ context.Context, name string) (*authority.Policy, error) {
+	defer s.lock()()
+
+	i := find(s.data.policies, func(p authority.Policy) bool { return p.Name == name })
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", authority.ErrPolicyNotFound, name)
+	}
+
+	policy := s.data.policies[i]
+
+	return &policy, nil
+}
+
+// ListPolicies implements authority.Store
+func (s *MemoryStore) ListPolicies(context.Context) ([]authority.Policy, error) {
+	defer s.lock()()
+
+	policies := clone(s.data.policies)
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+
+	return policies, nil
+}
+
+// SavePolicy implements authority.Store
+func (s *MemoryStore) SavePolicy(_ context.Context, policy *authority.Policy) error {
+	defer s.lock()()
+
+	if i := find(s.data.policies, func(p authority.Policy) bool { return p.Name == policy.Name }); i >= 0 {
+		s.data.policies[i].Expression = policy.Expression
+		policy.ID = s.data.policies[i].ID
+		return nil
+	}
+
+	policy.ID = s.data.nextID("policies")
+	s.data.policies = append(s.data.policies, *policy)
+
+	return nil
+}
+
+// DeletePolicy implements authority.Store
+func (s *MemoryStore) DeletePolicy(_ context.Context, name string) error {
+	defer s.lock()()
+
+	remove(&s.data.policies, func(p authority.Policy) bool { return p.Name == name })
+
+	return nil
+}
+
+// CreateAuditEntry implements authority.Store
+func (s *MemoryStore) CreateAuditEntry(_ context.Context, entry *authority.AuditEntry) error {
+	defer s.lock()()
+
+	entry.ID = s.data.nextID("audit_entries")
+	s.data.audit = append(s.data.audit, *entry)
+
+	return nil
+}
+
+// ListChanges implements authority.Store
+func (s *MemoryStore) ListChanges(_ context.Context, afterID uint, before time.Time, limit int) (
+	[]authority.AuditEntry, error) {
+	defer s.lock()()
+
+	entries := filter(s.data.audit, func(e authority.AuditEntry) bool {
+		return e.ID > afterID && e.CreatedAt.Before(before) && e.Action != authority.AuditPermissionChecked
+	})
+	if limit > 0 && limit < len(entries) {
+		entries = entries[:limit]
+	}
+
+	return entries, nil
+}
+
+// CreateAccessRequest implements authority.Store
+func (s *MemoryStore) CreateAccessRequest(_ context.Context, req *authority.AccessRequest) error {
+	defer s.lock()()
+
+	req.ID = s.data.nextID("access_requests")
+	s.data.requests = append(s.data.requests, *req)
+
+	return nil
+}
+
+// GetAccessRequest implements authority.Store
+func (s *MemoryStore) GetAccessRequest(_ context.Context, id uint) (*authority.AccessRequest, error) {
+	defer s.lock()()
+
+	i := find(s.data.requests, func(req authority.AccessRequest) bool { return req.ID == id })
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %d", authority.ErrAccessRequestNotFound, id)
+	}
+
+	req := s.data.requests[i]
+
+	return &req, nil
+}
+
+// ListAccessRequests implements authority.Store
+func (s *MemoryStore) ListAccessRequests(_ context.Context, userKey string, status string) (
+	[]authority.AccessRequest, error) {
+	defer s.lock()()
+
+	return filter(s.data.requests, func(req authority.AccessRequest) bool {
+		return (userKey == "" || req.UserKey == userKey) && (status == "" || req.Status == status)
+	}), nil
+}
+
+// DecideAccessRequest implements authority.Store
+func (s *MemoryStore) DecideAccessRequest(_ context.Context, req *authority.AccessRequest) error {
+	defer s.lock()()
+
+	i := find(s.data.requests, func(r authority.AccessRequest) bool {
+		return r.ID == req.ID && r.Status == authority.RequestPending
+	})
+	if i < 0 {
+		return authority.ErrAccessRequestDecided
+	}
+
+	stored := &s.data.requests[i]
+	stored.Status, stored.DecidedBy, stored.DecidedAt = req.Status, req.DecidedBy, req.DecidedAt
+
+	return nil
+}
+
+// AddRequestApproval implements authority.Store
+func (s *MemoryStore) AddRequestApproval(_ context.Context, approval *authority.RequestApproval) (int, error) {
+	defer s.lock()()
+
+	if find(s.data.requests, func(req authority.AccessRequest) bool { return req.ID == approval.RequestID }) < 0 {
+		return 0, fmt.Errorf("%w: %d", authority.ErrAccessRequestNotFound, approval.RequestID)
+	}
+
+	if find(s.data.approvals, func(a authority.RequestApproval) bool {
+		return a.RequestID == approval.RequestID && a.Approver == approval.Approver
+	}) >= 0 {
+		return 0, authority.ErrApprovalAlreadyGiven
+	}
+
+	approval.ID = s.data.nextID("request_approvals")
+	s.data.approvals = append(s.data.approvals, *approval)
+
+	return len(filter(s.data.approvals, func(a authority.RequestApproval) bool {
+		return a.RequestID == approval.RequestID
+	})), nil
+}
+
+// GetRequestApprovals implements authority.Store
+func (s *MemoryStore) GetRequestApprovals(_ context.Context, requestID uint) ([]authority.RequestApproval, error) {
+	defer s.lock()()
+
+	return append([]authority.RequestApproval{}, filter(s.data.approvals, func(a authority.RequestApproval) bool {
+		return a.RequestID == requestID
+	})...), nil
+}
+
+// CreateImportBatch implements authority.Store
+func (s *MemoryStore) CreateImportBatch(_ context.Context, batch *authority.ImportBatch) (bool, error) {
+	defer s.lock()()
+
+	if find(s.data.imports, func(b authority.ImportBatch) bool { return b.Key == batch.Key }) >= 0 {
+		return false, nil
+	}
+
+	batch.ID = s.data.nextID("import_batches")
+	s.data.imports = append(s.data.imports, *batch)
+
+	return true, nil
+}
+
+// GetImportBatch implements authority.Store
+func (s *MemoryStore) GetImportBatch(_ context.Context, key string) (*authority.ImportBatch, error) {
+	defer s.lock()()
+
+	i := find(s.data.imports, func(b authority.ImportBatch) bool { return b.Key == key })
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s", authority.ErrImportNotFound, key)
+	}
+
+	batch := s.data.imports[i]
+
+	return &batch, nil
+}
+
+// SaveImportBatch implements authority.Store
+func (s *MemoryStore) SaveImportBatch(_ context.Context, batch *authority.ImportBatch) error {
+	defer s.lock()()
+
+	if i := find(s.data.imports, func(b authority.ImportBatch) bool { return b.Key == batch.Key }); i >= 0 {
+		stored := &s.data.imports[i]
+		stored.Status, stored.Items, stored.Error = batch.Status, batch.Items, batch.Error
+		stored.StartedAt, stored.FinishedAt = batch.StartedAt, batch.FinishedAt
+		batch.ID = stored.ID
+		return nil
+	}
+
+	batch.ID = s.data.nextID("import_batches")
+	s.data.imports = append(s.data.imports, *batch)
+
+	return nil
+}
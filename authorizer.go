@@ -0,0 +1,382 @@
+package authority
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"net/http"
+	"time"
+)
+
+// Authorizer has the methods of Authority, e.g. for the code depending on authority to take an Authorizer and
+// be tested with a mock or a fake. WithTx and Committed are left out, an instance using a transaction is an
+// *Authority
+type Authorizer interface {
+	// the permissions of the actions on the resources
+	CreateActionPermission(action string, resource string) error
+	CreateActionPermissionCtx(ctx context.Context, action string, resource string) error
+	SetPermissionAction(permName string, action string, resource string) error
+	SetPermissionActionCtx(ctx context.Context, permName string, action string, resource string) error
+	Can(userID uint, action string, resource string) (bool, error)
+	CanCtx(ctx context.Context, userID uint, action string, resource string) (bool, error)
+
+	// the access requests
+	RequestRole(userID uint, roleName string, reason string) (*AccessRequest, error)
+	RequestRoleCtx(ctx context.Context, userID uint, roleName string, reason string) (*AccessRequest, error)
+	GetAccessRequest(id uint) (*AccessRequest, error)
+	GetAccessRequestCtx(ctx context.Context, id uint) (*AccessRequest, error)
+	GetPendingRequests() ([]AccessRequest, error)
+	GetPendingRequestsCtx(ctx context.Context) ([]AccessRequest, error)
+	GetUserRequests(userID uint) ([]AccessRequest, error)
+	GetUserRequestsCtx(ctx context.Context, userID uint) ([]AccessRequest, error)
+	ApproveRequest(id uint, approver string) (*AccessRequest, error)
+	ApproveRequestCtx(ctx context.Context, id uint, approver string) (*AccessRequest, error)
+	DenyRequest(id uint, approver string) (*AccessRequest, error)
+	DenyRequestCtx(ctx context.Context, id uint, approver string) (*AccessRequest, error)
+	GetRequestApprovals(id uint) ([]RequestApproval, error)
+	GetRequestApprovalsCtx(ctx context.Context, id uint) ([]RequestApproval, error)
+	FinalizeRequest(token string, approver string) (*AccessRequest, error)
+	FinalizeRequestCtx(ctx context.Context, token string, approver string) (*AccessRequest, error)
+	ApprovalTokens(req *AccessRequest) (ApprovalTokens, error)
+
+	// the roles, the permissions and their assignments
+	CreateRole(roleName string) error
+	CreateRoleCtx(ctx context.Context, roleName string) error
+	CreatePermission(permName string) error
+	CreatePermissionCtx(ctx context.Context, permName string) error
+	AssignPermissions(roleName string, permNames []string) error
+	AssignPermissionsCtx(ctx context.Context, roleName string, permNames []string) error
+	AssignPermissionsBetween(roleName string, permNames []string, startAt, endAt time.Time) error
+	AssignPermissionsBetweenCtx(ctx context.Context, roleName string, permNames []string, startAt,
+		endAt time.Time) error
+	AssignRole(userID uint, roleName string) error
+	AssignRoleCtx(ctx context.Context, userID uint, roleName string) error
+	AssignRoleFrom(userID uint, roleName string, startAt time.Time) error
+	AssignRoleFromCtx(ctx context.Context, userID uint, roleName string, startAt time.Time) error
+	AssignRoleUntil(userID uint, roleName string, expiresAt time.Time) error
+	AssignRoleUntilCtx(ctx context.Context, userID uint, roleName string, expiresAt time.Time) error
+	CheckRole(userID uint, roleName string) (bool, error)
+	CheckRoleCtx(ctx context.Context, userID uint, roleName string) (bool, error)
+	CheckPermission(userID uint, permName string) (bool, error)
+	CheckPermissionCtx(ctx context.Context, userID uint, permName string) (bool, error)
+	CheckRolePermission(roleName string, permName string) (bool, error)
+	CheckRolePermissionCtx(ctx context.Context, roleName string, permName string) (bool, error)
+	RevokeRole(userID uint, roleName string) error
+	RevokeRoleCtx(ctx context.Context, userID uint, roleName string) error
+	RevokePermission(userID uint, permName string) error
+	RevokePermissionCtx(ctx context.Context, userID uint, permName string) error
+	RevokeRolePermission(roleName string, permName string) error
+	RevokeRolePermissionCtx(ctx context.Context, roleName string, permName string) error
+	GetRoles() ([]string, error)
+	GetRolesCtx(ctx context.Context) ([]string, error)
+	GetRolesDetailed() ([]Role, error)
+	GetRolesDetailedCtx(ctx context.Context) ([]Role, error)
+	GetRole(roleName string) (*Role, error)
+	GetRoleCtx(ctx context.Context, roleName string) (*Role, error)
+	GetRoleByID(roleID uint) (*Role, error)
+	GetRoleByIDCtx(ctx context.Context, roleID uint) (*Role, error)
+	GetRoleUsers(roleName string, page Page) ([]uint, error)
+	GetRoleUsersCtx(ctx context.Context, roleName string, page Page) ([]uint, error)
+	GetRoleUserKeys(roleName string, page Page) ([]string, error)
+	GetRoleUserKeysCtx(ctx context.Context, roleName string, page Page) ([]string, error)
+	GetUserRoles(userID uint) ([]string, error)
+	GetUserRolesCtx(ctx context.Context, userID uint) ([]string, error)
+	GetUserRolesFull(userID uint) ([]Role, error)
+	GetUserRolesFullCtx(ctx context.Context, userID uint) ([]Role, error)
+	GetUserPermissions(userID uint) ([]string, error)
+	GetUserPermissionsCtx(ctx context.Context, userID uint) ([]string, error)
+	GetUserPermissionDetails(userID uint) ([]Permission, error)
+	GetUserPermissionDetailsCtx(ctx context.Context, userID uint) ([]Permission, error)
+	GetPermissions() ([]string, error)
+	GetPermissionsCtx(ctx context.Context) ([]string, error)
+	GetPermissionsDetailed() ([]Permission, error)
+	GetPermissionsDetailedCtx(ctx context.Context) ([]Permission, error)
+	GetPermission(permName string) (*Permission, error)
+	GetPermissionCtx(ctx context.Context, permName string) (*Permission, error)
+	GetPermissionByID(permID uint) (*Permission, error)
+	GetPermissionByIDCtx(ctx context.Context, permID uint) (*Permission, error)
+	DeleteRole(roleName string) error
+	DeleteRoleCtx(ctx context.Context, roleName string) error
+	DeletePermission(permName string) error
+	DeletePermissionCtx(ctx context.Context, permName string) error
+
+	// the bulk assignments
+	AssignRoles(userID uint, roleNames []string) error
+	AssignRolesCtx(ctx context.Context, userID uint, roleNames []string) error
+	AssignRoleToUsers(roleName string, userIDs []uint) error
+	AssignRoleToUsersCtx(ctx context.Context, roleName string, userIDs []uint) error
+
+	// the checks bypassing the cache
+	CheckPermissionFresh(userID uint, permName string) (bool, error)
+	CheckPermissionFreshCtx(ctx context.Context, userID uint, permName string) (bool, error)
+
+	// the capability manifests
+	BuildCapabilityManifest(userID uint) (*CapabilityManifest, error)
+	BuildCapabilityManifestCtx(ctx context.Context, userID uint) (*CapabilityManifest, error)
+
+	// the documentation of the permissions
+	PermissionCatalog() ([]PermissionDoc, error)
+	PermissionCatalogCtx(ctx context.Context) ([]PermissionDoc, error)
+
+	// the change feed
+	GetChangesSince(cursor string, limit int) (*ChangeFeed, error)
+	GetChangesSinceCtx(ctx context.Context, cursor string, limit int) (*ChangeFeed, error)
+
+	// the checks of several permissions
+	CheckPermissions(userID uint, permNames []string) (map[string]bool, error)
+	CheckPermissionsCtx(ctx context.Context, userID uint, permNames []string) (map[string]bool, error)
+	CheckAnyPermission(userID uint, permNames []string) (bool, error)
+	CheckAnyPermissionCtx(ctx context.Context, userID uint, permNames []string) (bool, error)
+	CheckAllPermissions(userID uint, permNames []string) (bool, error)
+	CheckAllPermissionsCtx(ctx context.Context, userID uint, permNames []string) (bool, error)
+
+	// the composite roles
+	AddCompositeRoles(roleName string, memberNames []string) error
+	AddCompositeRolesCtx(ctx context.Context, roleName string, memberNames []string) error
+	RemoveCompositeRole(roleName string, memberName string) error
+	RemoveCompositeRoleCtx(ctx context.Context, roleName string, memberName string) error
+	GetCompositeRoles(roleName string) ([]string, error)
+	GetCompositeRolesCtx(ctx context.Context, roleName string) ([]string, error)
+	FlattenRole(roleName string) ([]string, error)
+	FlattenRoleCtx(ctx context.Context, roleName string) ([]string, error)
+
+	// the conditions of the permissions
+	SetPermissionCondition(permName string, condition string) error
+	SetPermissionConditionCtx(ctx context.Context, permName string, condition string) error
+
+	// the explained decisions of the checks
+	CheckPermissionDetailed(userID uint, permName string) (Decision, error)
+	CheckPermissionDetailedCtx(ctx context.Context, userID uint, permName string) (Decision, error)
+	CheckPermissionDetailedInTenant(userID uint, permName string, tenant string) (Decision, error)
+	CheckPermissionDetailedInTenantCtx(ctx context.Context, userID uint, permName string, tenant string) (
+		Decision, error)
+
+	// the default roles
+	MaterializeDefaultRoles(userIDs []uint) error
+	MaterializeDefaultRolesCtx(ctx context.Context, userIDs []uint) error
+
+	// the denials
+	DenyPermission(userID uint, permName string) error
+	DenyPermissionCtx(ctx context.Context, userID uint, permName string) error
+	RevokePermissionDenial(userID uint, permName string) error
+	RevokePermissionDenialCtx(ctx context.Context, userID uint, permName string) error
+	DenyRolePermission(roleName string, permName string) error
+	DenyRolePermissionCtx(ctx context.Context, roleName string, permName string) error
+	RevokeRolePermissionDenial(roleName string, permName string) error
+	RevokeRolePermissionDenialCtx(ctx context.Context, roleName string, permName string) error
+	GetPermissionDenials(permName string) ([]PermissionDenial, error)
+	GetPermissionDenialsCtx(ctx context.Context, permName string) ([]PermissionDenial, error)
+
+	// the details of the assignments
+	GetUserRoleDetails(userID uint) ([]UserRoleDetail, error)
+	GetUserRoleDetailsCtx(ctx context.Context, userID uint) ([]UserRoleDetail, error)
+
+	// the time-bound assignments
+	ExtendAssignments(filter AssignmentFilter, newExpiry time.Time) (int, error)
+	ExtendAssignmentsCtx(ctx context.Context, filter AssignmentFilter, newExpiry time.Time) (int, error)
+	SweepExpiredAssignments() (int, error)
+	SweepExpiredAssignmentsCtx(ctx context.Context) (int, error)
+
+	// the fixtures
+	LoadFixtures(ctx context.Context, fsys fs.FS, names ...string) error
+
+	// the groups
+	CreateGroup(groupName string) error
+	CreateGroupCtx(ctx context.Context, groupName string) error
+	DeleteGroup(groupName string) error
+	DeleteGroupCtx(ctx context.Context, groupName string) error
+	AddUserToGroup(userID uint, groupName string) error
+	AddUserToGroupCtx(ctx context.Context, userID uint, groupName string) error
+	RemoveUserFromGroup(userID uint, groupName string) error
+	RemoveUserFromGroupCtx(ctx context.Context, userID uint, groupName string) error
+	AssignRoleToGroup(groupName string, roleName string) error
+	AssignRoleToGroupCtx(ctx context.Context, groupName string, roleName string) error
+	RevokeRoleFromGroup(groupName string, roleName string) error
+	RevokeRoleFromGroupCtx(ctx context.Context, groupName string, roleName string) error
+	GetGroupRoles(groupName string) ([]string, error)
+	GetGroupRolesCtx(ctx context.Context, groupName string) ([]string, error)
+	GetGroupMembers(groupName string) ([]string, error)
+	GetGroupMembersCtx(ctx context.Context, groupName string) ([]string, error)
+	GetUserGroups(userID uint) ([]string, error)
+	GetUserGroupsCtx(ctx context.Context, userID uint) ([]string, error)
+
+	// the hierarchy of the roles
+	SetRoleParent(roleName string, parentName string) error
+	SetRoleParentCtx(ctx context.Context, roleName string, parentName string) error
+	RemoveRoleParent(roleName string) error
+	RemoveRoleParentCtx(ctx context.Context, roleName string) error
+	GetRoleChildren(roleName string) ([]string, error)
+	GetRoleChildrenCtx(ctx context.Context, roleName string) ([]string, error)
+
+	// the http middlewares
+	RequirePermission(permName string, userID UserIDExtractor) func(http.Handler) http.Handler
+	RequirePermissionWith(permName string, opts MiddlewareOptions) func(http.Handler) http.Handler
+	ConventionMiddleware(opts ConventionOptions) func(http.Handler) http.Handler
+
+	// the idempotent imports
+	GetImportStatus(key string) (*ImportBatch, error)
+	GetImportStatusCtx(ctx context.Context, key string) (*ImportBatch, error)
+
+	// the lifecycle of the roles
+	CreateDraftRole(roleName string) error
+	CreateDraftRoleCtx(ctx context.Context, roleName string) error
+	ActivateRole(roleName string) error
+	ActivateRoleCtx(ctx context.Context, roleName string) error
+	RetireRole(roleName string) error
+	RetireRoleCtx(ctx context.Context, roleName string) error
+	GetRoleState(roleName string) (string, error)
+	GetRoleStateCtx(ctx context.Context, roleName string) (string, error)
+
+	// the segments of the users granted the roles
+	AssignRoleToSegment(roleName string, percent int, seed string) error
+	AssignRoleToSegmentCtx(ctx context.Context, roleName string, percent int, seed string) error
+
+	// the batch loaders
+	LoadUserRoles(ctx context.Context, userIDs []uint) ([][]string, []error)
+	LoadRolePermissions(ctx context.Context, roleNames []string) ([][]string, []error)
+
+	// the offboarding of the users
+	OffboardUser(userID uint, opts OffboardOptions) error
+	OffboardUserCtx(ctx context.Context, userID uint, opts OffboardOptions) error
+	RevokeAllRoles(userID uint) error
+	RevokeAllRolesCtx(ctx context.Context, userID uint) error
+	ForgetUser(userID uint) error
+	ForgetUserCtx(ctx context.Context, userID uint) error
+	GetUserTombstones(userID uint) ([]UserTombstone, error)
+	GetUserTombstonesCtx(ctx context.Context, userID uint) ([]UserTombstone, error)
+
+	// the paginated lists
+	ListRoles(opts ListOptions) (*RoleList, error)
+	ListRolesCtx(ctx context.Context, opts ListOptions) (*RoleList, error)
+	ListPermissions(opts ListOptions) (*PermissionList, error)
+	ListPermissionsCtx(ctx context.Context, opts ListOptions) (*PermissionList, error)
+
+	// the policies
+	DefinePolicy(name string, expression string) error
+	DefinePolicyCtx(ctx context.Context, name string, expression string) error
+	DeletePolicy(name string) error
+	DeletePolicyCtx(ctx context.Context, name string) error
+	CheckPolicy(userID uint, name string) (bool, error)
+	CheckPolicyCtx(ctx context.Context, userID uint, name string) (bool, error)
+	ReloadPolicies(ctx context.Context) error
+	WatchPolicies(ctx context.Context, opts PolicyWatchOptions) error
+
+	// the connection pool
+	PoolStats() PoolStats
+
+	// the declarative configuration
+	Reconcile(decl *Declaration, opts ReconcileOptions) (*ReconcilePlan, error)
+	ReconcileCtx(ctx context.Context, decl *Declaration, opts ReconcileOptions) (*ReconcilePlan, error)
+
+	// the replication
+	ResolveConflicts(remote Store) ([]ReplicationConflict, error)
+	ResolveConflictsCtx(ctx context.Context, remote Store) ([]ReplicationConflict, error)
+
+	// the access reports
+	GenerateAccessReport(w io.Writer, format ReportFormat) error
+	GenerateAccessReportCtx(ctx context.Context, w io.Writer, format ReportFormat) error
+
+	// the permissions on the resources
+	GrantPermissionOnResource(userID uint, permName string, resourceType string, resourceID interface{}) error
+	GrantPermissionOnResourceCtx(ctx context.Context, userID uint, permName string, resourceType string,
+		resourceID interface{}) error
+	RevokePermissionOnResource(userID uint, permName string, resourceType string, resourceID interface{}) error
+	RevokePermissionOnResourceCtx(ctx context.Context, userID uint, permName string, resourceType string,
+		resourceID interface{}) error
+	GrantRolePermissionOnResource(roleName string, permName string, resourceType string, resourceID interface{}) error
+	GrantRolePermissionOnResourceCtx(ctx context.Context, roleName string, permName string, resourceType string,
+		resourceID interface{}) error
+	RevokeRolePermissionOnResource(roleName string, permName string, resourceType string, resourceID interface{}) error
+	RevokeRolePermissionOnResourceCtx(ctx context.Context, roleName string, permName string, resourceType string,
+		resourceID interface{}) error
+	CheckPermissionOnResource(userID uint, permName string, resourceType string, resourceID interface{}) (bool, error)
+	CheckPermissionOnResourceCtx(ctx context.Context, userID uint, permName string, resourceType string,
+		resourceID interface{}) (bool, error)
+	GetResourcePermissions(resourceType string, resourceID interface{}) ([]ResourcePermission, error)
+	GetResourcePermissionsCtx(ctx context.Context, resourceType string, resourceID interface{}) ([]ResourcePermission,
+		error)
+
+	// the sensitivity classes
+	RequiresApproval(permName string) bool
+
+	// the snapshots
+	Export() (*PolicySnapshot, error)
+	ExportCtx(ctx context.Context) (*PolicySnapshot, error)
+	Import(snapshot *PolicySnapshot, mode ImportMode) error
+	ImportCtx(ctx context.Context, snapshot *PolicySnapshot, mode ImportMode) error
+	ExportPseudonymized(key []byte) (*PolicySnapshot, error)
+	ExportPseudonymizedCtx(ctx context.Context, key []byte) (*PolicySnapshot, error)
+
+	// the sources of the assignments
+	GetAssignmentConflicts() ([]AssignmentConflict, error)
+	GetAssignmentConflictsCtx(ctx context.Context) ([]AssignmentConflict, error)
+
+	// the indexes
+	MigrateIndexes() error
+	MigrateIndexesCtx(ctx context.Context) error
+
+	// the synchronization of the assignments
+	ReplaceRolePermissions(roleName string, permNames []string) (Changes, error)
+	ReplaceRolePermissionsCtx(ctx context.Context, roleName string, permNames []string) (Changes, error)
+	SyncUserRoles(userID uint, roleNames []string) (Changes, error)
+	SyncUserRolesCtx(ctx context.Context, userID uint, roleNames []string) (Changes, error)
+
+	// the tenants
+	CreateRoleInTenant(roleName string, tenant string) error
+	CreateRoleInTenantCtx(ctx context.Context, roleName string, tenant string) error
+	CreatePermissionInTenant(permName string, tenant string) error
+	CreatePermissionInTenantCtx(ctx context.Context, permName string, tenant string) error
+	AssignPermissionsInTenant(roleName string, permNames []string, tenant string) error
+	AssignPermissionsInTenantCtx(ctx context.Context, roleName string, permNames []string, tenant string) error
+	AssignRoleInTenant(userID uint, roleName string, tenant string) error
+	AssignRoleInTenantCtx(ctx context.Context, userID uint, roleName string, tenant string) error
+	RevokeRoleInTenant(userID uint, roleName string, tenant string) error
+	RevokeRoleInTenantCtx(ctx context.Context, userID uint, roleName string, tenant string) error
+	CheckRoleInTenant(userID uint, roleName string, tenant string) (bool, error)
+	CheckRoleInTenantCtx(ctx context.Context, userID uint, roleName string, tenant string) (bool, error)
+	CheckPermissionInTenant(userID uint, permName string, tenant string) (bool, error)
+	CheckPermissionInTenantCtx(ctx context.Context, userID uint, permName string, tenant string) (bool, error)
+	CheckRolePermissionInTenant(roleName string, permName string, tenant string) (bool, error)
+	CheckRolePermissionInTenantCtx(ctx context.Context, roleName string, permName string, tenant string) (bool, error)
+	GetUserRolesInTenant(userID uint, tenant string) ([]string, error)
+	GetUserRolesInTenantCtx(ctx context.Context, userID uint, tenant string) ([]string, error)
+	GetUserPermissionsInTenant(userID uint, tenant string) ([]string, error)
+	GetUserPermissionsInTenantCtx(ctx context.Context, userID uint, tenant string) ([]string, error)
+
+	// the relation tuples
+	ReadTuples() ([]RelationTuple, error)
+	ReadTuplesCtx(ctx context.Context) ([]RelationTuple, error)
+	WriteTuples(tuples []RelationTuple) error
+	WriteTuplesCtx(ctx context.Context, tuples []RelationTuple) error
+	DeleteTuples(tuples []RelationTuple) error
+	DeleteTuplesCtx(ctx context.Context, tuples []RelationTuple) error
+	CheckTuple(t RelationTuple) (bool, error)
+	CheckTupleCtx(ctx context.Context, t RelationTuple) (bool, error)
+
+	// the updates of the roles and the permissions
+	UpdateRole(roleName string, update RoleUpdate) error
+	UpdateRoleCtx(ctx context.Context, roleName string, update RoleUpdate) error
+	UpdatePermission(permName string, update PermissionUpdate) error
+	UpdatePermissionCtx(ctx context.Context, permName string, update PermissionUpdate) error
+	SetRoleDescription(roleName string, description string) error
+	SetRoleDescriptionCtx(ctx context.Context, roleName string, description string) error
+	SetRoleMetadata(roleName string, metadata map[string]interface{}) error
+	SetRoleMetadataCtx(ctx context.Context, roleName string, metadata map[string]interface{}) error
+	GetRoleMetadata(roleName string) (map[string]interface{}, error)
+	GetRoleMetadataCtx(ctx context.Context, roleName string) (map[string]interface{}, error)
+	SetPermissionDescription(permName string, description string) error
+	SetPermissionDescriptionCtx(ctx context.Context, permName string, description string) error
+	SetPermissionMetadata(permName string, metadata map[string]interface{}) error
+	SetPermissionMetadataCtx(ctx context.Context, permName string, metadata map[string]interface{}) error
+	GetPermissionMetadata(permName string) (map[string]interface{}, error)
+	GetPermissionMetadataCtx(ctx context.Context, permName string) (map[string]interface{}, error)
+
+	// the users
+	User(key string) User
+
+	// the policy version
+	PolicyVersion() (uint64, error)
+	PolicyVersionCtx(ctx context.Context) (uint64, error)
+}
+
+var _ Authorizer = (*Authority)(nil)
@@ -0,0 +1,135 @@
+package authority
+
+import (
+	"context"
+	"fmt"
+)
+
+// AssignRoles assigns several roles to a user in a single transaction, the roles already assigned to the user
+// are skipped. nothing is assigned if a role doesn't exist
+func (a *Authority) AssignRoles(userID uint, roleNames []string) error {
+	return a.AssignRolesCtx(context.Background(), userID, roleNames)
+}
+
+// AssignRolesCtx is the context-aware variant of AssignRoles
+func (a *Authority) AssignRolesCtx(ctx context.Context, userID uint, roleNames []string) error {
+	return a.assignRoles(ctx, roleNames, []string{userKey(userID)}, "")
+}
+
+// AssignRoleToUsers assigns a role to several users in a single transaction, the users already having the role
+// are skipped
+func (a *Authority) AssignRoleToUsers(roleName string, userIDs []uint) error {
+	return a.AssignRoleToUsersCtx(context.Background(), roleName, userIDs)
+}
+
+// AssignRoleToUsersCtx is the context-aware variant of AssignRoleToUsers
+func (a *Authority) AssignRoleToUsersCtx(ctx context.Context, roleName string, userIDs []uint) error {
+	users := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		users = append(users, userKey(userID))
+	}
+
+	return a.assignRoles(ctx, []string{roleName}, users, "")
+}
+
+// assignRoles assigns every role to every user with batched inserts
+func (a *Authority) assignRoles(ctx context.Context, roleNames []string, users []string, tenant string) error {
+	return a.importTx(ctx, ImportKindAssignRoles, func(ctx context.Context, tx *Authority) (int, error) {
+		roles := make([]*Role, 0, len(roleNames))
+		for _, roleName := range roleNames {
+			role, err := tx.getTenantRole(ctx, roleName, tenant)
+			if err != nil {
+				return 0, err
+			}
+			if role.stateOrDefault() == RoleStateRetired {
+				return 0, fmt.Errorf("%w: %s", ErrRoleRetired, roleName)
+			}
+			roles = append(roles, role)
+		}
+
+		existing, err := tx.store.GetUsersRoles(ctx, users)
+		if err != nil {
+			return 0, err
+		}
+
+		type assignment struct {
+			user   string
+			roleID uint
+		}
+
+		assigned := make(map[assignment]bool, len(existing))
+		for _, ur := range existing {
+			if ur.Tenant == tenant {
+				assigned[assignment{ur.UserKey, ur.RoleID}] = true
+			}
+		}
+
+		md, _ := RequestMetadataFromContext(ctx)
+		var userRoles, claimed []UserRole
+		for _, role := range roles {
+			added := 0
+			for _, user := range users {
+				// the source claims the assignments the users already have too
+				claimed = append(claimed, UserRole{UserKey: user, RoleID: role.ID, Tenant: tenant})
+
+				key := assignment{user, role.ID}
+				if assigned[key] {
+					continue
+				}
+				assigned[key] = true
+				added++
+
+				userRoles = append(userRoles, UserRole{
+					UserID: userIDOf(user), UserKey: user, RoleID: role.ID, Tenant: tenant, GrantedBy: md.Actor,
+				})
+			}
+
+			// make sure the tenant has enough seats left
+			if err = tx.checkQuota(ctx, role, tenant, added); err != nil {
+				return 0, err
+			}
+		}
+
+		names := make(map[uint]string, len(roles))
+		for _, role := range roles {
+			names[role.ID] = role.Name
+		}
+
+		for _, ur := range userRoles {
+			if err = tx.validate(ctx, AuditEntry{
+				Action: AuditRoleAssigned, UserKey: ur.UserKey, Role: names[ur.RoleID], Tenant: tenant,
+			}); err != nil {
+				return 0, err
+			}
+		}
+
+		// the assignments are inserted and recorded by batches to report the progress
+		p := tx.startProgress(ctx, ImportKindAssignRoles, len(userRoles))
+		for start := 0; start < len(userRoles); start += assignBatchSize {
+			end := start + assignBatchSize
+			if end > len(userRoles) {
+				end = len(userRoles)
+			}
+
+			if err = tx.store.AssignRoles(ctx, userRoles[start:end]); err != nil {
+				return 0, p.fail(err)
+			}
+
+			for _, ur := range userRoles[start:end] {
+				if err = tx.changed(ctx, AuditEntry{
+					Action: AuditRoleAssigned, UserKey: ur.UserKey, Role: names[ur.RoleID], Tenant: tenant,
+				}); err != nil {
+					return 0, p.fail(err)
+				}
+			}
+
+			p.add(end - start)
+		}
+
+		if err = tx.claim(ctx, claimed); err != nil {
+			return 0, p.fail(err)
+		}
+
+		return len(userRoles), nil
+	})
+}
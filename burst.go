@@ -0,0 +1,150 @@
+package authority
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MetricDenialBursts counts the bursts of denied checks detected by DenialBurstOptions
+const MetricDenialBursts = "authority.denial_bursts"
+
+// DenialBurstOptions detects the bursts of denied permission checks of a user, e.g. a stolen session or a script
+// probing the permissions. the denials are counted in memory by instance
+type DenialBurstOptions struct {
+	// Threshold is the number of denials of a user within Window making a burst, there is no detection when
+	// it's zero. a user makes one burst per window at most, the denials are counted again after it
+	Threshold int
+	Window    time.Duration
+	// OnBurst is called with the bursts, on the path of the check so it must not block
+	OnBurst func(ctx context.Context, burst DenialBurst)
+	// LockUser is called with the key of the user of a burst, e.g. to revoke its sessions or tokens
+	LockUser func(ctx context.Context, userKey string) error
+	// OnError is called with the errors of LockUser
+	OnError func(err error)
+}
+
+// DenialBurst is a burst of denied checks of a user
+type DenialBurst struct {
+	// UserKey identifies the user, it's the decimal id of the users identified by a number
+	UserKey string
+	// Denials is the number of denials within Window, Permissions their permissions by first denial
+	Denials     int
+	Window      time.Duration
+	Permissions []string
+	// At is the time of the denial reaching the threshold
+	At time.Time
+}
+
+// denialBursts counts the recent denials by user
+type denialBursts struct {
+	opts DenialBurstOptions
+	now  func() time.Time
+
+	mu    sync.Mutex
+	users map[string]*userDenials
+	// pruned is when the users without a recent denial were last dropped
+	pruned time.Time
+}
+
+// userDenials are the denials of a user in the current window
+type userDenials struct {
+	times       []time.Time
+	permissions []string
+	// quietUntil is the end of the window of the last burst
+	quietUntil time.Time
+}
+
+// newDenialBursts returns nil when the detection is disabled
+func newDenialBursts(opts DenialBurstOptions, now func() time.Time) *denialBursts {
+	if opts.Threshold <= 0 || opts.Window <= 0 {
+		return nil
+	}
+
+	return &denialBursts{opts: opts, now: now, users: make(map[string]*userDenials), pruned: now()}
+}
+
+// denied counts a denied check and reports the burst it completes
+func (b *denialBursts) denied(ctx context.Context, a *Authority, user string, permName string) {
+	if b == nil {
+		return
+	}
+
+	burst, ok := b.add(user, permName)
+	if !ok {
+		return
+	}
+
+	a.count(MetricDenialBursts, 1)
+
+	if b.opts.OnBurst != nil {
+		b.opts.OnBurst(ctx, burst)
+	}
+
+	if b.opts.LockUser != nil {
+		if err := b.opts.LockUser(ctx, user); err != nil && b.opts.OnError != nil {
+			b.opts.OnError(err)
+		}
+	}
+}
+
+// add records a denial, it returns the burst when the denial reaches the threshold
+func (b *denialBursts) add(user string, permName string) (DenialBurst, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	since := now.Add(-b.opts.Window)
+
+	// the users without a denial in the window are dropped once per window
+	if now.Sub(b.pruned) >= b.opts.Window {
+		for key, d := range b.users {
+			if n := len(d.times); (n == 0 || !d.times[n-1].After(since)) && !now.Before(d.quietUntil) {
+				delete(b.users, key)
+			}
+		}
+		b.pruned = now
+	}
+
+	d := b.users[user]
+	if d == nil {
+		d = &userDenials{}
+		b.users[user] = d
+	}
+
+	if now.Before(d.quietUntil) {
+		return DenialBurst{}, false
+	}
+
+	// the denials out of the window are dropped with their permissions
+	i := 0
+	for i < len(d.times) && !d.times[i].After(since) {
+		i++
+	}
+	d.times, d.permissions = append(d.times[i:], now), append(d.permissions[i:], permName)
+
+	if len(d.times) < b.opts.Threshold {
+		return DenialBurst{}, false
+	}
+
+	burst := DenialBurst{
+		UserKey: user, Denials: len(d.times), Window: b.opts.Window, Permissions: distinct(d.permissions), At: now,
+	}
+	d.times, d.permissions, d.quietUntil = nil, nil, now.Add(b.opts.Window)
+
+	return burst, true
+}
+
+// distinct returns the values without their duplicates, in order
+func distinct(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,203 @@
+package authority
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type noCacheKey struct{}
+
+// NoCache returns a copy of the context that makes the checks done with it skip every cache and read the
+// store, for the security-critical checks (e.g. moving money) that can't act on a stale decision
+func NoCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// cacheDisabled reports whether the context asks to skip the caches
+func cacheDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noCacheKey{}).(bool)
+	return disabled
+}
+
+// CheckPermissionFresh is like CheckPermission but it skips every cache
+func (a *Authority) CheckPermissionFresh(userID uint, permName string) (bool, error) {
+	return a.CheckPermissionFreshCtx(context.Background(), userID, permName)
+}
+
+// CheckPermissionFreshCtx is the context-aware variant of CheckPermissionFresh
+func (a *Authority) CheckPermissionFreshCtx(ctx context.Context, userID uint, permName string) (bool, error) {
+	return a.CheckPermissionCtx(NoCache(ctx), userID, permName)
+}
+
+// the defaults of the cache
+const (
+	// DefaultCacheSize is the number of entries the in-process cache keeps when Options.CacheSize is zero
+	DefaultCacheSize = 10000
+	// DefaultCacheTTL is how long the entries are kept when a CacheBackend is set without Options.CacheTTL
+	DefaultCacheTTL = time.Minute
+)
+
+// CacheBackend stores the cached lookups and check results encoded as bytes. the default backend keeps them
+// in process, a shared backend (e.g. the one of the rediscache package) lets several instances share them.
+// Purge is called after every change and must drop the entries for every instance using the backend
+type CacheBackend interface {
+	// Get returns the value of the key, false when it's missing or expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores the value of the key for the ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Purge drops every entry
+	Purge(ctx context.Context) error
+}
+
+// lruCache is the in-process CacheBackend, it keeps the most recently used entries and evicts the least
+// recently used ones when it's full
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	now   func() time.Time
+	items map[string]*list.Element
+	order *list.List
+}
+
+type cacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// newLRUCache returns an in-process cache keeping up to size entries
+func newLRUCache(size int, now func() time.Time) *lruCache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+
+	return &lruCache{size: size, now: now, items: make(map[string]*list.Element), order: list.New()}
+}
+
+func (c *lruCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := el.Value.(*cacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return nil, false, nil
+	}
+
+	c.order.MoveToFront(el)
+
+	return entry.value, true, nil
+}
+
+func (c *lruCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := c.now().Add(ttl)
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*cacheEntry)
+		entry.value, entry.expiresAt = value, expiresAt
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	c.items[key] = c.order.PushFront(&cacheEntry{key: key, value: value, expiresAt: expiresAt})
+
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+
+	return nil
+}
+
+func (c *lruCache) Purge(context.Context) error {
+	c.mu.Lock()
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+	c.mu.Unlock()
+
+	return nil
+}
+
+// cacheKey joins the parts of a key with a separator the names can't contain
+func cacheKey(parts ...string) string {
+	return strings.Join(parts, "\x00")
+}
+
+// cached returns the cached value of the key, it loads and caches the value when it's missing.
+// the errors, the entities without an id and the values loaded while the cache was purged are not cached, the
+// entities without an id are not returned from the cache either. a failing backend is bypassed and the context
+// may skip the cache with NoCache
+func cached[T any](ctx context.Context, a *Authority, key string, load func() (T, error)) (T, error) {
+	return cachedFor(ctx, a, key, a.cacheTTL, load)
+}
+
+// cachedFor is like cached with the ttl of the value
+func cachedFor[T any](ctx context.Context, a *Authority, key string, ttl time.Duration, load func() (T, error)) (
+	T, error) {
+	if a.cache == nil || cacheDisabled(ctx) {
+		return load()
+	}
+
+	var value T
+	b, ok, err := a.cache.Get(ctx, key)
+	if err == nil && ok && json.Unmarshal(b, &value) == nil && cacheable(value) {
+		a.count(MetricCacheHits, 1)
+		return value, nil
+	}
+	a.logError(ctx, "cache", err)
+	a.count(MetricCacheMisses, 1)
+
+	// a value loaded before a purge may be stale, it's returned but not cached
+	generation := atomic.LoadUint64(&a.stale.generation)
+
+	if value, err = load(); err != nil || !cacheable(value) {
+		return value, err
+	}
+
+	if atomic.LoadUint64(&a.stale.generation) != generation {
+		return value, nil
+	}
+
+	if b, err = json.Marshal(value); err == nil {
+		a.logError(ctx, "cache", a.cache.Set(ctx, key, b, ttl))
+	}
+
+	return value, nil
+}
+
+// cacheable reports whether a loaded value may be cached, the entities without an id were not stored
+func cacheable(value interface{}) bool {
+	switch v := value.(type) {
+	case Role:
+		return v.ID != 0
+	case Permission:
+		return v.ID != 0
+	}
+
+	return true
+}
+
+// purgeCache drops the cached lookups after a change
+func (a *Authority) purgeCache(ctx context.Context) error {
+	if a.cache == nil {
+		return nil
+	}
+
+	atomic.AddUint64(&a.stale.generation, 1)
+
+	return a.cache.Purge(ctx)
+}
@@ -0,0 +1,141 @@
+package authority_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"authority"
+	"authority/authoritytest"
+)
+
+var errStoreDown = errors.New("store down")
+
+// flakyStore fails the role lookups while down, or the lookups of the failing role only, and returns a role
+// without an id while empty. afterRead is called once after the next role is read
+type flakyStore struct {
+	authority.Store
+	down      bool
+	failing   string
+	empty     bool
+	reads     int
+	afterRead func()
+}
+
+func (s *flakyStore) GetRole(ctx context.Context, roleName string, tenant string) (*authority.Role, error) {
+	s.reads++
+	if s.down || roleName == s.failing {
+		return nil, errStoreDown
+	}
+	if s.empty {
+		return &authority.Role{}, nil
+	}
+
+	role, err := s.Store.GetRole(ctx, roleName, tenant)
+	if after := s.afterRead; after != nil {
+		s.afterRead = nil
+		after()
+	}
+
+	return role, err
+}
+
+func TestCacheSkipsFailedLookups(t *testing.T) {
+	store := &flakyStore{Store: authoritytest.NewMemoryStore()}
+	a, err := authority.NewE(authority.Options{Store: store, CacheTTL: time.Minute})
+	must(t, err)
+	must(t, a.CreateRole("editor"))
+
+	store.down = true
+	if _, err = a.GetRole("editor"); !errors.Is(err, errStoreDown) {
+		t.Fatalf("GetRole = %v, want the error of the store", err)
+	}
+
+	// a role without an id is not a role
+	store.down, store.empty = false, true
+	for i := 0; i < 2; i++ {
+		if _, err = a.GetRole("editor"); !errors.Is(err, authority.ErrRoleNotFound) {
+			t.Fatalf("GetRole of a role without an id = %v, want ErrRoleNotFound", err)
+		}
+	}
+
+	store.empty = false
+	role, err := a.GetRole("editor")
+	must(t, err)
+	if role.ID == 0 || role.Name != "editor" {
+		t.Fatalf("GetRole = %+v, the role without an id was cached", role)
+	}
+
+	reads := store.reads
+	if _, err = a.GetRole("editor"); err != nil {
+		t.Fatal(err)
+	}
+	if store.reads != reads {
+		t.Fatal("the role was not cached")
+	}
+}
+
+func TestGettersPropagateStoreErrors(t *testing.T) {
+	store := &flakyStore{Store: authoritytest.NewMemoryStore()}
+	a, err := authority.NewE(authority.Options{Store: store})
+	must(t, err)
+	must(t, a.CreateRole("editor"))
+
+	// the missing entities are named by the errors
+	if _, err = a.GetRole("publisher"); !errors.Is(err, authority.ErrRoleNotFound) ||
+		!strings.Contains(err.Error(), "publisher") {
+		t.Errorf("GetRole of a missing role = %v, want ErrRoleNotFound naming the role", err)
+	}
+	if _, err = a.GetPermission("articles.read"); !errors.Is(err, authority.ErrPermissionNotFound) ||
+		!strings.Contains(err.Error(), "articles.read") {
+		t.Errorf("GetPermission of a missing permission = %v, want ErrPermissionNotFound naming the permission", err)
+	}
+
+	// an outage of the store is not a missing role
+	store.down = true
+	if err = a.CreateRole("publisher"); !errors.Is(err, errStoreDown) {
+		t.Errorf("CreateRole = %v, want the error of the store", err)
+	}
+	if err = a.AssignRole(1, "editor"); !errors.Is(err, errStoreDown) {
+		t.Errorf("AssignRole = %v, want the error of the store", err)
+	}
+	if _, err = a.CheckRole(1, "editor"); !errors.Is(err, errStoreDown) {
+		t.Errorf("CheckRole = %v, want the error of the store", err)
+	}
+
+	// nor is it a free name to rename a role to
+	store.down, store.failing = false, "publisher"
+	if err = a.UpdateRole("editor", authority.RoleUpdate{Name: &store.failing}); !errors.Is(err, errStoreDown) {
+		t.Errorf("UpdateRole renaming the role = %v, want the error of the store", err)
+	}
+
+	store.failing = ""
+	if role, err := a.GetRole("editor"); err != nil || role.Name != "editor" {
+		t.Errorf("GetRole after the failed rename = %+v, %v", role, err)
+	}
+	if roles, err := a.GetRoles(); err != nil || len(roles) != 1 {
+		t.Errorf("GetRoles after the failed lookups = %v, %v, want only the editor", roles, err)
+	}
+}
+
+func TestCacheSkipsLookupsRacingChanges(t *testing.T) {
+	store := &flakyStore{Store: authoritytest.NewMemoryStore()}
+	a, err := authority.NewE(authority.Options{Store: store, CacheTTL: time.Minute})
+	must(t, err)
+	must(t, a.CreateRole("editor"))
+
+	// the role is changed while it's loaded, the loaded role is stale
+	title := "Editor"
+	store.afterRead = func() { must(t, a.UpdateRole("editor", authority.RoleUpdate{Title: &title})) }
+	if _, err = a.GetRole("editor"); err != nil {
+		t.Fatal(err)
+	}
+
+	role, err := a.GetRole("editor")
+	must(t, err)
+	if role.Title != title {
+		t.Errorf("the title of the role = %q, the role loaded before the change was cached", role.Title)
+	}
+}
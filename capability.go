@@ -0,0 +1,110 @@
+package authority
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Capability maps a capability of the frontends, e.g. "invoices.export_button", to the permissions enabling it.
+// it's enabled when the user has one of the AnyOf permissions, if any, and all the AllOf permissions
+type Capability struct {
+	AnyOf []string `json:"any_of,omitempty" yaml:"any_of,omitempty"`
+	AllOf []string `json:"all_of,omitempty" yaml:"all_of,omitempty"`
+}
+
+// CapabilityManifest lists the capabilities of a user, it's meant to be handed to the single-page applications
+// at login so they hide the controls the user can't use. the server still checks the permissions
+type CapabilityManifest struct {
+	Roles []string `json:"roles"`
+	// Capabilities has every capability of the options with whether it's enabled, or every permission the user
+	// has when the options map no capability
+	Capabilities map[string]bool `json:"capabilities"`
+	IssuedAt     time.Time       `json:"issued_at"`
+}
+
+// BuildCapabilityManifest returns the capabilities of a user derived from its permissions by Options.Capabilities,
+// the conditions of the permissions are evaluated with the attributes of the context.
+// it returns an error wrapping ErrPermissionNotFound if a capability refers to a permission that doesn't exist
+func (a *Authority) BuildCapabilityManifest(userID uint) (*CapabilityManifest, error) {
+	return a.BuildCapabilityManifestCtx(context.Background(), userID)
+}
+
+// BuildCapabilityManifestCtx is the context-aware variant of BuildCapabilityManifest
+func (a *Authority) BuildCapabilityManifestCtx(ctx context.Context, userID uint) (*CapabilityManifest, error) {
+	return a.capabilityManifest(ctx, userKey(userID), "")
+}
+
+func (a *Authority) capabilityManifest(ctx context.Context, user string, tenant string) (*CapabilityManifest,
+	error) {
+	roles, err := a.getUserRoles(ctx, user, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &CapabilityManifest{Roles: roles, IssuedAt: a.now()}
+
+	// without a mapping the capabilities are the permissions of the user
+	if len(a.capabilities) == 0 {
+		var perms []Permission
+		if perms, err = a.userPermissions(ctx, user, tenant); err != nil {
+			return nil, err
+		}
+
+		permNames := make([]string, 0, len(perms))
+		for _, perm := range perms {
+			permNames = append(permNames, perm.Name)
+		}
+
+		if manifest.Capabilities, err = a.checkPermissions(ctx, user, permNames, tenant); err != nil {
+			return nil, err
+		}
+
+		return manifest, nil
+	}
+
+	seen := make(map[string]bool)
+	var permNames []string
+	for _, c := range a.capabilities {
+		for _, permName := range append(c.AnyOf[:len(c.AnyOf):len(c.AnyOf)], c.AllOf...) {
+			if !seen[permName] {
+				seen[permName] = true
+				permNames = append(permNames, permName)
+			}
+		}
+	}
+	sort.Strings(permNames)
+
+	var granted map[string]bool
+	if granted, err = a.checkPermissions(ctx, user, permNames, tenant); err != nil {
+		return nil, err
+	}
+
+	manifest.Capabilities = make(map[string]bool, len(a.capabilities))
+	for name, c := range a.capabilities {
+		manifest.Capabilities[name] = c.enabled(granted)
+	}
+
+	return manifest, nil
+}
+
+// enabled reports whether the granted permissions enable the capability
+func (c Capability) enabled(granted map[string]bool) bool {
+	for _, permName := range c.AllOf {
+		if !granted[permName] {
+			return false
+		}
+	}
+
+	if len(c.AnyOf) == 0 {
+		return true
+	}
+
+	for _, permName := range c.AnyOf {
+		if granted[permName] {
+			return true
+		}
+	}
+
+	return false
+}
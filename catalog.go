@@ -0,0 +1,95 @@
+package authority
+
+import (
+	"context"
+	"sort"
+)
+
+// PermissionDoc documents a permission of the catalog
+type PermissionDoc struct {
+	Name        string
+	Title       string
+	Tenant      string
+	Description string
+	Condition   string
+	Action      string
+	Resource    string
+	// Class is the sensitivity class of the permission, see Options.PermissionClass, it's empty when the
+	// permissions have no class
+	Class string
+	// RequiresApproval is set when the class of the permission requires an approval to grant it
+	RequiresApproval bool
+	// Roles are the names of the active roles granting the permission now, directly, as a composite role or
+	// through the hierarchy, sorted
+	Roles []string
+}
+
+// PermissionCatalog returns the documentation of every permission read from the store, e.g. to publish the
+// permissions of an application without the docs drifting from the granted permissions
+func (a *Authority) PermissionCatalog() ([]PermissionDoc, error) {
+	return a.PermissionCatalogCtx(context.Background())
+}
+
+// PermissionCatalogCtx is the context-aware variant of PermissionCatalog
+func (a *Authority) PermissionCatalogCtx(ctx context.Context) ([]PermissionDoc, error) {
+	perms, err := a.store.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var roles []Role
+	if roles, err = a.store.ListRoles(ctx); err != nil {
+		return nil, err
+	}
+
+	// the roles granting every permission, by permission id
+	granting := make(map[uint][]string)
+	for _, role := range roles {
+		var roleIDs []uint
+		if roleIDs, err = a.grantingRoles(ctx, []uint{role.ID}); err != nil {
+			return nil, err
+		}
+		// the inactive roles grant nothing
+		if len(roleIDs) == 0 {
+			continue
+		}
+
+		var rolePerms []RolePermission
+		if rolePerms, err = a.activeRolePermissions(ctx, roleIDs); err != nil {
+			return nil, err
+		}
+
+		seen := make(map[uint]bool, len(rolePerms))
+		for _, rp := range rolePerms {
+			if !seen[rp.PermissionID] {
+				seen[rp.PermissionID] = true
+				granting[rp.PermissionID] = append(granting[rp.PermissionID], role.Name)
+			}
+		}
+	}
+
+	docs := make([]PermissionDoc, 0, len(perms))
+	for _, perm := range perms {
+		doc := PermissionDoc{
+			Name: perm.Name, Title: perm.Title, Tenant: perm.Tenant, Description: perm.Description,
+			Condition: perm.Condition, Action: perm.Action, Resource: perm.Resource,
+			RequiresApproval: a.RequiresApproval(perm.Name), Roles: distinct(granting[perm.ID]),
+		}
+		if a.stale.classOf != nil {
+			doc.Class = a.stale.classOf(perm.Name)
+		}
+		sort.Strings(doc.Roles)
+
+		docs = append(docs, doc)
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		if docs[i].Name != docs[j].Name {
+			return docs[i].Name < docs[j].Name
+		}
+
+		return docs[i].Tenant < docs[j].Tenant
+	})
+
+	return docs, nil
+}
@@ -0,0 +1,70 @@
+package authority
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// the defaults of the change feed
+const (
+	// DefaultChangeFeedLimit is the number of changes returned by GetChangesSince when the limit is zero
+	DefaultChangeFeedLimit = 100
+	// DefaultChangeFeedDelay is how old the changes must be to be returned when Options.ChangeFeedDelay is zero
+	DefaultChangeFeedDelay = 5 * time.Second
+)
+
+var (
+	ErrInvalidCursor      = errors.New("invalid change feed cursor")
+	ErrChangeFeedDisabled = errors.New("the change feed needs auditing")
+)
+
+// ChangeFeed is a batch of changes with the cursor of the next batch
+type ChangeFeed struct {
+	// Changes are the recorded changes in the order they were made
+	Changes []AuditEntry
+	// Cursor is passed to the next call of GetChangesSince, it's the given cursor when there is no new change
+	Cursor string
+}
+
+// GetChangesSince returns the changes recorded after the cursor, up to the limit, so that the downstream systems
+// poll the changes incrementally instead of exporting everything. the empty cursor starts from the first change.
+// the changes are read from the audit entries, it returns ErrChangeFeedDisabled when auditing is disabled.
+// the changes made in the last Options.ChangeFeedDelay are left for the next call, so that a transaction
+// committing after a later one doesn't make its changes appear behind the cursor
+func (a *Authority) GetChangesSince(cursor string, limit int) (*ChangeFeed, error) {
+	return a.GetChangesSinceCtx(context.Background(), cursor, limit)
+}
+
+// GetChangesSinceCtx is the context-aware variant of GetChangesSince
+func (a *Authority) GetChangesSinceCtx(ctx context.Context, cursor string, limit int) (*ChangeFeed, error) {
+	if !a.auditEnabled {
+		return nil, ErrChangeFeedDisabled
+	}
+
+	var afterID uint
+	if cursor != "" {
+		id, err := strconv.ParseUint(cursor, 10, 0)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		afterID = uint(id)
+	}
+
+	if limit <= 0 {
+		limit = DefaultChangeFeedLimit
+	}
+
+	changes, err := a.store.ListChanges(ctx, afterID, a.now().Add(-a.changeFeedDelay), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &ChangeFeed{Changes: changes, Cursor: cursor}
+	if len(changes) > 0 {
+		feed.Cursor = strconv.FormatUint(uint64(changes[len(changes)-1].ID), 10)
+	}
+
+	return feed, nil
+}
@@ -0,0 +1,145 @@
+package authority
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CheckPermissions checks several permissions of a user at once, it returns whether each permission is granted
+// by name. the permissions are checked with a single query when the store is a BatchPermissionChecker and their
+// results are not cached. it returns an error wrapping ErrPermissionNotFound if a permission doesn't exist
+func (a *Authority) CheckPermissions(userID uint, permNames []string) (map[string]bool, error) {
+	return a.CheckPermissionsCtx(context.Background(), userID, permNames)
+}
+
+// CheckPermissionsCtx is the context-aware variant of CheckPermissions
+func (a *Authority) CheckPermissionsCtx(ctx context.Context, userID uint, permNames []string) (map[string]bool,
+	error) {
+	return a.checkPermissions(ctx, userKey(userID), permNames, "")
+}
+
+// CheckPermissions checks several permissions of the user in its tenant at once, see Authority.CheckPermissions
+func (u User) CheckPermissions(permNames []string) (map[string]bool, error) {
+	return u.CheckPermissionsCtx(context.Background(), permNames)
+}
+
+// CheckPermissionsCtx is the context-aware variant of CheckPermissions
+func (u User) CheckPermissionsCtx(ctx context.Context, permNames []string) (map[string]bool, error) {
+	return u.a.checkPermissions(ctx, u.key, permNames, u.tenant)
+}
+
+// CheckAnyPermission checks if a user has at least one of the permissions, e.g. to guard a handler
+// the users allowed to do A or B can call. it's false when no permission is given
+func (a *Authority) CheckAnyPermission(userID uint, permNames []string) (bool, error) {
+	return a.CheckAnyPermissionCtx(context.Background(), userID, permNames)
+}
+
+// CheckAnyPermissionCtx is the context-aware variant of CheckAnyPermission
+func (a *Authority) CheckAnyPermissionCtx(ctx context.Context, userID uint, permNames []string) (bool, error) {
+	granted, err := a.checkPermissions(ctx, userKey(userID), permNames, "")
+	if err != nil {
+		return false, err
+	}
+
+	for _, ok := range granted {
+		if ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// CheckAllPermissions checks if a user has all the permissions, it's true when no permission is given
+func (a *Authority) CheckAllPermissions(userID uint, permNames []string) (bool, error) {
+	return a.CheckAllPermissionsCtx(context.Background(), userID, permNames)
+}
+
+// CheckAllPermissionsCtx is the context-aware variant of CheckAllPermissions
+func (a *Authority) CheckAllPermissionsCtx(ctx context.Context, userID uint, permNames []string) (bool, error) {
+	granted, err := a.checkPermissions(ctx, userKey(userID), permNames, "")
+	if err != nil {
+		return false, err
+	}
+
+	for _, ok := range granted {
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (a *Authority) checkPermissions(ctx context.Context, user string, permNames []string, tenant string) (
+	map[string]bool, error) {
+	start := time.Now()
+
+	release, err := a.checks.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var granted map[string]bool
+	var conditions map[string]string
+	if checker, ok := a.store.(BatchPermissionChecker); ok {
+		if granted, conditions, err = checker.CheckUserPermissions(ctx, user, permNames, tenant, a.now()); err != nil {
+			return nil, err
+		}
+	} else {
+		granted, conditions = make(map[string]bool, len(permNames)), make(map[string]string, len(permNames))
+		for _, permName := range permNames {
+			var grant permissionGrant
+			if grant, err = a.checkGrant(ctx, user, permName, tenant); err != nil {
+				return nil, err
+			}
+			granted[permName], conditions[permName] = grant.Granted, grant.Condition
+		}
+	}
+
+	result := make(map[string]bool, len(permNames))
+	for _, permName := range permNames {
+		ok, found := granted[permName]
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ErrPermissionNotFound, permName)
+		}
+
+		// a conditional permission is granted only when its condition holds for the attributes of the context
+		if ok && conditions[permName] != "" {
+			if ok, err = a.conditions.eval(ctx, conditions[permName], user); err != nil {
+				return nil, err
+			}
+		}
+
+		if err = a.auditCheck(ctx, user, permName, tenant, ok); err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			a.bursts.denied(ctx, a, user, permName)
+		}
+
+		result[permName] = ok
+	}
+
+	// the batches are not cached
+	if a.logger != nil {
+		for _, permName := range permNames {
+			a.logCheck(ctx, &checkLog{
+				kind: CheckKindPermissions, user: user, tenant: tenant, name: permName, start: start,
+				granted: result[permName], loads: 1,
+			})
+		}
+	}
+
+	if a.metrics != nil {
+		a.metrics.ObserveDuration(MetricCheckDuration, time.Since(start), Tag{"kind", CheckKindPermissions})
+		for permName, ok := range result {
+			a.count(MetricChecks, 1, checkTags(CheckKindPermissions, permName, checkResult(ok, nil))...)
+		}
+	}
+
+	return result, nil
+}
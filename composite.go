@@ -0,0 +1,166 @@
+package authority
+
+import "context"
+
+// AddCompositeRoles includes a group of roles in a composite role, it accepts the composite role name as the first
+// parameter and the names of the included roles as the second parameter.
+// a user holding the composite role is granted the included roles at check time.
+// it returns an error if any of the roles doesn't exist or if the inclusion would make the role include itself,
+// and ErrOperationLocked when another instance changes the composite roles or the parents for longer than
+// Options.LockTimeout
+func (a *Authority) AddCompositeRoles(roleName string, memberNames []string) error {
+	return a.AddCompositeRolesCtx(context.Background(), roleName, memberNames)
+}
+
+// AddCompositeRolesCtx is the context-aware variant of AddCompositeRoles
+func (a *Authority) AddCompositeRolesCtx(ctx context.Context, roleName string, memberNames []string) error {
+	// all the roles are added or none, under the lock of the role graph like SetRoleParent
+	return a.exclusive(ctx, roleGraphLock, a.lockTimeout, func(ctx context.Context) error {
+		return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+			return tx.addCompositeRoles(ctx, roleName, memberNames)
+		})
+	})
+}
+
+func (a *Authority) addCompositeRoles(ctx context.Context, roleName string, memberNames []string) error {
+	var err error
+
+	// find the composite role
+	var role *Role
+	if role, err = a.getRole(ctx, roleName); err != nil {
+		return err
+	}
+
+	var members []*Role
+	for _, memberName := range memberNames {
+		var member *Role
+		if member, err = a.getRole(ctx, memberName); err != nil {
+			return err
+		}
+
+		// the member must not include the composite role already
+		var included []uint
+		if included, err = a.expandRoles(ctx, []uint{member.ID}); err != nil {
+			return err
+		}
+		for _, id := range included {
+			if id == role.ID {
+				return ErrCompositeCycle
+			}
+		}
+
+		members = append(members, member)
+	}
+
+	for _, member := range members {
+		if err = a.store.AddRoleComposite(ctx, role.ID, member.ID); err != nil {
+			return err
+		}
+
+		if err = a.changed(ctx, AuditEntry{Action: AuditCompositeRoleAdded, Role: roleName, Detail: member.Name}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoveCompositeRole removes an included role from a composite role
+// it returns an error in case of any
+func (a *Authority) RemoveCompositeRole(roleName string, memberName string) error {
+	return a.RemoveCompositeRoleCtx(context.Background(), roleName, memberName)
+}
+
+// RemoveCompositeRoleCtx is the context-aware variant of RemoveCompositeRole
+func (a *Authority) RemoveCompositeRoleCtx(ctx context.Context, roleName string, memberName string) error {
+	var err error
+
+	var role, member *Role
+	if role, err = a.getRole(ctx, roleName); err != nil {
+		return err
+	}
+
+	if member, err = a.getRole(ctx, memberName); err != nil {
+		return err
+	}
+
+	if err = a.store.RemoveRoleComposite(ctx, role.ID, member.ID); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{Action: AuditCompositeRoleRemoved, Role: roleName, Detail: memberName})
+}
+
+// GetCompositeRoles returns the roles directly included in a composite role
+func (a *Authority) GetCompositeRoles(roleName string) ([]string, error) {
+	return a.GetCompositeRolesCtx(context.Background(), roleName)
+}
+
+// GetCompositeRolesCtx is the context-aware variant of GetCompositeRoles
+func (a *Authority) GetCompositeRolesCtx(ctx context.Context, roleName string) ([]string, error) {
+	var err error
+
+	var role *Role
+	if role, err = a.getRole(ctx, roleName); err != nil {
+		return nil, err
+	}
+
+	var composites []RoleComposite
+	if composites, err = a.store.GetRoleComposites(ctx, []uint{role.ID}); err != nil {
+		return nil, err
+	}
+
+	memberIDs := make([]uint, 0, len(composites))
+	for _, c := range composites {
+		memberIDs = append(memberIDs, c.MemberID)
+	}
+
+	var roles []Role
+	if roles, err = a.store.GetRolesByID(ctx, memberIDs); err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(roles))
+	for _, r := range roles {
+		result = append(result, r.Name)
+	}
+
+	return result, nil
+}
+
+// FlattenRole returns the given role followed by every role it includes, directly or through other composite roles
+func (a *Authority) FlattenRole(roleName string) ([]string, error) {
+	return a.FlattenRoleCtx(context.Background(), roleName)
+}
+
+// FlattenRoleCtx is the context-aware variant of FlattenRole
+func (a *Authority) FlattenRoleCtx(ctx context.Context, roleName string) ([]string, error) {
+	var err error
+
+	var role *Role
+	if role, err = a.getRole(ctx, roleName); err != nil {
+		return nil, err
+	}
+
+	var roleIDs []uint
+	if roleIDs, err = a.walkRoles(ctx, []uint{role.ID}, false, false); err != nil {
+		return nil, err
+	}
+
+	var roles []Role
+	if roles, err = a.store.GetRolesByID(ctx, roleIDs); err != nil {
+		return nil, err
+	}
+
+	names := make(map[uint]string, len(roles))
+	for _, r := range roles {
+		names[r.ID] = r.Name
+	}
+
+	result := make([]string, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		result = append(result, names[id])
+	}
+
+	return result, nil
+}
@@ -0,0 +1,149 @@
+package authority
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+)
+
+// ErrInvalidCondition is returned when a permission condition is not a valid boolean CEL expression
+var ErrInvalidCondition = errors.New("invalid permission condition")
+
+// the variables available to the conditions of the permissions
+const (
+	// conditionAttrs holds the attributes given with WithAttributes, e.g. attrs.amount < 1000
+	conditionAttrs = "attrs"
+	// conditionUserID holds the numeric id of the checked user, 0 for the users identified by a string
+	conditionUserID = "user_id"
+	// conditionUser holds the identifier of the checked user
+	conditionUser = "user"
+)
+
+type attributesKey struct{}
+
+// WithAttributes returns a copy of the context carrying the attributes the permission conditions are evaluated
+// against, e.g. the amount of a payment or the owner of a document
+func WithAttributes(ctx context.Context, attrs map[string]interface{}) context.Context {
+	return context.WithValue(ctx, attributesKey{}, attrs)
+}
+
+// AttributesFromContext returns the attributes carried by the context
+func AttributesFromContext(ctx context.Context) map[string]interface{} {
+	attrs, _ := ctx.Value(attributesKey{}).(map[string]interface{})
+	return attrs
+}
+
+// conditions compiles the CEL conditions of the permissions and keeps the compiled programs
+type conditions struct {
+	once sync.Once
+	env  *cel.Env
+	err  error
+
+	mu       sync.RWMutex
+	programs map[string]cel.Program
+}
+
+// program returns the compiled program of a condition
+func (c *conditions) program(expr string) (cel.Program, error) {
+	c.once.Do(func() {
+		c.env, c.err = cel.NewEnv(
+			cel.Variable(conditionAttrs, cel.MapType(cel.StringType, cel.DynType)),
+			cel.Variable(conditionUserID, cel.UintType),
+			cel.Variable(conditionUser, cel.StringType),
+		)
+	})
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	c.mu.RLock()
+	prg, ok := c.programs[expr]
+	c.mu.RUnlock()
+	if ok {
+		return prg, nil
+	}
+
+	ast, iss := c.env.Compile(expr)
+	if iss.Err() != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCondition, iss.Err())
+	}
+
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("%w: %q doesn't evaluate to a bool", ErrInvalidCondition, expr)
+	}
+
+	prg, err := c.env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCondition, err)
+	}
+
+	c.mu.Lock()
+	if c.programs == nil {
+		c.programs = make(map[string]cel.Program)
+	}
+	c.programs[expr] = prg
+	c.mu.Unlock()
+
+	return prg, nil
+}
+
+// eval evaluates a condition for a user against the attributes of the context,
+// a condition referring to a missing attribute doesn't hold
+func (c *conditions) eval(ctx context.Context, expr string, user string) (bool, error) {
+	prg, err := c.program(expr)
+	if err != nil {
+		return false, err
+	}
+
+	attrs := AttributesFromContext(ctx)
+	if attrs == nil {
+		attrs = map[string]interface{}{}
+	}
+
+	out, _, err := prg.Eval(map[string]interface{}{
+		conditionAttrs:  attrs,
+		conditionUserID: uint64(userIDOf(user)),
+		conditionUser:   user,
+	})
+	if err != nil {
+		// e.g. an attribute the caller didn't provide
+		return false, nil
+	}
+
+	allowed, ok := out.Value().(bool)
+	return ok && allowed, nil
+}
+
+// SetPermissionCondition sets the CEL condition of a permission, a user having the permission through a role
+// is granted it only when the condition holds for the attributes given with WithAttributes at check time, e.g.
+// SetPermissionCondition("approve-payment", "attrs.amount < 1000"). the id of the checked user is available as
+// user_id, or as user for the users identified by a string. an empty condition removes it. it returns ErrInvalidCondition if the expression doesn't compile
+func (a *Authority) SetPermissionCondition(permName string, condition string) error {
+	return a.SetPermissionConditionCtx(context.Background(), permName, condition)
+}
+
+// SetPermissionConditionCtx is the context-aware variant of SetPermissionCondition
+func (a *Authority) SetPermissionConditionCtx(ctx context.Context, permName string, condition string) error {
+	var err error
+
+	if condition != "" {
+		if _, err = a.conditions.program(condition); err != nil {
+			return err
+		}
+	}
+
+	var perm *Permission
+	if perm, err = a.getPermission(ctx, permName); err != nil {
+		return err
+	}
+
+	perm.Condition = condition
+	if err = a.store.UpdatePermission(ctx, perm); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{Action: AuditPermissionConditionSet, Permission: permName, Detail: condition})
+}
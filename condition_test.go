@@ -0,0 +1,87 @@
+package authority_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"authority"
+)
+
+func TestPermissionConditions(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	setupRole(t, a, "approver", "payments.approve", "documents.edit")
+	must(t, a.AssignRole(1, "approver"))
+	must(t, a.User("alice").AssignRole("approver"))
+
+	// the invalid conditions are rejected and leave the permission unconditional
+	for _, condition := range []string{"attrs.amount <", "attrs.amount + 1", "unknown == 1"} {
+		if err := a.SetPermissionCondition("payments.approve", condition); !errors.Is(err, authority.ErrInvalidCondition) {
+			t.Errorf("SetPermissionCondition(%q) = %v, want ErrInvalidCondition", condition, err)
+		}
+	}
+	if granted, err := a.CheckPermission(1, "payments.approve"); err != nil || !granted {
+		t.Fatalf("CheckPermission after the invalid conditions = %v, %v, want true", granted, err)
+	}
+
+	must(t, a.SetPermissionCondition("payments.approve", "attrs.amount < 1000"))
+	must(t, a.SetPermissionCondition("documents.edit", "attrs.owner == user || attrs.owner_id == user_id"))
+
+	withAttrs := func(attrs map[string]interface{}) context.Context {
+		return authority.WithAttributes(context.Background(), attrs)
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		userID   uint
+		user     string
+		permName string
+		want     bool
+	}{
+		{name: "holds", ctx: withAttrs(map[string]interface{}{"amount": 10}), userID: 1,
+			permName: "payments.approve", want: true},
+		{name: "fails", ctx: withAttrs(map[string]interface{}{"amount": 5000}), userID: 1,
+			permName: "payments.approve"},
+		{name: "missing attribute", ctx: withAttrs(map[string]interface{}{"total": 10}), userID: 1,
+			permName: "payments.approve"},
+		{name: "no attributes", ctx: context.Background(), userID: 1, permName: "payments.approve"},
+		{name: "mistyped attribute", ctx: withAttrs(map[string]interface{}{"amount": "10"}), userID: 1,
+			permName: "payments.approve"},
+		{name: "numeric user", ctx: withAttrs(map[string]interface{}{"owner_id": uint64(1)}), userID: 1,
+			permName: "documents.edit", want: true},
+		{name: "other numeric user", ctx: withAttrs(map[string]interface{}{"owner_id": uint64(2)}), userID: 1,
+			permName: "documents.edit"},
+		{name: "string user", ctx: withAttrs(map[string]interface{}{"owner": "alice"}), user: "alice",
+			permName: "documents.edit", want: true},
+		{name: "other string user", ctx: withAttrs(map[string]interface{}{"owner": "bob"}), user: "alice",
+			permName: "documents.edit"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var granted bool
+			var err error
+			if tc.user != "" {
+				granted, err = a.User(tc.user).CheckPermissionCtx(tc.ctx, tc.permName)
+			} else {
+				granted, err = a.CheckPermissionCtx(tc.ctx, tc.userID, tc.permName)
+			}
+			if err != nil || granted != tc.want {
+				t.Errorf("CheckPermissionCtx = %v, %v, want %v", granted, err, tc.want)
+			}
+		})
+	}
+
+	// the users without the permission aren't granted it by the condition
+	if granted, err := a.CheckPermissionCtx(withAttrs(map[string]interface{}{"amount": 10}), 2,
+		"payments.approve"); err != nil || granted {
+		t.Errorf("CheckPermissionCtx of a user without the role = %v, %v, want false", granted, err)
+	}
+
+	// an empty condition removes it
+	must(t, a.SetPermissionCondition("payments.approve", ""))
+	if granted, err := a.CheckPermission(1, "payments.approve"); err != nil || !granted {
+		t.Errorf("CheckPermission after removing the condition = %v, %v, want true", granted, err)
+	}
+}
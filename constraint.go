@@ -0,0 +1,78 @@
+package authority
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+// the kinds of constraint violations, a ConstraintError matches its kind with errors.Is
+var (
+	ErrDuplicate        = errors.New("duplicate entry")
+	ErrMissingReference = errors.New("referenced entry not found")
+)
+
+// the entities reported by the constraint errors
+const (
+	EntityRole           = "role"
+	EntityPermission     = "permission"
+	EntityRolePermission = "role_permission"
+	EntityUserRole       = "user_role"
+	EntityRoleComposite  = "role_composite"
+	EntityRoleParent     = "role_parent"
+
+	EntityResourcePermission = "resource_permission"
+	EntityPermissionDenial   = "permission_denial"
+	EntityGroup              = "group"
+)
+
+// ConstraintError is returned when a change violates a unique or a foreign key constraint of the storage,
+// callers can tell the violations apart with errors.Is(err, ErrDuplicate) instead of parsing the codes
+// of the driver. custom stores may return it too
+type ConstraintError struct {
+	// Kind is ErrDuplicate or ErrMissingReference
+	Kind error
+	// Entity is the kind of the changed entry, e.g. EntityRole
+	Entity string
+	// Name identifies the offending entry, e.g. the name of the role or the ids of an assignment
+	Name string
+	// Constraint is the name of the violated constraint as reported by the database
+	Constraint string
+	// Err is the error of the driver
+	Err error
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%s %q: %v", e.Entity, e.Name, e.Kind)
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches the kind of the violation, a duplicate role matches ErrRoleExists as well
+func (e *ConstraintError) Is(target error) bool {
+	return target == e.Kind || target == ErrRoleExists && e.Kind == ErrDuplicate && e.Entity == EntityRole
+}
+
+// pgConstraintError translates the unique and foreign key violations reported by pgdriver,
+// it returns the other errors as they are
+func pgConstraintError(err error, entity, name string) error {
+	var pgErr pgdriver.Error
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	var kind error
+	switch pgErr.Field('C') {
+	case "23505":
+		kind = ErrDuplicate
+	case "23503":
+		kind = ErrMissingReference
+	default:
+		return err
+	}
+
+	return &ConstraintError{Kind: kind, Entity: entity, Name: name, Constraint: pgErr.Field('n'), Err: err}
+}
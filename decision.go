@@ -0,0 +1,246 @@
+package authority
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// the reasons of the decisions of CheckPermissionDetailed
+const (
+	// ReasonGranted is the reason of a permission granted by a role of the user
+	ReasonGranted = "granted"
+	// ReasonSuperRole is the reason of a permission granted by the super role
+	ReasonSuperRole = "super_role"
+	// ReasonPermissionUnknown is the reason of a denial of a permission that doesn't exist
+	ReasonPermissionUnknown = "permission_unknown"
+	// ReasonNoRoles is the reason of a denial to a user without a role in effect
+	ReasonNoRoles = "no_roles"
+	// ReasonAssignmentExpired is the reason of a denial to a user whose assignment of a role granting the
+	// permission, or whose role's assignment of the permission, expired or hasn't started yet
+	ReasonAssignmentExpired = "assignment_expired"
+	// ReasonRoleLacksPermission is the reason of a denial to a user whose roles don't grant the permission
+	ReasonRoleLacksPermission = "role_lacks_permission"
+	// ReasonPermissionDenied is the reason of a denial of the permission to the user or to one of its roles
+	ReasonPermissionDenied = "permission_denied"
+	// ReasonConditionFailed is the reason of a denial of a conditional permission whose condition doesn't hold
+	ReasonConditionFailed = "condition_failed"
+)
+
+// Decision explains the result of a check, e.g. to tell the users why they can't do something or to debug
+// the policies
+type Decision struct {
+	Granted    bool
+	Reason     string
+	Permission string
+	Tenant     string
+	// Role is the role granting the permission, the super role included, or the role whose assignment
+	// expired for ReasonAssignmentExpired
+	Role string
+	// Roles are the roles of the user in effect, with the roles they include and inherit from
+	Roles []string
+	// Condition is the condition of the permission, if any
+	Condition string
+}
+
+// CheckPermissionDetailed checks a permission like CheckPermission and explains the decision, a permission that
+// doesn't exist is denied with ReasonPermissionUnknown instead of an error. the decision is made from the store
+// without the cache, it's meant for the diagnostics rather than the hot paths
+func (a *Authority) CheckPermissionDetailed(userID uint, permName string) (Decision, error) {
+	return a.CheckPermissionDetailedCtx(context.Background(), userID, permName)
+}
+
+// CheckPermissionDetailedCtx is the context-aware variant of CheckPermissionDetailed
+func (a *Authority) CheckPermissionDetailedCtx(ctx context.Context, userID uint, permName string) (Decision, error) {
+	return a.checkPermissionDetailed(NoCache(ctx), userKey(userID), permName, "")
+}
+
+// CheckPermissionDetailedInTenant is like CheckPermissionDetailed within the given tenant
+func (a *Authority) CheckPermissionDetailedInTenant(userID uint, permName string, tenant string) (Decision, error) {
+	return a.CheckPermissionDetailedInTenantCtx(context.Background(), userID, permName, tenant)
+}
+
+// CheckPermissionDetailedInTenantCtx is the context-aware variant of CheckPermissionDetailedInTenant
+func (a *Authority) CheckPermissionDetailedInTenantCtx(ctx context.Context, userID uint, permName string,
+	tenant string) (Decision, error) {
+	return a.checkPermissionDetailed(NoCache(ctx), userKey(userID), permName, tenant)
+}
+
+// CheckPermissionDetailed checks a permission of the user in its tenant and explains the decision,
+// see Authority.CheckPermissionDetailed
+func (u User) CheckPermissionDetailed(permName string) (Decision, error) {
+	return u.CheckPermissionDetailedCtx(context.Background(), permName)
+}
+
+// CheckPermissionDetailedCtx is the context-aware variant of CheckPermissionDetailed
+func (u User) CheckPermissionDetailedCtx(ctx context.Context, permName string) (Decision, error) {
+	return u.a.checkPermissionDetailed(NoCache(ctx), u.key, permName, u.tenant)
+}
+
+func (a *Authority) checkPermissionDetailed(ctx context.Context, user string, permName string, tenant string) (
+	Decision, error) {
+	d := Decision{Permission: permName, Tenant: tenant}
+
+	release, err := a.checks.acquire(ctx)
+	if err != nil {
+		return d, err
+	}
+	defer release()
+
+	// find the permission
+	perm, err := a.getTenantPermission(ctx, permName, tenant)
+	if errors.Is(err, ErrPermissionNotFound) {
+		d.Reason = ReasonPermissionUnknown
+		return d, nil
+	}
+	if err != nil {
+		return d, err
+	}
+	d.Condition = perm.Condition
+
+	// the roles of the user in effect
+	var roleIDs []uint
+	if roleIDs, err = a.userRoleIDs(ctx, user, tenant); err != nil {
+		return d, err
+	}
+	if roleIDs, err = a.grantingRoles(ctx, roleIDs); err != nil {
+		return d, err
+	}
+
+	names, err := a.roleNamesByID(ctx, roleIDs)
+	if err != nil {
+		return d, err
+	}
+	for _, id := range roleIDs {
+		d.Roles = append(d.Roles, names[id])
+	}
+
+	// the super role grants every permission unconditionally
+	var super bool
+	if super, err = a.holdsSuperRole(ctx, roleIDs, tenant); err != nil {
+		return d, err
+	}
+	if super {
+		d.Granted, d.Reason, d.Role, d.Condition = true, ReasonSuperRole, a.superRole, ""
+		return d, nil
+	}
+
+	// the first role granting the permission
+	now := a.now()
+	for _, id := range roleIDs {
+		var granted bool
+		if granted, err = a.store.RolesHavePermission(ctx, []uint{id}, perm.ID, now); err != nil {
+			return d, err
+		}
+		if granted {
+			d.Role = names[id]
+			break
+		}
+	}
+
+	if d.Role == "" {
+		return a.explainMissingGrant(ctx, d, user, perm, roleIDs, names, now)
+	}
+
+	// a denial to the user or to one of its roles overrides the grant
+	var denied bool
+	if denied, err = a.store.IsPermissionDenied(ctx, perm.ID, user, tenant, roleIDs); err != nil {
+		return d, err
+	}
+	if denied {
+		d.Reason = ReasonPermissionDenied
+		return d, nil
+	}
+
+	// a conditional permission is granted only when its condition holds for the attributes of the context
+	if perm.Condition != "" {
+		var holds bool
+		if holds, err = a.conditions.eval(ctx, perm.Condition, user); err != nil {
+			return d, err
+		}
+		if !holds {
+			d.Reason = ReasonConditionFailed
+			return d, nil
+		}
+	}
+
+	d.Granted, d.Reason = true, ReasonGranted
+
+	return d, nil
+}
+
+// explainMissingGrant returns the reason of a permission granted by none of the roles of the user in effect,
+// the assignments out of their period are looked up for ReasonAssignmentExpired
+func (a *Authority) explainMissingGrant(ctx context.Context, d Decision, user string, perm *Permission,
+	roleIDs []uint, names map[uint]string, now time.Time) (Decision, error) {
+	// the roles of the user granting the permission outside of the period of their assignment
+	userRoles, err := a.store.GetUserRoles(ctx, user)
+	if err != nil {
+		return d, err
+	}
+
+	for _, ur := range userRoles {
+		if (ur.Tenant != "" && ur.Tenant != d.Tenant) || ur.activeAt(now) {
+			continue
+		}
+
+		var expanded []uint
+		if expanded, err = a.grantingRoles(ctx, []uint{ur.RoleID}); err != nil {
+			return d, err
+		}
+
+		var granted bool
+		if granted, err = a.store.RolesHavePermission(ctx, expanded, perm.ID, now); err != nil {
+			return d, err
+		}
+		if granted {
+			var roles map[uint]string
+			if roles, err = a.roleNamesByID(ctx, []uint{ur.RoleID}); err != nil {
+				return d, err
+			}
+			d.Reason, d.Role = ReasonAssignmentExpired, roles[ur.RoleID]
+			return d, nil
+		}
+	}
+
+	// the roles of the user whose assignment of the permission is outside of its period
+	for _, id := range roleIDs {
+		rolePerm, err := a.store.GetRolePermission(ctx, id, perm.ID)
+		if errors.Is(err, ErrRolePermissionNotFound) {
+			continue
+		}
+		if err != nil {
+			return d, err
+		}
+		if !inPeriod(rolePerm.StartsAt, rolePerm.ExpiresAt, now) {
+			d.Reason, d.Role = ReasonAssignmentExpired, names[id]
+			return d, nil
+		}
+	}
+
+	if len(roleIDs) == 0 {
+		d.Reason = ReasonNoRoles
+	} else {
+		d.Reason = ReasonRoleLacksPermission
+	}
+
+	return d, nil
+}
+
+// roleNamesByID returns the names of the roles by id
+func (a *Authority) roleNamesByID(ctx context.Context, roleIDs []uint) (map[uint]string, error) {
+	names := make(map[uint]string, len(roleIDs))
+	if len(roleIDs) == 0 {
+		return names, nil
+	}
+
+	roles, err := a.store.GetRolesByID(ctx, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, role := range roles {
+		names[role.ID] = role.Name
+	}
+
+	return names, nil
+}
@@ -0,0 +1,87 @@
+package authority_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"authority"
+)
+
+func TestCheckPermissionDetailed(t *testing.T) {
+	now := time.Now()
+	a := newAuthority(t, authority.Options{SuperRole: "root", Now: func() time.Time { return now }})
+	setupRole(t, a, "editor", "articles.read", "articles.publish", "payments.approve")
+	setupRole(t, a, "viewer", "articles.read")
+	setupRole(t, a, "root")
+	must(t, a.SetPermissionCondition("payments.approve", "attrs.amount < 1000"))
+
+	check := func(ctx context.Context, userID uint, permName string) authority.Decision {
+		t.Helper()
+		d, err := a.CheckPermissionDetailedCtx(ctx, userID, permName)
+		must(t, err)
+		return d
+	}
+	ctx := context.Background()
+
+	if d := check(ctx, 1, "articles.missing"); d.Granted || d.Reason != authority.ReasonPermissionUnknown {
+		t.Errorf("the decision of an unknown permission = %+v", d)
+	}
+	if d := check(ctx, 1, "articles.read"); d.Granted || d.Reason != authority.ReasonNoRoles {
+		t.Errorf("the decision of a user without roles = %+v", d)
+	}
+
+	must(t, a.AssignRole(1, "viewer"))
+	d := check(ctx, 1, "articles.read")
+	if !d.Granted || d.Reason != authority.ReasonGranted || d.Role != "viewer" {
+		t.Errorf("the decision of a granted permission = %+v", d)
+	}
+	if want := []string{"viewer"}; !equalStrings(d.Roles, want) {
+		t.Errorf("the roles of the decision = %v, want %v", d.Roles, want)
+	}
+	if d = check(ctx, 1, "articles.publish"); d.Granted || d.Reason != authority.ReasonRoleLacksPermission {
+		t.Errorf("the decision of a permission the role lacks = %+v", d)
+	}
+
+	// the assignment of a role granting the permission expired
+	must(t, a.AssignRoleUntil(1, "editor", now.Add(time.Hour)))
+	if d = check(ctx, 1, "articles.publish"); !d.Granted || d.Role != "editor" {
+		t.Errorf("the decision of a time-bound assignment = %+v", d)
+	}
+	now = now.Add(2 * time.Hour)
+	d = check(ctx, 1, "articles.publish")
+	if d.Granted || d.Reason != authority.ReasonAssignmentExpired || d.Role != "editor" {
+		t.Errorf("the decision of an expired assignment = %+v", d)
+	}
+
+	must(t, a.AssignRole(2, "editor"))
+	must(t, a.DenyPermission(2, "articles.publish"))
+	if d = check(ctx, 2, "articles.publish"); d.Granted || d.Reason != authority.ReasonPermissionDenied {
+		t.Errorf("the decision of a denied permission = %+v", d)
+	}
+
+	d = check(ctx, 2, "payments.approve")
+	if d.Granted || d.Reason != authority.ReasonConditionFailed || d.Condition != "attrs.amount < 1000" {
+		t.Errorf("the decision of a failed condition = %+v", d)
+	}
+	d = check(authority.WithAttributes(ctx, map[string]interface{}{"amount": 10}), 2, "payments.approve")
+	if !d.Granted || d.Reason != authority.ReasonGranted {
+		t.Errorf("the decision of a condition holding = %+v", d)
+	}
+
+	must(t, a.AssignRole(3, "root"))
+	if d = check(ctx, 3, "articles.publish"); !d.Granted || d.Reason != authority.ReasonSuperRole || d.Role != "root" {
+		t.Errorf("the decision of the super role = %+v", d)
+	}
+
+	// the decisions agree with the checks
+	for _, userID := range []uint{1, 2, 3} {
+		for _, permName := range []string{"articles.read", "articles.publish", "payments.approve"} {
+			granted, err := a.CheckPermission(userID, permName)
+			must(t, err)
+			if d = check(ctx, userID, permName); d.Granted != granted {
+				t.Errorf("the decision of %s for the user %d = %+v, CheckPermission = %v", permName, userID, d, granted)
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package authority
+
+import (
+	"context"
+	"errors"
+)
+
+// MaterializeDefaultRoles assigns the default roles to the users as regular assignments, e.g. before removing
+// a role from Options.DefaultRoles while keeping it for the existing users. the users already having a role
+// are skipped
+func (a *Authority) MaterializeDefaultRoles(userIDs []uint) error {
+	return a.MaterializeDefaultRolesCtx(context.Background(), userIDs)
+}
+
+// MaterializeDefaultRolesCtx is the context-aware variant of MaterializeDefaultRoles
+func (a *Authority) MaterializeDefaultRolesCtx(ctx context.Context, userIDs []uint) error {
+	if len(a.defaultRoles) == 0 || len(userIDs) == 0 {
+		return nil
+	}
+
+	users := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		users = append(users, userKey(id))
+	}
+
+	return a.assignRoles(ctx, a.defaultRoles, users, "")
+}
+
+// defaultRoleIDs returns the ids of the default roles in the tenant, the default roles that don't exist
+// are ignored
+func (a *Authority) defaultRoleIDs(ctx context.Context, tenant string) ([]uint, error) {
+	roleIDs := make([]uint, 0, len(a.defaultRoles))
+	for _, roleName := range a.defaultRoles {
+		role, err := a.getTenantRole(ctx, roleName, tenant)
+		if errors.Is(err, ErrRoleNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		roleIDs = append(roleIDs, role.ID)
+	}
+
+	return roleIDs, nil
+}
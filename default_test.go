@@ -0,0 +1,33 @@
+package authority_test
+
+import (
+	"testing"
+
+	"authority"
+)
+
+func TestDefaultRoles(t *testing.T) {
+	a := newAuthority(t, authority.Options{DefaultRoles: []string{"member", "missing"}})
+	setupRole(t, a, "member", "comments.write")
+
+	ok, err := a.CheckPermission(1, "comments.write")
+	must(t, err)
+	if !ok {
+		t.Fatal("the default role is not considered assigned")
+	}
+
+	roles, err := a.GetUserRoles(1)
+	must(t, err)
+	if len(roles) != 0 {
+		t.Fatalf("GetUserRoles = %v, the default roles are not stored", roles)
+	}
+
+	must(t, a.CreateRole("missing"))
+	must(t, a.MaterializeDefaultRoles([]uint{1}))
+	if roles, err = a.GetUserRoles(1); err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 2 {
+		t.Fatalf("GetUserRoles = %v after MaterializeDefaultRoles", roles)
+	}
+}
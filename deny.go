@@ -0,0 +1,142 @@
+package authority
+
+import (
+	"context"
+)
+
+// the actions recorded on the audit entries of the denials
+const (
+	AuditPermissionDenied        = "permission.denied"
+	AuditPermissionDenialRevoked = "permission.denial_revoked"
+)
+
+// DenyPermission denies a permission to a user, the checks of the permission are false for the user whatever
+// its roles grant, e.g. to carve an exception out of a broad role. denying it twice is not an error
+func (a *Authority) DenyPermission(userID uint, permName string) error {
+	return a.DenyPermissionCtx(context.Background(), userID, permName)
+}
+
+// DenyPermissionCtx is the context-aware variant of DenyPermission
+func (a *Authority) DenyPermissionCtx(ctx context.Context, userID uint, permName string) error {
+	return a.deny(ctx, PermissionDenial{UserKey: userKey(userID)}, "", permName)
+}
+
+// RevokePermissionDenial revokes the denial of a permission to a user, the roles of the user grant it again
+func (a *Authority) RevokePermissionDenial(userID uint, permName string) error {
+	return a.RevokePermissionDenialCtx(context.Background(), userID, permName)
+}
+
+// RevokePermissionDenialCtx is the context-aware variant of RevokePermissionDenial
+func (a *Authority) RevokePermissionDenialCtx(ctx context.Context, userID uint, permName string) error {
+	return a.revokeDenial(ctx, PermissionDenial{UserKey: userKey(userID)}, "", permName)
+}
+
+// DenyRolePermission denies a permission to the users of a role, including the users of the composite roles
+// including it and of the roles inheriting from it, even when another of their roles grants it
+func (a *Authority) DenyRolePermission(roleName string, permName string) error {
+	return a.DenyRolePermissionCtx(context.Background(), roleName, permName)
+}
+
+// DenyRolePermissionCtx is the context-aware variant of DenyRolePermission
+func (a *Authority) DenyRolePermissionCtx(ctx context.Context, roleName string, permName string) error {
+	return a.deny(ctx, PermissionDenial{}, roleName, permName)
+}
+
+// RevokeRolePermissionDenial revokes the denial of a permission to the users of a role
+func (a *Authority) RevokeRolePermissionDenial(roleName string, permName string) error {
+	return a.RevokeRolePermissionDenialCtx(context.Background(), roleName, permName)
+}
+
+// RevokeRolePermissionDenialCtx is the context-aware variant of RevokeRolePermissionDenial
+func (a *Authority) RevokeRolePermissionDenialCtx(ctx context.Context, roleName string, permName string) error {
+	return a.revokeDenial(ctx, PermissionDenial{}, roleName, permName)
+}
+
+// GetPermissionDenials returns the denials of a permission to the users and to the roles
+func (a *Authority) GetPermissionDenials(permName string) ([]PermissionDenial, error) {
+	return a.GetPermissionDenialsCtx(context.Background(), permName)
+}
+
+// GetPermissionDenialsCtx is the context-aware variant of GetPermissionDenials
+func (a *Authority) GetPermissionDenialsCtx(ctx context.Context, permName string) ([]PermissionDenial, error) {
+	perm, err := a.getPermission(ctx, permName)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.store.GetPermissionDenials(ctx, perm.ID)
+}
+
+// DenyPermission denies a permission to the user in its tenant
+func (u User) DenyPermission(permName string) error {
+	return u.DenyPermissionCtx(context.Background(), permName)
+}
+
+// DenyPermissionCtx is the context-aware variant of DenyPermission
+func (u User) DenyPermissionCtx(ctx context.Context, permName string) error {
+	return u.a.deny(ctx, PermissionDenial{UserKey: u.key, Tenant: u.tenant}, "", permName)
+}
+
+// RevokePermissionDenial revokes the denial of a permission to the user in its tenant
+func (u User) RevokePermissionDenial(permName string) error {
+	return u.RevokePermissionDenialCtx(context.Background(), permName)
+}
+
+// RevokePermissionDenialCtx is the context-aware variant of RevokePermissionDenial
+func (u User) RevokePermissionDenialCtx(ctx context.Context, permName string) error {
+	return u.a.revokeDenial(ctx, PermissionDenial{UserKey: u.key, Tenant: u.tenant}, "", permName)
+}
+
+// deny denies a permission to the user of the denial, or to the role when it's named
+func (a *Authority) deny(ctx context.Context, denial PermissionDenial, roleName string, permName string) error {
+	if err := a.resolveDenial(ctx, &denial, roleName, permName); err != nil {
+		return err
+	}
+
+	denial.CreatedAt = a.now()
+	if err := a.store.DenyPermission(ctx, &denial); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{
+		Action: AuditPermissionDenied, UserKey: denial.UserKey, Role: roleName, Permission: permName,
+		Tenant: denial.Tenant,
+	})
+}
+
+// revokeDenial revokes the denial of a permission to the user of the denial, or to the role when it's named
+func (a *Authority) revokeDenial(ctx context.Context, denial PermissionDenial, roleName string,
+	permName string) error {
+	if err := a.resolveDenial(ctx, &denial, roleName, permName); err != nil {
+		return err
+	}
+
+	if err := a.store.RevokePermissionDenial(ctx, &denial); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{
+		Action: AuditPermissionDenialRevoked, UserKey: denial.UserKey, Role: roleName, Permission: permName,
+		Tenant: denial.Tenant,
+	})
+}
+
+// resolveDenial sets the ids of the permission and of the role when it's named
+func (a *Authority) resolveDenial(ctx context.Context, denial *PermissionDenial, roleName string,
+	permName string) error {
+	perm, err := a.getTenantPermission(ctx, permName, denial.Tenant)
+	if err != nil {
+		return err
+	}
+	denial.PermissionID = perm.ID
+
+	if roleName != "" {
+		var role *Role
+		if role, err = a.getRole(ctx, roleName); err != nil {
+			return err
+		}
+		denial.RoleID = role.ID
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package authority_test
+
+import (
+	"testing"
+
+	"authority"
+)
+
+func TestDenyPermissionOverridesRoles(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	setupRole(t, a, "editor", "articles.read", "articles.publish")
+	must(t, a.AssignRole(1, "editor"))
+	must(t, a.AssignRole(2, "editor"))
+	must(t, a.DefinePolicy("can_publish", "articles.publish AND editor"))
+
+	must(t, a.DenyPermission(1, "articles.publish"))
+
+	granted, err := a.CheckPermission(1, "articles.publish")
+	must(t, err)
+	if granted {
+		t.Error("CheckPermission of a denied permission = true")
+	}
+
+	perms, err := a.GetUserPermissions(1)
+	must(t, err)
+	if want := []string{"articles.read"}; !equalStrings(perms, want) {
+		t.Errorf("GetUserPermissions = %v, want %v", perms, want)
+	}
+
+	if granted, err = a.CheckPolicy(1, "can_publish"); err != nil || granted {
+		t.Errorf("CheckPolicy of a denied permission = %v, %v, want false", granted, err)
+	}
+
+	// the other users of the role keep the permission
+	if granted, err = a.CheckPolicy(2, "can_publish"); err != nil || !granted {
+		t.Errorf("CheckPolicy of another user = %v, %v, want true", granted, err)
+	}
+
+	// the role grants it again once the denial is revoked
+	must(t, a.RevokePermissionDenial(1, "articles.publish"))
+	if perms, err = a.GetUserPermissions(1); err != nil || len(perms) != 2 {
+		t.Errorf("GetUserPermissions after the revocation = %v, %v, want both permissions", perms, err)
+	}
+}
+
+func TestDenyRolePermission(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	setupRole(t, a, "editor", "articles.read", "articles.delete")
+	must(t, a.CreateRole("contractor"))
+	must(t, a.AssignRole(1, "editor"))
+	must(t, a.AssignRole(1, "contractor"))
+	must(t, a.AssignRole(2, "editor"))
+
+	must(t, a.DenyRolePermission("contractor", "articles.delete"))
+
+	for _, tt := range []struct {
+		user uint
+		want bool
+	}{
+		{1, false},
+		{2, true},
+	} {
+		granted, err := a.CheckPermission(tt.user, "articles.delete")
+		must(t, err)
+		if granted != tt.want {
+			t.Errorf("CheckPermission(%d) = %v, want %v", tt.user, granted, tt.want)
+		}
+	}
+
+	perms, err := a.User("1").GetPermissions()
+	must(t, err)
+	if want := []string{"articles.read"}; !equalStrings(perms, want) {
+		t.Errorf("User.GetPermissions = %v, want %v", perms, want)
+	}
+
+	denials, err := a.GetPermissionDenials("articles.delete")
+	must(t, err)
+	if len(denials) != 1 || denials[0].RoleID == 0 {
+		t.Errorf("GetPermissionDenials = %+v, want the denial to the role", denials)
+	}
+}
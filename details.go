@@ -0,0 +1,107 @@
+package authority
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// the sources of the roles listed by GetUserRoleDetails
+const (
+	// RoleSourceDirect is the source of the roles assigned to the user
+	RoleSourceDirect = "direct"
+	// RoleSourceIncluded is the source of the roles included by an assigned role, as a composite or a parent role
+	RoleSourceIncluded = "included"
+)
+
+// UserRoleDetail describes a role a user has, e.g. for a "your access" page
+type UserRoleDetail struct {
+	Name   string
+	Title  string
+	Tenant string
+	// Source is RoleSourceDirect or RoleSourceIncluded
+	Source string
+	// Via is the name of the assigned role including the role when it's included
+	Via string
+	// AssignedAt, ExpiresAt and GrantedBy describe the assignment, the one of the role including it
+	// when it's included. ExpiresAt is zero for the assignments without an expiry
+	AssignedAt time.Time
+	ExpiresAt  time.Time
+	GrantedBy  string
+}
+
+// GetUserRoleDetails returns the roles a user has with the details of their assignments, the roles included
+// by the assigned ones are listed after them. a role reachable from several assignments is listed once
+func (a *Authority) GetUserRoleDetails(userID uint) ([]UserRoleDetail, error) {
+	return a.GetUserRoleDetailsCtx(context.Background(), userID)
+}
+
+// GetUserRoleDetailsCtx is the context-aware variant of GetUserRoleDetails
+func (a *Authority) GetUserRoleDetailsCtx(ctx context.Context, userID uint) ([]UserRoleDetail, error) {
+	return a.userRoleDetails(ctx, userKey(userID), "")
+}
+
+func (a *Authority) userRoleDetails(ctx context.Context, user string, tenant string) ([]UserRoleDetail, error) {
+	userRoles, err := a.store.GetUserRoles(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	now := a.now()
+	assigned := make([]UserRole, 0, len(userRoles))
+	for _, ur := range userRoles {
+		if (ur.Tenant == "" || ur.Tenant == tenant) && ur.activeAt(now) {
+			assigned = append(assigned, ur)
+		}
+	}
+	sort.Slice(assigned, func(i, j int) bool { return assigned[i].CreatedAt.Before(assigned[j].CreatedAt) })
+
+	// the roles included by every assignment
+	included := make(map[uint][]uint, len(assigned))
+	roleIDs := make([]uint, 0, len(assigned))
+	for _, ur := range assigned {
+		ids, err := a.expandRoles(ctx, []uint{ur.RoleID})
+		if err != nil {
+			return nil, err
+		}
+		included[ur.ID] = ids[1:]
+		roleIDs = append(roleIDs, ids...)
+	}
+
+	roles, err := a.store.GetRolesByID(ctx, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]Role, len(roles))
+	for _, role := range roles {
+		byID[role.ID] = role
+	}
+
+	detail := func(roleID uint, ur UserRole, source, via string) UserRoleDetail {
+		return UserRoleDetail{
+			Name: byID[roleID].Name, Title: byID[roleID].Title, Tenant: ur.Tenant, Source: source, Via: via,
+			AssignedAt: ur.CreatedAt, ExpiresAt: ur.ExpiresAt, GrantedBy: ur.GrantedBy,
+		}
+	}
+
+	seen := make(map[uint]bool, len(roleIDs))
+	result := make([]UserRoleDetail, 0, len(roleIDs))
+	for _, ur := range assigned {
+		if !seen[ur.RoleID] {
+			seen[ur.RoleID] = true
+			result = append(result, detail(ur.RoleID, ur, RoleSourceDirect, ""))
+		}
+	}
+
+	for _, ur := range assigned {
+		for _, id := range included[ur.ID] {
+			if !seen[id] {
+				seen[id] = true
+				result = append(result, detail(id, ur, RoleSourceIncluded, byID[ur.RoleID].Name))
+			}
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,129 @@
+// Package echoauth provides echo middlewares enforcing the roles and permissions of authority
+package echoauth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"authority"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PermissionsKey is the key of the effective permissions stored in the echo context by LoadPermissions
+const PermissionsKey = "authority.permissions"
+
+// UserIDFunc returns the id of the user making the request,
+// it returns an error if the request is not authenticated
+type UserIDFunc func(c echo.Context) (uint, error)
+
+// ContextUserID returns a UserIDFunc reading the user id set in the echo context under the key,
+// e.g. by the authentication middleware
+func ContextUserID(key string) UserIDFunc {
+	return func(c echo.Context) (uint, error) {
+		v := c.Get(key)
+		if v == nil {
+			return 0, fmt.Errorf("no user id under %q", key)
+		}
+
+		userID, ok := v.(uint)
+		if !ok {
+			return 0, fmt.Errorf("the user id under %q is a %T", key, v)
+		}
+
+		return userID, nil
+	}
+}
+
+// RequirePermission returns a middleware that lets a request through only when the user making it has
+// the permission. it responds with 401 when the user id can't be extracted and with 403 when the permission is missing
+func RequirePermission(a *authority.Authority, userID UserIDFunc, permName string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id, err := userID(c)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized).SetInternal(err)
+			}
+
+			ok, err := a.CheckPermissionCtx(c.Request().Context(), id, permName)
+			if err = deny(ok, err, authority.ErrPermissionNotFound); err != nil {
+				return err
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// RequireRole returns a middleware that lets a request through only when the user making it has the role.
+// it responds with 401 when the user id can't be extracted and with 403 when the role is missing
+func RequireRole(a *authority.Authority, userID UserIDFunc, roleName string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id, err := userID(c)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized).SetInternal(err)
+			}
+
+			ok, err := a.CheckRoleCtx(c.Request().Context(), id, roleName)
+			if err = deny(ok, err, authority.ErrRoleNotFound); err != nil {
+				return err
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// LoadPermissions returns a middleware that stores the effective permissions of the user making the request
+// in the echo context, the handlers check them with HasPermission without querying the store again
+func LoadPermissions(a *authority.Authority, userID UserIDFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id, err := userID(c)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusUnauthorized).SetInternal(err)
+			}
+
+			perms, err := a.GetUserPermissionsCtx(c.Request().Context(), id)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+			}
+
+			c.Set(PermissionsKey, perms)
+
+			return next(c)
+		}
+	}
+}
+
+// Permissions returns the permissions stored by LoadPermissions
+func Permissions(c echo.Context) []string {
+	perms, _ := c.Get(PermissionsKey).([]string)
+	return perms
+}
+
+// HasPermission reports whether the permissions stored by LoadPermissions include the permission
+func HasPermission(c echo.Context, permName string) bool {
+	for _, perm := range Permissions(c) {
+		if perm == permName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// deny returns the error response of a failed check, a missing role or permission is treated as not granted
+func deny(ok bool, err error, notFound error) error {
+	if err != nil && !errors.Is(err, notFound) {
+		return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+
+	if !ok {
+		return echo.NewHTTPError(http.StatusForbidden)
+	}
+
+	return nil
+}
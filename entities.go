@@ -1,35 +1,267 @@
 package authority
 
-import "github.com/uptrace/bun"
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
 
 // Role represents the database model of roles
 type Role struct {
 	bun.BaseModel `bun:"table:roles,alias:role"`
 	ID            uint   `bun:"id,pk,autoincrement"`
-	Name          string `bun:"name,unique,notnull"`
+	Name          string `bun:"name,notnull"`
 	Title         string `bun:"title"`
+	Tenant        string `bun:"tenant,notnull,default:''"`
+	// Namespace is the application the role belongs to, see BunStoreOptions.Namespace
+	Namespace string `bun:"namespace,notnull,default:''"`
+	// Description is a human-readable explanation of the role, e.g. for the admin interfaces
+	Description string `bun:"description,notnull,default:''"`
+	// Metadata has the custom attributes of the role, stored as jsonb
+	Metadata map[string]interface{} `bun:"metadata,type:jsonb,nullzero"`
+	// State is the state of the role in its lifecycle, see RoleStateDraft
+	State string `bun:"state,notnull,default:'active'"`
+	// SegmentPercent is the percentage of the users granted the role without an assignment, selected by
+	// SegmentSeed, see AssignRoleToSegment
+	SegmentPercent int    `bun:"segment_percent,notnull,default:0"`
+	SegmentSeed    string `bun:"segment_seed,notnull,default:''"`
+	// UpdatedAt and Origin are the replication version of the role, they are read by ListRoleVersions only,
+	// see ReplicationOptions
+	UpdatedAt time.Time `bun:"updated_at,scanonly"`
+	Origin    string    `bun:"origin,scanonly"`
 }
 
 // Permission represents the database model of permissions
 type Permission struct {
 	bun.BaseModel `bun:"table:permissions,alias:perm"`
 	ID            uint   `bun:"id,pk,autoincrement"`
-	Name          string `bun:"name,unique,notnull"`
+	Name          string `bun:"name,notnull"`
 	Title         string `bun:"title"`
+	Tenant        string `bun:"tenant,notnull,default:''"`
+	// Namespace is the application the permission belongs to, see BunStoreOptions.Namespace
+	Namespace string `bun:"namespace,notnull,default:''"`
+	// Condition is a CEL expression that must hold at check time for the permission to be granted
+	Condition string `bun:"condition,notnull,default:''"`
+	// Action and Resource describe what the permission allows, e.g. "read" on "invoices", they are empty
+	// for the permissions only known by name
+	Action   string `bun:"action,notnull,default:''"`
+	Resource string `bun:"resource,notnull,default:''"`
+	// Description is a human-readable explanation of the permission, e.g. for the admin interfaces
+	Description string `bun:"description,notnull,default:''"`
+	// Metadata has the custom attributes of the permission, stored as jsonb
+	Metadata map[string]interface{} `bun:"metadata,type:jsonb,nullzero"`
+	// UpdatedAt and Origin are the replication version of the permission, they are read by
+	// ListPermissionVersions only, see ReplicationOptions
+	UpdatedAt time.Time `bun:"updated_at,scanonly"`
+	Origin    string    `bun:"origin,scanonly"`
 }
 
 // RolePermission stores the relationship between roles and permissions
 type RolePermission struct {
 	bun.BaseModel `bun:"table:role_permissions,alias:rp"`
+	ID            uint      `bun:"id,pk,autoincrement"`
+	RoleID        uint      `bun:"role_id,notnull"`
+	PermissionID  uint      `bun:"permission_id,notnull"`
+	StartsAt      time.Time `bun:"starts_at,nullzero"`
+	ExpiresAt     time.Time `bun:"expires_at,nullzero"`
+}
+
+// activeAt reports whether the permission is granted to the role at the given time
+func (rp RolePermission) activeAt(now time.Time) bool {
+	return inPeriod(rp.StartsAt, rp.ExpiresAt, now)
+}
+
+// AssignmentClaim records that a source wants a user to have a role, see WithAssignmentSource
+type AssignmentClaim struct {
+	bun.BaseModel `bun:"table:assignment_claims,alias:ac"`
+	ID            uint      `bun:"id,pk,autoincrement"`
+	UserKey       string    `bun:"user_key,notnull"`
+	RoleID        uint      `bun:"role_id,notnull"`
+	Tenant        string    `bun:"tenant,notnull,default:''"`
+	Source        string    `bun:"source,notnull"`
+	ClaimedAt     time.Time `bun:"claimed_at,notnull"`
+}
+
+// ResourcePermission grants a permission on a single resource to a user or to a role
+type ResourcePermission struct {
+	bun.BaseModel `bun:"table:resource_permissions,alias:resp"`
+	ID            uint   `bun:"id,pk,autoincrement"`
+	PermissionID  uint   `bun:"permission_id,notnull"`
+	ResourceType  string `bun:"resource_type,notnull"`
+	ResourceID    string `bun:"resource_id,notnull"`
+	// UserKey is the user the permission is granted to, it's empty when it's granted to a role
+	UserKey string `bun:"user_key,notnull,default:''"`
+	// RoleID is the role the permission is granted to, it's 0 when it's granted to a user
+	RoleID    uint      `bun:"role_id,nullzero"`
+	Tenant    string    `bun:"tenant,notnull,default:''"`
+	CreatedAt time.Time `bun:"created_at,nullzero,default:current_timestamp"`
+}
+
+// PermissionDenial denies a permission to a user or to the users of a role, it overrides the roles granting it
+type PermissionDenial struct {
+	bun.BaseModel `bun:"table:permission_denials,alias:pd"`
 	ID            uint `bun:"id,pk,autoincrement"`
-	RoleID        uint `bun:"role_id,notnull"`
 	PermissionID  uint `bun:"permission_id,notnull"`
+	// UserKey is the user the permission is denied to, it's empty when it's denied to a role
+	UserKey string `bun:"user_key,notnull,default:''"`
+	// RoleID is the role the permission is denied to, it's 0 when it's denied to a user
+	RoleID    uint      `bun:"role_id,nullzero"`
+	Tenant    string    `bun:"tenant,notnull,default:''"`
+	CreatedAt time.Time `bun:"created_at,nullzero,default:current_timestamp"`
+}
+
+// UserTombstone is the access a user had when it was offboarded, see OffboardUser
+type UserTombstone struct {
+	bun.BaseModel `bun:"table:user_tombstones,alias:ut"`
+	ID            uint               `bun:"id,pk,autoincrement"`
+	UserKey       string             `bun:"user_key,notnull"`
+	Roles         []SnapshotUserRole `bun:"roles,type:jsonb"`
+	Grants        []TombstoneGrant   `bun:"grants,type:jsonb"`
+	Reason        string             `bun:"reason,notnull,default:''"`
+	OffboardedBy  string             `bun:"offboarded_by,notnull,default:''"`
+	OffboardedAt  time.Time          `bun:"offboarded_at,notnull"`
+}
+
+// Group is a group of users, e.g. a team, whose members have its roles
+type Group struct {
+	bun.BaseModel `bun:"table:groups,alias:grp"`
+	ID            uint      `bun:"id,pk,autoincrement"`
+	Name          string    `bun:"name,notnull,unique"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,default:current_timestamp"`
+}
+
+// GroupMember is a user member of a group
+type GroupMember struct {
+	bun.BaseModel `bun:"table:group_members,alias:gm"`
+	ID            uint      `bun:"id,pk,autoincrement"`
+	GroupID       uint      `bun:"group_id,notnull"`
+	UserKey       string    `bun:"user_key,notnull"`
+	CreatedAt     time.Time `bun:"created_at,nullzero,default:current_timestamp"`
+}
+
+// GroupRole is a role assigned to a group
+type GroupRole struct {
+	bun.BaseModel `bun:"table:group_roles,alias:gr"`
+	ID            uint `bun:"id,pk,autoincrement"`
+	GroupID       uint `bun:"group_id,notnull"`
+	RoleID        uint `bun:"role_id,notnull"`
 }
 
 // UserRole represents the relationship between users and roles
 type UserRole struct {
 	bun.BaseModel `bun:"table:user_roles,alias:ur"`
 	ID            uint `bun:"id,pk,autoincrement"`
-	UserID        uint `bun:"user_id,notnull"`
+	// UserID is the id of the users identified by a number, it's 0 for the others
+	UserID uint `bun:"user_id,nullzero"`
+	// UserKey identifies the user, it's the decimal UserID for the users identified by a number
+	UserKey   string    `bun:"user_key,notnull,default:''"`
+	RoleID    uint      `bun:"role_id,notnull"`
+	Tenant    string    `bun:"tenant,notnull,default:''"`
+	StartsAt  time.Time `bun:"starts_at,nullzero"`
+	ExpiresAt time.Time `bun:"expires_at,nullzero"`
+	CreatedAt time.Time `bun:"created_at,nullzero,default:current_timestamp"`
+	// GrantedBy is the actor of the request metadata of the assignment
+	GrantedBy string `bun:"granted_by,notnull,default:''"`
+}
+
+// activeAt reports whether the assignment is in effect at the given time, an assignment without
+// a start time is in effect as soon as it's made and one without an expiry until it's revoked
+func (r UserRole) activeAt(now time.Time) bool {
+	return inPeriod(r.StartsAt, r.ExpiresAt, now)
+}
+
+// inPeriod reports whether the time is within the period, a zero start or end leaves the period open
+func inPeriod(start, end, now time.Time) bool {
+	return (start.IsZero() || !start.After(now)) && (end.IsZero() || now.Before(end))
+}
+
+// RoleComposite stores the roles included in a composite role
+type RoleComposite struct {
+	bun.BaseModel `bun:"table:role_composites,alias:rc"`
+	ID            uint `bun:"id,pk,autoincrement"`
 	RoleID        uint `bun:"role_id,notnull"`
+	MemberID      uint `bun:"member_id,notnull"`
+}
+
+// RoleParent stores the parent of a role, a role inherits the permissions of its parent
+type RoleParent struct {
+	bun.BaseModel `bun:"table:role_parents,alias:rpa"`
+	ID            uint `bun:"id,pk,autoincrement"`
+	RoleID        uint `bun:"role_id,notnull,unique"`
+	ParentID      uint `bun:"parent_id,notnull"`
+}
+
+// Policy is a named boolean expression over permission and role names, e.g. "articles.write AND (editor OR admin)"
+type Policy struct {
+	bun.BaseModel `bun:"table:policies,alias:pol"`
+	ID            uint   `bun:"id,pk,autoincrement"`
+	Name          string `bun:"name,notnull,unique"`
+	Expression    string `bun:"expression,notnull"`
+}
+
+// AccessRequest is a request of a user for a role, the role is assigned once the request is approved
+type AccessRequest struct {
+	bun.BaseModel `bun:"table:access_requests,alias:ar"`
+	ID            uint   `bun:"id,pk,autoincrement"`
+	UserKey       string `bun:"user_key,notnull"`
+	Role          string `bun:"role,notnull"`
+	Tenant        string `bun:"tenant,notnull,default:''"`
+	Reason        string `bun:"reason,notnull,default:''"`
+	// Status is RequestPending until the request is approved or denied
+	Status      string    `bun:"status,notnull"`
+	RequestedAt time.Time `bun:"requested_at,notnull"`
+	// DecidedBy is the approver who approved or denied the request
+	DecidedBy string    `bun:"decided_by,notnull,default:''"`
+	DecidedAt time.Time `bun:"decided_at,nullzero"`
+}
+
+// RequestApproval is the approval of an access request by one of the approvers of a role needing several
+// approvals, see ApprovalThreshold
+type RequestApproval struct {
+	bun.BaseModel `bun:"table:request_approvals,alias:rqa"`
+	ID            uint      `bun:"id,pk,autoincrement"`
+	RequestID     uint      `bun:"request_id,notnull"`
+	Approver      string    `bun:"approver,notnull"`
+	ApprovedAt    time.Time `bun:"approved_at,notnull"`
+}
+
+// ImportBatch records a bulk import made with an idempotency key
+type ImportBatch struct {
+	bun.BaseModel `bun:"table:import_batches,alias:ib"`
+	ID            uint   `bun:"id,pk,autoincrement"`
+	Key           string `bun:"key,notnull,unique"`
+	Kind          string `bun:"kind,notnull"`
+	// Status is ImportCompleted or ImportFailed
+	Status string `bun:"status,notnull"`
+	// Items is the number of imported items
+	Items int `bun:"items,notnull"`
+	// Error is the error of a failed import
+	Error      string    `bun:"error,notnull,default:''"`
+	StartedAt  time.Time `bun:"started_at,notnull"`
+	FinishedAt time.Time `bun:"finished_at,nullzero"`
+}
+
+// AuditEntry records a change made to the roles, permissions or their assignments
+type AuditEntry struct {
+	bun.BaseModel `bun:"table:audit_entries,alias:ae"`
+	ID            uint      `bun:"id,pk,autoincrement"`
+	Action        string    `bun:"action,notnull"`
+	UserID        uint      `bun:"user_id"`
+	UserKey       string    `bun:"user_key"`
+	Role          string    `bun:"role"`
+	Permission    string    `bun:"permission"`
+	Detail        string    `bun:"detail"`
+	Tenant        string    `bun:"tenant"`
+	Actor         string    `bun:"actor"`
+	IP            string    `bun:"ip"`
+	UserAgent     string    `bun:"user_agent"`
+	RequestID     string    `bun:"request_id"`
+	CreatedAt     time.Time `bun:"created_at,notnull"`
+}
+
+// PolicyVersion is the single row holding the version of the definitions of the roles and permissions
+type PolicyVersion struct {
+	bun.BaseModel `bun:"table:policy_versions,alias:pv"`
+	ID            uint   `bun:"id,pk"`
+	Version       uint64 `bun:"version,notnull"`
 }
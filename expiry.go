@@ -0,0 +1,167 @@
+package authority
+
+import (
+	"context"
+	"time"
+)
+
+// AuditAssignmentExtended is the action recorded when the expiry of an assignment changes
+const AuditAssignmentExtended = "assignment.extended"
+
+// AssignmentFilter selects the time-bound assignments to extend
+type AssignmentFilter struct {
+	// Role selects the assignments of the role, every role when it's empty
+	Role string
+	// Tenant selects the assignments made in the tenant, the global ones when it's empty
+	Tenant string
+	// UserIDs and UserKeys select the assignments of the users, every user when both are empty
+	UserIDs  []uint
+	UserKeys []string
+	// ExpiresBefore selects the assignments expiring before the time, every expiry when it's zero
+	ExpiresBefore time.Time
+}
+
+// ExtendAssignments sets the expiry of the time-bound assignments matching the filter, e.g. extending all the
+// contractor roles by 30 days. the assignments without an expiry are not affected and an assignment
+// expiring after newExpiry is never shortened. the changes are made atomically with an audit entry for every
+// assignment, it returns the number of extended assignments
+func (a *Authority) ExtendAssignments(filter AssignmentFilter, newExpiry time.Time) (int, error) {
+	return a.ExtendAssignmentsCtx(context.Background(), filter, newExpiry)
+}
+
+// ExtendAssignmentsCtx is the context-aware variant of ExtendAssignments
+func (a *Authority) ExtendAssignmentsCtx(ctx context.Context, filter AssignmentFilter, newExpiry time.Time) (int, error) {
+	var extended int
+
+	err := a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		extended = 0
+
+		var err error
+		var roleID uint
+		if filter.Role != "" {
+			var role *Role
+			if role, err = tx.getTenantRole(ctx, filter.Role, filter.Tenant); err != nil {
+				return err
+			}
+			roleID = role.ID
+		}
+
+		keys := append([]string(nil), filter.UserKeys...)
+		for _, userID := range filter.UserIDs {
+			keys = append(keys, userKey(userID))
+		}
+
+		var userRoles []UserRole
+		if userRoles, err = tx.store.GetTimeBoundUserRoles(ctx, roleID, filter.Tenant, keys); err != nil {
+			return err
+		}
+
+		ids := make([]uint, 0, len(userRoles))
+		matched := make([]UserRole, 0, len(userRoles))
+		for _, ur := range userRoles {
+			if !ur.ExpiresAt.Before(newExpiry) {
+				continue
+			}
+			if !filter.ExpiresBefore.IsZero() && !ur.ExpiresAt.Before(filter.ExpiresBefore) {
+				continue
+			}
+			ids = append(ids, ur.ID)
+			matched = append(matched, ur)
+		}
+
+		if err = tx.store.SetUserRolesExpiry(ctx, ids, newExpiry); err != nil {
+			return err
+		}
+
+		names, err := tx.roleNames(ctx, matched)
+		if err != nil {
+			return err
+		}
+
+		for _, ur := range matched {
+			if err = tx.changed(ctx, AuditEntry{
+				Action: AuditAssignmentExtended, UserKey: ur.UserKey, Role: names[ur.RoleID], Tenant: ur.Tenant,
+				Detail: ur.ExpiresAt.Format(time.RFC3339) + " -> " + newExpiry.Format(time.RFC3339),
+			}); err != nil {
+				return err
+			}
+		}
+
+		extended = len(matched)
+
+		return nil
+	})
+
+	return extended, err
+}
+
+// SweepExpiredAssignments revokes the assignments whose expiry passed, in a single transaction. the checks
+// ignore them already, the sweep deletes them with an audit entry whose detail is "expired" and notifies the
+// users like RevokeRole does. it returns the number of revoked assignments, and ErrOperationLocked when another
+// instance sweeps them for longer than Options.LockTimeout
+func (a *Authority) SweepExpiredAssignments() (int, error) {
+	return a.SweepExpiredAssignmentsCtx(context.Background())
+}
+
+// SweepExpiredAssignmentsCtx is the context-aware variant of SweepExpiredAssignments
+func (a *Authority) SweepExpiredAssignmentsCtx(ctx context.Context) (int, error) {
+	var swept int
+
+	sweep := func(ctx context.Context, tx *Authority) error {
+		swept = 0
+
+		userRoles, err := tx.store.GetExpiredUserRoles(ctx, tx.now())
+		if err != nil {
+			return err
+		}
+
+		var names map[uint]string
+		if names, err = tx.roleNames(ctx, userRoles); err != nil {
+			return err
+		}
+
+		for _, ur := range userRoles {
+			if err = tx.store.RevokeRole(ctx, ur.UserKey, ur.RoleID, ur.Tenant); err != nil {
+				return err
+			}
+
+			if err = tx.changed(ctx, AuditEntry{
+				Action: AuditRoleRevoked, UserKey: ur.UserKey, Role: names[ur.RoleID], Tenant: ur.Tenant,
+				Detail: "expired",
+			}); err != nil {
+				return err
+			}
+		}
+
+		swept = len(userRoles)
+
+		return nil
+	}
+
+	// the instances sweeping together would audit and notify the revocations twice
+	err := a.exclusive(ctx, "sweep_expired", a.lockTimeout, func(ctx context.Context) error {
+		return a.inTx(ctx, sweep)
+	})
+
+	return swept, err
+}
+
+// roleNames returns the names of the roles of the assignments by id
+func (a *Authority) roleNames(ctx context.Context, userRoles []UserRole) (map[uint]string, error) {
+	roleIDs := make([]uint, 0, len(userRoles))
+	for _, ur := range userRoles {
+		roleIDs = append(roleIDs, ur.RoleID)
+	}
+
+	roles, err := a.store.GetRolesByID(ctx, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uint]string, len(roles))
+	for _, role := range roles {
+		names[role.ID] = role.Name
+	}
+
+	return names, nil
+}
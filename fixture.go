@@ -0,0 +1,171 @@
+package authority
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/uptrace/bun/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// fixture is a document of a fixture file in bun's fixture format
+type fixture struct {
+	Model string                   `yaml:"model"`
+	Rows  []map[string]interface{} `yaml:"rows"`
+}
+
+type fixtureModel struct {
+	table    string
+	newModel func() interface{}
+}
+
+// LoadFixtures seeds the authority tables from fixture files written in bun's fixture format, e.g.
+//
+//	# testdata/fixture.yml
+//	- model: Role
+//	  rows:
+//	    - _id: admin
+//	      name: admin
+//	- model: UserRole
+//	  rows:
+//	    - user_id: 1
+//	      role_id: '{{ $.Role.admin.ID }}'
+//
+// the supported models are Role, Permission, RolePermission, UserRole and RoleComposite.
+// besides referencing rows of the same load by their _id, values can use the template functions
+// role and permission which return the id of a stored role or permission by its name, e.g. '{{ role "admin" }}'
+// it returns ErrNotSupported if the authority doesn't use a bun store
+func (a *Authority) LoadFixtures(ctx context.Context, fsys fs.FS, names ...string) error {
+	s, ok := a.store.(*BunStore)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	// the loaded rows change the results of the cached lookups
+	defer func() { _ = a.purgeCache(ctx) }()
+
+	models := map[string]fixtureModel{
+		"Role":           {table: s.tableRole, newModel: func() interface{} { return new(Role) }},
+		"Permission":     {table: s.tablePerm, newModel: func() interface{} { return new(Permission) }},
+		"RolePermission": {table: s.tableRolePerm, newModel: func() interface{} { return new(RolePermission) }},
+		"UserRole":       {table: s.tableUserRole, newModel: func() interface{} { return new(UserRole) }},
+		"RoleComposite":  {table: s.tableComposite, newModel: func() interface{} { return new(RoleComposite) }},
+	}
+
+	funcs := template.FuncMap{
+		"role": func(name string) (uint, error) {
+			role, err := a.getRole(ctx, name)
+			if err != nil {
+				return 0, err
+			}
+			return role.ID, nil
+		},
+		"permission": func(name string) (uint, error) {
+			perm, err := a.getPermission(ctx, name)
+			if err != nil {
+				return 0, err
+			}
+			return perm.ID, nil
+		},
+	}
+
+	// rows with an _id by model name, referenced by the templates
+	data := make(map[string]map[string]interface{})
+
+	for _, name := range names {
+		b, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		var fixtures []fixture
+		if err = yaml.Unmarshal(b, &fixtures); err != nil {
+			return fmt.Errorf("fixture %s: %w", name, err)
+		}
+
+		for _, f := range fixtures {
+			model, ok := models[f.Model]
+			if !ok {
+				return fmt.Errorf("fixture %s: unknown model %q", name, f.Model)
+			}
+
+			for _, row := range f.Rows {
+				dest := model.newModel()
+				if err = scanFixtureRow(s.db.Dialect().Tables(), dest, row, funcs, data); err != nil {
+					return fmt.Errorf("fixture %s: %s: %w", name, f.Model, err)
+				}
+
+				// the users of the fixtures may be identified by user_id or user_key
+				if ur, ok := dest.(*UserRole); ok {
+					if ur.UserKey == "" {
+						ur.UserKey = userKey(ur.UserID)
+					} else if ur.UserID == 0 {
+						ur.UserID = userIDOf(ur.UserKey)
+					}
+				}
+
+				if _, err = s.db.NewInsert().Model(dest).ModelTableExpr(model.table).Exec(ctx); err != nil {
+					return fmt.Errorf("fixture %s: %s: %w", name, f.Model, err)
+				}
+
+				if id, ok := row["_id"].(string); ok {
+					if data[f.Model] == nil {
+						data[f.Model] = make(map[string]interface{})
+					}
+					data[f.Model][id] = dest
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// scanFixtureRow sets the fields of the model from the columns of a fixture row
+func scanFixtureRow(
+	tables *schema.Tables, dest interface{}, row map[string]interface{}, funcs template.FuncMap,
+	data map[string]map[string]interface{},
+) error {
+	strct := reflect.ValueOf(dest).Elem()
+	table := tables.Get(strct.Type())
+
+	for column, value := range row {
+		if column == "_id" {
+			continue
+		}
+
+		field, err := table.Field(column)
+		if err != nil {
+			return err
+		}
+
+		switch v := value.(type) {
+		case int:
+			value = int64(v)
+		case string:
+			if strings.Contains(v, "{{") {
+				var t *template.Template
+				if t, err = template.New(column).Funcs(funcs).Parse(v); err != nil {
+					return err
+				}
+
+				var buf bytes.Buffer
+				if err = t.Execute(&buf, data); err != nil {
+					return err
+				}
+				value = buf.String()
+			}
+		}
+
+		if err = field.ScanValue(strct, value); err != nil {
+			return fmt.Errorf("column %s: %w", column, err)
+		}
+	}
+
+	return nil
+}
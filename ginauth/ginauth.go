@@ -0,0 +1,119 @@
+// Package ginauth provides gin middlewares enforcing the roles and permissions of authority
+package ginauth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"authority"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionsKey is the key of the effective permissions stored in the gin context by LoadPermissions
+const PermissionsKey = "authority.permissions"
+
+// UserIDFunc returns the id of the user making the request,
+// it returns an error if the request is not authenticated
+type UserIDFunc func(c *gin.Context) (uint, error)
+
+// ContextUserID returns a UserIDFunc reading the user id set in the gin context under the key,
+// e.g. by the authentication middleware
+func ContextUserID(key string) UserIDFunc {
+	return func(c *gin.Context) (uint, error) {
+		v, ok := c.Get(key)
+		if !ok {
+			return 0, fmt.Errorf("no user id under %q", key)
+		}
+
+		userID, ok := v.(uint)
+		if !ok {
+			return 0, fmt.Errorf("the user id under %q is a %T", key, v)
+		}
+
+		return userID, nil
+	}
+}
+
+// RequirePermission returns a middleware that lets a request through only when the user making it has
+// the permission. it aborts with 401 when the user id can't be extracted and with 403 when the permission is missing
+func RequirePermission(a *authority.Authority, userID UserIDFunc, permName string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := userID(c)
+		if err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		ok, err := a.CheckPermissionCtx(c.Request.Context(), id, permName)
+		abortUnless(c, ok, err, authority.ErrPermissionNotFound)
+	}
+}
+
+// RequireRole returns a middleware that lets a request through only when the user making it has the role.
+// it aborts with 401 when the user id can't be extracted and with 403 when the role is missing
+func RequireRole(a *authority.Authority, userID UserIDFunc, roleName string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := userID(c)
+		if err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		ok, err := a.CheckRoleCtx(c.Request.Context(), id, roleName)
+		abortUnless(c, ok, err, authority.ErrRoleNotFound)
+	}
+}
+
+// LoadPermissions returns a middleware that stores the effective permissions of the user making the request
+// in the gin context, the handlers check them with HasPermission without querying the store again
+func LoadPermissions(a *authority.Authority, userID UserIDFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := userID(c)
+		if err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		perms, err := a.GetUserPermissionsCtx(c.Request.Context(), id)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+
+		c.Set(PermissionsKey, perms)
+	}
+}
+
+// Permissions returns the permissions stored by LoadPermissions
+func Permissions(c *gin.Context) []string {
+	perms, _ := c.Value(PermissionsKey).([]string)
+	return perms
+}
+
+// HasPermission reports whether the permissions stored by LoadPermissions include the permission
+func HasPermission(c *gin.Context, permName string) bool {
+	for _, perm := range Permissions(c) {
+		if perm == permName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// abortUnless aborts the request when the check failed, a missing role or permission is treated as not granted
+func abortUnless(c *gin.Context, ok bool, err error, notFound error) {
+	if err != nil && !errors.Is(err, notFound) {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	if !ok {
+		c.AbortWithStatus(http.StatusForbidden)
+		return
+	}
+
+	c.Next()
+}
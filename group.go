@@ -0,0 +1,267 @@
+package authority
+
+import (
+	"context"
+	"errors"
+)
+
+// the actions recorded on the audit entries of the groups, the detail is the name of the group
+const (
+	AuditGroupCreated       = "group.created"
+	AuditGroupDeleted       = "group.deleted"
+	AuditGroupMemberAdded   = "group.member_added"
+	AuditGroupMemberRemoved = "group.member_removed"
+	AuditGroupRoleAssigned  = "group.role_assigned"
+	AuditGroupRoleRevoked   = "group.role_revoked"
+)
+
+var ErrGroupNotFound = errors.New("group not found")
+
+// the groups, e.g. teams, have users as members and global roles. the members of a group have its roles as if
+// they were assigned to them in every tenant. the checks consider the roles of the groups transparently while
+// GetUserRoles lists the roles assigned to the user only
+
+// CreateGroup stores a group, creating it twice is not an error
+func (a *Authority) CreateGroup(groupName string) error {
+	return a.CreateGroupCtx(context.Background(), groupName)
+}
+
+// CreateGroupCtx is the context-aware variant of CreateGroup
+func (a *Authority) CreateGroupCtx(ctx context.Context, groupName string) error {
+	if _, err := a.store.GetGroup(ctx, groupName); !errors.Is(err, ErrGroupNotFound) {
+		return err
+	}
+
+	if err := a.store.CreateGroup(ctx, &Group{Name: groupName, CreatedAt: a.now()}); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{Action: AuditGroupCreated, Detail: groupName})
+}
+
+// DeleteGroup deletes a group, its members lose its roles
+func (a *Authority) DeleteGroup(groupName string) error {
+	return a.DeleteGroupCtx(context.Background(), groupName)
+}
+
+// DeleteGroupCtx is the context-aware variant of DeleteGroup
+func (a *Authority) DeleteGroupCtx(ctx context.Context, groupName string) error {
+	group, err := a.store.GetGroup(ctx, groupName)
+	if err != nil {
+		return err
+	}
+
+	if err = a.store.DeleteGroup(ctx, group.ID); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{Action: AuditGroupDeleted, Detail: groupName})
+}
+
+// AddUserToGroup adds a user to the members of a group, adding it twice is not an error
+func (a *Authority) AddUserToGroup(userID uint, groupName string) error {
+	return a.AddUserToGroupCtx(context.Background(), userID, groupName)
+}
+
+// AddUserToGroupCtx is the context-aware variant of AddUserToGroup
+func (a *Authority) AddUserToGroupCtx(ctx context.Context, userID uint, groupName string) error {
+	return a.addGroupMember(ctx, userKey(userID), groupName)
+}
+
+// RemoveUserFromGroup removes a user from the members of a group
+func (a *Authority) RemoveUserFromGroup(userID uint, groupName string) error {
+	return a.RemoveUserFromGroupCtx(context.Background(), userID, groupName)
+}
+
+// RemoveUserFromGroupCtx is the context-aware variant of RemoveUserFromGroup
+func (a *Authority) RemoveUserFromGroupCtx(ctx context.Context, userID uint, groupName string) error {
+	return a.removeGroupMember(ctx, userKey(userID), groupName)
+}
+
+// AssignRoleToGroup assigns a role to a group, its members have the role. assigning it twice is not an error
+func (a *Authority) AssignRoleToGroup(groupName string, roleName string) error {
+	return a.AssignRoleToGroupCtx(context.Background(), groupName, roleName)
+}
+
+// AssignRoleToGroupCtx is the context-aware variant of AssignRoleToGroup
+func (a *Authority) AssignRoleToGroupCtx(ctx context.Context, groupName string, roleName string) error {
+	group, role, err := a.groupRole(ctx, groupName, roleName)
+	if err != nil {
+		return err
+	}
+
+	// a retired role is kept for the history only
+	if role.stateOrDefault() == RoleStateRetired {
+		return ErrRoleRetired
+	}
+
+	if err = a.store.AssignGroupRole(ctx, &GroupRole{GroupID: group.ID, RoleID: role.ID}); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{
+		Action: AuditGroupRoleAssigned, Role: roleName, Tenant: role.Tenant, Detail: groupName,
+	})
+}
+
+// RevokeRoleFromGroup revokes a role from a group
+func (a *Authority) RevokeRoleFromGroup(groupName string, roleName string) error {
+	return a.RevokeRoleFromGroupCtx(context.Background(), groupName, roleName)
+}
+
+// RevokeRoleFromGroupCtx is the context-aware variant of RevokeRoleFromGroup
+func (a *Authority) RevokeRoleFromGroupCtx(ctx context.Context, groupName string, roleName string) error {
+	group, role, err := a.groupRole(ctx, groupName, roleName)
+	if err != nil {
+		return err
+	}
+
+	if err = a.store.RevokeGroupRole(ctx, group.ID, role.ID); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{
+		Action: AuditGroupRoleRevoked, Role: roleName, Tenant: role.Tenant, Detail: groupName,
+	})
+}
+
+// GetGroupRoles returns the names of the roles of a group
+func (a *Authority) GetGroupRoles(groupName string) ([]string, error) {
+	return a.GetGroupRolesCtx(context.Background(), groupName)
+}
+
+// GetGroupRolesCtx is the context-aware variant of GetGroupRoles
+func (a *Authority) GetGroupRolesCtx(ctx context.Context, groupName string) ([]string, error) {
+	group, err := a.store.GetGroup(ctx, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	var roleIDs []uint
+	if roleIDs, err = a.store.GetGroupRoleIDs(ctx, group.ID); err != nil {
+		return nil, err
+	}
+
+	var roles []Role
+	if roles, err = a.store.GetRolesByID(ctx, roleIDs); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+
+	return names, nil
+}
+
+// GetGroupMembers returns the keys of the members of a group sorted
+func (a *Authority) GetGroupMembers(groupName string) ([]string, error) {
+	return a.GetGroupMembersCtx(context.Background(), groupName)
+}
+
+// GetGroupMembersCtx is the context-aware variant of GetGroupMembers
+func (a *Authority) GetGroupMembersCtx(ctx context.Context, groupName string) ([]string, error) {
+	group, err := a.store.GetGroup(ctx, groupName)
+	if err != nil {
+		return nil, err
+	}
+
+	return a.store.GetGroupMembers(ctx, group.ID)
+}
+
+// GetUserGroups returns the names of the groups of a user
+func (a *Authority) GetUserGroups(userID uint) ([]string, error) {
+	return a.GetUserGroupsCtx(context.Background(), userID)
+}
+
+// GetUserGroupsCtx is the context-aware variant of GetUserGroups
+func (a *Authority) GetUserGroupsCtx(ctx context.Context, userID uint) ([]string, error) {
+	return a.userGroups(ctx, userKey(userID))
+}
+
+// GetGroups returns the names of the groups of the user
+func (u User) GetGroups() ([]string, error) {
+	return u.GetGroupsCtx(context.Background())
+}
+
+// GetGroupsCtx is the context-aware variant of GetGroups
+func (u User) GetGroupsCtx(ctx context.Context) ([]string, error) {
+	return u.a.userGroups(ctx, u.key)
+}
+
+// AddToGroup adds the user to the members of a group
+func (u User) AddToGroup(groupName string) error {
+	return u.AddToGroupCtx(context.Background(), groupName)
+}
+
+// AddToGroupCtx is the context-aware variant of AddToGroup
+func (u User) AddToGroupCtx(ctx context.Context, groupName string) error {
+	return u.a.addGroupMember(ctx, u.key, groupName)
+}
+
+// RemoveFromGroup removes the user from the members of a group
+func (u User) RemoveFromGroup(groupName string) error {
+	return u.RemoveFromGroupCtx(context.Background(), groupName)
+}
+
+// RemoveFromGroupCtx is the context-aware variant of RemoveFromGroup
+func (u User) RemoveFromGroupCtx(ctx context.Context, groupName string) error {
+	return u.a.removeGroupMember(ctx, u.key, groupName)
+}
+
+func (a *Authority) addGroupMember(ctx context.Context, user string, groupName string) error {
+	group, err := a.store.GetGroup(ctx, groupName)
+	if err != nil {
+		return err
+	}
+
+	if err = a.store.AddGroupMember(ctx, &GroupMember{GroupID: group.ID, UserKey: user, CreatedAt: a.now()}); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{Action: AuditGroupMemberAdded, UserKey: user, Detail: groupName})
+}
+
+func (a *Authority) removeGroupMember(ctx context.Context, user string, groupName string) error {
+	group, err := a.store.GetGroup(ctx, groupName)
+	if err != nil {
+		return err
+	}
+
+	if err = a.store.RemoveGroupMember(ctx, group.ID, user); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{Action: AuditGroupMemberRemoved, UserKey: user, Detail: groupName})
+}
+
+// userGroups returns the names of the groups of a user
+func (a *Authority) userGroups(ctx context.Context, user string) ([]string, error) {
+	groups, err := a.store.GetUserGroups(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(groups))
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+
+	return names, nil
+}
+
+// groupRole finds a group and a global role
+func (a *Authority) groupRole(ctx context.Context, groupName string, roleName string) (*Group, *Role, error) {
+	group, err := a.store.GetGroup(ctx, groupName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var role *Role
+	if role, err = a.getRole(ctx, roleName); err != nil {
+		return nil, nil, err
+	}
+
+	return group, role, nil
+}
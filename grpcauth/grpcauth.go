@@ -0,0 +1,126 @@
+// Package grpcauth provides grpc server interceptors enforcing the permissions of authority
+package grpcauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"authority"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UserIDFunc returns the id of the user making the call,
+// it returns an error if the call is not authenticated
+type UserIDFunc func(ctx context.Context) (uint, error)
+
+// MetadataUserID returns a UserIDFunc reading the user id from the incoming metadata under the key,
+// e.g. set by a gateway that authenticated the call
+func MetadataUserID(key string) UserIDFunc {
+	return func(ctx context.Context) (uint, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(key)
+		if len(values) == 0 {
+			return 0, fmt.Errorf("no user id in the %q metadata", key)
+		}
+
+		userID, err := strconv.ParseUint(values[0], 10, 0)
+		if err != nil {
+			return 0, fmt.Errorf("invalid user id in the %q metadata: %w", key, err)
+		}
+
+		return uint(userID), nil
+	}
+}
+
+// MethodNamer derives the permission required by a method from its full name, e.g. "/blog.v1.Articles/Create",
+// returning an empty name lets the call through without a check
+type MethodNamer func(fullMethod string) string
+
+// DefaultMethodNamer requires a permission named after the full method name without the leading slash,
+// e.g. "blog.v1.Articles/Create"
+func DefaultMethodNamer(fullMethod string) string {
+	return strings.TrimPrefix(fullMethod, "/")
+}
+
+// Options has the options of the interceptors
+type Options struct {
+	// UserID extracts the user id from the call, it is required
+	UserID UserIDFunc
+	// Namer derives the required permission of the methods, DefaultMethodNamer is used when it's nil
+	Namer MethodNamer
+	// Methods overrides the required permission of some methods by their full name,
+	// an empty permission lets the calls through without a check, e.g. for health checks
+	Methods map[string]string
+}
+
+// UnaryServerInterceptor returns an interceptor checking the required permission before invoking the handler
+func UnaryServerInterceptor(a *authority.Authority, opts Options) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authorize(ctx, a, opts, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor checking the required permission before invoking the handler
+func StreamServerInterceptor(a *authority.Authority, opts Options) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(ss.Context(), a, opts, info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// authorize returns the status error of a call that must not go through: Unauthenticated when the user id can't be
+// extracted, PermissionDenied with the missing permission in its details and Internal when the check fails
+func authorize(ctx context.Context, a *authority.Authority, opts Options, fullMethod string) error {
+	permName, ok := opts.Methods[fullMethod]
+	if !ok {
+		namer := opts.Namer
+		if namer == nil {
+			namer = DefaultMethodNamer
+		}
+		permName = namer(fullMethod)
+	}
+
+	if permName == "" {
+		return nil
+	}
+
+	userID, err := opts.UserID(ctx)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	allowed, err := a.CheckPermissionCtx(ctx, userID, permName)
+	if err != nil && !errors.Is(err, authority.ErrPermissionNotFound) {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	if !allowed {
+		st := status.New(codes.PermissionDenied, "missing permission "+permName)
+		if detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+			Reason:   "PERMISSION_DENIED",
+			Domain:   "authority",
+			Metadata: map[string]string{"permission": permName, "method": fullMethod},
+		}); err == nil {
+			st = detailed
+		}
+
+		return st.Err()
+	}
+
+	return nil
+}
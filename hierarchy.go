@@ -0,0 +1,108 @@
+package authority
+
+import "context"
+
+// SetRoleParent makes a role inherit the permissions of a parent role, e.g. SetRoleParent("admin", "editor")
+// grants the admins everything the editors can do. a role has at most one parent, setting it again replaces it.
+// it returns ErrHierarchyCycle if the parent already includes or inherits from the role, and ErrOperationLocked
+// when another instance changes the composite roles or the parents for longer than Options.LockTimeout
+func (a *Authority) SetRoleParent(roleName string, parentName string) error {
+	return a.SetRoleParentCtx(context.Background(), roleName, parentName)
+}
+
+// SetRoleParentCtx is the context-aware variant of SetRoleParent
+func (a *Authority) SetRoleParentCtx(ctx context.Context, roleName string, parentName string) error {
+	// the cycle is checked and the parent set under the lock of the role graph, two concurrent changes can't
+	// close a cycle the other one doesn't see
+	return a.exclusive(ctx, roleGraphLock, a.lockTimeout, func(ctx context.Context) error {
+		return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+			return tx.setRoleParent(ctx, roleName, parentName)
+		})
+	})
+}
+
+func (a *Authority) setRoleParent(ctx context.Context, roleName string, parentName string) error {
+	var err error
+
+	var role, parent *Role
+	if role, err = a.getRole(ctx, roleName); err != nil {
+		return err
+	}
+
+	if parent, err = a.getRole(ctx, parentName); err != nil {
+		return err
+	}
+
+	// the role must not be one of the roles the parent includes or inherits from
+	var reachable []uint
+	if reachable, err = a.expandRoles(ctx, []uint{parent.ID}); err != nil {
+		return err
+	}
+	for _, id := range reachable {
+		if id == role.ID {
+			return ErrHierarchyCycle
+		}
+	}
+
+	if err = a.store.SetRoleParent(ctx, role.ID, parent.ID); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{Action: AuditRoleParentSet, Role: roleName, Detail: parentName})
+}
+
+// RemoveRoleParent removes the parent of a role, the role stops inheriting its permissions
+func (a *Authority) RemoveRoleParent(roleName string) error {
+	return a.RemoveRoleParentCtx(context.Background(), roleName)
+}
+
+// RemoveRoleParentCtx is the context-aware variant of RemoveRoleParent
+func (a *Authority) RemoveRoleParentCtx(ctx context.Context, roleName string) error {
+	role, err := a.getRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+
+	if err = a.store.RemoveRoleParent(ctx, role.ID); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{Action: AuditRoleParentRemoved, Role: roleName})
+}
+
+// GetRoleChildren returns the roles that directly inherit from the given role
+func (a *Authority) GetRoleChildren(roleName string) ([]string, error) {
+	return a.GetRoleChildrenCtx(context.Background(), roleName)
+}
+
+// GetRoleChildrenCtx is the context-aware variant of GetRoleChildren
+func (a *Authority) GetRoleChildrenCtx(ctx context.Context, roleName string) ([]string, error) {
+	var err error
+
+	var role *Role
+	if role, err = a.getRole(ctx, roleName); err != nil {
+		return nil, err
+	}
+
+	var children []RoleParent
+	if children, err = a.store.GetRoleChildren(ctx, role.ID); err != nil {
+		return nil, err
+	}
+
+	childIDs := make([]uint, 0, len(children))
+	for _, c := range children {
+		childIDs = append(childIDs, c.RoleID)
+	}
+
+	var roles []Role
+	if roles, err = a.store.GetRolesByID(ctx, childIDs); err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(roles))
+	for _, r := range roles {
+		result = append(result, r.Name)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,42 @@
+package authority_test
+
+import (
+	"errors"
+	"testing"
+
+	"authority"
+)
+
+func TestSetRoleParent(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	setupRole(t, a, "editor", "articles.write")
+	must(t, a.CreateRole("admin"))
+
+	must(t, a.SetRoleParent("admin", "editor"))
+	must(t, a.AssignRole(1, "admin"))
+
+	ok, err := a.CheckPermission(1, "articles.write")
+	must(t, err)
+	if !ok {
+		t.Fatal("the admin doesn't inherit the permission of the editor")
+	}
+
+	if err = a.SetRoleParent("editor", "admin"); !errors.Is(err, authority.ErrHierarchyCycle) {
+		t.Fatalf("SetRoleParent = %v, want ErrHierarchyCycle", err)
+	}
+}
+
+func TestSetRoleParentThroughComposite(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	for _, roleName := range []string{"support", "billing", "staff"} {
+		must(t, a.CreateRole(roleName))
+	}
+
+	// staff includes billing which inherits from support, support can't inherit from staff
+	must(t, a.AddCompositeRoles("staff", []string{"billing"}))
+	must(t, a.SetRoleParent("billing", "support"))
+
+	if err := a.SetRoleParent("support", "staff"); !errors.Is(err, authority.ErrHierarchyCycle) {
+		t.Fatalf("SetRoleParent = %v, want ErrHierarchyCycle", err)
+	}
+}
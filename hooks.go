@@ -0,0 +1,82 @@
+package authority
+
+import "context"
+
+// Hooks is notified of the changes, e.g. to invalidate a cache, feed an audit pipeline or send notifications.
+// the change is described by the same entry auditing records, with the request metadata of the context.
+// the changes made in a transaction are notified once it's committed, in order, and not at all when it's
+// rolled back. the changes of an instance returned by WithTx are notified when Committed is called.
+// embed NopHooks to implement only some of the methods
+type Hooks interface {
+	OnRoleCreated(ctx context.Context, change AuditEntry)
+	OnRoleDeleted(ctx context.Context, change AuditEntry)
+	OnPermissionCreated(ctx context.Context, change AuditEntry)
+	OnPermissionDeleted(ctx context.Context, change AuditEntry)
+	OnPermissionAssigned(ctx context.Context, change AuditEntry)
+	// OnPermissionRevoked is called when a permission is revoked from a role, the change has the user
+	// when the permission was revoked through the roles of a user
+	OnPermissionRevoked(ctx context.Context, change AuditEntry)
+	OnRoleAssigned(ctx context.Context, change AuditEntry)
+	OnRoleRevoked(ctx context.Context, change AuditEntry)
+	// OnChange is called for every change after the method of its action if any, e.g. for the changes
+	// of the composite roles, the parents, the conditions and the policies
+	OnChange(ctx context.Context, change AuditEntry)
+}
+
+// NopHooks implements Hooks doing nothing
+type NopHooks struct{}
+
+func (NopHooks) OnRoleCreated(context.Context, AuditEntry)        {}
+func (NopHooks) OnRoleDeleted(context.Context, AuditEntry)        {}
+func (NopHooks) OnPermissionCreated(context.Context, AuditEntry)  {}
+func (NopHooks) OnPermissionDeleted(context.Context, AuditEntry)  {}
+func (NopHooks) OnPermissionAssigned(context.Context, AuditEntry) {}
+func (NopHooks) OnPermissionRevoked(context.Context, AuditEntry)  {}
+func (NopHooks) OnRoleAssigned(context.Context, AuditEntry)       {}
+func (NopHooks) OnRoleRevoked(context.Context, AuditEntry)        {}
+func (NopHooks) OnChange(context.Context, AuditEntry)             {}
+
+// notify calls the hooks of a change, notifies the user of a role assigned or revoked and counts the change,
+// or keeps the change until the transaction of the instance is committed
+func (a *Authority) notify(ctx context.Context, entry AuditEntry) {
+	if a.hooks == nil && a.notifications == nil && a.metrics == nil && a.logger == nil {
+		return
+	}
+
+	if a.pending != nil {
+		*a.pending = append(*a.pending, entry)
+		return
+	}
+
+	a.count(MetricChanges, 1, Tag{"action", entry.Action})
+	a.logChange(ctx, entry)
+
+	if a.notifications != nil && (entry.Action == AuditRoleAssigned || entry.Action == AuditRoleRevoked) {
+		a.notifications.send(ctx, entry)
+	}
+
+	if a.hooks == nil {
+		return
+	}
+
+	switch entry.Action {
+	case AuditRoleCreated:
+		a.hooks.OnRoleCreated(ctx, entry)
+	case AuditRoleDeleted:
+		a.hooks.OnRoleDeleted(ctx, entry)
+	case AuditPermissionCreated:
+		a.hooks.OnPermissionCreated(ctx, entry)
+	case AuditPermissionDeleted:
+		a.hooks.OnPermissionDeleted(ctx, entry)
+	case AuditPermissionAssigned:
+		a.hooks.OnPermissionAssigned(ctx, entry)
+	case AuditPermissionRevoked:
+		a.hooks.OnPermissionRevoked(ctx, entry)
+	case AuditRoleAssigned:
+		a.hooks.OnRoleAssigned(ctx, entry)
+	case AuditRoleRevoked:
+		a.hooks.OnRoleRevoked(ctx, entry)
+	}
+
+	a.hooks.OnChange(ctx, entry)
+}
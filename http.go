@@ -0,0 +1,174 @@
+package authority
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// UserIDExtractor returns the id of the user making the request
+// it returns an error if the request is not authenticated
+type UserIDExtractor func(r *http.Request) (uint, error)
+
+// ErrorResponder writes the response of a request the middleware rejects, the status is 401 when the user id
+// can't be extracted, 403 when the permission is missing and 500 when the check fails
+type ErrorResponder func(w http.ResponseWriter, r *http.Request, status int, err error)
+
+// DefaultErrorResponder responds with the status text
+func DefaultErrorResponder(w http.ResponseWriter, _ *http.Request, status int, _ error) {
+	http.Error(w, http.StatusText(status), status)
+}
+
+// MiddlewareOptions has the options of the RequirePermission middleware
+type MiddlewareOptions struct {
+	// UserID extracts the user id from the request, it is required
+	UserID UserIDExtractor
+	// Responder writes the rejected responses, DefaultErrorResponder is used when it's nil
+	Responder ErrorResponder
+}
+
+// RouteFunc returns the route pattern matched by the request, e.g. "/articles/{id}"
+type RouteFunc func(r *http.Request) string
+
+// PermissionNamer derives the permission name required by a route from the request method and the route pattern
+// returning an empty name lets the request through without a check
+type PermissionNamer func(method, route string) string
+
+// ConventionOptions has the options of the convention-mode middleware
+type ConventionOptions struct {
+	// UserID extracts the user id from the request, it is required
+	UserID UserIDExtractor
+	// Route returns the route pattern of the request, the request path is used when it's nil
+	Route RouteFunc
+	// Namer derives the permission name, DefaultPermissionNamer is used when it's nil
+	Namer PermissionNamer
+	// Responder writes the rejected responses, DefaultErrorResponder is used when it's nil
+	Responder ErrorResponder
+}
+
+var methodActions = map[string]string{
+	http.MethodGet:    "read",
+	http.MethodHead:   "read",
+	http.MethodPost:   "create",
+	http.MethodPut:    "update",
+	http.MethodPatch:  "update",
+	http.MethodDelete: "delete",
+}
+
+// DefaultPermissionNamer joins the static segments of the route with the action of the method,
+// e.g. GET /articles/{id} becomes "articles.read" and DELETE /blog/:slug/comments becomes "blog.comments.delete".
+// route parameters written as {param}, :param or *param are skipped
+func DefaultPermissionNamer(method, route string) string {
+	action, ok := methodActions[method]
+	if !ok {
+		action = strings.ToLower(method)
+	}
+
+	var parts []string
+	for _, segment := range strings.Split(route, "/") {
+		if segment == "" || strings.HasPrefix(segment, "{") || strings.HasPrefix(segment, ":") ||
+			strings.HasPrefix(segment, "*") {
+			continue
+		}
+		parts = append(parts, segment)
+	}
+
+	return strings.Join(append(parts, action), ".")
+}
+
+// RequirePermission returns a net/http middleware that lets a request through only when the user making it
+// has the permission, using the instance returned by Resolve. use the method of the instance instead
+// when several instances are initiated
+func RequirePermission(permName string, userID UserIDExtractor) func(http.Handler) http.Handler {
+	return RequirePermissionWith(permName, MiddlewareOptions{UserID: userID})
+}
+
+// RequirePermissionWith is like RequirePermission with a configurable error responder
+func RequirePermissionWith(permName string, opts MiddlewareOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Resolve().RequirePermissionWith(permName, opts)(next).ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePermission returns a net/http middleware that lets a request through only when the user making it
+// has the permission. it responds with 401 when the user id can't be extracted and with 403 when the
+// permission is missing
+func (a *Authority) RequirePermission(permName string, userID UserIDExtractor) func(http.Handler) http.Handler {
+	return a.RequirePermissionWith(permName, MiddlewareOptions{UserID: userID})
+}
+
+// RequirePermissionWith is like RequirePermission with a configurable error responder
+func (a *Authority) RequirePermissionWith(permName string, opts MiddlewareOptions) func(http.Handler) http.Handler {
+	respond := opts.Responder
+	if respond == nil {
+		respond = DefaultErrorResponder
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if a.enforce(w, r, permName, opts.UserID, respond) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// enforce checks the permission against the user making the request,
+// it writes the rejected response and returns false when the request must not go through
+func (a *Authority) enforce(w http.ResponseWriter, r *http.Request, permName string, userID UserIDExtractor,
+	respond ErrorResponder) bool {
+	id, err := userID(r)
+	if err != nil {
+		respond(w, r, http.StatusUnauthorized, err)
+		return false
+	}
+
+	ok, err := a.CheckPermissionCtx(r.Context(), id, permName)
+	if err != nil && !errors.Is(err, ErrPermissionNotFound) {
+		respond(w, r, http.StatusInternalServerError, err)
+		return false
+	}
+
+	if !ok {
+		respond(w, r, http.StatusForbidden, err)
+		return false
+	}
+
+	return true
+}
+
+// ConventionMiddleware returns a net/http middleware that derives the required permission of every request
+// from its method and route pattern and checks it against the user making the request.
+// it responds with 401 when the user id can't be extracted and with 403 when the permission is missing
+func (a *Authority) ConventionMiddleware(opts ConventionOptions) func(http.Handler) http.Handler {
+	route := opts.Route
+	if route == nil {
+		route = func(r *http.Request) string { return r.URL.Path }
+	}
+
+	namer := opts.Namer
+	if namer == nil {
+		namer = DefaultPermissionNamer
+	}
+
+	respond := opts.Responder
+	if respond == nil {
+		respond = DefaultErrorResponder
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			permName := namer(r.Method, route(r))
+			if permName == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if a.enforce(w, r, permName, opts.UserID, respond) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package httpadmin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"authority"
+)
+
+// DefaultAdminPermission is the permission the users of the admin handler need when AdminOptions.Permission
+// is empty
+const DefaultAdminPermission = "authority.admin"
+
+// AdminOptions has the options of the admin handler
+type AdminOptions struct {
+	// UserID extracts the id of the authenticated user from the request, it is required
+	UserID authority.UserIDExtractor
+	// Permission is the permission the user needs, DefaultAdminPermission is used when it's empty
+	Permission string
+	// Responder writes the failed responses, authority.DefaultErrorResponder is used when it's nil
+	Responder authority.ErrorResponder
+	// IDs encodes the ids of the roles and the permissions, e.g. authority.PrefixedIDs, the ids of the database
+	// are listed when it's nil
+	IDs authority.IDCodec
+}
+
+// AdminRole is a role listed by the admin handler
+type AdminRole struct {
+	ID          ID                     `json:"id"`
+	Name        string                 `json:"name"`
+	Title       string                 `json:"title,omitempty"`
+	Tenant      string                 `json:"tenant,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	State       string                 `json:"state,omitempty"`
+}
+
+// AdminPermission is a permission listed by the admin handler
+type AdminPermission struct {
+	ID          ID                     `json:"id"`
+	Name        string                 `json:"name"`
+	Title       string                 `json:"title,omitempty"`
+	Tenant      string                 `json:"tenant,omitempty"`
+	Condition   string                 `json:"condition,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// List is a page of a list with the number of entries matching the filter
+type List[T any] struct {
+	Items []T `json:"items"`
+	Total int `json:"total"`
+}
+
+// Admin returns a handler of the endpoints listing the roles and the permissions for the admin dashboards,
+// the users need the permission of the options:
+//
+//	GET /roles        a page of the roles
+//	GET /permissions  a page of the permissions
+//
+// the lists take the offset, limit, prefix, sort ("name" or "id") and desc query parameters, see
+// authority.ListOptions. the responses have an ETag derived from the policy version when the store implements
+// authority.PolicyVersioner, from the body otherwise, and a request with a matching If-None-Match gets a 304.
+// mount it with http.StripPrefix to serve it under a prefix
+func Admin(a *authority.Authority, opts AdminOptions) http.Handler {
+	respond := opts.Responder
+	if respond == nil {
+		respond = authority.DefaultErrorResponder
+	}
+
+	permission := opts.Permission
+	if permission == "" {
+		permission = DefaultAdminPermission
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/roles", func(w http.ResponseWriter, r *http.Request) {
+		listOpts, ok := listOptions(w, r, respond)
+		if !ok {
+			return
+		}
+
+		serveVersioned(w, r, a, respond, func() (interface{}, error) {
+			roles, err := a.ListRolesCtx(r.Context(), listOpts)
+			if err != nil {
+				return nil, err
+			}
+
+			list := List[AdminRole]{Items: make([]AdminRole, 0, len(roles.Roles)), Total: roles.Total}
+			for _, role := range roles.Roles {
+				list.Items = append(list.Items, AdminRole{
+					ID: encodeID(opts.IDs, authority.EntityRole, role.ID), Name: role.Name, Title: role.Title, Tenant: role.Tenant,
+					Description: role.Description, Metadata: role.Metadata, State: role.State,
+				})
+			}
+
+			return list, nil
+		})
+	})
+
+	mux.HandleFunc("/permissions", func(w http.ResponseWriter, r *http.Request) {
+		listOpts, ok := listOptions(w, r, respond)
+		if !ok {
+			return
+		}
+
+		serveVersioned(w, r, a, respond, func() (interface{}, error) {
+			perms, err := a.ListPermissionsCtx(r.Context(), listOpts)
+			if err != nil {
+				return nil, err
+			}
+
+			list := List[AdminPermission]{Items: make([]AdminPermission, 0, len(perms.Permissions)),
+				Total: perms.Total}
+			for _, perm := range perms.Permissions {
+				list.Items = append(list.Items, AdminPermission{
+					ID: encodeID(opts.IDs, authority.EntityPermission, perm.ID), Name: perm.Name, Title: perm.Title,
+					Tenant: perm.Tenant, Condition: perm.Condition, Description: perm.Description,
+					Metadata: perm.Metadata,
+				})
+			}
+
+			return list, nil
+		})
+	})
+
+	return a.RequirePermissionWith(permission, authority.MiddlewareOptions{UserID: opts.UserID, Responder: respond})(mux)
+}
+
+// listOptions returns the list options of the query of a GET or HEAD request, it writes the rejected response
+// and returns false when the request must not go through
+func listOptions(w http.ResponseWriter, r *http.Request, respond authority.ErrorResponder) (authority.ListOptions,
+	bool) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		respond(w, r, http.StatusMethodNotAllowed, nil)
+		return authority.ListOptions{}, false
+	}
+
+	query := r.URL.Query()
+	opts := authority.ListOptions{Prefix: query.Get("prefix"), Sort: query.Get("sort")}
+
+	var err error
+	for name, dest := range map[string]*int{"offset": &opts.Offset, "limit": &opts.Limit} {
+		if value := query.Get(name); value != "" {
+			if *dest, err = strconv.Atoi(value); err != nil || *dest < 0 {
+				respond(w, r, http.StatusBadRequest, err)
+				return authority.ListOptions{}, false
+			}
+		}
+	}
+
+	if value := query.Get("desc"); value != "" {
+		if opts.Desc, err = strconv.ParseBool(value); err != nil {
+			respond(w, r, http.StatusBadRequest, err)
+			return authority.ListOptions{}, false
+		}
+	}
+
+	return opts, true
+}
+
+// serveVersioned writes the value returned by load with its ETag, or a 304 when the request has the ETag in
+// If-None-Match. the version is read before the value, a change made meanwhile changes the ETag of the next request
+func serveVersioned(w http.ResponseWriter, r *http.Request, a *authority.Authority,
+	respond authority.ErrorResponder, load func() (interface{}, error)) {
+	// the lists only change with the definitions, the query is part of the url the ETag belongs to
+	etag := ""
+	version, err := a.PolicyVersionCtx(r.Context())
+	switch {
+	case err == nil:
+		etag = `"v` + strconv.FormatUint(version, 10) + `"`
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Set("ETag", etag)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	case !errors.Is(err, authority.ErrNotSupported):
+		respond(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	v, err := load()
+	if errors.Is(err, authority.ErrUnknownSort) {
+		respond(w, r, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		respond(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	var body bytes.Buffer
+	if err = json.NewEncoder(&body).Encode(v); err != nil {
+		respond(w, r, http.StatusInternalServerError, err)
+		return
+	}
+
+	// without a version the ETag is derived from the body
+	if etag == "" {
+		sum := sha256.Sum256(body.Bytes())
+		etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Set("ETag", etag)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body.Bytes())
+}
+
+// etagMatches reports whether an If-None-Match header matches the ETag, weak ETags included
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package httpadmin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"authority"
+	"authority/authoritytest"
+	"authority/httpadmin"
+)
+
+func TestAdminOpaqueIDs(t *testing.T) {
+	a, err := authority.NewE(authority.Options{Store: authoritytest.NewMemoryStore()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range []error{
+		a.CreateRole("admin"),
+		a.CreatePermission(httpadmin.DefaultAdminPermission),
+		a.AssignPermissions("admin", []string{httpadmin.DefaultAdminPermission}),
+		a.AssignRole(1, "admin"),
+	} {
+		if step != nil {
+			t.Fatal(step)
+		}
+	}
+
+	role, err := a.GetRole("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := authority.PrefixedIDs([]byte("secret"))
+	userID := func(*http.Request) (uint, error) { return 1, nil }
+	for _, tc := range []struct {
+		opts authority.IDCodec
+		want string
+	}{
+		{nil, `"id":1,`},
+		{ids, `"id":"` + ids.EncodeID(authority.EntityRole, role.ID) + `"`},
+	} {
+		rec := httptest.NewRecorder()
+		httpadmin.Admin(a, httpadmin.AdminOptions{UserID: userID, IDs: tc.opts}).
+			ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/roles", nil))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), tc.want) {
+			t.Fatalf("GET /roles = %d %s, want the id %s", rec.Code, rec.Body, tc.want)
+		}
+
+		var list httpadmin.List[httpadmin.AdminRole]
+		if err = json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+			t.Fatal(err)
+		}
+		if len(list.Items) != 1 || tc.opts != nil && list.Items[0].ID.Number != 0 {
+			t.Fatalf("the roles are decoded as %+v", list.Items)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package httpadmin
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+
+	"authority"
+)
+
+// DocsOptions has the options of the permission documentation handler
+type DocsOptions struct {
+	// UserID extracts the id of the authenticated user from the request, the documentation is served to
+	// everyone when it's nil, e.g. on an internal listener
+	UserID authority.UserIDExtractor
+	// Permission is the permission the user needs when UserID is set, DefaultAdminPermission is used when it's
+	// empty
+	Permission string
+	// Title is the title of the html page, "Permissions" is used when it's empty
+	Title string
+	// Responder writes the failed responses, authority.DefaultErrorResponder is used when it's nil
+	Responder authority.ErrorResponder
+}
+
+// PermissionDoc is a permission documented by the docs handler
+type PermissionDoc struct {
+	Name             string   `json:"name"`
+	Title            string   `json:"title,omitempty"`
+	Tenant           string   `json:"tenant,omitempty"`
+	Description      string   `json:"description,omitempty"`
+	Condition        string   `json:"condition,omitempty"`
+	Action           string   `json:"action,omitempty"`
+	Resource         string   `json:"resource,omitempty"`
+	Class            string   `json:"class,omitempty"`
+	RequiresApproval bool     `json:"requires_approval,omitempty"`
+	Roles            []string `json:"roles"`
+}
+
+// Docs returns a handler serving the documentation of the permissions read from the store on every request, see
+// authority.PermissionCatalog, with their sensitivity class and the roles granting them. it's an html page, or
+// json when the request accepts application/json or has the format=json query parameter. mount it with
+// http.StripPrefix to serve it under a prefix
+func Docs(a *authority.Authority, opts DocsOptions) http.Handler {
+	respond := opts.Responder
+	if respond == nil {
+		respond = authority.DefaultErrorResponder
+	}
+
+	title := opts.Title
+	if title == "" {
+		title = "Permissions"
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			respond(w, r, http.StatusMethodNotAllowed, nil)
+			return
+		}
+
+		catalog, err := a.PermissionCatalogCtx(r.Context())
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		docs := make([]PermissionDoc, 0, len(catalog))
+		for _, doc := range catalog {
+			roles := doc.Roles
+			if roles == nil {
+				roles = []string{}
+			}
+
+			docs = append(docs, PermissionDoc{
+				Name: doc.Name, Title: doc.Title, Tenant: doc.Tenant, Description: doc.Description,
+				Condition: doc.Condition, Action: doc.Action, Resource: doc.Resource, Class: doc.Class,
+				RequiresApproval: doc.RequiresApproval, Roles: roles,
+			})
+		}
+
+		if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			writeJSON(w, docs)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = docsTemplate.Execute(w, struct {
+			Title       string
+			Permissions []PermissionDoc
+		}{title, docs})
+	})
+
+	if opts.UserID == nil {
+		return handler
+	}
+
+	permission := opts.Permission
+	if permission == "" {
+		permission = DefaultAdminPermission
+	}
+
+	return a.RequirePermissionWith(permission, authority.MiddlewareOptions{UserID: opts.UserID, Responder: respond})(
+		handler)
+}
+
+var docsTemplate = template.Must(template.New("docs").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #999; padding: 4px; text-align: left; vertical-align: top; }
+code { white-space: nowrap; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+<thead>
+<tr><th>Permission</th><th>Title</th><th>Description</th><th>Class</th><th>Granted by</th></tr>
+</thead>
+<tbody>
+{{- range .Permissions}}
+<tr id="{{.Name}}"><td><code>{{.Name}}</code>{{if .Tenant}} ({{.Tenant}}){{end}}</td><td>{{.Title}}</td><td>{{.Description}}{{if .Condition}}<br>Only when <code>{{.Condition}}</code>{{end}}</td><td>{{.Class}}{{if .RequiresApproval}}, requires approval{{end}}</td><td>{{range $i, $r := .Roles}}{{if $i}}, {{end}}{{$r}}{{end}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,127 @@
+package httpadmin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"authority"
+	"authority/authoritytest"
+	"authority/httpadmin"
+)
+
+// newDocsAuthority returns an instance documenting two permissions, articles.write granted by the editors
+func newDocsAuthority(t *testing.T) *authority.Authority {
+	t.Helper()
+
+	a, err := authority.NewE(authority.Options{Store: authoritytest.NewMemoryStore()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, step := range []error{
+		a.CreateRole("editor"),
+		a.CreatePermission("articles.write"),
+		a.CreatePermission(httpadmin.DefaultAdminPermission),
+		a.SetPermissionDescription("articles.write", "write <b>the</b> articles"),
+		a.AssignPermissions("editor", []string{"articles.write"}),
+	} {
+		if step != nil {
+			t.Fatal(step)
+		}
+	}
+
+	return a
+}
+
+func TestDocsJSON(t *testing.T) {
+	handler := httpadmin.Docs(newDocsAuthority(t), httpadmin.DocsOptions{})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?format=json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want 200", rec.Code)
+	}
+
+	var docs []httpadmin.PermissionDoc
+	if err := json.Unmarshal(rec.Body.Bytes(), &docs); err != nil {
+		t.Fatal(err)
+	}
+
+	var found bool
+	for _, doc := range docs {
+		if doc.Name == "articles.write" {
+			found = true
+			if doc.Description != "write <b>the</b> articles" || len(doc.Roles) != 1 || doc.Roles[0] != "editor" {
+				t.Fatalf("the documentation of articles.write is %+v", doc)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("articles.write is missing from %+v", docs)
+	}
+}
+
+func TestDocsHTML(t *testing.T) {
+	handler := httpadmin.Docs(newDocsAuthority(t), httpadmin.DocsOptions{Title: "Blog permissions"})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("status %d with %q, want an html page", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	// the descriptions are escaped
+	for _, want := range []string{
+		"<title>Blog permissions</title>", `id="articles.write"`, "write &lt;b&gt;the&lt;/b&gt;",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("the page misses %s", want)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status %d for a POST, want 405", rec.Code)
+	}
+}
+
+func TestDocsPermission(t *testing.T) {
+	a := newDocsAuthority(t)
+	if err := a.CreateRole("admin"); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AssignPermissions("admin", []string{httpadmin.DefaultAdminPermission}); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.AssignRole(1, "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := httpadmin.Docs(a, httpadmin.DocsOptions{UserID: func(r *http.Request) (uint, error) {
+		id, err := strconv.ParseUint(r.Header.Get("X-User"), 10, 64)
+		return uint(id), err
+	}})
+
+	for _, tc := range []struct {
+		user string
+		code int
+	}{
+		{"1", http.StatusOK},
+		{"2", http.StatusForbidden},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-User", tc.user)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.code {
+			t.Errorf("status %d for the user %s, want %d", rec.Code, tc.user, tc.code)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+// Package httpadmin provides http handlers exposing the roles and permissions of authority
+package httpadmin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"authority"
+)
+
+// SelfServiceOptions has the options of the self-service handler
+type SelfServiceOptions struct {
+	// UserID extracts the id of the authenticated user from the request, it is required
+	UserID authority.UserIDExtractor
+	// Responder writes the failed responses, authority.DefaultErrorResponder is used when it's nil
+	Responder authority.ErrorResponder
+	// IDs encodes the ids of the access requests, e.g. authority.PrefixedIDs, the ids of the database are
+	// returned when it's nil
+	IDs authority.IDCodec
+}
+
+// ID is the id of an entity in the responses, the number of the database or the opaque id of the IDCodec of
+// the options, it's encoded as a json number or string accordingly
+type ID struct {
+	Number uint
+	Opaque string
+}
+
+// encodeID returns the id of an entity encoded by the codec, if any
+func encodeID(ids authority.IDCodec, entity string, id uint) ID {
+	if ids == nil {
+		return ID{Number: id}
+	}
+
+	return ID{Opaque: ids.EncodeID(entity, id)}
+}
+
+func (id ID) MarshalJSON() ([]byte, error) {
+	if id.Opaque != "" {
+		return json.Marshal(id.Opaque)
+	}
+
+	return json.Marshal(id.Number)
+}
+
+func (id *ID) UnmarshalJSON(b []byte) error {
+	*id = ID{}
+	if len(b) > 0 && b[0] == '"' {
+		return json.Unmarshal(b, &id.Opaque)
+	}
+
+	return json.Unmarshal(b, &id.Number)
+}
+
+// Role is a role of the authenticated user
+type Role struct {
+	Name       string    `json:"name"`
+	Title      string    `json:"title,omitempty"`
+	Tenant     string    `json:"tenant,omitempty"`
+	Source     string    `json:"source"`
+	Via        string    `json:"via,omitempty"`
+	AssignedAt time.Time `json:"assigned_at"`
+	ExpiresAt  time.Time `json:"expires_at,omitempty"`
+	GrantedBy  string    `json:"granted_by,omitempty"`
+}
+
+// Permission is a permission of the authenticated user
+type Permission struct {
+	Name  string `json:"name"`
+	Title string `json:"title,omitempty"`
+	// Conditional is set when the permission is granted only when its condition holds
+	Conditional bool `json:"conditional,omitempty"`
+}
+
+// Request is an access request of the authenticated user
+type Request struct {
+	ID          ID        `json:"id"`
+	Role        string    `json:"role"`
+	Tenant      string    `json:"tenant,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	Status      string    `json:"status"`
+	RequestedAt time.Time `json:"requested_at"`
+	DecidedBy   string    `json:"decided_by,omitempty"`
+	DecidedAt   time.Time `json:"decided_at,omitempty"`
+}
+
+// SelfService returns a handler of the endpoints the users call to see their own access, e.g. to back a
+// "your access" page. the user is the one authenticated on the request:
+//
+//	GET /me/roles        the roles of the user with the details of their assignments
+//	GET /me/permissions  the permissions the roles grant
+//	GET /me/capabilities the capability manifest of the user, see authority.BuildCapabilityManifest
+//	GET /me/requests     the access requests of the user
+//	POST /me/requests    requests a role, the body is {"role": "editor", "reason": "..."}
+//
+// mount it with http.StripPrefix to serve it under a prefix
+func SelfService(a *authority.Authority, opts SelfServiceOptions) http.Handler {
+	respond := opts.Responder
+	if respond == nil {
+		respond = authority.DefaultErrorResponder
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/me/roles", func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(w, r, opts.UserID, respond)
+		if !ok {
+			return
+		}
+
+		details, err := a.GetUserRoleDetailsCtx(r.Context(), userID)
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		roles := make([]Role, 0, len(details))
+		for _, d := range details {
+			roles = append(roles, Role{
+				Name: d.Name, Title: d.Title, Tenant: d.Tenant, Source: d.Source, Via: d.Via,
+				AssignedAt: d.AssignedAt, ExpiresAt: d.ExpiresAt, GrantedBy: d.GrantedBy,
+			})
+		}
+
+		writeJSON(w, roles)
+	})
+
+	mux.HandleFunc("/me/permissions", func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(w, r, opts.UserID, respond)
+		if !ok {
+			return
+		}
+
+		details, err := a.GetUserPermissionDetailsCtx(r.Context(), userID)
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		perms := make([]Permission, 0, len(details))
+		for _, d := range details {
+			perms = append(perms, Permission{Name: d.Name, Title: d.Title, Conditional: d.Condition != ""})
+		}
+
+		writeJSON(w, perms)
+	})
+
+	mux.HandleFunc("/me/capabilities", func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(w, r, opts.UserID, respond)
+		if !ok {
+			return
+		}
+
+		manifest, err := a.BuildCapabilityManifestCtx(r.Context(), userID)
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		writeJSON(w, manifest)
+	})
+
+	mux.HandleFunc("/me/requests", func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := authenticate(w, r, opts.UserID, respond, http.MethodGet, http.MethodHead, http.MethodPost)
+		if !ok {
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			var body struct {
+				Role   string `json:"role"`
+				Reason string `json:"reason"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Role == "" {
+				respond(w, r, http.StatusBadRequest, err)
+				return
+			}
+
+			req, err := a.RequestRoleCtx(r.Context(), userID, body.Role, body.Reason)
+			if errors.Is(err, authority.ErrRoleNotFound) {
+				respond(w, r, http.StatusNotFound, err)
+				return
+			}
+			if err != nil {
+				respond(w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			w.WriteHeader(http.StatusCreated)
+			writeJSON(w, requestView(opts.IDs, *req))
+			return
+		}
+
+		reqs, err := a.GetUserRequestsCtx(r.Context(), userID)
+		if err != nil {
+			respond(w, r, http.StatusInternalServerError, err)
+			return
+		}
+
+		views := make([]Request, 0, len(reqs))
+		for _, req := range reqs {
+			views = append(views, requestView(opts.IDs, req))
+		}
+
+		writeJSON(w, views)
+	})
+
+	return mux
+}
+
+// requestView returns the json view of an access request
+func requestView(ids authority.IDCodec, req authority.AccessRequest) Request {
+	return Request{
+		ID: encodeID(ids, authority.EntityAccessRequest, req.ID), Role: req.Role, Tenant: req.Tenant,
+		Reason: req.Reason, Status: req.Status, RequestedAt: req.RequestedAt, DecidedBy: req.DecidedBy, DecidedAt: req.DecidedAt,
+	}
+}
+
+// authenticate returns the id of the user making a request with one of the methods, GET and HEAD when none is
+// given. it writes the rejected response and returns false when the request must not go through
+func authenticate(w http.ResponseWriter, r *http.Request, userID authority.UserIDExtractor,
+	respond authority.ErrorResponder, methods ...string) (uint, bool) {
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead}
+	}
+
+	allowed := false
+	for _, method := range methods {
+		allowed = allowed || r.Method == method
+	}
+
+	if !allowed {
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		respond(w, r, http.StatusMethodNotAllowed, nil)
+		return 0, false
+	}
+
+	id, err := userID(r)
+	if err != nil {
+		respond(w, r, http.StatusUnauthorized, err)
+		return 0, false
+	}
+
+	return id, true
+}
+
+// writeJSON writes the value as the json body of the response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,114 @@
+package authority
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// the statuses of the import batches
+const (
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+	// importRunning is the status of a batch whose transaction is not committed yet, it's never read
+	importRunning = "running"
+)
+
+// the kinds of the import batches
+const (
+	ImportKindAssignRoles = "assign_roles"
+	ImportKindSnapshot    = "snapshot"
+)
+
+var (
+	ErrImportNotFound       = errors.New("import not found")
+	ErrIdempotencyKeyReused = errors.New("idempotency key used by another kind of import")
+)
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a copy of the context carrying an idempotency key, the bulk imports made with it
+// (AssignRolesCtx, AssignRoleToUsersCtx and ImportCtx) are applied once: a retry with the same key after the
+// import completed does nothing, e.g. when a job is delivered twice by a queue. GetImportStatus reports the
+// outcome of the import of a key
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key carried by the context
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKey{}).(string)
+	return key, ok && key != ""
+}
+
+// withoutIdempotencyKey returns a copy of the context without idempotency key, for the imports nested in
+// another operation
+func withoutIdempotencyKey(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, "")
+}
+
+// GetImportStatus returns the import batch of an idempotency key,
+// it returns ErrImportNotFound if no import completed or failed with the key
+func (a *Authority) GetImportStatus(key string) (*ImportBatch, error) {
+	return a.GetImportStatusCtx(context.Background(), key)
+}
+
+// GetImportStatusCtx is the context-aware variant of GetImportStatus
+func (a *Authority) GetImportStatusCtx(ctx context.Context, key string) (*ImportBatch, error) {
+	return a.store.GetImportBatch(ctx, key)
+}
+
+// importTx runs an import atomically, once per idempotency key when the context carries one.
+// fn returns the number of imported items
+func (a *Authority) importTx(ctx context.Context, kind string,
+	fn func(ctx context.Context, tx *Authority) (int, error)) error {
+	key, ok := IdempotencyKeyFromContext(ctx)
+	if !ok {
+		return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+			_, err := fn(ctx, tx)
+			return err
+		})
+	}
+
+	batch := &ImportBatch{Key: key, Kind: kind, Status: importRunning, StartedAt: a.now()}
+	err := a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		// a concurrent import with the same key holds the row until it's committed or rolled back
+		created, err := tx.store.CreateImportBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		if !created {
+			var existing *ImportBatch
+			if existing, err = tx.store.GetImportBatch(ctx, key); err != nil {
+				return err
+			}
+			if existing.Kind != kind {
+				return fmt.Errorf("%w: %s", ErrIdempotencyKeyReused, key)
+			}
+			if existing.Status == ImportCompleted {
+				*batch = *existing
+				return nil
+			}
+		}
+
+		if batch.Items, err = fn(ctx, tx); err != nil {
+			return err
+		}
+
+		batch.Status, batch.Error, batch.FinishedAt = ImportCompleted, "", tx.now()
+
+		return tx.store.SaveImportBatch(ctx, batch)
+	})
+	// the failure is recorded outside of the rolled back transaction, a retry runs the import again. an import
+	// nested in the transaction of another operation or of WithTx leaves it to the caller, the failure would be
+	// recorded in the transaction the caller rolls back
+	if err != nil && a.pending == nil && !errors.Is(err, ErrIdempotencyKeyReused) {
+		batch.Status, batch.Items, batch.Error, batch.FinishedAt = ImportFailed, 0, err.Error(), a.now()
+		if saveErr := a.store.SaveImportBatch(ctx, batch); saveErr != nil {
+			return fmt.Errorf("%w (recording the failure: %v)", err, saveErr)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,59 @@
+package authority_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"authority"
+)
+
+func TestImportFailureIsRecorded(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	must(t, a.CreateRole("reader"))
+
+	ctx := authority.WithIdempotencyKey(context.Background(), "job-1")
+	if err := a.AssignRolesCtx(ctx, 1, []string{"reader", "missing"}); !errors.Is(err, authority.ErrRoleNotFound) {
+		t.Fatalf("AssignRolesCtx = %v, want ErrRoleNotFound", err)
+	}
+
+	batch, err := a.GetImportStatus("job-1")
+	must(t, err)
+	if batch.Status != authority.ImportFailed {
+		t.Fatalf("status %q, want %q", batch.Status, authority.ImportFailed)
+	}
+
+	// the retry runs the import again
+	must(t, a.CreateRole("missing"))
+	must(t, a.AssignRolesCtx(ctx, 1, []string{"reader", "missing"}))
+
+	if batch, err = a.GetImportStatus("job-1"); err != nil {
+		t.Fatal(err)
+	}
+	if batch.Status != authority.ImportCompleted || batch.Items != 2 {
+		t.Fatalf("batch %+v, want 2 items completed", batch)
+	}
+}
+
+func TestNestedImportFailureIsLeftToTheCaller(t *testing.T) {
+	db := newBunDB(t)
+	a, err := authority.NewE(authority.Options{Store: newBunStore(t, db, authority.BunStoreOptions{})})
+	must(t, err)
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	must(t, err)
+	defer func() { _ = tx.Rollback() }()
+
+	txa, err := a.WithTx(tx)
+	must(t, err)
+
+	keyed := authority.WithIdempotencyKey(ctx, "job-1")
+	if err = txa.AssignRolesCtx(keyed, 1, []string{"missing"}); !errors.Is(err, authority.ErrRoleNotFound) {
+		t.Fatalf("AssignRolesCtx = %v, want ErrRoleNotFound", err)
+	}
+
+	if _, err = txa.GetImportStatusCtx(ctx, "job-1"); !errors.Is(err, authority.ErrImportNotFound) {
+		t.Fatalf("GetImportStatusCtx = %v, want ErrImportNotFound", err)
+	}
+}
@@ -0,0 +1,86 @@
+package authority
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EntityAccessRequest is the kind of the access requests for the IDCodec, the other entities are the ones of
+// the constraint errors, e.g. EntityRole
+const EntityAccessRequest = "access_request"
+
+// ErrInvalidID is matched by the errors of the ids an IDCodec can't decode
+var ErrInvalidID = errors.New("invalid id")
+
+// IDCodec translates the ids of the entities to the opaque ids exposed by the admin APIs and back, so that the
+// clients never see the ids of the database
+type IDCodec interface {
+	// EncodeID returns the opaque id of an entity, e.g. of the EntityRole with the id 42
+	EncodeID(entity string, id uint) string
+	// DecodeID returns the id of the database of an opaque id of the entity, or an error wrapping ErrInvalidID
+	DecodeID(entity string, opaque string) (uint, error)
+}
+
+// feistelRounds are the rounds of the permutation of PrefixedIDs
+const feistelRounds = 4
+
+type prefixedIDs struct {
+	secret []byte
+}
+
+// PrefixedIDs returns an IDCodec of the ids prefixed with their entity, e.g. "role_1ekf8w2xz4d1", the ids are
+// scrambled with a keyed permutation so that they reveal neither the ids nor how many entities were created.
+// the same secret must be kept to decode the ids, and a client can't forge the id of an entity of another kind
+func PrefixedIDs(secret []byte) IDCodec {
+	return prefixedIDs{secret: secret}
+}
+
+func (c prefixedIDs) EncodeID(entity string, id uint) string {
+	left, right := uint32(uint64(id)>>32), uint32(id)
+	for round := 0; round < feistelRounds; round++ {
+		left, right = right, left^c.round(entity, round, right)
+	}
+
+	return entity + "_" + strconv.FormatUint(uint64(left)<<32|uint64(right), 36)
+}
+
+func (c prefixedIDs) DecodeID(entity string, opaque string) (uint, error) {
+	digits, ok := strings.CutPrefix(opaque, entity+"_")
+	if !ok {
+		return 0, fmt.Errorf("%w: %q is not an id of %s", ErrInvalidID, opaque, entity)
+	}
+
+	v, err := strconv.ParseUint(digits, 36, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidID, opaque)
+	}
+
+	left, right := uint32(v>>32), uint32(v)
+	for round := feistelRounds - 1; round >= 0; round-- {
+		left, right = right^c.round(entity, round, left), left
+	}
+
+	id := uint64(left)<<32 | uint64(right)
+	if id == 0 || uint64(uint(id)) != id {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidID, opaque)
+	}
+
+	return uint(id), nil
+}
+
+// round is the round function of the permutation, keyed by the secret and the entity
+func (c prefixedIDs) round(entity string, round int, half uint32) uint32 {
+	mac := hmac.New(sha256.New, c.secret)
+	var b [5]byte
+	b[0] = byte(round)
+	binary.BigEndian.PutUint32(b[1:], half)
+	mac.Write([]byte(entity))
+	mac.Write(b[:])
+
+	return binary.BigEndian.Uint32(mac.Sum(nil))
+}
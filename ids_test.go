@@ -0,0 +1,42 @@
+package authority_test
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"authority"
+)
+
+func TestPrefixedIDs(t *testing.T) {
+	ids := authority.PrefixedIDs([]byte("secret"))
+
+	for _, id := range []uint{1, 2, 42, 1 << 40} {
+		opaque := ids.EncodeID(authority.EntityRole, id)
+		if !strings.HasPrefix(opaque, "role_") || opaque == "role_"+strconv.FormatUint(uint64(id), 36) {
+			t.Errorf("EncodeID(%d) = %s", id, opaque)
+		}
+
+		decoded, err := ids.DecodeID(authority.EntityRole, opaque)
+		must(t, err)
+		if decoded != id {
+			t.Errorf("DecodeID(%s) = %d, want %d", opaque, decoded, id)
+		}
+
+		// the id of a role isn't the id of a permission
+		if _, err = ids.DecodeID(authority.EntityPermission, opaque); !errors.Is(err, authority.ErrInvalidID) {
+			t.Errorf("DecodeID of the role %s as a permission = %v, want ErrInvalidID", opaque, err)
+		}
+	}
+
+	if ids.EncodeID(authority.EntityRole, 1) == authority.PrefixedIDs([]byte("other")).EncodeID(authority.EntityRole, 1) {
+		t.Error("the secrets encode the ids the same way")
+	}
+
+	for _, opaque := range []string{"role_", "role_!", "1", "permission_1"} {
+		if _, err := ids.DecodeID(authority.EntityRole, opaque); !errors.Is(err, authority.ErrInvalidID) {
+			t.Errorf("DecodeID(%s) = %v, want ErrInvalidID", opaque, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package authority
+
+import (
+	"context"
+	"sync"
+)
+
+// Impersonation is an actor, e.g. a support agent, acting as another user for the rest of a request
+type Impersonation struct {
+	// Actor is the key of the user impersonating
+	Actor string
+	// User is the key of the impersonated user
+	User string
+}
+
+type impersonationKey struct{}
+
+type checkMemoKey struct{}
+
+// checkMemo remembers the decisions of the checks of a request, the decisions are tagged with the actor
+// impersonating a user when they were made, if any
+type checkMemo struct {
+	mu        sync.Mutex
+	decisions map[string]memoDecision
+}
+
+// memoDecision is the grant of a check before the condition of the permission is evaluated, the condition
+// depends on the attributes of the context which may differ between the checks of a request
+type memoDecision struct {
+	grant permissionGrant
+	actor string
+}
+
+// WithCheckMemo returns a copy of the context remembering the decisions of the role and permission checks done
+// with it, e.g. for the request of a GraphQL query checking the same permission for every resolved field.
+// the conditions of the permissions are evaluated on every check, the changes made with the context forget
+// the decisions and NoCache skips them
+func WithCheckMemo(ctx context.Context) context.Context {
+	return context.WithValue(ctx, checkMemoKey{}, &checkMemo{decisions: make(map[string]memoDecision)})
+}
+
+// Impersonate returns a copy of the context where the actor acts as the user, the actor is recorded on the
+// audit entries of the changes made with it. the checks are still made for the users they are given, call
+// EndImpersonation once the actor stops acting as the user
+func Impersonate(ctx context.Context, actor string, user string) context.Context {
+	md, _ := RequestMetadataFromContext(ctx)
+	md.Actor = actor
+	ctx = WithRequestMetadata(ctx, md)
+
+	return context.WithValue(ctx, impersonationKey{}, Impersonation{Actor: actor, User: user})
+}
+
+// ImpersonationFromContext returns the impersonation carried by the context
+func ImpersonationFromContext(ctx context.Context) (Impersonation, bool) {
+	imp, ok := ctx.Value(impersonationKey{}).(Impersonation)
+	return imp, ok && imp.Actor != ""
+}
+
+// EndImpersonation returns a copy of the context without the impersonation and forgets the decisions the
+// checks of the request memoized while the actor was impersonating, since the conditions of the permissions
+// were evaluated with the attributes of the impersonated identity. it's a no-op without an impersonation
+func EndImpersonation(ctx context.Context) context.Context {
+	imp, ok := ImpersonationFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	if memo, ok := ctx.Value(checkMemoKey{}).(*checkMemo); ok {
+		memo.forget(func(d memoDecision) bool { return d.actor == imp.Actor })
+	}
+
+	return context.WithValue(ctx, impersonationKey{}, Impersonation{})
+}
+
+// memoized returns the grant of the check of the key memoized by the request of the context
+func memoized(ctx context.Context, key string) (grant permissionGrant, ok bool) {
+	memo, found := ctx.Value(checkMemoKey{}).(*checkMemo)
+	if !found || cacheDisabled(ctx) {
+		return permissionGrant{}, false
+	}
+
+	memo.mu.Lock()
+	defer memo.mu.Unlock()
+
+	d, ok := memo.decisions[key]
+
+	return d.grant, ok
+}
+
+// memoize remembers the grant of the check of the key for the request of the context
+func memoize(ctx context.Context, key string, grant permissionGrant) {
+	memo, ok := ctx.Value(checkMemoKey{}).(*checkMemo)
+	if !ok {
+		return
+	}
+
+	imp, _ := ImpersonationFromContext(ctx)
+
+	memo.mu.Lock()
+	memo.decisions[key] = memoDecision{grant: grant, actor: imp.Actor}
+	memo.mu.Unlock()
+}
+
+// forgetChecks forgets the decisions memoized by the request of the context after a change
+func forgetChecks(ctx context.Context) {
+	if memo, ok := ctx.Value(checkMemoKey{}).(*checkMemo); ok {
+		memo.forget(func(memoDecision) bool { return true })
+	}
+}
+
+// forget drops the decisions matched by match
+func (m *checkMemo) forget(match func(d memoDecision) bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, d := range m.decisions {
+		if match(d) {
+			delete(m.decisions, key)
+		}
+	}
+}
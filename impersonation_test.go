@@ -0,0 +1,100 @@
+package authority_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"authority"
+)
+
+func TestCheckMemo(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	setupRole(t, a, "editor", "articles.write")
+
+	ctx := authority.WithCheckMemo(context.Background())
+	if granted, err := a.CheckPermissionCtx(ctx, 1, "articles.write"); err != nil || granted {
+		t.Fatalf("CheckPermissionCtx = %v, %v, want false", granted, err)
+	}
+
+	// the changes made with the context forget the decisions of the request
+	must(t, a.AssignRoleCtx(ctx, 1, "editor"))
+	if granted, err := a.CheckPermissionCtx(ctx, 1, "articles.write"); err != nil || !granted {
+		t.Fatalf("CheckPermissionCtx after the assignment = %v, %v, want true", granted, err)
+	}
+
+	// the changes made with another context don't reach the memo
+	must(t, a.RevokeRole(1, "editor"))
+	if granted, err := a.CheckPermissionCtx(ctx, 1, "articles.write"); err != nil || !granted {
+		t.Errorf("CheckPermissionCtx memoized = %v, %v, want true", granted, err)
+	}
+	if granted, err := a.CheckPermissionCtx(authority.NoCache(ctx), 1, "articles.write"); err != nil || granted {
+		t.Errorf("CheckPermissionCtx with NoCache = %v, %v, want false", granted, err)
+	}
+}
+
+func TestEndImpersonation(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	setupRole(t, a, "editor", "articles.write")
+	must(t, a.AssignRole(2, "editor"))
+
+	ctx := authority.WithCheckMemo(context.Background())
+	if granted, err := a.CheckRoleCtx(ctx, 1, "editor"); err != nil || granted {
+		t.Fatalf("CheckRoleCtx of the actor = %v, %v, want false", granted, err)
+	}
+
+	ctx = authority.Impersonate(ctx, "1", "2")
+	if imp, ok := authority.ImpersonationFromContext(ctx); !ok || imp.Actor != "1" || imp.User != "2" {
+		t.Fatalf("ImpersonationFromContext = %+v, %v", imp, ok)
+	}
+	if md, _ := authority.RequestMetadataFromContext(ctx); md.Actor != "1" {
+		t.Errorf("the actor of the request metadata = %q, want 1", md.Actor)
+	}
+	if granted, err := a.CheckPermissionCtx(ctx, 2, "articles.write"); err != nil || !granted {
+		t.Fatalf("CheckPermissionCtx while impersonating = %v, %v, want true", granted, err)
+	}
+
+	// the decisions made while impersonating are forgotten, the others are kept
+	must(t, a.RevokeRole(2, "editor"))
+	must(t, a.AssignRole(1, "editor"))
+	ctx = authority.EndImpersonation(ctx)
+	if _, ok := authority.ImpersonationFromContext(ctx); ok {
+		t.Error("ImpersonationFromContext after EndImpersonation: want none")
+	}
+	if granted, err := a.CheckPermissionCtx(ctx, 2, "articles.write"); err != nil || granted {
+		t.Errorf("CheckPermissionCtx after EndImpersonation = %v, %v, want false", granted, err)
+	}
+	if granted, err := a.CheckRoleCtx(ctx, 1, "editor"); err != nil || granted {
+		t.Errorf("CheckRoleCtx memoized before the impersonation = %v, %v, want false", granted, err)
+	}
+}
+
+func TestCheckMemoEvaluatesConditions(t *testing.T) {
+	var bursts []authority.DenialBurst
+	a := newAuthority(t, authority.Options{DenialBursts: authority.DenialBurstOptions{
+		Threshold: 3, Window: time.Minute,
+		OnBurst: func(_ context.Context, burst authority.DenialBurst) { bursts = append(bursts, burst) },
+	}})
+	setupRole(t, a, "approver", "payments.approve")
+	must(t, a.SetPermissionCondition("payments.approve", "attrs.amount < 1000"))
+	must(t, a.AssignRole(1, "approver"))
+
+	ctx := authority.WithCheckMemo(context.Background())
+	small := authority.WithAttributes(ctx, map[string]interface{}{"amount": 10})
+	if granted, err := a.CheckPermissionCtx(small, 1, "payments.approve"); err != nil || !granted {
+		t.Fatalf("CheckPermissionCtx of a small amount = %v, %v, want true", granted, err)
+	}
+
+	// the memo of the request doesn't grant the permission for other attributes
+	large := authority.WithAttributes(ctx, map[string]interface{}{"amount": 1000000})
+	for i := 0; i < 3; i++ {
+		if granted, err := a.CheckPermissionCtx(large, 1, "payments.approve"); err != nil || granted {
+			t.Fatalf("CheckPermissionCtx of a large amount = %v, %v, want false", granted, err)
+		}
+	}
+
+	// and the memoized denials are counted
+	if len(bursts) != 1 || bursts[0].Denials != 3 {
+		t.Errorf("the bursts of the memoized denials = %+v, want one of 3 denials", bursts)
+	}
+}
@@ -0,0 +1,145 @@
+package authority
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// the states of the roles, a draft role can be configured but grants nothing until it's activated and a
+// retired role stops granting while its assignments and permissions are kept for the history
+const (
+	RoleStateDraft   = "draft"
+	RoleStateActive  = "active"
+	RoleStateRetired = "retired"
+)
+
+// AuditRoleStateChanged is the action recorded on the audit entries of the transitions, the detail is
+// "from -> to"
+const AuditRoleStateChanged = "role.state_changed"
+
+var (
+	ErrInvalidRoleTransition = errors.New("invalid role state transition")
+	ErrRoleRetired           = errors.New("role is retired")
+)
+
+// roleTransitions are the states a role can move to from each state
+var roleTransitions = map[string][]string{
+	RoleStateDraft:   {RoleStateActive, RoleStateRetired},
+	RoleStateActive:  {RoleStateRetired},
+	RoleStateRetired: {RoleStateActive},
+}
+
+// CreateDraftRole stores a role in the draft state, its permissions and assignments can be configured but it
+// grants nothing until ActivateRole is called
+func (a *Authority) CreateDraftRole(roleName string) error {
+	return a.CreateDraftRoleCtx(context.Background(), roleName)
+}
+
+// CreateDraftRoleCtx is the context-aware variant of CreateDraftRole
+func (a *Authority) CreateDraftRoleCtx(ctx context.Context, roleName string) error {
+	return a.createRole(ctx, Role{Name: roleName, State: RoleStateDraft})
+}
+
+// ActivateRole moves a draft or a retired role to the active state, the role grants its permissions again
+func (a *Authority) ActivateRole(roleName string) error {
+	return a.ActivateRoleCtx(context.Background(), roleName)
+}
+
+// ActivateRoleCtx is the context-aware variant of ActivateRole
+func (a *Authority) ActivateRoleCtx(ctx context.Context, roleName string) error {
+	return a.setRoleState(ctx, roleName, RoleStateActive)
+}
+
+// RetireRole moves a role to the retired state, it stops granting its permissions and it can't be assigned
+// anymore but its assignments and permissions are kept
+func (a *Authority) RetireRole(roleName string) error {
+	return a.RetireRoleCtx(context.Background(), roleName)
+}
+
+// RetireRoleCtx is the context-aware variant of RetireRole
+func (a *Authority) RetireRoleCtx(ctx context.Context, roleName string) error {
+	return a.setRoleState(ctx, roleName, RoleStateRetired)
+}
+
+// GetRoleState returns the state of a role
+func (a *Authority) GetRoleState(roleName string) (string, error) {
+	return a.GetRoleStateCtx(context.Background(), roleName)
+}
+
+// GetRoleStateCtx is the context-aware variant of GetRoleState
+func (a *Authority) GetRoleStateCtx(ctx context.Context, roleName string) (string, error) {
+	role, err := a.getRole(ctx, roleName)
+	if err != nil {
+		return "", err
+	}
+
+	return role.stateOrDefault(), nil
+}
+
+// setRoleState moves a role to the state, it returns an error wrapping ErrInvalidRoleTransition when the state
+// can't be reached from the current one. moving a role to its current state is not an error
+func (a *Authority) setRoleState(ctx context.Context, roleName string, state string) error {
+	role, err := a.getRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+
+	from := role.stateOrDefault()
+	if from == state {
+		return nil
+	}
+
+	allowed := false
+	for _, to := range roleTransitions[from] {
+		allowed = allowed || to == state
+	}
+	if !allowed {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidRoleTransition, from, state)
+	}
+
+	if err = a.store.SetRoleState(ctx, role.ID, state); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{
+		Action: AuditRoleStateChanged, Role: roleName, Tenant: role.Tenant, Detail: from + " -> " + state,
+	})
+}
+
+// stateOrDefault returns the state of the role, the roles of the stores without states are active
+func (r Role) stateOrDefault() string {
+	if r.State == "" {
+		return RoleStateActive
+	}
+
+	return r.State
+}
+
+// activeRoleIDs returns the ids of the active roles among the given ones, in the same order
+func (a *Authority) activeRoleIDs(ctx context.Context, roleIDs []uint) ([]uint, error) {
+	roles, err := a.store.GetRolesByID(ctx, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make(map[uint]bool, len(roles))
+	for _, role := range roles {
+		active[role.ID] = role.stateOrDefault() == RoleStateActive
+	}
+
+	result := roleIDs[:0:0]
+	for _, id := range roleIDs {
+		if active[id] {
+			result = append(result, id)
+		}
+	}
+
+	return result, nil
+}
+
+// grantingRoles returns the active roles among the given ones followed by the active roles they include as
+// composite roles and inherit from, the roles reached only through an inactive role are left out
+func (a *Authority) grantingRoles(ctx context.Context, roleIDs []uint) ([]uint, error) {
+	return a.walkRoles(ctx, roleIDs, true, true)
+}
@@ -0,0 +1,138 @@
+package authority
+
+import (
+	"context"
+	"fmt"
+)
+
+// LoadUserRoles is a batch function returning the names of the roles assigned to each of the given users,
+// the results and errors are in the order of the user ids.
+// its signature matches the batch functions of dataloader packages (e.g. dataloadgen), so GraphQL resolvers
+// can load the roles of many users with a single query
+func (a *Authority) LoadUserRoles(ctx context.Context, userIDs []uint) ([][]string, []error) {
+	results := make([][]string, len(userIDs))
+	errs := make([]error, len(userIDs))
+
+	keys := make([]string, len(userIDs))
+	for i, userID := range userIDs {
+		keys[i] = userKey(userID)
+	}
+
+	userRoles, err := a.store.GetUsersRoles(ctx, keys)
+	if err != nil {
+		return results, fillErrors(errs, err)
+	}
+
+	// only the global assignments in effect
+	now := a.now()
+	n := 0
+	for _, r := range userRoles {
+		if r.Tenant == "" && r.activeAt(now) {
+			userRoles[n] = r
+			n++
+		}
+	}
+	userRoles = userRoles[:n]
+
+	roleIDs := make([]uint, 0, len(userRoles))
+	for _, r := range userRoles {
+		roleIDs = append(roleIDs, r.RoleID)
+	}
+
+	roles, err := a.store.GetRolesByID(ctx, roleIDs)
+	if err != nil {
+		return results, fillErrors(errs, err)
+	}
+
+	names := make(map[uint]string, len(roles))
+	for _, role := range roles {
+		names[role.ID] = role.Name
+	}
+
+	byUser := make(map[string][]string, len(userIDs))
+	for _, r := range userRoles {
+		if name, ok := names[r.RoleID]; ok {
+			byUser[r.UserKey] = append(byUser[r.UserKey], name)
+		}
+	}
+
+	for i, key := range keys {
+		results[i] = byUser[key]
+		if results[i] == nil {
+			results[i] = []string{}
+		}
+	}
+
+	return results, errs
+}
+
+// LoadRolePermissions is a batch function returning the names of the permissions assigned to each of the given
+// roles, the results and errors are in the order of the role names.
+// a role that doesn't exist gets ErrRoleNotFound as its error
+func (a *Authority) LoadRolePermissions(ctx context.Context, roleNames []string) ([][]string, []error) {
+	results := make([][]string, len(roleNames))
+	errs := make([]error, len(roleNames))
+
+	roles, err := a.store.GetRolesByName(ctx, roleNames)
+	if err != nil {
+		return results, fillErrors(errs, err)
+	}
+
+	roleIDs := make([]uint, 0, len(roles))
+	roleByName := make(map[string]uint, len(roles))
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.ID)
+		roleByName[role.Name] = role.ID
+	}
+
+	rolePerms, err := a.activeRolePermissions(ctx, roleIDs)
+	if err != nil {
+		return results, fillErrors(errs, err)
+	}
+
+	permIDs := make([]uint, 0, len(rolePerms))
+	for _, rp := range rolePerms {
+		permIDs = append(permIDs, rp.PermissionID)
+	}
+
+	perms, err := a.store.GetPermissionsByID(ctx, permIDs)
+	if err != nil {
+		return results, fillErrors(errs, err)
+	}
+
+	names := make(map[uint]string, len(perms))
+	for _, perm := range perms {
+		names[perm.ID] = perm.Name
+	}
+
+	byRole := make(map[uint][]string, len(roles))
+	for _, rp := range rolePerms {
+		if name, ok := names[rp.PermissionID]; ok {
+			byRole[rp.RoleID] = append(byRole[rp.RoleID], name)
+		}
+	}
+
+	for i, roleName := range roleNames {
+		roleID, ok := roleByName[roleName]
+		if !ok {
+			errs[i] = fmt.Errorf("%w: %s", ErrRoleNotFound, roleName)
+			continue
+		}
+
+		results[i] = byRole[roleID]
+		if results[i] == nil {
+			results[i] = []string{}
+		}
+	}
+
+	return results, errs
+}
+
+// fillErrors sets every error of a batch to the given error
+func fillErrors(errs []error, err error) []error {
+	for i := range errs {
+		errs[i] = err
+	}
+
+	return errs
+}
@@ -0,0 +1,61 @@
+package authority
+
+import (
+	"context"
+	"time"
+)
+
+// Locker is implemented by the stores that can hold a lock shared by all the instances using the same storage,
+// the heavyweight admin operations take it so only one instance runs them at a time
+type Locker interface {
+	// TryLock takes the lock with the given key without waiting, it reports false when another holder has it.
+	// the returned function releases the lock
+	TryLock(ctx context.Context, key string) (unlock func() error, locked bool, err error)
+}
+
+// roleGraphLock is the key of the lock taken to change the composite roles and the parents of the roles
+const roleGraphLock = "role_graph"
+
+// lockRetryInterval is how often a lock held by another instance is retried
+const lockRetryInterval = 100 * time.Millisecond
+
+// exclusive runs fn while holding the lock with the given key, waiting at most wait for another holder to release it.
+// it returns ErrOperationLocked when the lock isn't released in time, a negative wait waits until the context is
+// done. stores that don't implement Locker run fn without a lock
+func (a *Authority) exclusive(ctx context.Context, key string, wait time.Duration, fn func(ctx context.Context) error) error {
+	locker, ok := a.store.(Locker)
+	if !ok {
+		return fn(ctx)
+	}
+
+	var deadline time.Time
+	if wait >= 0 {
+		deadline = time.Now().Add(wait)
+	}
+
+	for {
+		unlock, locked, err := locker.TryLock(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if locked {
+			err = fn(ctx)
+			if uerr := unlock(); err == nil {
+				err = uerr
+			}
+
+			return err
+		}
+
+		if !deadline.IsZero() && time.Now().Add(lockRetryInterval).After(deadline) {
+			return ErrOperationLocked
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package authority
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// the fields of the log records of the checks, see Options.LogChecks
+const (
+	LogFieldKind        = "kind"
+	LogFieldDecision    = "decision"
+	LogFieldUser        = "user"
+	LogFieldTenant      = "tenant"
+	LogFieldPermission  = "perm"
+	LogFieldRole        = "role"
+	LogFieldRoleMatched = "role_matched"
+	LogFieldCacheHit    = "cache_hit"
+	LogFieldLatency     = "latency"
+	LogFieldError       = "error"
+)
+
+// the fields of the log records of the changes and of the errors, the changes have the user, the tenant, the
+// perm and the role of the checks as well
+const (
+	LogFieldAction    = "action"
+	LogFieldDetail    = "detail"
+	LogFieldActor     = "actor"
+	LogFieldRequestID = "request_id"
+	LogFieldSource    = "source"
+)
+
+// the messages of the log records
+const (
+	// LogMessageCheck is the message of the checks logged at the debug level, see Options.LogChecks
+	LogMessageCheck = "authority check"
+	// LogMessageDenied is the message of the denied checks logged at the info level
+	LogMessageDenied = "authority denied"
+	// LogMessageCheckFailed is the message of the failed checks logged at the error level
+	LogMessageCheckFailed = "authority check failed"
+	// LogMessageChange is the message of the changes logged at the info level once they are committed
+	LogMessageChange = "authority change"
+	// LogMessageError is the message of the errors the instance goes on after, logged at the warn level, e.g.
+	// of the cache backend
+	LogMessageError = "authority error"
+)
+
+// checkLog is a check to log
+type checkLog struct {
+	kind   string
+	user   string
+	tenant string
+	// name is the permission or the role checked
+	name    string
+	start   time.Time
+	granted bool
+	err     error
+	// loads counts the loads of the result, the result was cached when it's zero
+	loads int32
+}
+
+// loaded counts a load of the result, the stale results are reloaded in the background
+func (c *checkLog) loaded() {
+	atomic.AddInt32(&c.loads, 1)
+}
+
+// logging reports whether the checks are logged
+func (a *Authority) logging(ctx context.Context) bool {
+	return a.logChecks && a.logger != nil && a.logger.Enabled(ctx, slog.LevelDebug)
+}
+
+// logCheck logs a check at the debug level when the checks are logged, a denied check at the info level
+// otherwise and a failed check at the error level as well. the role granting a permission is looked up for the
+// record
+func (a *Authority) logCheck(ctx context.Context, c *checkLog) {
+	if a.logger == nil {
+		return
+	}
+
+	switch {
+	case a.logging(ctx):
+		a.logger.LogAttrs(ctx, slog.LevelDebug, LogMessageCheck, a.checkAttrs(ctx, c)...)
+	case !c.granted && c.err == nil && a.logger.Enabled(ctx, slog.LevelInfo):
+		a.logger.LogAttrs(ctx, slog.LevelInfo, LogMessageDenied, a.checkAttrs(ctx, c)...)
+	}
+
+	if c.err != nil && a.logger.Enabled(ctx, slog.LevelError) {
+		a.logger.LogAttrs(ctx, slog.LevelError, LogMessageCheckFailed, a.checkAttrs(ctx, c)...)
+	}
+}
+
+// checkAttrs returns the fields of the log record of a check
+func (a *Authority) checkAttrs(ctx context.Context, c *checkLog) []slog.Attr {
+	attrs := []slog.Attr{
+		slog.String(LogFieldKind, c.kind),
+		slog.String(LogFieldDecision, checkResult(c.granted, c.err)),
+		slog.String(LogFieldUser, c.user),
+		slog.String(LogFieldTenant, c.tenant),
+		slog.Duration(LogFieldLatency, time.Since(c.start)),
+	}
+
+	// the failed checks may stop before the cache
+	if c.err == nil {
+		attrs = append(attrs, slog.Bool(LogFieldCacheHit, atomic.LoadInt32(&c.loads) == 0))
+	}
+
+	switch {
+	case c.kind == CheckKindRole:
+		attrs = append(attrs, slog.String(LogFieldRole, c.name))
+		if c.granted {
+			attrs = append(attrs, slog.String(LogFieldRoleMatched, c.name))
+		}
+	case c.granted:
+		attrs = append(attrs, slog.String(LogFieldPermission, c.name))
+		if role, err := a.matchedRole(ctx, c.user, c.name, c.tenant); err == nil && role != "" {
+			attrs = append(attrs, slog.String(LogFieldRoleMatched, role))
+		}
+	default:
+		attrs = append(attrs, slog.String(LogFieldPermission, c.name))
+	}
+
+	if c.err != nil {
+		attrs = append(attrs, slog.String(LogFieldError, c.err.Error()))
+	}
+
+	return attrs
+}
+
+// logChange logs a committed change at the info level with the request metadata of the context
+func (a *Authority) logChange(ctx context.Context, entry AuditEntry) {
+	if a.logger == nil || !a.logger.Enabled(ctx, slog.LevelInfo) {
+		return
+	}
+
+	attrs := []slog.Attr{slog.String(LogFieldAction, entry.Action)}
+	for _, field := range []struct{ key, value string }{
+		{LogFieldUser, entry.UserKey}, {LogFieldRole, entry.Role}, {LogFieldPermission, entry.Permission},
+		{LogFieldTenant, entry.Tenant}, {LogFieldDetail, entry.Detail}, {LogFieldActor, entry.Actor},
+		{LogFieldRequestID, entry.RequestID},
+	} {
+		if field.value != "" {
+			attrs = append(attrs, slog.String(field.key, field.value))
+		}
+	}
+
+	a.logger.LogAttrs(ctx, slog.LevelInfo, LogMessageChange, attrs...)
+}
+
+// logError logs at the warn level an error the instance goes on after, the source tells what failed
+func (a *Authority) logError(ctx context.Context, source string, err error) {
+	if err == nil || a.logger == nil || !a.logger.Enabled(ctx, slog.LevelWarn) {
+		return
+	}
+
+	a.logger.LogAttrs(ctx, slog.LevelWarn, LogMessageError, slog.String(LogFieldSource, source),
+		slog.String(LogFieldError, err.Error()))
+}
+
+// matchedRole returns the name of a role of the user granting a permission, the super role when the user
+// holds it without a role granting the permission, or "" when the permission is granted on a resource
+func (a *Authority) matchedRole(ctx context.Context, user string, permName string, tenant string) (string, error) {
+	perm, err := a.getTenantPermission(ctx, permName, tenant)
+	if err != nil {
+		return "", err
+	}
+
+	var roleIDs []uint
+	if roleIDs, err = a.userRoleIDs(ctx, user, tenant); err != nil {
+		return "", err
+	}
+	if roleIDs, err = a.grantingRoles(ctx, roleIDs); err != nil {
+		return "", err
+	}
+
+	for _, id := range roleIDs {
+		var granted bool
+		if granted, err = a.store.RolesHavePermission(ctx, []uint{id}, perm.ID, a.now()); err != nil {
+			return "", err
+		}
+		if !granted {
+			continue
+		}
+
+		var roles []Role
+		if roles, err = a.store.GetRolesByID(ctx, []uint{id}); err != nil || len(roles) == 0 {
+			return "", err
+		}
+
+		return roles[0].Name, nil
+	}
+
+	super, err := a.holdsSuperRole(ctx, roleIDs, tenant)
+	if err != nil || !super {
+		return "", err
+	}
+
+	return a.superRole, nil
+}
@@ -0,0 +1,67 @@
+package authority_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"authority"
+)
+
+// records decodes the json log records of the buffer
+func records(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var result []map[string]interface{}
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		var record map[string]interface{}
+		must(t, dec.Decode(&record))
+		result = append(result, record)
+	}
+	buf.Reset()
+
+	return result
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	a := newAuthority(t, authority.Options{Logger: slog.New(slog.NewJSONHandler(&buf, nil))})
+	setupRole(t, a, "editor", "articles.write")
+	must(t, a.CreatePermission("articles.delete"))
+	records(t, &buf)
+
+	// the changes are logged with the request metadata
+	ctx := authority.WithRequestMetadata(context.Background(), authority.RequestMetadata{Actor: "admin"})
+	must(t, a.AssignRoleCtx(ctx, 1, "editor"))
+	logged := records(t, &buf)
+	if len(logged) != 1 || logged[0]["msg"] != authority.LogMessageChange || logged[0]["level"] != "INFO" ||
+		logged[0][authority.LogFieldAction] != authority.AuditRoleAssigned ||
+		logged[0][authority.LogFieldUser] != "1" || logged[0][authority.LogFieldActor] != "admin" {
+		t.Fatalf("the assignment is logged as %v", logged)
+	}
+
+	// the granted checks are logged with LogChecks only
+	for _, permName := range []string{"articles.write", "articles.delete"} {
+		_, err := a.CheckPermission(1, permName)
+		must(t, err)
+	}
+	if _, err := a.CheckPermission(1, "missing"); err == nil {
+		t.Fatal("the check of a missing permission succeeded")
+	}
+
+	logged = records(t, &buf)
+	if len(logged) != 2 {
+		t.Fatalf("the checks are logged as %v, want the denial and the failure", logged)
+	}
+	if logged[0]["msg"] != authority.LogMessageDenied || logged[0]["level"] != "INFO" ||
+		logged[0][authority.LogFieldPermission] != "articles.delete" {
+		t.Errorf("the denial is logged as %v", logged[0])
+	}
+	if logged[1]["msg"] != authority.LogMessageCheckFailed || logged[1]["level"] != "ERROR" ||
+		logged[1][authority.LogFieldError] == nil {
+		t.Errorf("the failure is logged as %v", logged[1])
+	}
+}
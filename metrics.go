@@ -0,0 +1,115 @@
+package authority
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// the names of the metrics sent to the MetricsSink
+const (
+	// MetricChecks counts the checks by kind and result, the checks of the permissions by permission as well
+	MetricChecks = "authority.checks"
+	// MetricCheckDuration times the checks by kind
+	MetricCheckDuration = "authority.check.duration"
+	// MetricCacheHits counts the lookups and check results found in the cache, the stale ones included
+	MetricCacheHits = "authority.cache.hits"
+	// MetricCacheMisses counts the lookups and check results loaded from the store
+	MetricCacheMisses = "authority.cache.misses"
+	// MetricChanges counts the committed changes by action
+	MetricChanges = "authority.changes"
+	// MetricStoreErrors counts the failed queries of the database by operation, see MetricsQueryHook
+	MetricStoreErrors = "authority.store.errors"
+)
+
+// the kinds of the checks, the values of the kind tag
+const (
+	CheckKindRole        = "role"
+	CheckKindPermission  = "permission"
+	CheckKindPermissions = "permissions"
+)
+
+// the results of the checks, the values of the result tag
+const (
+	CheckGranted = "granted"
+	CheckDenied  = "denied"
+	CheckError   = "error"
+)
+
+// Tag is a dimension of a metric
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// MetricsSink receives the counters and the timers of the instance, e.g. to push them to StatsD or to expose
+// them to Prometheus. the methods are called on the path of the checks and must not block
+type MetricsSink interface {
+	IncrCounter(name string, delta int64, tags ...Tag)
+	ObserveDuration(name string, d time.Duration, tags ...Tag)
+}
+
+func (a *Authority) count(name string, delta int64, tags ...Tag) {
+	if a.metrics != nil {
+		a.metrics.IncrCounter(name, delta, tags...)
+	}
+}
+
+// measureCheck records the duration and the result of a check started at the given time,
+// it's deferred with the results of the check. the permission is empty for the checks of the roles
+func (a *Authority) measureCheck(kind string, permName string, start time.Time, granted *bool, err *error) {
+	if a.metrics == nil {
+		return
+	}
+
+	a.metrics.ObserveDuration(MetricCheckDuration, time.Since(start), Tag{"kind", kind})
+	a.metrics.IncrCounter(MetricChecks, 1, checkTags(kind, permName, checkResult(*granted, *err))...)
+}
+
+func checkTags(kind string, permName string, result string) []Tag {
+	tags := []Tag{{"kind", kind}, {"result", result}}
+	if permName != "" {
+		tags = append(tags, Tag{"permission", permName})
+	}
+
+	return tags
+}
+
+func checkResult(granted bool, err error) string {
+	switch {
+	case err != nil:
+		return CheckError
+	case granted:
+		return CheckGranted
+	default:
+		return CheckDenied
+	}
+}
+
+// MetricsQueryHook is a bun query hook counting the failed queries in MetricStoreErrors, the queries finding
+// no rows aren't failures. it's added to the database of the instance, e.g.
+// db.AddQueryHook(authority.MetricsQueryHook{Sink: sink}), and counts the failures of the other queries made
+// with the database as well
+type MetricsQueryHook struct {
+	Sink MetricsSink
+}
+
+var _ bun.QueryHook = MetricsQueryHook{}
+
+// BeforeQuery implements bun.QueryHook
+func (h MetricsQueryHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+// AfterQuery implements bun.QueryHook
+func (h MetricsQueryHook) AfterQuery(_ context.Context, event *bun.QueryEvent) {
+	if event.Err == nil || errors.Is(event.Err, sql.ErrNoRows) {
+		return
+	}
+
+	h.Sink.IncrCounter(MetricStoreErrors, 1, Tag{"operation", strings.ToLower(event.Operation())})
+}
@@ -0,0 +1,136 @@
+package authority
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect"
+	"github.com/uptrace/bun/schema"
+)
+
+// GenerateMigrationSQL returns the statements Migrate runs on an empty database with the options, e.g. to write
+// them to the files of an external migration tool when the applications can't run DDL, see
+// Options.SkipMigration. the steps reading the database are left out: the changes of ColumnTypes and
+// CaseInsensitiveNames and the interleaved sequences of the replication. every statement is skipped when it's
+// already applied so they can run on a database migrated by an older version. only postgres is supported,
+// it returns ErrNotSupported for the other dialects
+func GenerateMigrationSQL(d schema.Dialect, opts BunStoreOptions) ([]string, error) {
+	if d.Name() != dialect.PG {
+		return nil, ErrNotSupported
+	}
+
+	rec := &sqlRecorder{}
+	db := bun.NewDB(sql.OpenDB(rec), d)
+	defer db.Close()
+
+	opts.ColumnTypes, opts.CaseInsensitiveNames, opts.Replication.Regions = ColumnTypes{}, false, 0
+	s := NewBunStoreWith(db, opts)
+
+	ctx := context.Background()
+	for _, m := range schemaMigrations {
+		if err := m.up(s, ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.createForeignKeyIndexes(ctx, false); err != nil {
+		return nil, err
+	}
+
+	if opts.CheckFunctions {
+		if err := s.migrateCheckFunctions(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.ReportingViews {
+		if err := s.migrateReportingViews(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Replication.Origin != "" {
+		if err := s.migrateReplication(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return rec.statements, nil
+}
+
+var errRecorderQuery = errors.New("authority: the migration sql can't be generated from a query")
+
+// sqlRecorder is a database/sql connector recording the statements instead of running them
+type sqlRecorder struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (r *sqlRecorder) Connect(context.Context) (driver.Conn, error) {
+	return recorderConn{r}, nil
+}
+
+func (r *sqlRecorder) Driver() driver.Driver {
+	return recorderDriver{r}
+}
+
+type recorderDriver struct {
+	r *sqlRecorder
+}
+
+func (d recorderDriver) Open(string) (driver.Conn, error) {
+	return recorderConn(d), nil
+}
+
+type recorderConn struct {
+	r *sqlRecorder
+}
+
+func (c recorderConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.r.mu.Lock()
+	defer c.r.mu.Unlock()
+
+	c.r.statements = append(c.r.statements, query)
+
+	return driver.RowsAffected(0), nil
+}
+
+// QueryContext records the statements returning rows, they return none
+func (c recorderConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if _, err := c.ExecContext(ctx, query, args); err != nil {
+		return nil, err
+	}
+
+	return recorderRows{}, nil
+}
+
+func (c recorderConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errRecorderQuery
+}
+
+func (c recorderConn) Close() error {
+	return nil
+}
+
+func (c recorderConn) Begin() (driver.Tx, error) {
+	return nil, errRecorderQuery
+}
+
+type recorderRows struct{}
+
+func (recorderRows) Columns() []string {
+	return nil
+}
+
+func (recorderRows) Close() error {
+	return nil
+}
+
+func (recorderRows) Next([]driver.Value) error {
+	return io.EOF
+}
@@ -0,0 +1,33 @@
+package authority_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/uptrace/bun/dialect/pgdialect"
+
+	"authority"
+)
+
+func TestGenerateMigrationSQL(t *testing.T) {
+	statements, err := authority.GenerateMigrationSQL(pgdialect.New(), authority.BunStoreOptions{
+		TablesPrefix: "app_", CheckFunctions: true,
+	})
+	must(t, err)
+
+	var roles, function bool
+	for _, statement := range statements {
+		roles = roles || strings.Contains(statement, "CREATE TABLE IF NOT EXISTS app_roles (")
+		function = function || strings.Contains(statement, `CREATE OR REPLACE FUNCTION "app_authority_check"`)
+	}
+	if !roles || !function {
+		t.Fatalf("the statements miss the roles table or the check function:\n%s", strings.Join(statements, ";\n"))
+	}
+
+	// nothing is recorded in a schema_migrations table
+	for _, statement := range statements {
+		if strings.Contains(statement, "schema_migrations") {
+			t.Fatalf("the statements write the migrations table: %s", statement)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package authority
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// the default messages of the notifications
+const (
+	DefaultGainedMessage = "You were granted the role {{.Role}}{{if .Tenant}} in {{.Tenant}}{{end}}" +
+		"{{if .Actor}} by {{.Actor}}{{end}}."
+	DefaultLostMessage = "The role {{.Role}}{{if .Tenant}} in {{.Tenant}}{{end}} was revoked" +
+		"{{if .Actor}} by {{.Actor}}{{end}}."
+)
+
+// Notification tells a user they gained or lost a role
+type Notification struct {
+	// UserKey identifies the user, it's the decimal id of the users identified by a number
+	UserKey string
+	Role    string
+	Tenant  string
+	// Gained is set when the role was assigned and unset when it was revoked
+	Gained bool
+	// Actor is the actor of the request metadata of the change
+	Actor string
+	At    time.Time
+	// Message is the rendered message
+	Message string
+}
+
+// Notifier delivers the notifications, e.g. by email or on Slack
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NotifierFunc is a function implementing Notifier
+type NotifierFunc func(ctx context.Context, n Notification) error
+
+// Notify implements Notifier
+func (f NotifierFunc) Notify(ctx context.Context, n Notification) error {
+	return f(ctx, n)
+}
+
+// RoleNotification configures the notifications of a role, the messages are text/template templates
+// executed with the Notification
+type RoleNotification struct {
+	// Notifier replaces the notifier of the options for the role
+	Notifier Notifier
+	// Gained is the message sent when the role is assigned, DefaultGainedMessage is used when it's empty
+	Gained string
+	// Lost is the message sent when the role is revoked, DefaultLostMessage is used when it's empty
+	Lost string
+	// Disabled sends no notification for the role
+	Disabled bool
+}
+
+// NotificationOptions has the options of the notifications sent to the users when they gain or lose a role,
+// they are sent in the background once the change is committed
+type NotificationOptions struct {
+	// Notifier delivers the notifications, none are sent when it's nil and no role has its own
+	Notifier Notifier
+	// Roles configures the notifications by role name, the "" entry applies to the roles without one
+	Roles map[string]RoleNotification
+	// OnError is called with the errors of the notifiers
+	OnError func(err error)
+}
+
+// notifications has the parsed templates of the notifications
+type notifications struct {
+	opts      NotificationOptions
+	templates map[string][2]*template.Template
+}
+
+func newNotifications(opts NotificationOptions) (*notifications, error) {
+	roles := opts.Roles
+	if _, ok := roles[""]; !ok {
+		roles = make(map[string]RoleNotification, len(opts.Roles)+1)
+		for name, rn := range opts.Roles {
+			roles[name] = rn
+		}
+		roles[""] = RoleNotification{}
+	}
+
+	n := &notifications{opts: opts, templates: make(map[string][2]*template.Template, len(roles))}
+	for name, rn := range roles {
+		gained, lost := rn.Gained, rn.Lost
+		if gained == "" {
+			gained = DefaultGainedMessage
+		}
+		if lost == "" {
+			lost = DefaultLostMessage
+		}
+
+		var tmpls [2]*template.Template
+		for i, text := range []string{gained, lost} {
+			tmpl, err := template.New(name).Parse(text)
+			if err != nil {
+				return nil, fmt.Errorf("notification of role %q: %w", name, err)
+			}
+			tmpls[i] = tmpl
+		}
+		n.templates[name] = tmpls
+	}
+	n.opts.Roles = roles
+
+	return n, nil
+}
+
+// send renders and sends the notification of a role assigned or revoked, in the background
+func (n *notifications) send(ctx context.Context, entry AuditEntry) {
+	name := entry.Role
+	rn, ok := n.opts.Roles[name]
+	if !ok {
+		name = ""
+		rn = n.opts.Roles[name]
+	}
+
+	notifier := rn.Notifier
+	if notifier == nil {
+		notifier = n.opts.Notifier
+	}
+	if rn.Disabled || notifier == nil {
+		return
+	}
+
+	notification := Notification{
+		UserKey: entry.UserKey, Role: entry.Role, Tenant: entry.Tenant, Gained: entry.Action == AuditRoleAssigned,
+		Actor: entry.Actor, At: entry.CreatedAt,
+	}
+
+	tmpl := n.templates[name][1]
+	if notification.Gained {
+		tmpl = n.templates[name][0]
+	}
+
+	var message strings.Builder
+	if err := tmpl.Execute(&message, notification); err != nil {
+		n.report(err)
+		return
+	}
+	notification.Message = message.String()
+
+	// the delivery outlives the request that made the change
+	ctx = detachedContext{ctx}
+	go func() {
+		if err := notifier.Notify(ctx, notification); err != nil {
+			n.report(err)
+		}
+	}()
+}
+
+func (n *notifications) report(err error) {
+	if n.opts.OnError != nil {
+		n.opts.OnError(fmt.Errorf("notification: %w", err))
+	}
+}
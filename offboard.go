@@ -0,0 +1,293 @@
+package authority
+
+import (
+	"context"
+	"fmt"
+)
+
+// the actions recorded on the audit entries of an offboarding, after the entries of the revoked roles, grants,
+// denials and group memberships, and of the erasure of a user, which doesn't name the user
+const (
+	AuditUserOffboarded = "user.offboarded"
+	AuditUserForgotten  = "user.forgotten"
+)
+
+// OffboardOptions configures OffboardUser
+type OffboardOptions struct {
+	// Tombstone keeps a record of the roles and of the grants of the user, see GetUserTombstones
+	Tombstone bool
+	// Reason is recorded on the tombstone, e.g. "left the company"
+	Reason string
+}
+
+// TombstoneGrant is a permission on a resource a user was granted
+type TombstoneGrant struct {
+	Permission   string `json:"permission"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	Tenant       string `json:"tenant,omitempty"`
+}
+
+// OffboardUser revokes all the roles of a user in every tenant, the permissions granted to it on resources and
+// the denials of permissions to it, and removes it from its groups, in a single transaction. the revocations are
+// audited and fire the hooks like RevokeRole does. with opts.Tombstone the former access of the user is kept for
+// compliance
+func (a *Authority) OffboardUser(userID uint, opts OffboardOptions) error {
+	return a.OffboardUserCtx(context.Background(), userID, opts)
+}
+
+// OffboardUserCtx is the context-aware variant of OffboardUser
+func (a *Authority) OffboardUserCtx(ctx context.Context, userID uint, opts OffboardOptions) error {
+	return a.offboardUser(ctx, userKey(userID), opts)
+}
+
+// RevokeAllRoles revokes all the roles of a user in every tenant in a single transaction, the revocations are
+// audited and fire the hooks like RevokeRole does
+func (a *Authority) RevokeAllRoles(userID uint) error {
+	return a.RevokeAllRolesCtx(context.Background(), userID)
+}
+
+// RevokeAllRolesCtx is the context-aware variant of RevokeAllRoles
+func (a *Authority) RevokeAllRolesCtx(ctx context.Context, userID uint) error {
+	return a.revokeAllRoles(ctx, userKey(userID))
+}
+
+// ForgetUser deletes every row about a user in a single transaction, its assignments, claims, grants, denials,
+// group memberships, access requests and tombstones, and clears the user from the audit entries, e.g. for the
+// erasure of the personal data of a deleted user. the hooks are notified with an entry that doesn't name the user
+func (a *Authority) ForgetUser(userID uint) error {
+	return a.ForgetUserCtx(context.Background(), userID)
+}
+
+// ForgetUserCtx is the context-aware variant of ForgetUser
+func (a *Authority) ForgetUserCtx(ctx context.Context, userID uint) error {
+	return a.forgetUser(ctx, userKey(userID))
+}
+
+// GetUserTombstones returns the records of the access of a user kept by its offboardings, the oldest first
+func (a *Authority) GetUserTombstones(userID uint) ([]UserTombstone, error) {
+	return a.GetUserTombstonesCtx(context.Background(), userID)
+}
+
+// GetUserTombstonesCtx is the context-aware variant of GetUserTombstones
+func (a *Authority) GetUserTombstonesCtx(ctx context.Context, userID uint) ([]UserTombstone, error) {
+	return a.store.GetUserTombstones(ctx, userKey(userID))
+}
+
+// RevokeAllRoles revokes all the roles of the user in every tenant
+func (u User) RevokeAllRoles() error {
+	return u.RevokeAllRolesCtx(context.Background())
+}
+
+// RevokeAllRolesCtx is the context-aware variant of RevokeAllRoles
+func (u User) RevokeAllRolesCtx(ctx context.Context) error {
+	return u.a.revokeAllRoles(ctx, u.key)
+}
+
+// Forget deletes every row about the user, see ForgetUser
+func (u User) Forget() error {
+	return u.ForgetCtx(context.Background())
+}
+
+// ForgetCtx is the context-aware variant of Forget
+func (u User) ForgetCtx(ctx context.Context) error {
+	return u.a.forgetUser(ctx, u.key)
+}
+
+// Offboard revokes all the roles, the grants and the denials of the user and removes it from its groups, see
+// OffboardUser
+func (u User) Offboard(opts OffboardOptions) error {
+	return u.OffboardCtx(context.Background(), opts)
+}
+
+// OffboardCtx is the context-aware variant of Offboard
+func (u User) OffboardCtx(ctx context.Context, opts OffboardOptions) error {
+	return u.a.offboardUser(ctx, u.key, opts)
+}
+
+func (a *Authority) offboardUser(ctx context.Context, user string, opts OffboardOptions) error {
+	return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		userRoles, err := tx.store.GetUserRoles(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		var grants []ResourcePermission
+		if grants, err = tx.store.GetUserResourcePermissions(ctx, user); err != nil {
+			return err
+		}
+
+		var denials []PermissionDenial
+		if denials, err = tx.store.GetUserPermissionDenials(ctx, user); err != nil {
+			return err
+		}
+
+		var groups []Group
+		if groups, err = tx.store.GetUserGroups(ctx, user); err != nil {
+			return err
+		}
+
+		roleIDs := make([]uint, 0, len(userRoles))
+		for _, ur := range userRoles {
+			roleIDs = append(roleIDs, ur.RoleID)
+		}
+
+		var roles []Role
+		if roles, err = tx.store.GetRolesByID(ctx, roleIDs); err != nil {
+			return err
+		}
+
+		roleNames := make(map[uint]string, len(roles))
+		for _, role := range roles {
+			roleNames[role.ID] = role.Name
+		}
+
+		permIDs := make([]uint, 0, len(grants)+len(denials))
+		for _, grant := range grants {
+			permIDs = append(permIDs, grant.PermissionID)
+		}
+		for _, denial := range denials {
+			permIDs = append(permIDs, denial.PermissionID)
+		}
+
+		var perms []Permission
+		if perms, err = tx.store.GetPermissionsByID(ctx, permIDs); err != nil {
+			return err
+		}
+
+		permNames := make(map[uint]string, len(perms))
+		for _, perm := range perms {
+			permNames[perm.ID] = perm.Name
+		}
+
+		// the tombstone is stored before the access is revoked, in the same transaction
+		if opts.Tombstone {
+			md, _ := RequestMetadataFromContext(ctx)
+			tombstone := UserTombstone{UserKey: user, Reason: opts.Reason, OffboardedBy: md.Actor,
+				OffboardedAt: tx.now()}
+			for _, ur := range userRoles {
+				tombstone.Roles = append(tombstone.Roles, SnapshotUserRole{
+					User: ur.UserKey, Role: roleNames[ur.RoleID], Tenant: ur.Tenant,
+					StartsAt: snapshotTime(ur.StartsAt), ExpiresAt: snapshotTime(ur.ExpiresAt),
+					AssignedAt: snapshotTime(ur.CreatedAt), GrantedBy: ur.GrantedBy,
+				})
+			}
+			for _, grant := range grants {
+				tombstone.Grants = append(tombstone.Grants, TombstoneGrant{
+					Permission: permNames[grant.PermissionID], ResourceType: grant.ResourceType,
+					ResourceID: grant.ResourceID, Tenant: grant.Tenant,
+				})
+			}
+
+			if err = tx.store.SaveUserTombstone(ctx, &tombstone); err != nil {
+				return err
+			}
+		}
+
+		if err = tx.revokeUserRoles(ctx, user, userRoles, roleNames); err != nil {
+			return err
+		}
+
+		for i := range grants {
+			if err = tx.store.RevokeResourcePermission(ctx, &grants[i]); err != nil {
+				return err
+			}
+
+			if err = tx.changed(ctx, AuditEntry{
+				Action: AuditResourcePermissionRevoked, UserKey: user, Permission: permNames[grants[i].PermissionID],
+				Tenant: grants[i].Tenant, Detail: grants[i].ResourceType + ":" + grants[i].ResourceID,
+			}); err != nil {
+				return err
+			}
+		}
+
+		for i := range denials {
+			if err = tx.store.RevokePermissionDenial(ctx, &denials[i]); err != nil {
+				return err
+			}
+
+			if err = tx.changed(ctx, AuditEntry{
+				Action: AuditPermissionDenialRevoked, UserKey: user, Permission: permNames[denials[i].PermissionID],
+				Tenant: denials[i].Tenant,
+			}); err != nil {
+				return err
+			}
+		}
+
+		// the roles of the groups would still be granted to a member
+		for _, group := range groups {
+			if err = tx.store.RemoveGroupMember(ctx, group.ID, user); err != nil {
+				return err
+			}
+
+			if err = tx.changed(ctx, AuditEntry{
+				Action: AuditGroupMemberRemoved, UserKey: user, Detail: group.Name,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return tx.changed(ctx, AuditEntry{
+			Action: AuditUserOffboarded, UserKey: user,
+			Detail: fmt.Sprintf("%d roles, %d grants and %d denials revoked, removed from %d groups", len(userRoles),
+				len(grants), len(denials), len(groups)),
+		})
+	})
+}
+
+func (a *Authority) revokeAllRoles(ctx context.Context, user string) error {
+	return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		userRoles, err := tx.store.GetUserRoles(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		roleIDs := make([]uint, 0, len(userRoles))
+		for _, ur := range userRoles {
+			roleIDs = append(roleIDs, ur.RoleID)
+		}
+
+		var roles []Role
+		if roles, err = tx.store.GetRolesByID(ctx, roleIDs); err != nil {
+			return err
+		}
+
+		roleNames := make(map[uint]string, len(roles))
+		for _, role := range roles {
+			roleNames[role.ID] = role.Name
+		}
+
+		return tx.revokeUserRoles(ctx, user, userRoles, roleNames)
+	})
+}
+
+// revokeUserRoles revokes the assignments of a user and records their changes
+func (a *Authority) revokeUserRoles(ctx context.Context, user string, userRoles []UserRole,
+	roleNames map[uint]string) error {
+	p := a.startProgress(ctx, OperationRevokeRoles, len(userRoles))
+	for _, ur := range userRoles {
+		if err := a.store.RevokeRole(ctx, user, ur.RoleID, ur.Tenant); err != nil {
+			return p.fail(err)
+		}
+
+		if err := a.changed(ctx, AuditEntry{
+			Action: AuditRoleRevoked, UserKey: user, Role: roleNames[ur.RoleID], Tenant: ur.Tenant,
+		}); err != nil {
+			return p.fail(err)
+		}
+
+		p.add(1)
+	}
+
+	return nil
+}
+
+func (a *Authority) forgetUser(ctx context.Context, user string) error {
+	return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		if err := tx.store.ForgetUser(ctx, user); err != nil {
+			return err
+		}
+
+		return tx.changed(ctx, AuditEntry{Action: AuditUserForgotten})
+	})
+}
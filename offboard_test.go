@@ -0,0 +1,73 @@
+package authority_test
+
+import (
+	"testing"
+
+	"authority"
+)
+
+func TestOffboardUser(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	setupRole(t, a, "editor", "articles.read", "articles.write")
+	setupRole(t, a, "reviewer", "articles.review")
+	must(t, a.CreatePermission("documents.edit"))
+	must(t, a.CreateGroup("team"))
+	must(t, a.AssignRoleToGroup("team", "reviewer"))
+
+	for _, userID := range []uint{1, 2} {
+		must(t, a.AssignRole(userID, "editor"))
+		must(t, a.AddUserToGroup(userID, "team"))
+		must(t, a.GrantPermissionOnResource(userID, "documents.edit", "document", 42))
+		must(t, a.DenyPermission(userID, "articles.write"))
+	}
+
+	must(t, a.OffboardUser(1, authority.OffboardOptions{Tombstone: true, Reason: "left the company"}))
+
+	roles, err := a.GetUserRoles(1)
+	must(t, err)
+	if len(roles) != 0 {
+		t.Errorf("the roles after offboarding = %v", roles)
+	}
+
+	groups, err := a.GetUserGroups(1)
+	must(t, err)
+	if len(groups) != 0 {
+		t.Errorf("the groups after offboarding = %v", groups)
+	}
+
+	// the role of the group isn't granted anymore
+	granted, err := a.CheckPermission(1, "articles.review")
+	must(t, err)
+	if granted {
+		t.Error("the permission of the group is granted after offboarding")
+	}
+
+	granted, err = a.CheckPermissionOnResource(1, "documents.edit", "document", 42)
+	must(t, err)
+	if granted {
+		t.Error("the permission on the resource is granted after offboarding")
+	}
+
+	denials, err := a.GetPermissionDenials("articles.write")
+	must(t, err)
+	if len(denials) != 1 || denials[0].UserKey != "2" {
+		t.Errorf("the denials after offboarding = %+v, want the denial to the user 2", denials)
+	}
+
+	tombstones, err := a.GetUserTombstones(1)
+	must(t, err)
+	if len(tombstones) != 1 || len(tombstones[0].Roles) != 1 || tombstones[0].Roles[0].Role != "editor" ||
+		len(tombstones[0].Grants) != 1 || tombstones[0].Reason != "left the company" {
+		t.Errorf("the tombstones = %+v", tombstones)
+	}
+
+	// the other users keep their access
+	for _, permName := range []string{"articles.read", "articles.review"} {
+		if granted, err = a.CheckPermission(2, permName); err != nil || !granted {
+			t.Errorf("CheckPermission of %s for the user 2 = %v, %v", permName, granted, err)
+		}
+	}
+	if granted, err = a.CheckPermissionOnResource(2, "documents.edit", "document", 42); err != nil || !granted {
+		t.Errorf("CheckPermissionOnResource for the user 2 = %v, %v", granted, err)
+	}
+}
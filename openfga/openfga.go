@@ -0,0 +1,246 @@
+// Package openfga converts the roles, permissions and assignments of authority from and to the authorization model
+// and the relation tuples of OpenFGA, in the JSON format of its api, e.g. to migrate or to compare the decisions
+// of both side by side
+package openfga
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"authority"
+)
+
+// SchemaVersion is the schema version of the authorization model
+const SchemaVersion = "1.1"
+
+// the relations of the model that are not relations of the authority tuples
+const (
+	// RelationGrantee holds the users granted the permissions of a role, its members and the members of the
+	// roles inheriting from it
+	RelationGrantee = "grantee"
+)
+
+// AuthorizationModel is an OpenFGA authorization model
+type AuthorizationModel struct {
+	SchemaVersion   string           `json:"schema_version"`
+	TypeDefinitions []TypeDefinition `json:"type_definitions"`
+}
+
+// TypeDefinition defines the relations of a type
+type TypeDefinition struct {
+	Type      string             `json:"type"`
+	Relations map[string]Userset `json:"relations,omitempty"`
+	Metadata  *Metadata          `json:"metadata,omitempty"`
+}
+
+// Userset defines the users of a relation, only one of the fields is set
+type Userset struct {
+	This            *struct{}       `json:"this,omitempty"`
+	ComputedUserset *ObjectRelation `json:"computedUserset,omitempty"`
+	Union           *Usersets       `json:"union,omitempty"`
+}
+
+// Usersets are the children of a union
+type Usersets struct {
+	Child []Userset `json:"child"`
+}
+
+// ObjectRelation references a relation
+type ObjectRelation struct {
+	Object   string `json:"object,omitempty"`
+	Relation string `json:"relation,omitempty"`
+}
+
+// Metadata has the types allowed in the relations of a type
+type Metadata struct {
+	Relations map[string]RelationMetadata `json:"relations,omitempty"`
+}
+
+// RelationMetadata has the types allowed in a relation
+type RelationMetadata struct {
+	DirectlyRelatedUserTypes []RelationReference `json:"directly_related_user_types"`
+}
+
+// RelationReference is a type allowed in a relation, a user set when Relation is set
+type RelationReference struct {
+	Type     string `json:"type"`
+	Relation string `json:"relation,omitempty"`
+}
+
+// TupleKey is an OpenFGA relation tuple
+type TupleKey struct {
+	User     string `json:"user"`
+	Relation string `json:"relation"`
+	Object   string `json:"object"`
+}
+
+// Document is an authorization model with its tuples
+type Document struct {
+	AuthorizationModel AuthorizationModel `json:"authorization_model"`
+	Tuples             []TupleKey         `json:"tuples"`
+}
+
+// Model returns the authorization model of the authority tuples:
+//
+//	type user
+//	type role
+//	  relations
+//	    define member: [user, role#member]
+//	    define parent: [role]
+//	    define grantee: [role#grantee] or member
+//	type permission
+//	  relations
+//	    define granted: [role#grantee]
+//
+// the members of a role are the users assigned the role and the members of the composite roles including it,
+// the permissions of a role are granted to its members and to the grantees of the roles inheriting from it.
+// a parent is stored twice, as role:child#parent@role:parent to read it back and as
+// role:parent#grantee@role:child#grantee for the permissions to flow to the child
+func Model() AuthorizationModel {
+	direct := Userset{This: &struct{}{}}
+
+	return AuthorizationModel{
+		SchemaVersion: SchemaVersion,
+		TypeDefinitions: []TypeDefinition{
+			{Type: authority.TupleTypeUser},
+			{
+				Type: authority.TupleTypeRole,
+				Relations: map[string]Userset{
+					authority.TupleRelationMember: direct,
+					authority.TupleRelationParent: direct,
+					RelationGrantee: {Union: &Usersets{Child: []Userset{
+						direct,
+						{ComputedUserset: &ObjectRelation{Relation: authority.TupleRelationMember}},
+					}}},
+				},
+				Metadata: &Metadata{Relations: map[string]RelationMetadata{
+					authority.TupleRelationMember: {DirectlyRelatedUserTypes: []RelationReference{
+						{Type: authority.TupleTypeUser},
+						{Type: authority.TupleTypeRole, Relation: authority.TupleRelationMember},
+					}},
+					authority.TupleRelationParent: {DirectlyRelatedUserTypes: []RelationReference{
+						{Type: authority.TupleTypeRole},
+					}},
+					RelationGrantee: {DirectlyRelatedUserTypes: []RelationReference{
+						{Type: authority.TupleTypeRole, Relation: RelationGrantee},
+					}},
+				}},
+			},
+			{
+				Type: authority.TupleTypePermission,
+				Relations: map[string]Userset{
+					authority.TupleRelationGranted: direct,
+				},
+				Metadata: &Metadata{Relations: map[string]RelationMetadata{
+					authority.TupleRelationGranted: {DirectlyRelatedUserTypes: []RelationReference{
+						{Type: authority.TupleTypeRole, Relation: RelationGrantee},
+					}},
+				}},
+			},
+		},
+	}
+}
+
+// FromTuples returns the OpenFGA tuples of the authority tuples
+func FromTuples(tuples []authority.RelationTuple) []TupleKey {
+	keys := make([]TupleKey, 0, len(tuples))
+	for _, t := range tuples {
+		switch {
+		case t.ObjectType == authority.TupleTypeRole && t.Relation == authority.TupleRelationParent:
+			keys = append(keys, tupleKey(t), TupleKey{
+				User:     t.ObjectType + ":" + t.ObjectID + "#" + RelationGrantee,
+				Relation: RelationGrantee,
+				Object:   t.SubjectType + ":" + t.SubjectID,
+			})
+		case t.ObjectType == authority.TupleTypePermission && t.Relation == authority.TupleRelationGranted &&
+			t.SubjectRelation == authority.TupleRelationMember:
+			t.SubjectRelation = RelationGrantee
+			keys = append(keys, tupleKey(t))
+		default:
+			keys = append(keys, tupleKey(t))
+		}
+	}
+
+	return keys
+}
+
+// ToTuples returns the authority tuples of the OpenFGA tuples, the grantee tuples written along with the parents
+// are skipped. it returns an error wrapping authority.ErrInvalidTuple if a tuple can't be parsed
+func ToTuples(keys []TupleKey) ([]authority.RelationTuple, error) {
+	tuples := make([]authority.RelationTuple, 0, len(keys))
+	for _, key := range keys {
+		if key.Relation == RelationGrantee && strings.HasPrefix(key.Object, authority.TupleTypeRole+":") {
+			continue
+		}
+
+		t, err := authority.ParseRelationTuple(key.Object + "#" + key.Relation + "@" + key.User)
+		if err != nil {
+			return nil, err
+		}
+
+		if t.ObjectType == authority.TupleTypePermission && t.SubjectRelation == RelationGrantee {
+			t.SubjectRelation = authority.TupleRelationMember
+		}
+
+		tuples = append(tuples, t)
+	}
+
+	return tuples, nil
+}
+
+// tupleKey returns the OpenFGA tuple of an authority tuple
+func tupleKey(t authority.RelationTuple) TupleKey {
+	user := t.SubjectType + ":" + t.SubjectID
+	if t.SubjectRelation != "" {
+		user += "#" + t.SubjectRelation
+	}
+
+	return TupleKey{User: user, Relation: t.Relation, Object: t.ObjectType + ":" + t.ObjectID}
+}
+
+// Export returns the authorization model and the tuples of the global roles, permissions and assignments
+func Export(ctx context.Context, a *authority.Authority) (*Document, error) {
+	tuples, err := a.ReadTuplesCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Document{AuthorizationModel: Model(), Tuples: FromTuples(tuples)}, nil
+}
+
+// Import writes the tuples of the document, the authorization model is not read.
+// the roles and permissions the tuples refer to must exist
+func Import(ctx context.Context, a *authority.Authority, doc *Document) error {
+	tuples, err := ToTuples(doc.Tuples)
+	if err != nil {
+		return err
+	}
+
+	return a.WriteTuplesCtx(ctx, tuples)
+}
+
+// WriteJSON exports the document as indented JSON
+func WriteJSON(ctx context.Context, a *authority.Authority, w io.Writer) error {
+	doc, err := Export(ctx, a)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(doc)
+}
+
+// ReadJSON imports a document read as JSON
+func ReadJSON(ctx context.Context, a *authority.Authority, r io.Reader) error {
+	var doc Document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return fmt.Errorf("openfga: %w", err)
+	}
+
+	return Import(ctx, a, &doc)
+}
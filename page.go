@@ -0,0 +1,117 @@
+package authority
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// the orders of the lists
+const (
+	// SortByName orders the list by name, it's the default
+	SortByName = "name"
+	// SortByID orders the list by id, i.e. in the creation order
+	SortByID = "id"
+)
+
+var ErrUnknownSort = errors.New("unknown sort order")
+
+// Page selects a page of a list, the whole list is returned when Limit is zero
+type Page struct {
+	Offset int
+	Limit  int
+}
+
+// ListOptions selects the page, the filter and the order of a list
+type ListOptions struct {
+	Page
+	// Prefix keeps the entries whose name starts with it
+	Prefix string
+	// Sort is SortByName or SortByID
+	Sort string
+	// Desc reverses the order
+	Desc bool
+}
+
+// RoleList is a page of roles
+type RoleList struct {
+	Roles []Role
+	// Total is the number of roles matching the filter, in all the pages
+	Total int
+}
+
+// PermissionList is a page of permissions
+type PermissionList struct {
+	Permissions []Permission
+	// Total is the number of permissions matching the filter, in all the pages
+	Total int
+}
+
+// ListRoles returns a page of the roles of every tenant, filtered and ordered by the options,
+// with the number of roles matching the filter
+func (a *Authority) ListRoles(opts ListOptions) (*RoleList, error) {
+	return a.ListRolesCtx(context.Background(), opts)
+}
+
+// ListRolesCtx is the context-aware variant of ListRoles
+func (a *Authority) ListRolesCtx(ctx context.Context, opts ListOptions) (*RoleList, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	roles, total, err := a.store.FindRoles(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RoleList{Roles: roles, Total: total}, nil
+}
+
+// ListPermissions returns a page of the permissions of every tenant, filtered and ordered by the options,
+// with the number of permissions matching the filter
+func (a *Authority) ListPermissions(opts ListOptions) (*PermissionList, error) {
+	return a.ListPermissionsCtx(context.Background(), opts)
+}
+
+// ListPermissionsCtx is the context-aware variant of ListPermissions
+func (a *Authority) ListPermissionsCtx(ctx context.Context, opts ListOptions) (*PermissionList, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	perms, total, err := a.store.FindPermissions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PermissionList{Permissions: perms, Total: total}, nil
+}
+
+func (o ListOptions) validate() error {
+	switch o.Sort {
+	case "", SortByName, SortByID:
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownSort, o.Sort)
+	}
+}
+
+// order returns the ORDER BY expression of the options, the id breaks the ties of the names of the tenants
+func (o ListOptions) order() string {
+	dir := " ASC"
+	if o.Desc {
+		dir = " DESC"
+	}
+
+	if o.Sort == SortByID {
+		return "id" + dir
+	}
+
+	return "name" + dir + ", id" + dir
+}
+
+// likePrefix returns the LIKE pattern matching the strings starting with the prefix
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix) + "%"
+}
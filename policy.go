@@ -0,0 +1,545 @@
+package authority
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+var (
+	ErrPolicyNotFound         = errors.New("policy not found")
+	ErrInvalidPolicy          = errors.New("invalid policy expression")
+	ErrUnknownPolicyReference = errors.New("policy refers to an unknown permission or role")
+)
+
+// policyExpr is a parsed policy expression
+type policyExpr interface {
+	// eval evaluates the expression, has reports whether the user has the named permission or role
+	eval(has func(name string) bool) bool
+	// names appends the permission and role names the expression refers to
+	names(dst []string) []string
+}
+
+type policyName string
+
+func (n policyName) eval(has func(string) bool) bool { return has(string(n)) }
+func (n policyName) names(dst []string) []string     { return append(dst, string(n)) }
+
+type policyNot struct{ x policyExpr }
+
+func (n policyNot) eval(has func(string) bool) bool { return !n.x.eval(has) }
+func (n policyNot) names(dst []string) []string     { return n.x.names(dst) }
+
+type policyAnd struct{ x, y policyExpr }
+
+func (n policyAnd) eval(has func(string) bool) bool { return n.x.eval(has) && n.y.eval(has) }
+func (n policyAnd) names(dst []string) []string     { return n.y.names(n.x.names(dst)) }
+
+type policyOr struct{ x, y policyExpr }
+
+func (n policyOr) eval(has func(string) bool) bool { return n.x.eval(has) || n.y.eval(has) }
+func (n policyOr) names(dst []string) []string     { return n.y.names(n.x.names(dst)) }
+
+// parsePolicy parses a policy expression made of permission and role names combined with AND, OR, NOT
+// and parentheses, NOT binds tighter than AND which binds tighter than OR
+func parsePolicy(expression string) (policyExpr, error) {
+	p := &policyParser{tokens: tokenizePolicy(expression)}
+
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidPolicy, p.tokens[p.pos])
+	}
+
+	return expr, nil
+}
+
+// tokenizePolicy splits an expression into parentheses and words
+func tokenizePolicy(expression string) []string {
+	var tokens []string
+	var word strings.Builder
+
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range expression {
+		switch {
+		case r == '(' || r == ')':
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+type policyParser struct {
+	tokens []string
+	pos    int
+}
+
+// accept consumes the next token when it's the given keyword
+func (p *policyParser) accept(keyword string) bool {
+	if p.pos < len(p.tokens) && strings.EqualFold(p.tokens[p.pos], keyword) {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *policyParser) or() (policyExpr, error) {
+	x, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("OR") {
+		var y policyExpr
+		if y, err = p.and(); err != nil {
+			return nil, err
+		}
+		x = policyOr{x, y}
+	}
+
+	return x, nil
+}
+
+func (p *policyParser) and() (policyExpr, error) {
+	x, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("AND") {
+		var y policyExpr
+		if y, err = p.not(); err != nil {
+			return nil, err
+		}
+		x = policyAnd{x, y}
+	}
+
+	return x, nil
+}
+
+func (p *policyParser) not() (policyExpr, error) {
+	if p.accept("NOT") {
+		x, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+
+		return policyNot{x}, nil
+	}
+
+	return p.operand()
+}
+
+func (p *policyParser) operand() (policyExpr, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected end", ErrInvalidPolicy)
+	}
+
+	if p.accept("(") {
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.accept(")") {
+			return nil, fmt.Errorf("%w: missing )", ErrInvalidPolicy)
+		}
+
+		return x, nil
+	}
+
+	token := p.tokens[p.pos]
+	switch strings.ToUpper(token) {
+	case ")", "AND", "OR", "NOT":
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidPolicy, token)
+	}
+	p.pos++
+
+	return policyName(token), nil
+}
+
+// DefinePolicy stores a named policy, e.g. DefinePolicy("can_publish", "articles.write AND (editor OR admin)").
+// the names in the expression refer to permissions or roles, combined with AND, OR, NOT and parentheses.
+// defining an existing policy replaces its expression. it returns ErrInvalidPolicy if the expression doesn't parse
+// and ErrUnknownPolicyReference if it refers to a name that is neither a permission nor a role
+func (a *Authority) DefinePolicy(name string, expression string) error {
+	return a.DefinePolicyCtx(context.Background(), name, expression)
+}
+
+// DefinePolicyCtx is the context-aware variant of DefinePolicy
+func (a *Authority) DefinePolicyCtx(ctx context.Context, name string, expression string) error {
+	known, err := a.knownNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	var expr policyExpr
+	if expr, err = compilePolicy(expression, known); err != nil {
+		return err
+	}
+
+	if err = a.store.SavePolicy(ctx, &Policy{Name: name, Expression: expression}); err != nil {
+		return err
+	}
+
+	a.policies.set(name, expr)
+	if err = a.notifyPolicies(ctx); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{Action: AuditPolicyDefined, Detail: name + " = " + expression})
+}
+
+// DeletePolicy deletes a named policy
+func (a *Authority) DeletePolicy(name string) error {
+	return a.DeletePolicyCtx(context.Background(), name)
+}
+
+// DeletePolicyCtx is the context-aware variant of DeletePolicy
+func (a *Authority) DeletePolicyCtx(ctx context.Context, name string) error {
+	if err := a.store.DeletePolicy(ctx, name); err != nil {
+		return err
+	}
+
+	a.policies.set(name, nil)
+	if err := a.notifyPolicies(ctx); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{Action: AuditPolicyDeleted, Detail: name})
+}
+
+// CheckPolicy checks if a user satisfies a named policy, a name in the policy holds when the user has
+// a permission or a role with that name. it returns ErrPolicyNotFound if the policy doesn't exist.
+// once the policies are loaded with ReloadPolicies or WatchPolicies they are evaluated from memory
+func (a *Authority) CheckPolicy(userID uint, name string) (bool, error) {
+	return a.CheckPolicyCtx(context.Background(), userID, name)
+}
+
+// CheckPolicyCtx is the context-aware variant of CheckPolicy
+func (a *Authority) CheckPolicyCtx(ctx context.Context, userID uint, name string) (bool, error) {
+	return a.checkPolicy(ctx, userKey(userID), name, "")
+}
+
+// CheckPolicy checks if the user satisfies a named policy with its roles and permissions in its tenant,
+// see Authority.CheckPolicy
+func (u User) CheckPolicy(name string) (bool, error) {
+	return u.CheckPolicyCtx(context.Background(), name)
+}
+
+// CheckPolicyCtx is the context-aware variant of CheckPolicy
+func (u User) CheckPolicyCtx(ctx context.Context, name string) (bool, error) {
+	return u.a.checkPolicy(ctx, u.key, name, u.tenant)
+}
+
+func (a *Authority) checkPolicy(ctx context.Context, user string, name string, tenant string) (bool, error) {
+	release, err := a.checks.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	var expr policyExpr
+	if expr, err = a.policy(ctx, name); err != nil {
+		return false, err
+	}
+
+	var granted map[string]bool
+	if granted, err = a.grantedNames(ctx, user, tenant); err != nil {
+		return false, err
+	}
+
+	return expr.eval(func(name string) bool { return granted[name] }), nil
+}
+
+// grantedNames returns the names of the roles and the permissions a user has in the tenant,
+// the conditional permissions are included when their condition holds for the attributes of the context.
+// the holders of the super role have every role and every permission of the tenant
+func (a *Authority) grantedNames(ctx context.Context, user string, tenant string) (map[string]bool, error) {
+	var err error
+
+	var roleIDs []uint
+	if roleIDs, err = a.userRoleIDs(ctx, user, tenant); err != nil {
+		return nil, err
+	}
+
+	if roleIDs, err = a.grantingRoles(ctx, roleIDs); err != nil {
+		return nil, err
+	}
+
+	// the super role passes the checks of every role and permission
+	var super bool
+	if super, err = a.holdsSuperRole(ctx, roleIDs, tenant); err != nil {
+		return nil, err
+	}
+
+	var roles []Role
+	if super {
+		roles, err = a.tenantRoles(ctx, tenant)
+	} else {
+		roles, err = a.store.GetRolesByID(ctx, roleIDs)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var perms []Permission
+	if perms, err = a.userPermissions(ctx, user, tenant); err != nil {
+		return nil, err
+	}
+
+	granted := make(map[string]bool, len(roles)+len(perms))
+	for _, role := range roles {
+		granted[role.Name] = true
+	}
+
+	for _, perm := range perms {
+		if perm.Condition != "" && !super {
+			var ok bool
+			if ok, err = a.conditions.eval(ctx, perm.Condition, user); err != nil {
+				return nil, err
+			}
+
+			if !ok {
+				continue
+			}
+		}
+		granted[perm.Name] = true
+	}
+
+	return granted, nil
+}
+
+// tenantRoles returns the roles visible in the tenant, the global ones included
+func (a *Authority) tenantRoles(ctx context.Context, tenant string) ([]Role, error) {
+	roles, err := a.store.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	n := 0
+	for _, role := range roles {
+		if role.Tenant == "" || role.Tenant == tenant {
+			roles[n] = role
+			n++
+		}
+	}
+
+	return roles[:n], nil
+}
+
+// compilePolicy parses a policy expression and makes sure every name it refers to is known
+func compilePolicy(expression string, known map[string]bool) (policyExpr, error) {
+	expr, err := parsePolicy(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range expr.names(nil) {
+		if !known[name] {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownPolicyReference, name)
+		}
+	}
+
+	return expr, nil
+}
+
+// knownNames returns the names of all the permissions and roles
+func (a *Authority) knownNames(ctx context.Context) (map[string]bool, error) {
+	roles, err := a.store.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var perms []Permission
+	if perms, err = a.store.ListPermissions(ctx); err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(roles)+len(perms))
+	for _, role := range roles {
+		known[role.Name] = true
+	}
+	for _, perm := range perms {
+		known[perm.Name] = true
+	}
+
+	return known, nil
+}
+
+// policySet holds the compiled policies once they are loaded
+type policySet struct {
+	mu    sync.RWMutex
+	exprs map[string]policyExpr
+}
+
+// get returns a loaded policy, loaded reports whether the policies are loaded at all
+func (s *policySet) get(name string) (expr policyExpr, loaded bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.exprs[name], s.exprs != nil
+}
+
+// set replaces a loaded policy, a nil expression removes it. it does nothing until the policies are loaded
+func (s *policySet) set(name string, expr policyExpr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.exprs == nil {
+		return
+	}
+
+	if expr == nil {
+		delete(s.exprs, name)
+		return
+	}
+	s.exprs[name] = expr
+}
+
+// replace replaces all the loaded policies
+func (s *policySet) replace(exprs map[string]policyExpr) {
+	s.mu.Lock()
+	s.exprs = exprs
+	s.mu.Unlock()
+}
+
+// policy returns the compiled policy from memory once the policies are loaded, from the store otherwise
+func (a *Authority) policy(ctx context.Context, name string) (policyExpr, error) {
+	if expr, loaded := a.policies.get(name); loaded {
+		if expr == nil {
+			return nil, fmt.Errorf("%w: %s", ErrPolicyNotFound, name)
+		}
+
+		return expr, nil
+	}
+
+	policy, err := a.store.GetPolicy(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsePolicy(policy.Expression)
+}
+
+// PolicyNotifier is implemented by the stores that can notify all the instances when the policies change
+type PolicyNotifier interface {
+	// NotifyPolicies notifies the listening instances that the policies changed
+	NotifyPolicies(ctx context.Context) error
+	// ListenPolicies calls changed on every notification until the context is done
+	ListenPolicies(ctx context.Context, changed func()) error
+}
+
+// notifyPolicies tells the other instances to reload the policies when the store supports it
+func (a *Authority) notifyPolicies(ctx context.Context) error {
+	if notifier, ok := a.store.(PolicyNotifier); ok {
+		return notifier.NotifyPolicies(ctx)
+	}
+
+	return nil
+}
+
+// ReloadPolicies loads all the policies in memory, CheckPolicy stops reading them from the store.
+// the policies are validated first, when any of them is invalid or refers to an unknown permission or role the
+// previously loaded policies are kept and the error is returned
+func (a *Authority) ReloadPolicies(ctx context.Context) error {
+	policies, err := a.store.ListPolicies(ctx)
+	if err != nil {
+		return err
+	}
+
+	var known map[string]bool
+	if known, err = a.knownNames(ctx); err != nil {
+		return err
+	}
+
+	exprs := make(map[string]policyExpr, len(policies))
+	for _, policy := range policies {
+		if exprs[policy.Name], err = compilePolicy(policy.Expression, known); err != nil {
+			return fmt.Errorf("policy %s: %w", policy.Name, err)
+		}
+	}
+
+	a.policies.replace(exprs)
+
+	return nil
+}
+
+// PolicyWatchOptions has the options of WatchPolicies
+type PolicyWatchOptions struct {
+	// Interval is how often the policies are reloaded, they are reloaded only on notifications when it's zero
+	Interval time.Duration
+	// OnError is called with the errors of the reloads and of the listener, the watch goes on after them
+	OnError func(err error)
+}
+
+// WatchPolicies loads the policies and keeps them up to date until the context is done, so the changes made by
+// any instance take effect without restarts. the policies are reloaded on every interval and, when the store
+// implements PolicyNotifier, as soon as another instance changes them. it returns the error of the first load
+func (a *Authority) WatchPolicies(ctx context.Context, opts PolicyWatchOptions) error {
+	if err := a.ReloadPolicies(ctx); err != nil {
+		return err
+	}
+
+	report := func(err error) {
+		if opts.OnError != nil {
+			opts.OnError(err)
+		}
+	}
+
+	changed := make(chan struct{}, 1)
+	if notifier, ok := a.store.(PolicyNotifier); ok {
+		go func() {
+			err := notifier.ListenPolicies(ctx, func() {
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			})
+			if err != nil && !errors.Is(err, ErrNotSupported) {
+				report(err)
+			}
+		}()
+	}
+
+	var tick <-chan time.Time
+	if opts.Interval > 0 {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick:
+		case <-changed:
+		}
+
+		if err := a.ReloadPolicies(ctx); err != nil {
+			report(err)
+		}
+	}
+}
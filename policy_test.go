@@ -0,0 +1,88 @@
+package authority_test
+
+import (
+	"errors"
+	"testing"
+
+	"authority"
+)
+
+func TestCheckPolicy(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	setupRole(t, a, "editor", "articles.write")
+	must(t, a.CreateRole("admin"))
+	must(t, a.CreateRole("banned"))
+	must(t, a.AssignRole(1, "editor"))
+	must(t, a.AssignRole(2, "admin"))
+	must(t, a.AssignRole(3, "editor"))
+	must(t, a.AssignRole(3, "banned"))
+
+	must(t, a.DefinePolicy("can_publish", "articles.write AND NOT banned OR admin"))
+	for _, tc := range []struct {
+		user    uint
+		granted bool
+	}{
+		{1, true},
+		{2, true},
+		{3, false},
+		{4, false},
+	} {
+		granted, err := a.CheckPolicy(tc.user, "can_publish")
+		must(t, err)
+		if granted != tc.granted {
+			t.Errorf("CheckPolicy of %d = %t, want %t", tc.user, granted, tc.granted)
+		}
+	}
+
+	// redefining the policy replaces its expression, the parentheses group the names
+	must(t, a.DefinePolicy("can_publish", "articles.write AND (NOT banned OR admin)"))
+	granted, err := a.CheckPolicy(2, "can_publish")
+	must(t, err)
+	if granted {
+		t.Fatal("the admin without articles.write satisfies the redefined policy")
+	}
+
+	must(t, a.DeletePolicy("can_publish"))
+	if _, err = a.CheckPolicy(1, "can_publish"); !errors.Is(err, authority.ErrPolicyNotFound) {
+		t.Fatalf("CheckPolicy of a deleted policy = %v, want ErrPolicyNotFound", err)
+	}
+}
+
+func TestDefinePolicyErrors(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	must(t, a.CreateRole("editor"))
+
+	for _, expression := range []string{"editor AND", "(editor", "editor editor", ""} {
+		if err := a.DefinePolicy("p", expression); !errors.Is(err, authority.ErrInvalidPolicy) {
+			t.Errorf("DefinePolicy(%q) = %v, want ErrInvalidPolicy", expression, err)
+		}
+	}
+
+	if err := a.DefinePolicy("p", "editor OR missing"); !errors.Is(err, authority.ErrUnknownPolicyReference) {
+		t.Fatalf("DefinePolicy with an unknown name = %v, want ErrUnknownPolicyReference", err)
+	}
+}
+
+func TestUserCheckPolicy(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	setupRole(t, a, "editor", "articles.write")
+	must(t, a.DefinePolicy("can_publish", "articles.write AND editor"))
+	must(t, a.User("alice@example.com").AssignRole("editor"))
+	must(t, a.User("carol@example.com").InTenant("acme").AssignRole("editor"))
+
+	for _, tc := range []struct {
+		user    authority.User
+		granted bool
+	}{
+		{a.User("alice@example.com"), true},
+		{a.User("bob@example.com"), false},
+		{a.User("carol@example.com"), false},
+		{a.User("carol@example.com").InTenant("acme"), true},
+	} {
+		granted, err := tc.user.CheckPolicy("can_publish")
+		must(t, err)
+		if granted != tc.granted {
+			t.Errorf("CheckPolicy of %s = %t, want %t", tc.user.Key(), granted, tc.granted)
+		}
+	}
+}
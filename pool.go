@@ -0,0 +1,81 @@
+package authority
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+)
+
+// ConnectionsPerCheck is the number of pool connections a check holds at the same time,
+// the queries of a check run one after the other
+const ConnectionsPerCheck = 1
+
+// checkLimiter limits the checks running at the same time
+type checkLimiter struct {
+	slots    chan struct{}
+	inFlight int64
+	waiting  int64
+}
+
+func newCheckLimiter(max int) *checkLimiter {
+	l := &checkLimiter{}
+	if max > 0 {
+		l.slots = make(chan struct{}, max)
+	}
+
+	return l
+}
+
+// acquire waits for a free slot or for the context to be done, release frees the slot
+func (l *checkLimiter) acquire(ctx context.Context) (release func(), err error) {
+	if l.slots != nil {
+		atomic.AddInt64(&l.waiting, 1)
+		select {
+		case l.slots <- struct{}{}:
+			atomic.AddInt64(&l.waiting, -1)
+		case <-ctx.Done():
+			atomic.AddInt64(&l.waiting, -1)
+			return nil, ctx.Err()
+		}
+	}
+
+	atomic.AddInt64(&l.inFlight, 1)
+
+	return func() {
+		atomic.AddInt64(&l.inFlight, -1)
+		if l.slots != nil {
+			<-l.slots
+		}
+	}, nil
+}
+
+// PoolStats describes the load the checks put on the connection pool
+type PoolStats struct {
+	// MaxConcurrentChecks is the limit of the checks running at the same time, 0 when there is no limit
+	MaxConcurrentChecks int
+	// InFlightChecks is the number of checks running
+	InFlightChecks int
+	// WaitingChecks is the number of checks waiting for a free slot
+	WaitingChecks int
+	// ConnectionsPerCheck is the number of connections a check holds at the same time
+	ConnectionsPerCheck int
+	// DB has the statistics of the pool of the default store, it's empty when a custom store is used
+	DB sql.DBStats
+}
+
+// PoolStats returns the load the checks put on the connection pool, e.g. to size the pool: the checks need at most
+// MaxConcurrentChecks * ConnectionsPerCheck connections, the rest of the pool is left to the application
+func (a *Authority) PoolStats() PoolStats {
+	stats := PoolStats{
+		MaxConcurrentChecks: cap(a.checks.slots),
+		InFlightChecks:      int(atomic.LoadInt64(&a.checks.inFlight)),
+		WaitingChecks:       int(atomic.LoadInt64(&a.checks.waiting)),
+		ConnectionsPerCheck: ConnectionsPerCheck,
+	}
+
+	if a.DB != nil {
+		stats.DB = a.DB.Stats()
+	}
+
+	return stats
+}
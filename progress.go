@@ -0,0 +1,94 @@
+package authority
+
+import (
+	"context"
+	"time"
+)
+
+// the operations reporting their progress besides the imports, whose operation is their import kind
+const (
+	OperationClear       = "clear"
+	OperationRevokeRoles = "revoke_roles"
+)
+
+// Progress is a report of the progress of a bulk operation
+type Progress struct {
+	// Operation is ImportKindAssignRoles, ImportKindSnapshot, OperationClear or OperationRevokeRoles
+	Operation string
+	// Done is the number of items processed out of Total
+	Done  int
+	Total int
+	// Err is the error that stopped the operation, the operations are atomic so the items done are rolled back
+	Err error
+	// Elapsed is the time since the start of the operation
+	Elapsed time.Duration
+}
+
+// ETA returns an estimate of the time left, extrapolated from the pace of the items done so far.
+// it's zero before the first item is done
+func (p Progress) ETA() time.Duration {
+	if p.Done == 0 || p.Done >= p.Total {
+		return 0
+	}
+
+	return time.Duration(float64(p.Elapsed) / float64(p.Done) * float64(p.Total-p.Done))
+}
+
+type progressKey struct{}
+
+// WithProgress returns a copy of the context making the bulk operations made with it (AssignRolesCtx,
+// AssignRoleToUsersCtx, ImportCtx, RevokeAllRolesCtx and OffboardUserCtx) report their progress to fn,
+// e.g. to render a progress bar. fn is called within the transaction of the operation so it must be quick,
+// e.g. a non-blocking send on a channel. a failed operation sends a last report with the error
+func WithProgress(ctx context.Context, fn func(Progress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// progress reports the progress of an operation, the nil progress reports nothing
+type progress struct {
+	fn    func(Progress)
+	now   func() time.Time
+	start time.Time
+	state Progress
+}
+
+// startProgress starts reporting the progress of an operation on total items,
+// it returns nil when the context has no progress callback
+func (a *Authority) startProgress(ctx context.Context, operation string, total int) *progress {
+	fn, _ := ctx.Value(progressKey{}).(func(Progress))
+	if fn == nil {
+		return nil
+	}
+
+	p := &progress{fn: fn, now: a.now, start: a.now(), state: Progress{Operation: operation, Total: total}}
+	p.report()
+
+	return p
+}
+
+// add counts n more items done
+func (p *progress) add(n int) {
+	if p == nil || n == 0 {
+		return
+	}
+
+	p.state.Done += n
+	p.report()
+}
+
+// fail reports the error stopping the operation and returns it
+func (p *progress) fail(err error) error {
+	if p == nil || err == nil {
+		return err
+	}
+
+	p.state.Err = err
+	p.report()
+
+	return err
+}
+
+func (p *progress) report() {
+	p.state.Elapsed = p.now().Sub(p.start)
+	p.fn(p.state)
+}
@@ -0,0 +1,144 @@
+// Package prometheus provides an authority metrics sink exposing the counters and the timers to Prometheus
+// through the collectors registered on a prometheus.Registerer
+package prometheus
+
+import (
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"authority"
+)
+
+// DefaultNamespace prefixes the names of the metrics when Options.Namespace is empty
+const DefaultNamespace = "authority"
+
+// Options has the options of the sink
+type Options struct {
+	// Registerer registers the collectors of the sink, prometheus.DefaultRegisterer is used when it's nil
+	Registerer prom.Registerer
+	// Namespace prefixes the names of the metrics, DefaultNamespace is used when it's empty
+	Namespace string
+	// ConstLabels are added to every metric, e.g. the environment
+	ConstLabels prom.Labels
+	// Buckets are the buckets of the histogram of the check durations in seconds, prometheus.DefBuckets is
+	// used when it's empty
+	Buckets []float64
+}
+
+// Sink is an authority.MetricsSink updating the collectors of the metrics:
+//
+//	authority_checks_total{kind, result, permission}
+//	authority_check_duration_seconds{kind}
+//	authority_cache_hits_total
+//	authority_cache_misses_total
+//	authority_changes_total{action}
+//	authority_denial_bursts_total
+//	authority_store_errors_total{operation}
+//
+// the hit ratio of the cache is the rate of the hits over the rate of the hits and the misses. the metrics
+// without a collector are dropped
+type Sink struct {
+	checks      *prom.CounterVec
+	duration    *prom.HistogramVec
+	cacheHits   prom.Counter
+	cacheMisses prom.Counter
+	changes     *prom.CounterVec
+	bursts      prom.Counter
+	storeErrors *prom.CounterVec
+}
+
+var _ authority.MetricsSink = (*Sink)(nil)
+
+// New returns a sink with its collectors registered, it fails when they are registered already
+func New(opts Options) (*Sink, error) {
+	if opts.Registerer == nil {
+		opts.Registerer = prom.DefaultRegisterer
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = DefaultNamespace
+	}
+	if len(opts.Buckets) == 0 {
+		opts.Buckets = prom.DefBuckets
+	}
+
+	counter := func(name string, help string) prom.Counter {
+		return prom.NewCounter(prom.CounterOpts{
+			Namespace: opts.Namespace, Name: name, Help: help, ConstLabels: opts.ConstLabels,
+		})
+	}
+	counterVec := func(name string, help string, labels ...string) *prom.CounterVec {
+		return prom.NewCounterVec(prom.CounterOpts{
+			Namespace: opts.Namespace, Name: name, Help: help, ConstLabels: opts.ConstLabels,
+		}, labels)
+	}
+
+	s := &Sink{
+		checks: counterVec("checks_total", "The checks by kind, result and permission.",
+			"kind", "result", "permission"),
+		duration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: opts.Namespace, Name: "check_duration_seconds", Help: "The durations of the checks by kind.",
+			ConstLabels: opts.ConstLabels, Buckets: opts.Buckets,
+		}, []string{"kind"}),
+		cacheHits:   counter("cache_hits_total", "The lookups and check results found in the cache."),
+		cacheMisses: counter("cache_misses_total", "The lookups and check results loaded from the store."),
+		changes:     counterVec("changes_total", "The committed changes by action.", "action"),
+		bursts:      counter("denial_bursts_total", "The bursts of denied checks."),
+		storeErrors: counterVec("store_errors_total", "The failed queries of the database by operation.",
+			"operation"),
+	}
+
+	for _, c := range []prom.Collector{
+		s.checks, s.duration, s.cacheHits, s.cacheMisses, s.changes, s.bursts, s.storeErrors,
+	} {
+		if err := opts.Registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// IncrCounter implements authority.MetricsSink
+func (s *Sink) IncrCounter(name string, delta int64, tags ...authority.Tag) {
+	var counter prom.Counter
+	switch name {
+	case authority.MetricChecks:
+		counter = s.checks.WithLabelValues(values(tags, "kind", "result", "permission")...)
+	case authority.MetricCacheHits:
+		counter = s.cacheHits
+	case authority.MetricCacheMisses:
+		counter = s.cacheMisses
+	case authority.MetricChanges:
+		counter = s.changes.WithLabelValues(values(tags, "action")...)
+	case authority.MetricDenialBursts:
+		counter = s.bursts
+	case authority.MetricStoreErrors:
+		counter = s.storeErrors.WithLabelValues(values(tags, "operation")...)
+	default:
+		return
+	}
+
+	counter.Add(float64(delta))
+}
+
+// ObserveDuration implements authority.MetricsSink, the duration is observed in seconds
+func (s *Sink) ObserveDuration(name string, d time.Duration, tags ...authority.Tag) {
+	if name == authority.MetricCheckDuration {
+		s.duration.WithLabelValues(values(tags, "kind")...).Observe(d.Seconds())
+	}
+}
+
+// values returns the values of the tags with the given keys in order, the missing ones are empty
+func values(tags []authority.Tag, keys ...string) []string {
+	values := make([]string, len(keys))
+	for _, tag := range tags {
+		for i, key := range keys {
+			if tag.Key == key {
+				values[i] = tag.Value
+			}
+		}
+	}
+
+	return values
+}
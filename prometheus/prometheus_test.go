@@ -0,0 +1,111 @@
+package prometheus_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/uptrace/bun"
+
+	"authority"
+	"authority/authoritytest"
+	"authority/prometheus"
+)
+
+// value returns the value of the counter or the sample count of the histogram with the given labels
+func value(t *testing.T, reg *prom.Registry, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if want, ok := labels[label.GetName()]; ok && want != label.GetValue() {
+					continue metrics
+				}
+			}
+			if m.GetHistogram() != nil {
+				return float64(m.GetHistogram().GetSampleCount())
+			}
+			return m.GetCounter().GetValue()
+		}
+	}
+
+	return 0
+}
+
+func TestSink(t *testing.T) {
+	reg := prom.NewRegistry()
+	sink, err := prometheus.New(prometheus.Options{Registerer: reg})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := authority.NewE(authority.Options{
+		Store: authoritytest.NewMemoryStore(), Metrics: sink, CacheTTL: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = a.CreateRole("editor"); err != nil {
+		t.Fatal(err)
+	}
+	for _, permName := range []string{"articles.write", "articles.delete"} {
+		if err = a.CreatePermission(permName); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = a.AssignPermissions("editor", []string{"articles.write"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = a.AssignRole(1, "editor"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, permName := range []string{"articles.write", "articles.write", "articles.delete"} {
+		if _, err = a.CheckPermission(1, permName); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"authority_checks_total", map[string]string{"permission": "articles.write", "result": "granted"}, 2},
+		{"authority_checks_total", map[string]string{"permission": "articles.delete", "result": "denied"}, 1},
+		{"authority_check_duration_seconds", map[string]string{"kind": authority.CheckKindPermission}, 3},
+		{"authority_changes_total", map[string]string{"action": authority.AuditRoleAssigned}, 1},
+	} {
+		if got := value(t, reg, tc.name, tc.labels); got != tc.want {
+			t.Errorf("%s%v = %v, want %v", tc.name, tc.labels, got, tc.want)
+		}
+	}
+	if value(t, reg, "authority_cache_hits_total", nil) == 0 || value(t, reg, "authority_cache_misses_total", nil) == 0 {
+		t.Error("the lookups of the cache are not counted")
+	}
+
+	// the failed queries are counted by the hook, not finding a row isn't a failure
+	hook := authority.MetricsQueryHook{Sink: sink}
+	hook.AfterQuery(context.Background(), &bun.QueryEvent{Query: "SELECT 1", Err: errors.New("connection refused")})
+	hook.AfterQuery(context.Background(), &bun.QueryEvent{Query: "SELECT 1", Err: sql.ErrNoRows})
+	if got := value(t, reg, "authority_store_errors_total", map[string]string{"operation": "select"}); got != 1 {
+		t.Errorf("authority_store_errors_total = %v, want 1", got)
+	}
+
+	if _, err = prometheus.New(prometheus.Options{Registerer: reg}); err == nil {
+		t.Error("the collectors are registered twice")
+	}
+}
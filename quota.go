@@ -0,0 +1,66 @@
+package authority
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrQuotaExceeded is matched by the QuotaError returned when a role has no seat left in a tenant
+var ErrQuotaExceeded = errors.New("role quota exceeded")
+
+// QuotaProvider returns the seat limits of the roles, e.g. from the plan of the tenant
+// in the entitlement layer of the application
+type QuotaProvider interface {
+	// RoleLimit returns how many users may hold the role in the tenant, a negative limit means no limit.
+	// the tenant is empty for the global assignments
+	RoleLimit(ctx context.Context, tenant string, roleName string) (int, error)
+}
+
+// QuotaFunc adapts a function to a QuotaProvider
+type QuotaFunc func(ctx context.Context, tenant string, roleName string) (int, error)
+
+// RoleLimit implements QuotaProvider
+func (f QuotaFunc) RoleLimit(ctx context.Context, tenant string, roleName string) (int, error) {
+	return f(ctx, tenant, roleName)
+}
+
+// QuotaError is returned when assigning a role would exceed its limit in a tenant
+type QuotaError struct {
+	Tenant string
+	Role   string
+	Limit  int
+}
+
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("role %q is limited to %d users in tenant %q", e.Role, e.Limit, e.Tenant)
+}
+
+// Is matches ErrQuotaExceeded
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExceeded
+}
+
+// checkQuota returns a QuotaError when the role has not enough seats left in the tenant for the given number
+// of users, the assignments that are not expired take a seat, including the scheduled ones
+func (a *Authority) checkQuota(ctx context.Context, role *Role, tenant string, users int) error {
+	if a.quotas == nil {
+		return nil
+	}
+
+	limit, err := a.quotas.RoleLimit(ctx, tenant, role.Name)
+	if err != nil || limit < 0 {
+		return err
+	}
+
+	count, err := a.store.CountRoleUsers(ctx, role.ID, tenant, a.now())
+	if err != nil {
+		return err
+	}
+
+	if count+users > limit {
+		return &QuotaError{Tenant: tenant, Role: role.Name, Limit: limit}
+	}
+
+	return nil
+}
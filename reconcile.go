@@ -0,0 +1,326 @@
+package authority
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Declaration declares the global roles and permissions the storage must hold, it's read from a YAML or JSON file:
+//
+//	permissions:
+//	  - name: articles.write
+//	    condition: resource.owner == user.id
+//	roles:
+//	  - name: editor
+//	    permissions: [articles.read, articles.write]
+//
+// the permissions the roles refer to are declared implicitly
+type Declaration struct {
+	Permissions []DeclaredPermission `yaml:"permissions" json:"permissions"`
+	Roles       []DeclaredRole       `yaml:"roles" json:"roles"`
+}
+
+// DeclaredPermission is a permission of a declaration
+type DeclaredPermission struct {
+	Name      string `yaml:"name" json:"name"`
+	Condition string `yaml:"condition" json:"condition"`
+}
+
+// DeclaredRole is a role of a declaration with all its permissions
+type DeclaredRole struct {
+	Name        string   `yaml:"name" json:"name"`
+	Permissions []string `yaml:"permissions" json:"permissions"`
+}
+
+// ReadDeclaration reads a declaration written in YAML or JSON
+func ReadDeclaration(r io.Reader) (*Declaration, error) {
+	var decl Declaration
+	if err := yaml.NewDecoder(r).Decode(&decl); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("declaration: %w", err)
+	}
+
+	return &decl, nil
+}
+
+// ReconcileOptions has the options of Reconcile
+type ReconcileOptions struct {
+	// Prune revokes the permissions the declaration doesn't give to the roles and deletes the global roles
+	// and permissions it doesn't declare, otherwise only what's missing is added
+	Prune bool
+	// DryRun returns the plan without applying it
+	DryRun bool
+}
+
+// RolePermissionChange is a permission assigned to or revoked from a role
+type RolePermissionChange struct {
+	Role       string
+	Permission string
+}
+
+// ReconcilePlan is the list of changes making the storage match a declaration
+type ReconcilePlan struct {
+	CreatePermissions []string
+	// SetConditions has the new condition of the permissions by name
+	SetConditions     map[string]string
+	CreateRoles       []string
+	AssignPermissions []RolePermissionChange
+	RevokePermissions []RolePermissionChange
+	DeleteRoles       []string
+	DeletePermissions []string
+}
+
+// Empty reports whether the storage already matches the declaration
+func (p *ReconcilePlan) Empty() bool {
+	return len(p.CreatePermissions) == 0 && len(p.SetConditions) == 0 && len(p.CreateRoles) == 0 &&
+		len(p.AssignPermissions) == 0 && len(p.RevokePermissions) == 0 && len(p.DeleteRoles) == 0 &&
+		len(p.DeletePermissions) == 0
+}
+
+// String returns the plan as a diff, one change per line
+func (p *ReconcilePlan) String() string {
+	var b strings.Builder
+	for _, name := range p.CreatePermissions {
+		fmt.Fprintf(&b, "+ permission %s\n", name)
+	}
+	conditions := make([]string, 0, len(p.SetConditions))
+	for name := range p.SetConditions {
+		conditions = append(conditions, name)
+	}
+	sort.Strings(conditions)
+	for _, name := range conditions {
+		fmt.Fprintf(&b, "~ permission %s condition %q\n", name, p.SetConditions[name])
+	}
+	for _, name := range p.CreateRoles {
+		fmt.Fprintf(&b, "+ role %s\n", name)
+	}
+	for _, c := range p.AssignPermissions {
+		fmt.Fprintf(&b, "+ role %s permission %s\n", c.Role, c.Permission)
+	}
+	for _, c := range p.RevokePermissions {
+		fmt.Fprintf(&b, "- role %s permission %s\n", c.Role, c.Permission)
+	}
+	for _, name := range p.DeleteRoles {
+		fmt.Fprintf(&b, "- role %s\n", name)
+	}
+	for _, name := range p.DeletePermissions {
+		fmt.Fprintf(&b, "- permission %s\n", name)
+	}
+
+	return b.String()
+}
+
+// Reconcile makes the global roles and permissions match a declaration, e.g. one kept in version control.
+// the changes are made atomically by one instance at a time and the returned plan lists them, with DryRun
+// the plan is returned without making them. a pruned role still assigned to users fails with ErrRoleInUse
+func (a *Authority) Reconcile(decl *Declaration, opts ReconcileOptions) (*ReconcilePlan, error) {
+	return a.ReconcileCtx(context.Background(), decl, opts)
+}
+
+// ReconcileCtx is the context-aware variant of Reconcile
+func (a *Authority) ReconcileCtx(ctx context.Context, decl *Declaration, opts ReconcileOptions) (*ReconcilePlan,
+	error) {
+	var plan *ReconcilePlan
+
+	err := a.exclusive(ctx, "reconcile", a.lockTimeout, func(ctx context.Context) error {
+		var err error
+		if plan, err = a.plan(ctx, decl, opts.Prune); err != nil || opts.DryRun {
+			return err
+		}
+
+		return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+			return tx.apply(ctx, plan)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+// plan compares the declaration with the global roles and permissions
+func (a *Authority) plan(ctx context.Context, decl *Declaration, prune bool) (*ReconcilePlan, error) {
+	plan := &ReconcilePlan{SetConditions: map[string]string{}}
+
+	// the declared permissions with their condition, the implicit ones have none
+	declaredPerms := make(map[string]*string)
+	for _, p := range decl.Permissions {
+		condition := p.Condition
+		declaredPerms[p.Name] = &condition
+	}
+	for _, r := range decl.Roles {
+		for _, name := range r.Permissions {
+			if _, ok := declaredPerms[name]; !ok {
+				declaredPerms[name] = nil
+			}
+		}
+	}
+
+	perms, err := a.store.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	permNames := make(map[uint]string, len(perms))
+	for _, perm := range perms {
+		if perm.Tenant != "" {
+			continue
+		}
+		permNames[perm.ID] = perm.Name
+
+		condition, declared := declaredPerms[perm.Name]
+		switch {
+		case !declared && prune:
+			plan.DeletePermissions = append(plan.DeletePermissions, perm.Name)
+		case condition != nil && *condition != perm.Condition:
+			plan.SetConditions[perm.Name] = *condition
+		}
+	}
+
+	existing := make(map[string]bool, len(permNames))
+	for _, name := range permNames {
+		existing[name] = true
+	}
+	for name, condition := range declaredPerms {
+		if !existing[name] {
+			plan.CreatePermissions = append(plan.CreatePermissions, name)
+			if condition != nil && *condition != "" {
+				plan.SetConditions[name] = *condition
+			}
+		}
+	}
+
+	var roles []Role
+	if roles, err = a.store.ListRoles(ctx); err != nil {
+		return nil, err
+	}
+
+	roleByName := make(map[string]Role, len(roles))
+	roleIDs := make([]uint, 0, len(roles))
+	for _, role := range roles {
+		if role.Tenant == "" {
+			roleByName[role.Name] = role
+			roleIDs = append(roleIDs, role.ID)
+		}
+	}
+
+	var rolePerms []RolePermission
+	if rolePerms, err = a.store.GetRolePermissions(ctx, roleIDs); err != nil {
+		return nil, err
+	}
+
+	granted := make(map[uint]map[string]bool, len(roleIDs))
+	for _, rp := range rolePerms {
+		if granted[rp.RoleID] == nil {
+			granted[rp.RoleID] = map[string]bool{}
+		}
+		granted[rp.RoleID][permNames[rp.PermissionID]] = true
+	}
+
+	declaredRoles := make(map[string]bool, len(decl.Roles))
+	for _, r := range decl.Roles {
+		declaredRoles[r.Name] = true
+
+		role, ok := roleByName[r.Name]
+		if !ok {
+			plan.CreateRoles = append(plan.CreateRoles, r.Name)
+		}
+
+		wanted := make(map[string]bool, len(r.Permissions))
+		for _, name := range r.Permissions {
+			wanted[name] = true
+			if !granted[role.ID][name] {
+				change := RolePermissionChange{Role: r.Name, Permission: name}
+				plan.AssignPermissions = append(plan.AssignPermissions, change)
+			}
+		}
+
+		if ok && prune {
+			for name := range granted[role.ID] {
+				if !wanted[name] && name != "" {
+					change := RolePermissionChange{Role: r.Name, Permission: name}
+					plan.RevokePermissions = append(plan.RevokePermissions, change)
+				}
+			}
+		}
+	}
+
+	if prune {
+		for name := range roleByName {
+			if !declaredRoles[name] {
+				plan.DeleteRoles = append(plan.DeleteRoles, name)
+			}
+		}
+	}
+
+	sort.Strings(plan.CreatePermissions)
+	sort.Strings(plan.CreateRoles)
+	sort.Strings(plan.DeleteRoles)
+	sort.Strings(plan.DeletePermissions)
+	sortChanges(plan.AssignPermissions)
+	sortChanges(plan.RevokePermissions)
+
+	return plan, nil
+}
+
+// apply makes the changes of a plan
+func (a *Authority) apply(ctx context.Context, plan *ReconcilePlan) error {
+	for _, name := range plan.CreatePermissions {
+		if err := a.createPermission(ctx, name, ""); err != nil {
+			return err
+		}
+	}
+
+	for name, condition := range plan.SetConditions {
+		if err := a.SetPermissionConditionCtx(ctx, name, condition); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range plan.CreateRoles {
+		if err := a.createRole(ctx, Role{Name: name}); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range plan.AssignPermissions {
+		if err := a.assignRolePermissions(ctx, c.Role, []string{c.Permission}, RolePermission{}, ""); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range plan.RevokePermissions {
+		if err := a.RevokeRolePermissionCtx(ctx, c.Role, c.Permission); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range plan.DeleteRoles {
+		if err := a.deleteRole(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range plan.DeletePermissions {
+		if err := a.deletePermission(ctx, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sortChanges(changes []RolePermissionChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Role != changes[j].Role {
+			return changes[i].Role < changes[j].Role
+		}
+		return changes[i].Permission < changes[j].Permission
+	})
+}
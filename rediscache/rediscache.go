@@ -0,0 +1,170 @@
+// Package rediscache provides an authority cache backend shared by the instances through redis
+package rediscache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"authority"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// DefaultPrefix prefixes the keys and the channel when Options.Prefix is empty
+const DefaultPrefix = "authority:"
+
+// retryInterval is how long the listener waits before restoring a lost subscription
+const retryInterval = time.Second
+
+// Options has the options of the cache
+type Options struct {
+	// Prefix is prepended to the keys and the channel, DefaultPrefix is used when it's empty
+	Prefix string
+}
+
+// Cache is an authority.CacheBackend keeping the entries in redis. the keys of the entries include a generation
+// number that Purge increments and publishes, every instance subscribed to the channel switches to the
+// new generation right away and the entries of the old one expire with their ttl
+type Cache struct {
+	client     redis.UniversalClient
+	prefix     string
+	generation int64
+
+	pubsub *redis.PubSub
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+var _ authority.CacheBackend = (*Cache)(nil)
+
+// New returns a cache using the client, it subscribes to the purges of the other instances until Close is called
+func New(ctx context.Context, client redis.UniversalClient, opts Options) (*Cache, error) {
+	c := &Cache{client: client, prefix: opts.Prefix, done: make(chan struct{})}
+	if c.prefix == "" {
+		c.prefix = DefaultPrefix
+	}
+
+	c.pubsub = client.Subscribe(ctx, c.channel())
+	if _, err := c.pubsub.Receive(ctx); err != nil {
+		_ = c.pubsub.Close()
+		return nil, err
+	}
+
+	if err := c.loadGeneration(ctx); err != nil {
+		_ = c.pubsub.Close()
+		return nil, err
+	}
+
+	var listenCtx context.Context
+	listenCtx, c.cancel = context.WithCancel(context.Background())
+	go c.listen(listenCtx)
+
+	return c, nil
+}
+
+// Get implements authority.CacheBackend
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := c.client.Get(ctx, c.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return b, true, nil
+}
+
+// Set implements authority.CacheBackend
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.client.Set(ctx, c.key(key), value, ttl).Err()
+}
+
+// Purge implements authority.CacheBackend, it starts a new generation and publishes it to the other instances
+func (c *Cache) Purge(ctx context.Context) error {
+	generation, err := c.client.Incr(ctx, c.prefix+"generation").Result()
+	if err != nil {
+		return err
+	}
+
+	c.setGeneration(generation)
+
+	return c.client.Publish(ctx, c.channel(), generation).Err()
+}
+
+// Close stops listening to the purges of the other instances
+func (c *Cache) Close() error {
+	var err error
+	c.once.Do(func() {
+		c.cancel()
+		err = c.pubsub.Close()
+		<-c.done
+	})
+
+	return err
+}
+
+func (c *Cache) channel() string {
+	return c.prefix + "purge"
+}
+
+func (c *Cache) key(key string) string {
+	return c.prefix + strconv.FormatInt(atomic.LoadInt64(&c.generation), 10) + ":" + key
+}
+
+// listen follows the generations published by the purges, the generation is read again when the subscription
+// is restored since the purges published meanwhile are lost
+func (c *Cache) listen(ctx context.Context) {
+	defer close(c.done)
+
+	for {
+		msg, err := c.pubsub.Receive(ctx)
+		if err != nil {
+			// the subscription is restored by the next receive
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryInterval):
+				continue
+			}
+		}
+
+		switch msg := msg.(type) {
+		case *redis.Message:
+			if generation, err := strconv.ParseInt(msg.Payload, 10, 64); err == nil {
+				c.setGeneration(generation)
+			}
+		case *redis.Subscription:
+			_ = c.loadGeneration(ctx)
+		}
+	}
+}
+
+func (c *Cache) loadGeneration(ctx context.Context) error {
+	generation, err := c.client.Get(ctx, c.prefix+"generation").Int64()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	c.setGeneration(generation)
+
+	return nil
+}
+
+// setGeneration moves to a newer generation, the messages of the older ones arriving late are ignored
+func (c *Cache) setGeneration(generation int64) {
+	for {
+		current := atomic.LoadInt64(&c.generation)
+		if generation <= current || atomic.CompareAndSwapInt64(&c.generation, current, generation) {
+			return
+		}
+	}
+}
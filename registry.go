@@ -0,0 +1,57 @@
+package authority
+
+import (
+	"sort"
+	"sync"
+)
+
+// Registry keeps several instances by name, e.g. one per database or tables prefix.
+// it's safe for concurrent use
+type Registry struct {
+	mu        sync.RWMutex
+	instances map[string]*Authority
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{instances: make(map[string]*Authority)}
+}
+
+// Register adds an instance under the name, replacing the instance registered under the same name
+func (r *Registry) Register(name string, a *Authority) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.instances[name] = a
+}
+
+// Unregister removes the instance registered under the name
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.instances, name)
+}
+
+// Get returns the instance registered under the name
+func (r *Registry) Get(name string) (*Authority, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	a, ok := r.instances[name]
+	return a, ok
+}
+
+// Names returns the sorted names of the registered instances
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.instances))
+	for name := range r.instances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,243 @@
+package authority
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// AuditReplicationConflictResolved is the action recorded on the audit entries of the rows replaced by
+// ResolveConflicts, the detail is the origin of the winning version
+const AuditReplicationConflictResolved = "replication.conflict_resolved"
+
+// ReplicationOptions makes the tables of the bun store safe to replicate across regions, e.g. with the logical
+// replication of postgres, when every region writes to its own database:
+//   - Migrate adds the updated_at and origin columns to the replicated tables, a trigger stamps the rows
+//     written by the region with the time and its origin. the trigger doesn't fire for the rows applied by the
+//     logical replication, they keep the version of the region that wrote them
+//   - with Regions, the ids are allocated from interleaved sequences, the region i allocates the ids equal
+//     to i+1 modulo Regions, so the rows created concurrently by two regions never share an id
+//   - the names are unique per namespace and tenant in every region, creating the same role in two regions makes the
+//     replication fail, the roles and permissions should be created by a single region
+//
+// the replicated tables are roles, permissions, role_permissions, user_roles, role_composites, role_parents,
+// permission_denials, groups, group_members, group_roles and policies. the audit entries, the import batches
+// and the policy version are kept per region
+type ReplicationOptions struct {
+	// Origin names the region, e.g. "eu-west-1", it enables the replication metadata
+	Origin string
+	// Regions is the number of regions writing to the tables and Region the index of this one, from 0 to
+	// Regions-1. the ids are allocated from the usual sequences when Regions is zero
+	Regions int
+	Region  int
+}
+
+// RowVersion is the version of a replicated row, the last writer wins: the latest update, or the greatest
+// origin for updates made at the same time so that every region picks the same one
+type RowVersion struct {
+	UpdatedAt time.Time
+	Origin    string
+}
+
+// After reports whether the version wins over the other one
+func (v RowVersion) After(other RowVersion) bool {
+	if !v.UpdatedAt.Equal(other.UpdatedAt) {
+		return v.UpdatedAt.After(other.UpdatedAt)
+	}
+
+	return v.Origin > other.Origin
+}
+
+// ReplicationConflict is a role or a permission whose two regions have different versions
+type ReplicationConflict struct {
+	// Entity is EntityRole or EntityPermission
+	Entity    string
+	Namespace string
+	Name      string
+	Tenant    string
+	Local     RowVersion
+	Remote    RowVersion
+	// RemoteWon reports whether the local row was replaced by the remote one, the remote row was replaced
+	// by the local one otherwise
+	RemoteWon bool
+}
+
+// Replicator is implemented by the stores keeping the versions of their rows for the replication across regions
+type Replicator interface {
+	// ListRoleVersions returns all the roles with their versions
+	ListRoleVersions(ctx context.Context) ([]Role, error)
+	// ListPermissionVersions returns all the permissions with their versions
+	ListPermissionVersions(ctx context.Context) ([]Permission, error)
+	// ApplyRole sets the title, the description, the metadata, the state and the version of the role with the
+	// same namespace, name and tenant
+	ApplyRole(ctx context.Context, role *Role) error
+	// ApplyPermission sets the title, the condition, the action, the resource, the description, the metadata and
+	// the version of the permission with the same namespace, name and tenant
+	ApplyPermission(ctx context.Context, perm *Permission) error
+}
+
+// ResolveConflicts compares the roles and the permissions with the ones of the store of another region and
+// replaces the ones differing with the last written version, in both stores, e.g. after a network partition
+// during which the regions updated the same roles. the rows missing from one of the stores are left to the
+// replication. it returns ErrNotSupported if one of the stores doesn't implement Replicator, and
+// ErrOperationLocked when another instance resolves them for longer than Options.LockTimeout
+func (a *Authority) ResolveConflicts(remote Store) ([]ReplicationConflict, error) {
+	return a.ResolveConflictsCtx(context.Background(), remote)
+}
+
+// ResolveConflictsCtx is the context-aware variant of ResolveConflicts
+func (a *Authority) ResolveConflictsCtx(ctx context.Context, remote Store) ([]ReplicationConflict, error) {
+	remoteReplicator, ok := remote.(Replicator)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	if _, ok = a.store.(Replicator); !ok {
+		return nil, ErrNotSupported
+	}
+
+	var conflicts []ReplicationConflict
+	resolve := func(ctx context.Context, tx *Authority) error {
+		conflicts = nil
+		local := tx.store.(Replicator)
+
+		localRoles, err := local.ListRoleVersions(ctx)
+		if err != nil {
+			return err
+		}
+
+		var remoteRoles []Role
+		if remoteRoles, err = remoteReplicator.ListRoleVersions(ctx); err != nil {
+			return err
+		}
+
+		roles := make(map[[3]string]Role, len(localRoles))
+		for _, role := range localRoles {
+			roles[[3]string{role.Namespace, role.Name, role.Tenant}] = role
+		}
+
+		for i := range remoteRoles {
+			theirs := &remoteRoles[i]
+			ours, found := roles[[3]string{theirs.Namespace, theirs.Name, theirs.Tenant}]
+			if !found || sameRole(ours, *theirs) {
+				continue
+			}
+
+			conflict := ReplicationConflict{
+				Entity: EntityRole, Namespace: theirs.Namespace, Name: theirs.Name, Tenant: theirs.Tenant,
+				Local: ours.version(), Remote: theirs.version(), RemoteWon: theirs.version().After(ours.version()),
+			}
+			if conflict.RemoteWon {
+				err = local.ApplyRole(ctx, theirs)
+			} else {
+				err = remoteReplicator.ApplyRole(ctx, &ours)
+			}
+			if err != nil {
+				return err
+			}
+
+			if err = tx.resolved(ctx, conflict); err != nil {
+				return err
+			}
+			conflicts = append(conflicts, conflict)
+		}
+
+		var localPerms, remotePerms []Permission
+		if localPerms, err = local.ListPermissionVersions(ctx); err != nil {
+			return err
+		}
+		if remotePerms, err = remoteReplicator.ListPermissionVersions(ctx); err != nil {
+			return err
+		}
+
+		perms := make(map[[3]string]Permission, len(localPerms))
+		for _, perm := range localPerms {
+			perms[[3]string{perm.Namespace, perm.Name, perm.Tenant}] = perm
+		}
+
+		for i := range remotePerms {
+			theirs := &remotePerms[i]
+			ours, found := perms[[3]string{theirs.Namespace, theirs.Name, theirs.Tenant}]
+			if !found || samePermission(ours, *theirs) {
+				continue
+			}
+
+			conflict := ReplicationConflict{
+				Entity: EntityPermission, Namespace: theirs.Namespace, Name: theirs.Name, Tenant: theirs.Tenant,
+				Local: ours.version(), Remote: theirs.version(), RemoteWon: theirs.version().After(ours.version()),
+			}
+			if conflict.RemoteWon {
+				err = local.ApplyPermission(ctx, theirs)
+			} else {
+				err = remoteReplicator.ApplyPermission(ctx, &ours)
+			}
+			if err != nil {
+				return err
+			}
+
+			if err = tx.resolved(ctx, conflict); err != nil {
+				return err
+			}
+			conflicts = append(conflicts, conflict)
+		}
+
+		return nil
+	}
+
+	err := a.exclusive(ctx, "resolve_conflicts", a.lockTimeout, func(ctx context.Context) error {
+		return a.inTx(ctx, resolve)
+	})
+
+	return conflicts, err
+}
+
+// resolved records a resolved conflict
+func (a *Authority) resolved(ctx context.Context, conflict ReplicationConflict) error {
+	winner := conflict.Local.Origin
+	if conflict.RemoteWon {
+		winner = conflict.Remote.Origin
+	}
+
+	entry := AuditEntry{Action: AuditReplicationConflictResolved, Tenant: conflict.Tenant, Detail: winner}
+	if conflict.Entity == EntityRole {
+		entry.Role = conflict.Name
+	} else {
+		entry.Permission = conflict.Name
+	}
+
+	return a.changed(ctx, entry)
+}
+
+// version returns the replication version of the role
+func (r Role) version() RowVersion {
+	return RowVersion{UpdatedAt: r.UpdatedAt, Origin: r.Origin}
+}
+
+// version returns the replication version of the permission
+func (p Permission) version() RowVersion {
+	return RowVersion{UpdatedAt: p.UpdatedAt, Origin: p.Origin}
+}
+
+// sameRole reports whether the replicated fields of the roles are equal
+func sameRole(a, b Role) bool {
+	return a.Title == b.Title && a.Description == b.Description && a.stateOrDefault() == b.stateOrDefault() &&
+		sameMetadata(a.Metadata, b.Metadata)
+}
+
+// samePermission reports whether the replicated fields of the permissions are equal
+func samePermission(a, b Permission) bool {
+	return a.Title == b.Title && a.Condition == b.Condition && a.Action == b.Action && a.Resource == b.Resource &&
+		a.Description == b.Description && sameMetadata(a.Metadata, b.Metadata)
+}
+
+// sameMetadata compares the metadata as they are stored
+func sameMetadata(a, b map[string]interface{}) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+
+	// the keys of the maps are sorted by json
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
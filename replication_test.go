@@ -0,0 +1,38 @@
+package authority_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"authority"
+)
+
+func TestRowVersionAfter(t *testing.T) {
+	now := time.Now()
+	earlyA := authority.RowVersion{UpdatedAt: now, Origin: "a"}
+	earlyB := authority.RowVersion{UpdatedAt: now, Origin: "b"}
+	lateA := authority.RowVersion{UpdatedAt: now.Add(time.Second), Origin: "a"}
+
+	for _, tc := range []struct {
+		v, other authority.RowVersion
+		after    bool
+	}{
+		{lateA, earlyB, true},
+		{earlyB, lateA, false},
+		{earlyB, earlyA, true},
+		{earlyA, earlyA, false},
+	} {
+		if got := tc.v.After(tc.other); got != tc.after {
+			t.Errorf("%+v.After(%+v) = %t, want %t", tc.v, tc.other, got, tc.after)
+		}
+	}
+}
+
+func TestResolveConflictsNeedsReplicator(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+
+	if _, err := a.ResolveConflicts(nil); !errors.Is(err, authority.ErrNotSupported) {
+		t.Fatalf("ResolveConflicts = %v, want ErrNotSupported", err)
+	}
+}
@@ -0,0 +1,201 @@
+package authority
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrUnknownReportFormat is returned when the format of a report is not supported
+var ErrUnknownReportFormat = errors.New("unknown report format")
+
+// ReportFormat is the format of an access report
+type ReportFormat string
+
+// the formats of the access reports
+const (
+	ReportCSV ReportFormat = "csv"
+	// ReportHTML is a standalone page that prints well, e.g. to save it as a PDF
+	ReportHTML ReportFormat = "html"
+)
+
+// AccessReportRow is a role assignment in an access report
+type AccessReportRow struct {
+	// User identifies the user, it's the decimal id of the users identified by a number
+	User       string
+	Role       string
+	Tenant     string
+	AssignedAt time.Time
+	StartsAt   time.Time
+	ExpiresAt  time.Time
+	// Permissions are the permissions the role grants, including its composite and inherited roles
+	Permissions []string
+}
+
+// GenerateAccessReport writes a report of every user, their roles, the permissions the roles grant and the dates
+// of the assignments, e.g. for a quarterly access review
+func (a *Authority) GenerateAccessReport(w io.Writer, format ReportFormat) error {
+	return a.GenerateAccessReportCtx(context.Background(), w, format)
+}
+
+// GenerateAccessReportCtx is the context-aware variant of GenerateAccessReport
+func (a *Authority) GenerateAccessReportCtx(ctx context.Context, w io.Writer, format ReportFormat) error {
+	if format != ReportCSV && format != ReportHTML {
+		return ErrUnknownReportFormat
+	}
+
+	rows, err := a.accessReport(ctx)
+	if err != nil {
+		return err
+	}
+
+	if format == ReportCSV {
+		return writeCSVReport(w, rows)
+	}
+
+	return reportTemplate.Execute(w, struct {
+		GeneratedAt time.Time
+		Rows        []AccessReportRow
+	}{a.now(), rows})
+}
+
+// accessReport returns a row for every role assignment
+func (a *Authority) accessReport(ctx context.Context) ([]AccessReportRow, error) {
+	userRoles, err := a.store.ListUserRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var roles []Role
+	if roles, err = a.store.ListRoles(ctx); err != nil {
+		return nil, err
+	}
+
+	names := make(map[uint]string, len(roles))
+	for _, role := range roles {
+		names[role.ID] = role.Name
+	}
+
+	// the permissions of every role, many users have the same roles
+	perms := make(map[uint][]string)
+
+	rows := make([]AccessReportRow, 0, len(userRoles))
+	for _, ur := range userRoles {
+		if _, ok := perms[ur.RoleID]; !ok {
+			if perms[ur.RoleID], err = a.rolePermissionNames(ctx, ur.RoleID); err != nil {
+				return nil, err
+			}
+		}
+
+		rows = append(rows, AccessReportRow{
+			User:        ur.UserKey,
+			Role:        names[ur.RoleID],
+			Tenant:      ur.Tenant,
+			AssignedAt:  ur.CreatedAt,
+			StartsAt:    ur.StartsAt,
+			ExpiresAt:   ur.ExpiresAt,
+			Permissions: perms[ur.RoleID],
+		})
+	}
+
+	return rows, nil
+}
+
+// rolePermissionNames returns the names of the permissions a role grants, including its composite
+// and inherited roles
+func (a *Authority) rolePermissionNames(ctx context.Context, roleID uint) ([]string, error) {
+	roleIDs, err := a.expandRoles(ctx, []uint{roleID})
+	if err != nil {
+		return nil, err
+	}
+
+	var rolePerms []RolePermission
+	if rolePerms, err = a.activeRolePermissions(ctx, roleIDs); err != nil {
+		return nil, err
+	}
+
+	permIDs := make([]uint, 0, len(rolePerms))
+	for _, rp := range rolePerms {
+		permIDs = append(permIDs, rp.PermissionID)
+	}
+
+	var perms []Permission
+	if perms, err = a.store.GetPermissionsByID(ctx, permIDs); err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(perms))
+	for _, perm := range perms {
+		result = append(result, perm.Name)
+	}
+
+	return result, nil
+}
+
+func writeCSVReport(w io.Writer, rows []AccessReportRow) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"user", "role", "tenant", "assigned_at", "starts_at", "expires_at", "permissions"}); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if err := cw.Write([]string{
+			row.User,
+			row.Role,
+			row.Tenant,
+			reportDate(row.AssignedAt),
+			reportDate(row.StartsAt),
+			reportDate(row.ExpiresAt),
+			strings.Join(row.Permissions, " "),
+		}); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// reportDate formats a date of the report, unknown dates are left empty
+func reportDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{"date": reportDate}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Access report</title>
+<style>
+body { font-family: sans-serif; font-size: 12px; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #999; padding: 4px; text-align: left; vertical-align: top; }
+thead { display: table-header-group; }
+tr { page-break-inside: avoid; }
+</style>
+</head>
+<body>
+<h1>Access report</h1>
+<p>Generated at {{date .GeneratedAt}}</p>
+<table>
+<thead>
+<tr><th>User</th><th>Role</th><th>Tenant</th><th>Assigned at</th><th>Starts at</th><th>Expires at</th><th>Permissions</th></tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr><td>{{.User}}</td><td>{{.Role}}</td><td>{{.Tenant}}</td><td>{{date .AssignedAt}}</td><td>{{date .StartsAt}}</td><td>{{date .ExpiresAt}}</td><td>{{range $i, $p := .Permissions}}{{if $i}}, {{end}}{{$p}}{{end}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,74 @@
+package authority_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"authority"
+)
+
+func TestGenerateAccessReport(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := newAuthority(t, authority.Options{Now: func() time.Time { return now }})
+	setupRole(t, a, "viewer", "articles.read")
+	setupRole(t, a, "editor", "articles.write")
+	must(t, a.AddCompositeRoles("editor", []string{"viewer"}))
+
+	must(t, a.AssignRole(1, "viewer"))
+	must(t, a.AssignRoleUntil(2, "editor", now.Add(24*time.Hour)))
+	must(t, a.User("<b>carol</b>").AssignRole("viewer"))
+
+	var buf bytes.Buffer
+	must(t, a.GenerateAccessReport(&buf, authority.ReportCSV))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	must(t, err)
+	header := []string{"user", "role", "tenant", "assigned_at", "starts_at", "expires_at", "permissions"}
+	if len(records) != 4 || !equalStrings(records[0], header) {
+		t.Fatalf("the CSV report = %q", records)
+	}
+
+	rows := make(map[string][]string, len(records)-1)
+	for _, record := range records[1:] {
+		rows[record[0]] = record
+	}
+
+	if row := rows["1"]; row[1] != "viewer" || row[5] != "" || row[6] != "articles.read" {
+		t.Errorf("the row of the user 1 = %q", row)
+	}
+
+	// the permissions of the composite roles are included
+	row := rows["2"]
+	if row[1] != "editor" || row[5] != now.Add(24*time.Hour).Format(time.RFC3339) {
+		t.Errorf("the row of the user 2 = %q", row)
+	}
+	if perms := strings.Fields(row[6]); len(perms) != 2 || !strings.Contains(row[6], "articles.read") ||
+		!strings.Contains(row[6], "articles.write") {
+		t.Errorf("the permissions of the user 2 = %q, want articles.read and articles.write", row[6])
+	}
+
+	if row = rows["<b>carol</b>"]; row == nil || row[3] == "" {
+		t.Errorf("the row of carol = %q", row)
+	}
+
+	buf.Reset()
+	must(t, a.GenerateAccessReport(&buf, authority.ReportHTML))
+	html := buf.String()
+	for _, want := range []string{"Generated at " + now.Format(time.RFC3339), "<td>editor</td>",
+		"&lt;b&gt;carol&lt;/b&gt;"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("the HTML report doesn't contain %q:\n%s", want, html)
+		}
+	}
+	if strings.Contains(html, "<b>carol</b>") {
+		t.Error("the HTML report doesn't escape the user keys")
+	}
+
+	if err = a.GenerateAccessReport(&buf, "pdf"); !errors.Is(err, authority.ErrUnknownReportFormat) {
+		t.Errorf("GenerateAccessReport in an unknown format = %v, want ErrUnknownReportFormat", err)
+	}
+}
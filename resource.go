@@ -0,0 +1,266 @@
+package authority
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// the actions recorded on the audit entries of the resource permissions, the detail is "type:id"
+const (
+	AuditResourcePermissionGranted = "resource_permission.granted"
+	AuditResourcePermissionRevoked = "resource_permission.revoked"
+)
+
+// CheckKindResource is the kind of the checks of the permissions on a resource in the metrics
+const CheckKindResource = "resource"
+
+// GrantPermissionOnResource grants a permission to a user on a single resource, e.g.
+// GrantPermissionOnResource(7, "edit", "document", 42). the resource id is a number, a string or a fmt.Stringer,
+// it's stored as text. granting it twice is not an error
+func (a *Authority) GrantPermissionOnResource(userID uint, permName string, resourceType string,
+	resourceID interface{}) error {
+	return a.GrantPermissionOnResourceCtx(context.Background(), userID, permName, resourceType, resourceID)
+}
+
+// GrantPermissionOnResourceCtx is the context-aware variant of GrantPermissionOnResource
+func (a *Authority) GrantPermissionOnResourceCtx(ctx context.Context, userID uint, permName string,
+	resourceType string, resourceID interface{}) error {
+	return a.grantOnResource(ctx, ResourcePermission{UserKey: userKey(userID)}, "", permName, resourceType,
+		resourceID)
+}
+
+// RevokePermissionOnResource revokes a permission granted to a user on a resource
+func (a *Authority) RevokePermissionOnResource(userID uint, permName string, resourceType string,
+	resourceID interface{}) error {
+	return a.RevokePermissionOnResourceCtx(context.Background(), userID, permName, resourceType, resourceID)
+}
+
+// RevokePermissionOnResourceCtx is the context-aware variant of RevokePermissionOnResource
+func (a *Authority) RevokePermissionOnResourceCtx(ctx context.Context, userID uint, permName string,
+	resourceType string, resourceID interface{}) error {
+	return a.revokeOnResource(ctx, ResourcePermission{UserKey: userKey(userID)}, "", permName, resourceType,
+		resourceID)
+}
+
+// GrantRolePermissionOnResource grants a permission on a single resource to the users of a role, including
+// the users of the composite roles including it and of the roles inheriting from it
+func (a *Authority) GrantRolePermissionOnResource(roleName string, permName string, resourceType string,
+	resourceID interface{}) error {
+	return a.GrantRolePermissionOnResourceCtx(context.Background(), roleName, permName, resourceType, resourceID)
+}
+
+// GrantRolePermissionOnResourceCtx is the context-aware variant of GrantRolePermissionOnResource
+func (a *Authority) GrantRolePermissionOnResourceCtx(ctx context.Context, roleName string, permName string,
+	resourceType string, resourceID interface{}) error {
+	return a.grantOnResource(ctx, ResourcePermission{}, roleName, permName, resourceType, resourceID)
+}
+
+// RevokeRolePermissionOnResource revokes a permission granted to a role on a resource
+func (a *Authority) RevokeRolePermissionOnResource(roleName string, permName string, resourceType string,
+	resourceID interface{}) error {
+	return a.RevokeRolePermissionOnResourceCtx(context.Background(), roleName, permName, resourceType, resourceID)
+}
+
+// RevokeRolePermissionOnResourceCtx is the context-aware variant of RevokeRolePermissionOnResource
+func (a *Authority) RevokeRolePermissionOnResourceCtx(ctx context.Context, roleName string, permName string,
+	resourceType string, resourceID interface{}) error {
+	return a.revokeOnResource(ctx, ResourcePermission{}, roleName, permName, resourceType, resourceID)
+}
+
+// CheckPermissionOnResource checks if a user has a permission on a resource, either through its roles, which
+// grant the permission on every resource, or through a grant on the resource to the user or to one of its roles.
+// the condition of the permission is evaluated and its denials take precedence like CheckPermission does
+func (a *Authority) CheckPermissionOnResource(userID uint, permName string, resourceType string,
+	resourceID interface{}) (bool, error) {
+	return a.CheckPermissionOnResourceCtx(context.Background(), userID, permName, resourceType, resourceID)
+}
+
+// CheckPermissionOnResourceCtx is the context-aware variant of CheckPermissionOnResource
+func (a *Authority) CheckPermissionOnResourceCtx(ctx context.Context, userID uint, permName string,
+	resourceType string, resourceID interface{}) (bool, error) {
+	return a.checkResourcePermission(ctx, userKey(userID), permName, resourceType, resourceKey(resourceID), "")
+}
+
+// GetResourcePermissions returns the permissions granted on a resource, to the users and to the roles
+func (a *Authority) GetResourcePermissions(resourceType string, resourceID interface{}) ([]ResourcePermission,
+	error) {
+	return a.GetResourcePermissionsCtx(context.Background(), resourceType, resourceID)
+}
+
+// GetResourcePermissionsCtx is the context-aware variant of GetResourcePermissions
+func (a *Authority) GetResourcePermissionsCtx(ctx context.Context, resourceType string, resourceID interface{}) (
+	[]ResourcePermission, error) {
+	return a.store.GetResourcePermissions(ctx, resourceType, resourceKey(resourceID))
+}
+
+// GrantPermissionOnResource grants a permission to the user on a single resource, the grant applies in every
+// tenant like the grants to the users identified by a number
+func (u User) GrantPermissionOnResource(permName string, resourceType string, resourceID interface{}) error {
+	return u.GrantPermissionOnResourceCtx(context.Background(), permName, resourceType, resourceID)
+}
+
+// GrantPermissionOnResourceCtx is the context-aware variant of GrantPermissionOnResource
+func (u User) GrantPermissionOnResourceCtx(ctx context.Context, permName string, resourceType string,
+	resourceID interface{}) error {
+	return u.a.grantOnResource(ctx, ResourcePermission{UserKey: u.key}, "", permName, resourceType, resourceID)
+}
+
+// RevokePermissionOnResource revokes a permission granted to the user on a resource
+func (u User) RevokePermissionOnResource(permName string, resourceType string, resourceID interface{}) error {
+	return u.RevokePermissionOnResourceCtx(context.Background(), permName, resourceType, resourceID)
+}
+
+// RevokePermissionOnResourceCtx is the context-aware variant of RevokePermissionOnResource
+func (u User) RevokePermissionOnResourceCtx(ctx context.Context, permName string, resourceType string,
+	resourceID interface{}) error {
+	return u.a.revokeOnResource(ctx, ResourcePermission{UserKey: u.key}, "", permName, resourceType, resourceID)
+}
+
+// CheckPermissionOnResource checks if the user has a permission on a resource in its tenant,
+// see Authority.CheckPermissionOnResource
+func (u User) CheckPermissionOnResource(permName string, resourceType string, resourceID interface{}) (bool,
+	error) {
+	return u.CheckPermissionOnResourceCtx(context.Background(), permName, resourceType, resourceID)
+}
+
+// CheckPermissionOnResourceCtx is the context-aware variant of CheckPermissionOnResource
+func (u User) CheckPermissionOnResourceCtx(ctx context.Context, permName string, resourceType string,
+	resourceID interface{}) (bool, error) {
+	return u.a.checkResourcePermission(ctx, u.key, permName, resourceType, resourceKey(resourceID), u.tenant)
+}
+
+// grantOnResource grants a permission on a resource to the user of the grant, or to the role when it's named
+func (a *Authority) grantOnResource(ctx context.Context, grant ResourcePermission, roleName string,
+	permName string, resourceType string, resourceID interface{}) error {
+	if err := a.resolveResourceGrant(ctx, &grant, roleName, permName, resourceType, resourceID); err != nil {
+		return err
+	}
+
+	grant.CreatedAt = a.now()
+	if err := a.store.GrantResourcePermission(ctx, &grant); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{
+		Action: AuditResourcePermissionGranted, UserKey: grant.UserKey, Role: roleName, Permission: permName,
+		Detail: grant.ResourceType + ":" + grant.ResourceID,
+	})
+}
+
+// revokeOnResource revokes a permission on a resource from the user of the grant, or from the role when it's named
+func (a *Authority) revokeOnResource(ctx context.Context, grant ResourcePermission, roleName string,
+	permName string, resourceType string, resourceID interface{}) error {
+	if err := a.resolveResourceGrant(ctx, &grant, roleName, permName, resourceType, resourceID); err != nil {
+		return err
+	}
+
+	if err := a.store.RevokeResourcePermission(ctx, &grant); err != nil {
+		return err
+	}
+
+	return a.changed(ctx, AuditEntry{
+		Action: AuditResourcePermissionRevoked, UserKey: grant.UserKey, Role: roleName, Permission: permName,
+		Detail: grant.ResourceType + ":" + grant.ResourceID,
+	})
+}
+
+// resolveResourceGrant sets the ids of the permission, of the role when it's named, and of the resource
+func (a *Authority) resolveResourceGrant(ctx context.Context, grant *ResourcePermission, roleName string,
+	permName string, resourceType string, resourceID interface{}) error {
+	perm, err := a.getPermission(ctx, permName)
+	if err != nil {
+		return err
+	}
+	grant.PermissionID = perm.ID
+
+	if roleName != "" {
+		var role *Role
+		if role, err = a.getRole(ctx, roleName); err != nil {
+			return err
+		}
+		grant.RoleID = role.ID
+	}
+
+	grant.ResourceType, grant.ResourceID = resourceType, resourceKey(resourceID)
+
+	return nil
+}
+
+func (a *Authority) checkResourcePermission(ctx context.Context, user string, permName string,
+	resourceType string, resourceID string, tenant string) (granted bool, err error) {
+	c := &checkLog{kind: CheckKindResource, user: user, tenant: tenant, name: permName, start: time.Now()}
+	defer func() { c.granted, c.err = granted, err; a.logCheck(ctx, c) }()
+	defer a.measureCheck(CheckKindResource, permName, c.start, &granted, &err)
+
+	release, err := a.checks.acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	// the roles grant the permission on every resource
+	var grant permissionGrant
+	if grant, err = a.cachedGrant(ctx, user, permName, tenant, c); err != nil {
+		return false, err
+	}
+
+	if !grant.Granted {
+		c.loaded()
+
+		var perm *Permission
+		if perm, err = a.getTenantPermission(ctx, permName, tenant); err != nil {
+			return false, err
+		}
+
+		var roleIDs []uint
+		if roleIDs, err = a.userRoleIDs(ctx, user, tenant); err != nil {
+			return false, err
+		}
+		if roleIDs, err = a.grantingRoles(ctx, roleIDs); err != nil {
+			return false, err
+		}
+
+		grant.Condition = perm.Condition
+		grant.Granted, err = a.store.HasResourcePermission(ctx, perm.ID, resourceType, resourceID, user, tenant,
+			roleIDs)
+		if err != nil {
+			return false, err
+		}
+
+		// a denial of the permission overrides its grants on the resources too
+		if grant.Granted {
+			var denied bool
+			if denied, err = a.store.IsPermissionDenied(ctx, perm.ID, user, tenant, roleIDs); err != nil {
+				return false, err
+			}
+			grant.Granted = !denied
+		}
+	}
+
+	granted = grant.Granted
+	if granted && grant.Condition != "" {
+		if granted, err = a.conditions.eval(ctx, grant.Condition, user); err != nil {
+			return false, err
+		}
+	}
+
+	if err = a.auditCheck(ctx, user, permName, tenant, granted); err != nil {
+		return false, err
+	}
+
+	if !granted {
+		a.bursts.denied(ctx, a, user, permName)
+	}
+
+	return granted, nil
+}
+
+// resourceKey returns the text form of a resource id
+func resourceKey(resourceID interface{}) string {
+	if s, ok := resourceID.(string); ok {
+		return s
+	}
+
+	return fmt.Sprint(resourceID)
+}
@@ -0,0 +1,59 @@
+package authority_test
+
+import (
+	"errors"
+	"testing"
+
+	"authority"
+)
+
+func TestAssignRoleToSegment(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	setupRole(t, a, "beta", "reports.v2")
+
+	if err := a.AssignRoleToSegment("beta", 101, "reports"); !errors.Is(err, authority.ErrInvalidSegment) {
+		t.Fatalf("AssignRoleToSegment = %v, want ErrInvalidSegment", err)
+	}
+
+	granted := func() map[uint]bool {
+		t.Helper()
+
+		users := make(map[uint]bool)
+		for id := uint(1); id <= 1000; id++ {
+			ok, err := a.CheckPermission(id, "reports.v2")
+			must(t, err)
+			if ok {
+				users[id] = true
+			}
+		}
+
+		return users
+	}
+
+	must(t, a.AssignRoleToSegment("beta", 10, "reports"))
+	small := granted()
+	if len(small) < 50 || len(small) > 150 {
+		t.Fatalf("%d users of 1000 are in the segment of 10%%", len(small))
+	}
+	for id := range small {
+		if !authority.InSegment(id, 10, "reports") {
+			t.Fatalf("the user %d is granted the role outside of the segment", id)
+		}
+	}
+
+	// the users stay in the segment while it grows
+	must(t, a.AssignRoleToSegment("beta", 50, "reports"))
+	large := granted()
+	for id := range small {
+		if !large[id] {
+			t.Fatalf("the user %d left the segment while it grew", id)
+		}
+	}
+
+	// a segment of 0 rolls back to the assignments
+	must(t, a.AssignRole(1, "beta"))
+	must(t, a.AssignRoleToSegment("beta", 0, "reports"))
+	if users := granted(); len(users) != 1 || !users[1] {
+		t.Fatalf("the users %v are granted the role after the rollback", users)
+	}
+}
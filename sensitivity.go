@@ -0,0 +1,104 @@
+package authority
+
+import (
+	"context"
+	"time"
+)
+
+// the sensitivity classes of the permissions suggested for Options.PermissionClass, any name can be used
+const (
+	ClassPublic     = "public"
+	ClassInternal   = "internal"
+	ClassPrivileged = "privileged"
+)
+
+// AuditPermissionChecked is the action recorded for the checks of the permissions whose class audits them
+const AuditPermissionChecked = "permission.checked"
+
+// AuditLevel is what's recorded about the permissions of a sensitivity class
+type AuditLevel int
+
+const (
+	// AuditDefault records the changes when Options.Audit is set
+	AuditDefault AuditLevel = iota
+	// AuditOff records nothing
+	AuditOff
+	// AuditChanges records the changes, even when Options.Audit is not set
+	AuditChanges
+	// AuditChecks records the changes and the result of every check
+	AuditChecks
+)
+
+// SensitivityClass configures how the permissions of a class are checked and audited, e.g. a privileged class
+// whose checks are never served from the cache and are all recorded:
+//
+//	SensitivityClasses: map[string]authority.SensitivityClass{
+//		authority.ClassPrivileged: {CacheTTL: -1, Audit: authority.AuditChecks, RequireApproval: true},
+//	}
+type SensitivityClass struct {
+	// CacheTTL replaces Options.CacheTTL for the check results of the permissions when the cache is enabled,
+	// they are not cached when it's negative
+	CacheTTL time.Duration
+	// StaleWindow replaces Options.StaleWhileRevalidate, the expired results are never served when it's zero
+	StaleWindow time.Duration
+	// Audit is what's recorded about the changes of the permissions and their checks
+	Audit AuditLevel
+	// RequireApproval marks the permissions that must be approved before they are granted, it's reported by
+	// RequiresApproval for the workflows granting access
+	RequireApproval bool
+}
+
+// permissionClass returns the sensitivity class of a permission, false when it has none configured
+func (s *staleness) permissionClass(permName string) (SensitivityClass, bool) {
+	if s.classOf == nil {
+		return SensitivityClass{}, false
+	}
+
+	class, ok := s.classes[s.classOf(permName)]
+
+	return class, ok
+}
+
+// checkPolicy returns how the check results of a permission are cached
+func (s *staleness) checkPolicy(permName string) cachePolicy {
+	if class, ok := s.permissionClass(permName); ok {
+		return cachePolicy{ttl: class.CacheTTL, window: class.StaleWindow}
+	}
+
+	return cachePolicy{window: s.window}
+}
+
+// RequiresApproval reports whether the sensitivity class of a permission requires an approval to grant it
+func (a *Authority) RequiresApproval(permName string) bool {
+	class, _ := a.stale.permissionClass(permName)
+	return class.RequireApproval
+}
+
+// audits reports whether a change is recorded, the changes of a permission follow the level of its class
+func (a *Authority) audits(entry AuditEntry) bool {
+	if entry.Permission != "" {
+		if class, ok := a.stale.permissionClass(entry.Permission); ok && class.Audit != AuditDefault {
+			return class.Audit != AuditOff
+		}
+	}
+
+	return a.auditEnabled
+}
+
+// auditCheck records the result of a check when the class of the permission audits the checks
+func (a *Authority) auditCheck(ctx context.Context, user string, permName string, tenant string, granted bool) error {
+	if class, ok := a.stale.permissionClass(permName); !ok || class.Audit != AuditChecks {
+		return nil
+	}
+
+	detail := "denied"
+	if granted {
+		detail = "granted"
+	}
+
+	entry := a.describe(ctx, AuditEntry{
+		Action: AuditPermissionChecked, UserKey: user, Permission: permName, Tenant: tenant, Detail: detail,
+	})
+
+	return a.store.CreateAuditEntry(ctx, &entry)
+}
@@ -0,0 +1,556 @@
+package authority
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshots Export writes
+const SnapshotVersion = 1
+
+// AuditSnapshotImported is the action recorded when a snapshot is imported
+const AuditSnapshotImported = "snapshot.imported"
+
+var (
+	// ErrUnknownSnapshotVersion is returned when a snapshot was written by a newer version of the package
+	ErrUnknownSnapshotVersion = errors.New("unknown snapshot version")
+	// ErrNoPseudonymKey is returned when the users of a snapshot are pseudonymized without a key
+	ErrNoPseudonymKey = errors.New("pseudonymization needs a key")
+)
+
+// ImportMode tells Import what to do with the stored data
+type ImportMode int
+
+const (
+	// ImportMerge adds what's missing and keeps the rest, the existing roles and permissions keep their
+	// title and condition and a role's parent is replaced by the one of the snapshot
+	ImportMerge ImportMode = iota
+	// ImportReplace deletes everything first, the storage ends up holding exactly the snapshot
+	ImportReplace
+)
+
+// PolicySnapshot is a dump of the roles, the permissions, their assignments and the policies that can be encoded
+// as JSON. the rows refer to each other by name instead of id so a snapshot can be restored in another database,
+// e.g. to promote the roles of a staging environment or to seed the tests. a role or a permission is looked up in
+// the tenant of the row first and then among the global ones
+type PolicySnapshot struct {
+	Version         int                      `json:"version"`
+	TakenAt         time.Time                `json:"taken_at"`
+	Roles           []SnapshotRole           `json:"roles"`
+	Permissions     []SnapshotPermission     `json:"permissions"`
+	RolePermissions []SnapshotRolePermission `json:"role_permissions"`
+	UserRoles       []SnapshotUserRole       `json:"user_roles"`
+	Composites      []SnapshotRoleRelation   `json:"composites"`
+	Parents         []SnapshotRoleRelation   `json:"parents"`
+	Policies        []SnapshotPolicy         `json:"policies"`
+}
+
+// SnapshotRole is a role of a snapshot
+type SnapshotRole struct {
+	Name        string                 `json:"name"`
+	Title       string                 `json:"title,omitempty"`
+	Tenant      string                 `json:"tenant,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	State       string                 `json:"state,omitempty"`
+}
+
+// SnapshotPermission is a permission of a snapshot
+type SnapshotPermission struct {
+	Name        string                 `json:"name"`
+	Title       string                 `json:"title,omitempty"`
+	Tenant      string                 `json:"tenant,omitempty"`
+	Condition   string                 `json:"condition,omitempty"`
+	Action      string                 `json:"action,omitempty"`
+	Resource    string                 `json:"resource,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// SnapshotRolePermission is a permission assigned to a role, Tenant is the tenant of the role
+type SnapshotRolePermission struct {
+	Role       string     `json:"role"`
+	Permission string     `json:"permission"`
+	Tenant     string     `json:"tenant,omitempty"`
+	StartsAt   *time.Time `json:"starts_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// SnapshotUserRole is a role assigned to a user, Tenant is the tenant of the assignment
+type SnapshotUserRole struct {
+	User       string     `json:"user"`
+	Role       string     `json:"role"`
+	Tenant     string     `json:"tenant,omitempty"`
+	StartsAt   *time.Time `json:"starts_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	AssignedAt *time.Time `json:"assigned_at,omitempty"`
+	GrantedBy  string     `json:"granted_by,omitempty"`
+}
+
+// SnapshotRoleRelation relates two roles, a composite role and an included role or a role and its parent.
+// Tenant is the tenant of the first role
+type SnapshotRoleRelation struct {
+	Role    string `json:"role"`
+	Related string `json:"related"`
+	Tenant  string `json:"tenant,omitempty"`
+}
+
+// SnapshotPolicy is a named policy of a snapshot
+type SnapshotPolicy struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+// Export returns a snapshot of all the roles, permissions, assignments and policies
+func (a *Authority) Export() (*PolicySnapshot, error) {
+	return a.ExportCtx(context.Background())
+}
+
+// ExportCtx is the context-aware variant of Export
+func (a *Authority) ExportCtx(ctx context.Context) (*PolicySnapshot, error) {
+	snapshot := &PolicySnapshot{Version: SnapshotVersion, TakenAt: a.now()}
+
+	roles, err := a.store.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roleIDs := make([]uint, 0, len(roles))
+	roleByID := make(map[uint]Role, len(roles))
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.ID)
+		roleByID[role.ID] = role
+		snapshot.Roles = append(snapshot.Roles, SnapshotRole{
+			Name: role.Name, Title: role.Title, Tenant: role.Tenant, Description: role.Description,
+			Metadata: role.Metadata, State: role.State,
+		})
+	}
+
+	var perms []Permission
+	if perms, err = a.store.ListPermissions(ctx); err != nil {
+		return nil, err
+	}
+
+	permNames := make(map[uint]string, len(perms))
+	for _, perm := range perms {
+		permNames[perm.ID] = perm.Name
+		snapshot.Permissions = append(snapshot.Permissions, SnapshotPermission{
+			Name: perm.Name, Title: perm.Title, Tenant: perm.Tenant, Condition: perm.Condition,
+			Action: perm.Action, Resource: perm.Resource, Description: perm.Description, Metadata: perm.Metadata,
+		})
+	}
+
+	var rolePerms []RolePermission
+	if rolePerms, err = a.store.GetRolePermissions(ctx, roleIDs); err != nil {
+		return nil, err
+	}
+
+	for _, rp := range rolePerms {
+		role := roleByID[rp.RoleID]
+		snapshot.RolePermissions = append(snapshot.RolePermissions, SnapshotRolePermission{
+			Role: role.Name, Permission: permNames[rp.PermissionID], Tenant: role.Tenant,
+			StartsAt: snapshotTime(rp.StartsAt), ExpiresAt: snapshotTime(rp.ExpiresAt),
+		})
+	}
+
+	var userRoles []UserRole
+	if userRoles, err = a.store.ListUserRoles(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, ur := range userRoles {
+		snapshot.UserRoles = append(snapshot.UserRoles, SnapshotUserRole{
+			User: ur.UserKey, Role: roleByID[ur.RoleID].Name, Tenant: ur.Tenant,
+			StartsAt: snapshotTime(ur.StartsAt), ExpiresAt: snapshotTime(ur.ExpiresAt),
+			AssignedAt: snapshotTime(ur.CreatedAt), GrantedBy: ur.GrantedBy,
+		})
+	}
+
+	var composites []RoleComposite
+	if composites, err = a.store.GetRoleComposites(ctx, roleIDs); err != nil {
+		return nil, err
+	}
+
+	for _, rc := range composites {
+		role := roleByID[rc.RoleID]
+		snapshot.Composites = append(snapshot.Composites, SnapshotRoleRelation{
+			Role: role.Name, Related: roleByID[rc.MemberID].Name, Tenant: role.Tenant,
+		})
+	}
+
+	var parents []RoleParent
+	if parents, err = a.store.GetRoleParents(ctx, roleIDs); err != nil {
+		return nil, err
+	}
+
+	for _, rp := range parents {
+		role := roleByID[rp.RoleID]
+		snapshot.Parents = append(snapshot.Parents, SnapshotRoleRelation{
+			Role: role.Name, Related: roleByID[rp.ParentID].Name, Tenant: role.Tenant,
+		})
+	}
+
+	var policies []Policy
+	if policies, err = a.store.ListPolicies(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, policy := range policies {
+		snapshot.Policies = append(snapshot.Policies, SnapshotPolicy{Name: policy.Name, Expression: policy.Expression})
+	}
+
+	return snapshot, nil
+}
+
+// Import restores a snapshot atomically, merging it with the stored data or replacing it depending on the mode.
+// the policies are validated against the roles and permissions once they are imported
+func (a *Authority) Import(snapshot *PolicySnapshot, mode ImportMode) error {
+	return a.ImportCtx(context.Background(), snapshot, mode)
+}
+
+// ImportCtx is the context-aware variant of Import
+func (a *Authority) ImportCtx(ctx context.Context, snapshot *PolicySnapshot, mode ImportMode) error {
+	if snapshot.Version > SnapshotVersion {
+		return fmt.Errorf("%w: %d", ErrUnknownSnapshotVersion, snapshot.Version)
+	}
+
+	err := a.importTx(ctx, ImportKindSnapshot, func(ctx context.Context, tx *Authority) (int, error) {
+		if mode == ImportReplace {
+			if err := tx.clear(ctx); err != nil {
+				return 0, err
+			}
+		}
+
+		items := len(snapshot.Roles) + len(snapshot.Permissions) + len(snapshot.RolePermissions) +
+			len(snapshot.UserRoles) + len(snapshot.Composites) + len(snapshot.Parents) + len(snapshot.Policies)
+
+		p := tx.startProgress(ctx, ImportKindSnapshot, items)
+		if err := tx.importSnapshot(ctx, snapshot, p); err != nil {
+			return 0, p.fail(err)
+		}
+
+		if err := tx.notifyPolicies(ctx); err != nil {
+			return 0, p.fail(err)
+		}
+
+		return items, tx.changed(ctx, AuditEntry{
+			Action: AuditSnapshotImported,
+			Detail: fmt.Sprintf("%d roles, %d permissions, %d assignments", len(snapshot.Roles),
+				len(snapshot.Permissions), len(snapshot.UserRoles)),
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	// the policies loaded in memory are replaced once the import is committed
+	if _, loaded := a.policies.get(""); loaded {
+		return a.ReloadPolicies(ctx)
+	}
+
+	return nil
+}
+
+// clear deletes all the roles, permissions, assignments and policies
+func (a *Authority) clear(ctx context.Context) error {
+	userRoles, err := a.store.ListUserRoles(ctx)
+	if err != nil {
+		return err
+	}
+
+	var roles []Role
+	if roles, err = a.store.ListRoles(ctx); err != nil {
+		return err
+	}
+
+	roleIDs := make([]uint, 0, len(roles))
+	for _, role := range roles {
+		roleIDs = append(roleIDs, role.ID)
+	}
+
+	var composites []RoleComposite
+	if composites, err = a.store.GetRoleComposites(ctx, roleIDs); err != nil {
+		return err
+	}
+
+	var perms []Permission
+	if perms, err = a.store.ListPermissions(ctx); err != nil {
+		return err
+	}
+
+	var policies []Policy
+	if policies, err = a.store.ListPolicies(ctx); err != nil {
+		return err
+	}
+
+	// everything is listed first to report the progress of the deletions
+	p := a.startProgress(ctx, OperationClear, len(userRoles)+len(composites)+len(roles)+len(perms)+len(policies))
+
+	for _, ur := range userRoles {
+		if err = a.store.RevokeRole(ctx, ur.UserKey, ur.RoleID, ur.Tenant); err != nil {
+			return p.fail(err)
+		}
+		p.add(1)
+	}
+
+	for _, rc := range composites {
+		if err = a.store.RemoveRoleComposite(ctx, rc.RoleID, rc.MemberID); err != nil {
+			return p.fail(err)
+		}
+		p.add(1)
+	}
+
+	for _, role := range roles {
+		if err = a.store.RemoveRoleParent(ctx, role.ID); err != nil {
+			return p.fail(err)
+		}
+		if err = a.store.RevokeRolePermissions(ctx, role.ID); err != nil {
+			return p.fail(err)
+		}
+		if err = a.store.DeleteRole(ctx, role.ID); err != nil {
+			return p.fail(err)
+		}
+		p.add(1)
+	}
+
+	for _, perm := range perms {
+		if err = a.store.DeletePermission(ctx, perm.ID); err != nil {
+			return p.fail(err)
+		}
+		p.add(1)
+	}
+
+	for _, policy := range policies {
+		if err = a.store.DeletePolicy(ctx, policy.Name); err != nil {
+			return p.fail(err)
+		}
+		p.add(1)
+	}
+
+	// the following lookups must not find the deleted rows
+	return a.purgeCache(ctx)
+}
+
+// importSnapshot adds the rows of the snapshot that are missing, counting every row of the snapshot on prog
+func (a *Authority) importSnapshot(ctx context.Context, snapshot *PolicySnapshot, prog *progress) error {
+	for _, r := range snapshot.Roles {
+		// the lookup falls back to the global role of the same name
+		role, err := a.store.GetRole(ctx, r.Name, r.Tenant)
+		if errors.Is(err, ErrRoleNotFound) || err == nil && role.Tenant != r.Tenant {
+			err = a.store.CreateRole(ctx, &Role{
+				Name: r.Name, Title: r.Title, Tenant: r.Tenant, Description: r.Description, Metadata: r.Metadata,
+				State: r.State,
+			})
+		}
+		if err != nil {
+			return err
+		}
+		prog.add(1)
+	}
+
+	for _, p := range snapshot.Permissions {
+		perm, err := a.store.GetPermission(ctx, p.Name, p.Tenant)
+		if errors.Is(err, ErrPermissionNotFound) || err == nil && perm.Tenant != p.Tenant {
+			err = a.store.CreatePermission(ctx, &Permission{
+				Name: p.Name, Title: p.Title, Tenant: p.Tenant, Condition: p.Condition,
+				Action: p.Action, Resource: p.Resource, Description: p.Description, Metadata: p.Metadata,
+			})
+		}
+		if err != nil {
+			return err
+		}
+		prog.add(1)
+	}
+
+	// the roles and permissions created above must be found by the lookups
+	if err := a.purgeCache(ctx); err != nil {
+		return err
+	}
+
+	for _, rp := range snapshot.RolePermissions {
+		role, err := a.getTenantRole(ctx, rp.Role, rp.Tenant)
+		if err != nil {
+			return err
+		}
+
+		var perm *Permission
+		if perm, err = a.getTenantPermission(ctx, rp.Permission, rp.Tenant); err != nil {
+			return err
+		}
+
+		err = a.store.AssignPermission(ctx, &RolePermission{
+			RoleID: role.ID, PermissionID: perm.ID, StartsAt: timeOf(rp.StartsAt), ExpiresAt: timeOf(rp.ExpiresAt),
+		})
+		if err != nil && !errors.Is(err, ErrPermissionAlreadyAssigned) {
+			return err
+		}
+		prog.add(1)
+	}
+
+	userRoles := make([]UserRole, 0, len(snapshot.UserRoles))
+	for _, ur := range snapshot.UserRoles {
+		role, err := a.getTenantRole(ctx, ur.Role, ur.Tenant)
+		if err != nil {
+			return err
+		}
+
+		userRoles = append(userRoles, UserRole{
+			UserID: userIDOf(ur.User), UserKey: ur.User, RoleID: role.ID, Tenant: ur.Tenant,
+			StartsAt: timeOf(ur.StartsAt), ExpiresAt: timeOf(ur.ExpiresAt), CreatedAt: timeOf(ur.AssignedAt),
+			GrantedBy: ur.GrantedBy,
+		})
+	}
+
+	// the existing assignments are skipped
+	if err := a.store.AssignRoles(ctx, userRoles); err != nil {
+		return err
+	}
+	prog.add(len(userRoles))
+
+	for _, rc := range snapshot.Composites {
+		role, member, err := a.relatedRoles(ctx, rc)
+		if err != nil {
+			return err
+		}
+
+		if err = a.store.AddRoleComposite(ctx, role.ID, member.ID); err != nil {
+			return err
+		}
+		prog.add(1)
+	}
+
+	for _, rp := range snapshot.Parents {
+		role, parent, err := a.relatedRoles(ctx, rp)
+		if err != nil {
+			return err
+		}
+
+		if err = a.store.SetRoleParent(ctx, role.ID, parent.ID); err != nil {
+			return err
+		}
+		prog.add(1)
+	}
+
+	if len(snapshot.Policies) == 0 {
+		return nil
+	}
+
+	known, err := a.knownNames(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range snapshot.Policies {
+		if _, err = compilePolicy(p.Expression, known); err != nil {
+			return fmt.Errorf("policy %s: %w", p.Name, err)
+		}
+
+		if err = a.store.SavePolicy(ctx, &Policy{Name: p.Name, Expression: p.Expression}); err != nil {
+			return err
+		}
+		prog.add(1)
+	}
+
+	return nil
+}
+
+// relatedRoles returns the two roles of a relation
+func (a *Authority) relatedRoles(ctx context.Context, rel SnapshotRoleRelation) (*Role, *Role, error) {
+	role, err := a.getTenantRole(ctx, rel.Role, rel.Tenant)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var related *Role
+	if related, err = a.getTenantRole(ctx, rel.Related, rel.Tenant); err != nil {
+		return nil, nil, err
+	}
+
+	return role, related, nil
+}
+
+// snapshotTime returns the time of a snapshot row, nil when it's zero
+func snapshotTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// timeOf returns the time of a snapshot row, the zero time when it's nil
+func timeOf(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return *t
+}
+
+// ExportPseudonymized is like Export with the users replaced by pseudonyms, e.g. to copy the roles of production
+// into staging for load tests without leaking who has them. see PolicySnapshot.Pseudonymize
+func (a *Authority) ExportPseudonymized(key []byte) (*PolicySnapshot, error) {
+	return a.ExportPseudonymizedCtx(context.Background(), key)
+}
+
+// ExportPseudonymizedCtx is the context-aware variant of ExportPseudonymized
+func (a *Authority) ExportPseudonymizedCtx(ctx context.Context, key []byte) (*PolicySnapshot, error) {
+	if len(key) == 0 {
+		return nil, ErrNoPseudonymKey
+	}
+
+	snapshot, err := a.ExportCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = snapshot.Pseudonymize(key); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// Pseudonymize replaces the users of the assignments and the actors who granted them with pseudonyms derived from
+// the key, the same user always gets the same pseudonym so the structure of the snapshot is kept.
+// the users identified by a number get a number and the others a string. the key must stay secret, the numeric ids
+// are few enough to be guessed from their pseudonyms otherwise
+func (s *PolicySnapshot) Pseudonymize(key []byte) error {
+	if len(key) == 0 {
+		return ErrNoPseudonymKey
+	}
+
+	for i := range s.UserRoles {
+		s.UserRoles[i].User = pseudonym(key, s.UserRoles[i].User)
+		if s.UserRoles[i].GrantedBy != "" {
+			s.UserRoles[i].GrantedBy = pseudonym(key, s.UserRoles[i].GrantedBy)
+		}
+	}
+
+	return nil
+}
+
+// pseudonymBits is the size of the numeric pseudonyms, large enough to make collisions unlikely among millions
+// of users and small enough to be exact in JSON numbers
+const pseudonymBits = 48
+
+// pseudonym returns the pseudonym of a user key, a number for the numeric keys
+func pseudonym(key []byte, user string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(user))
+	sum := mac.Sum(nil)
+
+	if userIDOf(user) != 0 {
+		// never 0, which is not a user id
+		return strconv.FormatUint(binary.BigEndian.Uint64(sum)>>(64-pseudonymBits)+1, 10)
+	}
+
+	return "u_" + hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,102 @@
+package authority_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"authority"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	src := newAuthority(t, authority.Options{})
+	setupRole(t, src, "viewer", "articles.read")
+	setupRole(t, src, "editor", "articles.write")
+	setupRole(t, src, "publisher", "articles.publish")
+	must(t, src.SetPermissionCondition("articles.publish", "attrs.draft == false"))
+	must(t, src.AddCompositeRoles("publisher", []string{"editor"}))
+	must(t, src.SetRoleParent("editor", "viewer"))
+	must(t, src.CreateRoleInTenant("billing", "acme"))
+	must(t, src.CreatePermissionInTenant("invoices.read", "acme"))
+	must(t, src.AssignPermissionsInTenant("billing", []string{"invoices.read"}, "acme"))
+	must(t, src.DefinePolicy("can_publish", "articles.write AND publisher"))
+
+	must(t, src.AssignRole(1, "publisher"))
+	must(t, src.AssignRoleUntil(2, "editor", time.Now().Add(time.Hour).Truncate(time.Second)))
+	must(t, src.AssignRoleInTenant(3, "billing", "acme"))
+	must(t, src.User("alice").AssignRole("viewer"))
+
+	exported, err := src.Export()
+	must(t, err)
+
+	// the snapshot goes through its json encoding like when it's promoted to another environment
+	b, err := json.Marshal(exported)
+	must(t, err)
+	var snapshot authority.PolicySnapshot
+	must(t, json.Unmarshal(b, &snapshot))
+
+	dst := newAuthority(t, authority.Options{})
+	setupRole(t, dst, "obsolete", "obsolete.permission")
+	must(t, dst.AssignRole(9, "obsolete"))
+	must(t, dst.Import(&snapshot, authority.ImportReplace))
+
+	reexported, err := dst.Export()
+	must(t, err)
+	exported.TakenAt, reexported.TakenAt = time.Time{}, time.Time{}
+	for _, s := range []*authority.PolicySnapshot{exported, reexported} {
+		for i := range s.UserRoles {
+			s.UserRoles[i].AssignedAt = nil
+		}
+	}
+	want, err := json.Marshal(exported)
+	must(t, err)
+	got, err := json.Marshal(reexported)
+	must(t, err)
+	if string(got) != string(want) {
+		t.Errorf("the imported snapshot = %s, want %s", got, want)
+	}
+
+	// the imported assignments grant the same access
+	checks := []struct {
+		userID   uint
+		permName string
+		tenant   string
+		want     bool
+	}{
+		{userID: 1, permName: "articles.write", want: true},
+		{userID: 1, permName: "articles.read", want: true},
+		{userID: 2, permName: "articles.read", want: true},
+		{userID: 2, permName: "articles.publish"},
+		{userID: 3, permName: "invoices.read", tenant: "acme", want: true},
+		{userID: 9, permName: "articles.read"},
+	}
+	for _, c := range checks {
+		granted, err := dst.CheckPermissionInTenant(c.userID, c.permName, c.tenant)
+		if err != nil || granted != c.want {
+			t.Errorf("CheckPermissionInTenant(%d, %s, %q) = %v, %v, want %v", c.userID, c.permName, c.tenant,
+				granted, err, c.want)
+		}
+	}
+	if granted, err := dst.User("alice").CheckPermission("articles.read"); err != nil || !granted {
+		t.Errorf("CheckPermission of the string user = %v, %v, want true", granted, err)
+	}
+	if granted, err := dst.CheckPolicy(1, "can_publish"); err != nil || !granted {
+		t.Errorf("CheckPolicy of the imported policy = %v, %v, want true", granted, err)
+	}
+
+	// ImportReplace dropped what the snapshot doesn't have
+	if _, err = dst.GetRole("obsolete"); err == nil {
+		t.Error("GetRole of a role missing from the replacing snapshot: want an error")
+	}
+
+	// and a merge keeps it
+	merged := newAuthority(t, authority.Options{})
+	setupRole(t, merged, "obsolete", "obsolete.permission")
+	must(t, merged.Import(&snapshot, authority.ImportMerge))
+	if _, err = merged.GetRole("obsolete"); err != nil {
+		t.Errorf("GetRole of a role kept by the merge: %v", err)
+	}
+	if granted, err := merged.CheckPermission(1, "articles.write"); err != nil || !granted {
+		t.Errorf("CheckPermission after the merge = %v, %v, want true", granted, err)
+	}
+}
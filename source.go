@@ -0,0 +1,134 @@
+package authority
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// the sources of the assignments
+const (
+	SourceManual    = "manual"
+	SourceSCIM      = "scim"
+	SourceGroupSync = "group_sync"
+	SourceTemplate  = "template"
+)
+
+type assignmentSourceKey struct{}
+
+// WithAssignmentSource returns a copy of the context carrying the source making the assignments, e.g. SourceSCIM
+// for a SCIM endpoint. every assignment made with it records a claim of the source, including the assignments
+// the user already had. the assignments made without a source are claimed by SourceManual
+func WithAssignmentSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, assignmentSourceKey{}, source)
+}
+
+// AssignmentSourceFromContext returns the source carried by the context, SourceManual when there is none
+func AssignmentSourceFromContext(ctx context.Context) string {
+	if source, ok := ctx.Value(assignmentSourceKey{}).(string); ok && source != "" {
+		return source
+	}
+
+	return SourceManual
+}
+
+// AssignmentConflict is an assignment claimed by several sources, revoking it because one source doesn't want it
+// anymore would drop the access another one grants
+type AssignmentConflict struct {
+	UserKey string
+	Role    string
+	Tenant  string
+	// Sources are the sources claiming the assignment sorted by name
+	Sources []string
+	// ClaimedAt is when each source claimed the assignment last
+	ClaimedAt map[string]time.Time
+}
+
+// GetAssignmentConflicts returns the assignments claimed by several sources so that their owner is settled before
+// a source prunes the assignments it doesn't claim. the claims are dropped when the role is revoked, the
+// assignments made before the claims were recorded have none
+func (a *Authority) GetAssignmentConflicts() ([]AssignmentConflict, error) {
+	return a.GetAssignmentConflictsCtx(context.Background())
+}
+
+// GetAssignmentConflictsCtx is the context-aware variant of GetAssignmentConflicts
+func (a *Authority) GetAssignmentConflictsCtx(ctx context.Context) ([]AssignmentConflict, error) {
+	claims, err := a.store.ListConflictingClaims(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	roleIDs := make([]uint, 0, len(claims))
+	for _, c := range claims {
+		roleIDs = append(roleIDs, c.RoleID)
+	}
+
+	roles, err := a.store.GetRolesByID(ctx, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uint]string, len(roles))
+	for _, role := range roles {
+		names[role.ID] = role.Name
+	}
+
+	type assignment struct {
+		user   string
+		roleID uint
+		tenant string
+	}
+
+	var result []AssignmentConflict
+	index := make(map[assignment]int)
+	for _, c := range claims {
+		key := assignment{c.UserKey, c.RoleID, c.Tenant}
+		i, ok := index[key]
+		if !ok {
+			i = len(result)
+			index[key] = i
+			result = append(result, AssignmentConflict{
+				UserKey: c.UserKey, Role: names[c.RoleID], Tenant: c.Tenant, ClaimedAt: make(map[string]time.Time),
+			})
+		}
+		result[i].Sources = append(result[i].Sources, c.Source)
+		result[i].ClaimedAt[c.Source] = c.ClaimedAt
+	}
+
+	for i := range result {
+		sort.Strings(result[i].Sources)
+	}
+
+	return result, nil
+}
+
+// claim records the claims of the source of the context over the assignments
+func (a *Authority) claim(ctx context.Context, userRoles []UserRole) error {
+	if len(userRoles) == 0 {
+		return nil
+	}
+
+	type assignment struct {
+		user   string
+		roleID uint
+		tenant string
+	}
+
+	source, now := AssignmentSourceFromContext(ctx), a.now()
+	claims := make([]AssignmentClaim, 0, len(userRoles))
+	seen := make(map[assignment]bool, len(userRoles))
+	for _, ur := range userRoles {
+		// a claim is inserted once per statement
+		key := assignment{ur.UserKey, ur.RoleID, ur.Tenant}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		claims = append(claims, AssignmentClaim{
+			UserKey: ur.UserKey, RoleID: ur.RoleID, Tenant: ur.Tenant, Source: source, ClaimedAt: now,
+		})
+	}
+
+	return a.store.ClaimAssignments(ctx, claims)
+}
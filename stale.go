@@ -0,0 +1,145 @@
+package authority
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// staleness has the stale-while-revalidate settings of the check results
+type staleness struct {
+	window  time.Duration
+	classOf func(permName string) string
+	classes map[string]SensitivityClass
+
+	// refreshing has the keys being refreshed in the background
+	refreshing sync.Map
+	// generation changes on every purge, a refresh started before a purge doesn't store its stale result
+	generation uint64
+}
+
+func newStaleness(opts Options) *staleness {
+	return &staleness{
+		window:  opts.StaleWhileRevalidate,
+		classOf: opts.PermissionClass,
+		classes: opts.SensitivityClasses,
+	}
+}
+
+// cachePolicy is how long a check result is cached, a negative ttl disables the cache and a zero ttl is the ttl
+// of the instance. the result is served for the window after its ttl while it's refreshed
+type cachePolicy struct {
+	ttl    time.Duration
+	window time.Duration
+}
+
+// staleEntry is a cached value with the time it stops being fresh, the fields are exported for the cache
+type staleEntry[T any] struct {
+	Value      T
+	FreshUntil time.Time
+}
+
+// cachedStale is like cached but the value is kept for the window of the policy after its ttl, a value found
+// in the window is returned and reloaded in the background
+func cachedStale[T any](ctx context.Context, a *Authority, key string, policy cachePolicy,
+	load func(ctx context.Context) (T, error)) (T, error) {
+	if policy.ttl == 0 {
+		policy.ttl = a.cacheTTL
+	}
+	if policy.ttl < 0 || a.cache == nil || cacheDisabled(ctx) {
+		return load(ctx)
+	}
+	if policy.window <= 0 {
+		return cachedFor(ctx, a, key, policy.ttl, func() (T, error) { return load(ctx) })
+	}
+
+	var entry staleEntry[T]
+	b, ok, err := a.cache.Get(ctx, key)
+	if err == nil && ok && json.Unmarshal(b, &entry) == nil {
+		now := a.now()
+		if now.Before(entry.FreshUntil) {
+			a.count(MetricCacheHits, 1)
+			return entry.Value, nil
+		}
+		if now.Before(entry.FreshUntil.Add(policy.window)) {
+			a.count(MetricCacheHits, 1)
+			a.revalidate(ctx, key, policy, func(ctx context.Context) (interface{}, error) { return load(ctx) })
+			return entry.Value, nil
+		}
+	}
+	a.logError(ctx, "cache", err)
+	a.count(MetricCacheMisses, 1)
+
+	generation := atomic.LoadUint64(&a.stale.generation)
+
+	value, err := load(ctx)
+	if err != nil {
+		return value, err
+	}
+
+	a.storeStale(ctx, key, policy, generation, value)
+
+	return value, nil
+}
+
+// revalidate reloads a stale value in the background, once at a time per key
+func (a *Authority) revalidate(ctx context.Context, key string, policy cachePolicy,
+	load func(ctx context.Context) (interface{}, error)) {
+	if _, running := a.stale.refreshing.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+
+	// the refresh outlives the check, it keeps the values of the context but not its deadline
+	ctx = detachedContext{ctx}
+	generation := atomic.LoadUint64(&a.stale.generation)
+
+	go func() {
+		defer a.stale.refreshing.Delete(key)
+
+		release, err := a.checks.acquire(ctx)
+		if err != nil {
+			return
+		}
+		defer release()
+
+		value, err := load(ctx)
+		if err != nil {
+			a.logError(ctx, "revalidate", err)
+			return
+		}
+
+		a.storeStale(ctx, key, policy, generation, value)
+	}()
+}
+
+// storeStale caches a value loaded at the generation, unless the cache was purged since
+func (a *Authority) storeStale(ctx context.Context, key string, policy cachePolicy, generation uint64,
+	value interface{}) {
+	if atomic.LoadUint64(&a.stale.generation) != generation {
+		return
+	}
+
+	b, err := json.Marshal(staleEntry[interface{}]{Value: value, FreshUntil: a.now().Add(policy.ttl)})
+	if err == nil {
+		a.logError(ctx, "cache", a.cache.Set(ctx, key, b, policy.ttl+policy.window))
+	}
+}
+
+// detachedContext keeps the values of a context without its cancellation
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
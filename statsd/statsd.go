@@ -0,0 +1,175 @@
+// Package statsd provides an authority metrics sink pushing the counters and the timers to a StatsD server,
+// e.g. the Datadog agent
+package statsd
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"authority"
+)
+
+// DefaultAddr is the address of the local StatsD server or Datadog agent
+const DefaultAddr = "127.0.0.1:8125"
+
+// maxPacketSize keeps the packets below the usual MTU
+const maxPacketSize = 1432
+
+// Options has the options of the sink
+type Options struct {
+	// Addr is the UDP address of the server, DefaultAddr is used when it's empty
+	Addr string
+	// Prefix is prepended to the names of the metrics, e.g. "myapp."
+	Prefix string
+	// Tags are added to every metric, e.g. the environment
+	Tags []authority.Tag
+	// Datadog sends the tags in the DogStatsD format, they are dropped when it's unset since plain StatsD has
+	// no tags
+	Datadog bool
+	// FlushInterval is how often the buffered metrics are sent, they are sent as they are recorded when it's zero
+	FlushInterval time.Duration
+	// OnError is called with the errors of the writes
+	OnError func(err error)
+}
+
+// Sink is an authority.MetricsSink writing the metrics to a StatsD server over UDP, the metrics are buffered
+// up to the size of a packet until the flush interval
+type Sink struct {
+	opts Options
+	conn net.Conn
+
+	mu  sync.Mutex
+	buf []byte
+
+	done chan struct{}
+	once sync.Once
+}
+
+var _ authority.MetricsSink = (*Sink)(nil)
+
+// New returns a sink writing to the server of the options, it flushes the metrics until Close is called
+func New(opts Options) (*Sink, error) {
+	if opts.Addr == "" {
+		opts.Addr = DefaultAddr
+	}
+
+	conn, err := net.Dial("udp", opts.Addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sink{opts: opts, conn: conn, buf: make([]byte, 0, maxPacketSize), done: make(chan struct{})}
+	if opts.FlushInterval > 0 {
+		go s.flushEvery(opts.FlushInterval)
+	}
+
+	return s, nil
+}
+
+// IncrCounter implements authority.MetricsSink
+func (s *Sink) IncrCounter(name string, delta int64, tags ...authority.Tag) {
+	s.write(name, strconv.FormatInt(delta, 10), "c", tags)
+}
+
+// ObserveDuration implements authority.MetricsSink, the duration is sent in milliseconds
+func (s *Sink) ObserveDuration(name string, d time.Duration, tags ...authority.Tag) {
+	s.write(name, strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', -1, 64), "ms", tags)
+}
+
+// Flush sends the buffered metrics
+func (s *Sink) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.flush()
+}
+
+// Close flushes the buffered metrics and closes the connection
+func (s *Sink) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		s.Flush()
+		err = s.conn.Close()
+	})
+
+	return err
+}
+
+// write buffers a metric line, "name:value|type|#key:value,..."
+func (s *Sink) write(name string, value string, typ string, tags []authority.Tag) {
+	var line strings.Builder
+	line.WriteString(s.opts.Prefix)
+	line.WriteString(name)
+	line.WriteByte(':')
+	line.WriteString(value)
+	line.WriteByte('|')
+	line.WriteString(typ)
+
+	if s.opts.Datadog && len(tags)+len(s.opts.Tags) > 0 {
+		line.WriteString("|#")
+		for i, tag := range append(s.opts.Tags[:len(s.opts.Tags):len(s.opts.Tags)], tags...) {
+			if i > 0 {
+				line.WriteByte(',')
+			}
+			line.WriteString(sanitize(tag.Key))
+			line.WriteByte(':')
+			line.WriteString(sanitize(tag.Value))
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.buf) > 0 && len(s.buf)+1+line.Len() > maxPacketSize {
+		s.flush()
+	}
+	if len(s.buf) > 0 {
+		s.buf = append(s.buf, '\n')
+	}
+	s.buf = append(s.buf, line.String()...)
+
+	if s.opts.FlushInterval <= 0 {
+		s.flush()
+	}
+}
+
+// flush sends the buffer, the lock must be held
+func (s *Sink) flush() {
+	if len(s.buf) == 0 {
+		return
+	}
+
+	if _, err := s.conn.Write(s.buf); err != nil && s.opts.OnError != nil {
+		s.opts.OnError(err)
+	}
+	s.buf = s.buf[:0]
+}
+
+func (s *Sink) flushEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Flush()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// sanitize replaces the characters separating the parts of a line
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', ',', '#', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
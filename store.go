@@ -0,0 +1,310 @@
+package authority
+
+import (
+	"context"
+	"time"
+)
+
+// Store persists roles, permissions and their assignments.
+// the bun implementation returned by NewBunStore is used when Options.Store is not set
+type Store interface {
+	// Migrate prepares the storage, e.g. creates the tables
+	Migrate(ctx context.Context) error
+
+	// GetRole returns the role with the given name defined in the tenant, or the global one when the tenant doesn't
+	// define it, or ErrRoleNotFound. an empty tenant only matches global roles
+	GetRole(ctx context.Context, roleName string, tenant string) (*Role, error)
+	// GetRolesByID returns the roles with the given ids
+	GetRolesByID(ctx context.Context, roleIDs []uint) ([]Role, error)
+	// GetRolesByName returns the roles with the given names
+	GetRolesByName(ctx context.Context, roleNames []string) ([]Role, error)
+	// ListRoles returns all roles
+	ListRoles(ctx context.Context) ([]Role, error)
+	// FindRoles returns the page of the roles selected by the options and the number of roles matching the filter
+	FindRoles(ctx context.Context, opts ListOptions) ([]Role, int, error)
+	// CreateRole stores a role and sets its id
+	CreateRole(ctx context.Context, role *Role) error
+	// SetRoleState sets the state of a role
+	SetRoleState(ctx context.Context, roleID uint, state string) error
+	// SetRoleSegment sets the percentage of the users granted a role and the seed selecting them, 0 removes
+	// the segment
+	SetRoleSegment(ctx context.Context, roleID uint, percent int, seed string) error
+	// GetSegmentRoles returns the roles granted to a segment of the users
+	GetSegmentRoles(ctx context.Context) ([]Role, error)
+	// UpdateRole updates the name, the title, the description and the metadata of a role
+	UpdateRole(ctx context.Context, role *Role) error
+	// DeleteRole deletes a role
+	DeleteRole(ctx context.Context, roleID uint) error
+	// RoleAssigned reports whether a role is assigned to any user
+	RoleAssigned(ctx context.Context, roleID uint) (bool, error)
+	// CountRoleUsers returns the number of users the role is assigned to in the tenant, counting the assignments
+	// not expired at the given time
+	CountRoleUsers(ctx context.Context, roleID uint, tenant string, at time.Time) (int, error)
+
+	// GetPermission returns the permission with the given name defined in the tenant, or the global one when the
+	// tenant doesn't define it, or ErrPermissionNotFound. an empty tenant only matches global permissions
+	GetPermission(ctx context.Context, permName string, tenant string) (*Permission, error)
+	// GetPermissionByAction returns the permission allowing the action on the resource defined in the tenant,
+	// or the global one, or ErrPermissionNotFound
+	GetPermissionByAction(ctx context.Context, action string, resource string, tenant string) (*Permission, error)
+	// GetPermissionsByID returns the permissions with the given ids
+	GetPermissionsByID(ctx context.Context, permIDs []uint) ([]Permission, error)
+	// ListPermissions returns all permissions
+	ListPermissions(ctx context.Context) ([]Permission, error)
+	// FindPermissions returns the page of the permissions selected by the options and the number of permissions
+	// matching the filter
+	FindPermissions(ctx context.Context, opts ListOptions) ([]Permission, int, error)
+	// CreatePermission stores a permission and sets its id
+	CreatePermission(ctx context.Context, perm *Permission) error
+	// UpdatePermission updates the name, the title, the condition, the description, the metadata, the action and
+	// the resource of a permission
+	UpdatePermission(ctx context.Context, perm *Permission) error
+	// DeletePermission deletes a permission
+	DeletePermission(ctx context.Context, permID uint) error
+	// PermissionAssigned reports whether a permission is assigned to any role
+	PermissionAssigned(ctx context.Context, permID uint) (bool, error)
+
+	// GetRolePermission returns the assignment of a permission to a role or ErrRolePermissionNotFound
+	GetRolePermission(ctx context.Context, roleID, permID uint) (*RolePermission, error)
+	// GetRolePermissions returns the permission assignments of the given roles
+	GetRolePermissions(ctx context.Context, roleIDs []uint) ([]RolePermission, error)
+	// RolesHavePermission reports whether any of the roles has the permission assigned at the given time
+	RolesHavePermission(ctx context.Context, roleIDs []uint, permID uint, at time.Time) (bool, error)
+	// AssignPermission stores the assignment of a permission to a role,
+	// it returns ErrPermissionAlreadyAssigned when the role has the permission
+	AssignPermission(ctx context.Context, rolePerm *RolePermission) error
+	// RevokePermission revokes a permission from a role
+	RevokePermission(ctx context.Context, roleID, permID uint) error
+	// RevokeRolePermissions revokes all the permissions of a role
+	RevokeRolePermissions(ctx context.Context, roleID uint) error
+
+	// GetResourcePermissions returns the grants of the permissions on a resource
+	GetResourcePermissions(ctx context.Context, resourceType string, resourceID string) ([]ResourcePermission,
+		error)
+	// HasResourcePermission reports whether the permission is granted on the resource to the user in the tenant,
+	// global grants included, or to one of the roles
+	HasResourcePermission(ctx context.Context, permID uint, resourceType string, resourceID string, userKey string,
+		tenant string, roleIDs []uint) (bool, error)
+	// GetUserResourcePermissions returns the grants of the permissions on resources to a user in every tenant
+	GetUserResourcePermissions(ctx context.Context, userKey string) ([]ResourcePermission, error)
+	// GrantResourcePermission stores the grant of a permission on a resource, granting it twice is not an error
+	GrantResourcePermission(ctx context.Context, grant *ResourcePermission) error
+	// RevokeResourcePermission deletes the grant of a permission on a resource to the user or the role
+	RevokeResourcePermission(ctx context.Context, grant *ResourcePermission) error
+
+	// SaveUserTombstone stores the access of an offboarded user
+	SaveUserTombstone(ctx context.Context, tombstone *UserTombstone) error
+	// GetUserTombstones returns the tombstones of a user ordered by date
+	GetUserTombstones(ctx context.Context, userKey string) ([]UserTombstone, error)
+
+	// GetGroup returns a group by name, ErrGroupNotFound when it doesn't exist
+	GetGroup(ctx context.Context, groupName string) (*Group, error)
+	// CreateGroup stores a group
+	CreateGroup(ctx context.Context, group *Group) error
+	// DeleteGroup deletes a group with its members and its roles
+	DeleteGroup(ctx context.Context, groupID uint) error
+	// AddGroupMember adds a user to a group, adding it twice is not an error
+	AddGroupMember(ctx context.Context, member *GroupMember) error
+	// RemoveGroupMember removes a user from a group
+	RemoveGroupMember(ctx context.Context, groupID uint, userKey string) error
+	// GetGroupMembers returns the keys of the members of a group sorted
+	GetGroupMembers(ctx context.Context, groupID uint) ([]string, error)
+	// GetUserGroups returns the groups of a user sorted by name
+	GetUserGroups(ctx context.Context, userKey string) ([]Group, error)
+	// AssignGroupRole assigns a role to a group, assigning it twice is not an error
+	AssignGroupRole(ctx context.Context, groupRole *GroupRole) error
+	// RevokeGroupRole revokes a role from a group
+	RevokeGroupRole(ctx context.Context, groupID uint, roleID uint) error
+	// GetGroupRoleIDs returns the ids of the roles of a group
+	GetGroupRoleIDs(ctx context.Context, groupID uint) ([]uint, error)
+	// GetUserGroupRoleIDs returns the ids of the roles of the groups of a user
+	GetUserGroupRoleIDs(ctx context.Context, userKey string) ([]uint, error)
+
+	// ForgetUser deletes the rows of every table about a user and clears the user from the audit entries,
+	// the stores adding tables keyed by user must delete their rows too
+	ForgetUser(ctx context.Context, userKey string) error
+
+	// GetPermissionDenials returns the denials of a permission
+	GetPermissionDenials(ctx context.Context, permID uint) ([]PermissionDenial, error)
+	// GetUserPermissionDenials returns the denials of permissions to a user in all tenants
+	GetUserPermissionDenials(ctx context.Context, userKey string) ([]PermissionDenial, error)
+	// IsPermissionDenied reports whether the permission is denied to the user in the tenant, global denials
+	// included, or to one of the roles. the user is ignored when its key is empty
+	IsPermissionDenied(ctx context.Context, permID uint, userKey string, tenant string, roleIDs []uint) (bool,
+		error)
+	// DenyPermission stores the denial of a permission, denying it twice is not an error
+	DenyPermission(ctx context.Context, denial *PermissionDenial) error
+	// RevokePermissionDenial deletes the denial of a permission to the user or the role
+	RevokePermissionDenial(ctx context.Context, denial *PermissionDenial) error
+
+	// GetUserRole returns the assignment of a role to a user in the tenant or ErrUserRoleNotFound
+	GetUserRole(ctx context.Context, userKey string, roleID uint, tenant string) (*UserRole, error)
+	// GetUserRoles returns the role assignments of a user in all tenants
+	GetUserRoles(ctx context.Context, userKey string) ([]UserRole, error)
+	// GetAssignedRoles returns the roles assigned to a user in the tenant and in effect at the given time,
+	// including the global assignments, in the order of the assignments
+	GetAssignedRoles(ctx context.Context, userKey string, tenant string, at time.Time) ([]Role, error)
+	// GetUsersRoles returns the role assignments of the given users in all tenants
+	GetUsersRoles(ctx context.Context, userKeys []string) ([]UserRole, error)
+	// GetRoleUsers returns the keys of the users the role is assigned to in the tenant at the given time, ordered
+	// by user. numeric only returns the users identified by a number
+	GetRoleUsers(ctx context.Context, roleID uint, tenant string, at time.Time, numeric bool, page Page) (
+		[]string, error)
+	// ListUserRoles returns all the role assignments ordered by user
+	ListUserRoles(ctx context.Context) ([]UserRole, error)
+	// AssignRole stores the assignment of a role to a user,
+	// it returns ErrRoleAlreadyAssigned when the user has the role in the tenant
+	AssignRole(ctx context.Context, userRole *UserRole) error
+	// AssignRoles stores the role assignments in batches, the assignments the users have are skipped
+	AssignRoles(ctx context.Context, userRoles []UserRole) error
+	// GetTimeBoundUserRoles returns the assignments having an expiry, of the role in the tenant or of every role
+	// when roleID is 0, to the given users or to every user when userKeys is empty
+	GetTimeBoundUserRoles(ctx context.Context, roleID uint, tenant string, userKeys []string) ([]UserRole, error)
+	// GetExpiredUserRoles returns the assignments expired at the given time
+	GetExpiredUserRoles(ctx context.Context, at time.Time) ([]UserRole, error)
+	// SetUserRolesExpiry sets the expiry of the given assignments
+	SetUserRolesExpiry(ctx context.Context, userRoleIDs []uint, expiresAt time.Time) error
+	// RevokeRole revokes a role from a user in the tenant, with the claims of the assignment
+	RevokeRole(ctx context.Context, userKey string, roleID uint, tenant string) error
+
+	// ClaimAssignments stores the claims, replacing the date of the claims of the same source
+	ClaimAssignments(ctx context.Context, claims []AssignmentClaim) error
+	// ListConflictingClaims returns the claims of the assignments claimed by several sources,
+	// ordered by assignment
+	ListConflictingClaims(ctx context.Context) ([]AssignmentClaim, error)
+
+	// GetRoleComposites returns the roles included in the given composite roles
+	GetRoleComposites(ctx context.Context, roleIDs []uint) ([]RoleComposite, error)
+	// AddRoleComposite includes a role in a composite role, including it twice is not an error
+	AddRoleComposite(ctx context.Context, roleID, memberID uint) error
+	// RemoveRoleComposite removes an included role from a composite role
+	RemoveRoleComposite(ctx context.Context, roleID, memberID uint) error
+
+	// GetRoleParents returns the parents of the given roles
+	GetRoleParents(ctx context.Context, roleIDs []uint) ([]RoleParent, error)
+	// GetRoleChildren returns the roles whose parent is the given role
+	GetRoleChildren(ctx context.Context, parentID uint) ([]RoleParent, error)
+	// SetRoleParent sets the parent of a role, replacing its current parent
+	SetRoleParent(ctx context.Context, roleID, parentID uint) error
+	// RemoveRoleParent removes the parent of a role
+	RemoveRoleParent(ctx context.Context, roleID uint) error
+
+	// GetPolicy returns the policy with the given name or ErrPolicyNotFound
+	GetPolicy(ctx context.Context, name string) (*Policy, error)
+	// ListPolicies returns all policies
+	ListPolicies(ctx context.Context) ([]Policy, error)
+	// SavePolicy stores a policy, replacing the expression of the policy with the same name
+	SavePolicy(ctx context.Context, policy *Policy) error
+	// DeletePolicy deletes the policy with the given name
+	DeletePolicy(ctx context.Context, name string) error
+
+	// CreateAuditEntry stores an audit entry
+	CreateAuditEntry(ctx context.Context, entry *AuditEntry) error
+	// ListChanges returns the audit entries of the changes whose id is greater than afterID and created before
+	// the given time, up to the limit, ordered by id. the entries of the checks are left out
+	ListChanges(ctx context.Context, afterID uint, before time.Time, limit int) ([]AuditEntry, error)
+
+	// CreateAccessRequest stores an access request
+	CreateAccessRequest(ctx context.Context, req *AccessRequest) error
+	// GetAccessRequest returns the access request with the given id, ErrAccessRequestNotFound when it doesn't exist
+	GetAccessRequest(ctx context.Context, id uint) (*AccessRequest, error)
+	// ListAccessRequests returns the access requests of a user with the status, of every user or with every status
+	// when they are empty, the oldest first
+	ListAccessRequests(ctx context.Context, userKey string, status string) ([]AccessRequest, error)
+	// DecideAccessRequest stores the status and the decision of a pending access request,
+	// it returns ErrAccessRequestDecided when the request is not pending anymore
+	DecideAccessRequest(ctx context.Context, req *AccessRequest) error
+	// AddRequestApproval records the approval of an access request and returns the number of approvals of the
+	// request, the approvals of the same request are counted one after the other. it returns
+	// ErrApprovalAlreadyGiven when the approver already approved the request
+	AddRequestApproval(ctx context.Context, approval *RequestApproval) (int, error)
+	// GetRequestApprovals returns the approvals of an access request, the oldest first
+	GetRequestApprovals(ctx context.Context, requestID uint) ([]RequestApproval, error)
+
+	// CreateImportBatch stores an import batch unless one with the same key exists, it reports whether it was stored
+	CreateImportBatch(ctx context.Context, batch *ImportBatch) (bool, error)
+	// GetImportBatch returns the import batch with the given key, ErrImportNotFound when it doesn't exist
+	GetImportBatch(ctx context.Context, key string) (*ImportBatch, error)
+	// SaveImportBatch stores an import batch, replacing the one with the same key
+	SaveImportBatch(ctx context.Context, batch *ImportBatch) error
+}
+
+// PermissionChecker is implemented by the stores that can check a permission of a user in a single round trip
+type PermissionChecker interface {
+	// CheckUserPermission reports whether the roles the user has in the tenant at the given time, including
+	// the composite and inherited roles, grant the permission. it returns the condition of the permission
+	// and ErrPermissionNotFound when the permission doesn't exist. the holders of the super role of the store,
+	// if any, are granted every permission without condition
+	CheckUserPermission(ctx context.Context, userKey string, permName string, tenant string, at time.Time) (
+		granted bool, condition string, err error)
+}
+
+// BatchPermissionChecker is implemented by the stores that can check several permissions of a user in a single
+// round trip
+type BatchPermissionChecker interface {
+	// CheckUserPermissions is like CheckUserPermission for each of the permissions, it returns whether the
+	// permissions are granted and their conditions by name. the permissions that don't exist are left out
+	CheckUserPermissions(ctx context.Context, userKey string, permNames []string, tenant string, at time.Time) (
+		granted map[string]bool, conditions map[string]string, err error)
+}
+
+// Transactor is implemented by the stores that can run a group of changes atomically
+type Transactor interface {
+	// InTx calls fn with a store whose changes are committed when fn returns nil and rolled back otherwise
+	InTx(ctx context.Context, fn func(ctx context.Context, store Store) error) error
+}
+
+// IndexMigrator is implemented by the stores that can create their indexes without blocking the writes
+type IndexMigrator interface {
+	// MigrateIndexes creates the missing indexes of the foreign keys
+	MigrateIndexes(ctx context.Context) error
+}
+
+// MigrateIndexes creates the missing indexes of the foreign keys without blocking the writes to the tables,
+// for the deployments whose tables were created before Migrate created them. it returns ErrNotSupported if
+// the store doesn't implement IndexMigrator, and ErrOperationLocked when another instance runs it for longer than
+// Options.LockTimeout
+func (a *Authority) MigrateIndexes() error {
+	return a.MigrateIndexesCtx(context.Background())
+}
+
+// MigrateIndexesCtx is the context-aware variant of MigrateIndexes
+func (a *Authority) MigrateIndexesCtx(ctx context.Context) error {
+	migrator, ok := a.store.(IndexMigrator)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	return a.exclusive(ctx, "migrate_indexes", a.lockTimeout, migrator.MigrateIndexes)
+}
+
+// inTx runs fn atomically with an instance using the transaction of the store,
+// stores that don't implement Transactor run fn with the instance itself
+func (a *Authority) inTx(ctx context.Context, fn func(ctx context.Context, tx *Authority) error) error {
+	transactor, ok := a.store.(Transactor)
+	if !ok {
+		return fn(ctx, a)
+	}
+
+	var pending []AuditEntry
+	err := transactor.InTx(ctx, func(ctx context.Context, store Store) error {
+		tx := *a
+		tx.store = store
+		tx.pending, pending = &pending, nil
+
+		return fn(ctx, &tx)
+	})
+
+	// the lookups cached while the transaction was running are stale once it's committed or rolled back
+	if purgeErr := a.purgeCache(ctx); err == nil {
+		err = purgeErr
+	}
+
+	if err == nil {
+		for _, entry := range pending {
+			a.notify(ctx, entry)
+		}
+	}
+
+	return err
+}
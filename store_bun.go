@@ -0,0 +1,2682 @@
+package authority
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/driver/pgdriver"
+	"github.com/uptrace/bun/migrate"
+)
+
+// BunStore is the Store backed by a bun database
+type BunStore struct {
+	db     bun.IDB
+	prefix string
+	opts   BunStoreOptions
+	// caseInsensitive is set when the name columns are citext, found by Migrate. it follows the options
+	// until then, e.g. when the migrations are skipped
+	caseInsensitive bool
+	// conn is the connection of the transaction of the store returned by InTx
+	conn *bun.Conn
+
+	tableRole      string
+	tablePerm      string
+	tableRolePerm  string
+	tableUserRole  string
+	tableComposite string
+	tableParent    string
+	tableAudit     string
+	tablePolicy    string
+	tableRequest   string
+	tableImport    string
+	tableVersion   string
+	tableResource  string
+	tableClaim     string
+	tableDenial    string
+	tableTombstone string
+	tableGroup     string
+	tableMember    string
+	tableGroupRole string
+	tableApproval  string
+}
+
+// the sizes of the inserts of AssignRoles
+const (
+	// assignBatchSize is the number of assignments inserted by a statement
+	assignBatchSize = 1000
+	// copyThreshold is the number of assignments from which they are copied with COPY FROM
+	copyThreshold = 10000
+)
+
+var (
+	_ Store                  = (*BunStore)(nil)
+	_ Locker                 = (*BunStore)(nil)
+	_ PolicyNotifier         = (*BunStore)(nil)
+	_ Transactor             = (*BunStore)(nil)
+	_ PermissionChecker      = (*BunStore)(nil)
+	_ BatchPermissionChecker = (*BunStore)(nil)
+	_ PolicyVersioner        = (*BunStore)(nil)
+	_ Replicator             = (*BunStore)(nil)
+	_ IndexMigrator          = (*BunStore)(nil)
+)
+
+// BunStoreOptions has the options of the bun store
+type BunStoreOptions struct {
+	// TablesPrefix prefixes the names of the tables
+	TablesPrefix string
+	// Namespace scopes the store to the roles and the permissions of an application, e.g. "billing", so that
+	// the applications sharing the tables keep their catalogs apart. the names are unique within a namespace,
+	// the store creates the roles and the permissions in its namespace and only finds the ones of its namespace
+	// with their assignments. the users, the groups, the policies and the audit entries are shared. the check
+	// functions of a namespace are suffixed with it, e.g. authority_check_billing, and the reporting views have
+	// a namespace column. the stores without a namespace have the empty one
+	Namespace string
+	// CaseInsensitiveNames makes Migrate create the name columns of the roles and the permissions as citext,
+	// installing the extension, so that the names differing only by case are the same role or permission.
+	// the lookups by name ignore the case and the names keep the case they were created with, creating
+	// "Admin" when "admin" exists does nothing. Migrate fails if the existing names already differ only
+	// by case. the store adapts to name columns made citext by other means as well
+	CaseInsensitiveNames bool
+	// SuperRole is the role whose holders are granted every permission by CheckUserPermission and
+	// CheckUserPermissions, within the same query
+	SuperRole string
+	// DefaultRoles are the roles CheckUserPermission and CheckUserPermissions consider assigned to every user
+	DefaultRoles []string
+	// ColumnTypes overrides the types of some columns of the tables
+	ColumnTypes ColumnTypes
+	// CheckFunctions makes Migrate create the SQL functions checking the permissions of the users,
+	// authority_check(user_key text, permission text, tenant text DEFAULT '') and its variant taking a bigint
+	// user id, prefixed like the tables and suffixed with the namespace, if any. they can be called from views,
+	// row level security policies or reports and they are replaced by every migration to follow SuperRole and
+	// DefaultRoles. they grant the conditional permissions only to the holders of the super role since the
+	// conditions are evaluated in Go
+	CheckFunctions bool
+	// ReportingViews makes Migrate create views for the reporting tools, prefixed like the tables.
+	// role_permission_matrix_v has a row per role and permission it grants, directly or through the roles it
+	// includes and inherits from, and user_effective_permissions_v a row per user, tenant and permission granted
+	// by its assignments and its groups. the tenant of the assignments valid in every tenant is empty. they
+	// follow the states of the roles, the validity of the assignments and the denials but they ignore
+	// SuperRole and DefaultRoles, the conditional permissions are flagged. the namespace column has the
+	// namespace of the roles, the views don't cross namespaces
+	ReportingViews bool
+	// Replication adds the metadata making the tables safe to replicate across regions
+	Replication ReplicationOptions
+	// IndexName returns the name of the index of the columns of a foreign key of the table, whose name is
+	// prefixed, e.g. to follow the naming standards of an organization. the default name is
+	// table_column_idx
+	IndexName func(table string, columns []string) string
+}
+
+// ColumnTypes sets the types of columns created by Migrate, e.g. to follow the schema standards of an
+// organization. Migrate alters the columns of the existing tables to the types, the empty ones keep the
+// default types
+type ColumnTypes struct {
+	// Name is the type of the names of the roles and the permissions, e.g. "varchar(100)" or "text".
+	// it's ignored with CaseInsensitiveNames
+	Name string
+	// Title is the type of the titles of the roles and the permissions
+	Title string
+	// ID is the type of the numeric ids and of the columns referencing them, "integer" or "bigint"
+	ID string
+}
+
+// foreignKeyIndexes are the columns of the foreign keys that aren't the first columns of another index, they
+// are indexed so that the lookups and the cascading deletes by role or permission don't scan the tables
+var foreignKeyIndexes = []struct {
+	table   string
+	columns []string
+}{
+	{"role_permissions", []string{"permission_id"}},
+	{"role_composites", []string{"role_id"}},
+	{"role_composites", []string{"member_id"}},
+	{"role_parents", []string{"parent_id"}},
+	{"user_roles", []string{"role_id"}},
+	{"user_roles", []string{"user_id"}},
+	{"assignment_claims", []string{"role_id"}},
+	{"resource_permissions", []string{"permission_id"}},
+	{"resource_permissions", []string{"role_id"}},
+	{"permission_denials", []string{"role_id"}},
+	{"group_roles", []string{"role_id"}},
+}
+
+// the tables of the store, the column types are migrated in all of them
+var storeTables = []string{
+	"roles", "permissions", "role_permissions", "role_composites", "role_parents", "user_roles", "audit_entries",
+	"policies", "access_requests", "request_approvals", "import_batches", "assignment_claims", "resource_permissions",
+	"permission_denials", "user_tombstones", "policy_versions", "groups", "group_members", "group_roles",
+}
+
+var (
+	columnTypePattern = regexp.MustCompile(`^[a-z][a-z ]*(\([0-9]+\))?$`)
+	// columnTypeAliases are the names information_schema gives to the types
+	columnTypeAliases = map[string]string{
+		"character varying": "varchar", "int": "integer", "int4": "integer", "int8": "bigint", "int2": "smallint",
+	}
+)
+
+// NewBunStore returns a store that keeps its tables in the given database,
+// the names of the tables are prefixed with the given prefix
+func NewBunStore(db bun.IDB, tablesPrefix string) *BunStore {
+	return NewBunStoreWith(db, BunStoreOptions{TablesPrefix: tablesPrefix})
+}
+
+// NewBunStoreWith returns a store that keeps its tables in the given database with the options
+func NewBunStoreWith(db bun.IDB, opts BunStoreOptions) *BunStore {
+	tablesPrefix := opts.TablesPrefix
+
+	return &BunStore{
+		db:              db,
+		prefix:          tablesPrefix,
+		opts:            opts,
+		caseInsensitive: opts.CaseInsensitiveNames,
+		tableRole:       tablesPrefix + "roles AS role",
+		tablePerm:       tablesPrefix + "permissions AS perm",
+		tableRolePerm:   tablesPrefix + "role_permissions AS rp",
+		tableUserRole:   tablesPrefix + "user_roles AS ur",
+		tableComposite:  tablesPrefix + "role_composites AS rc",
+		tableParent:     tablesPrefix + "role_parents AS rpa",
+		tableAudit:      tablesPrefix + "audit_entries AS ae",
+		tablePolicy:     tablesPrefix + "policies AS pol",
+		tableRequest:    tablesPrefix + "access_requests AS ar",
+		tableImport:     tablesPrefix + "import_batches AS ib",
+		tableVersion:    tablesPrefix + "policy_versions AS pv",
+		tableResource:   tablesPrefix + "resource_permissions AS resp",
+		tableClaim:      tablesPrefix + "assignment_claims AS ac",
+		tableDenial:     tablesPrefix + "permission_denials AS pd",
+		tableTombstone:  tablesPrefix + "user_tombstones AS ut",
+		tableGroup:      tablesPrefix + "groups AS grp",
+		tableMember:     tablesPrefix + "group_members AS gm",
+		tableGroupRole:  tablesPrefix + "group_roles AS gr",
+		tableApproval:   tablesPrefix + "request_approvals AS rqa",
+	}
+}
+
+// GetRole implements Store
+func (s *BunStore) GetRole(ctx context.Context, roleName string, tenant string) (*Role, error) {
+	var role Role
+	if err := s.db.NewSelect().Model(&role).Where("name = ?", roleName).ModelTableExpr(s.tableRole).
+		Where("namespace = ?", s.opts.Namespace).Where("tenant IN (?)", bun.In([]string{"", tenant})).
+		OrderExpr("tenant DESC").Limit(1).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrRoleNotFound, roleName)
+		}
+		return nil, err
+	}
+
+	return &role, nil
+}
+
+// GetRolesByID implements Store
+func (s *BunStore) GetRolesByID(ctx context.Context, roleIDs []uint) ([]Role, error) {
+	if len(roleIDs) == 0 {
+		return nil, nil
+	}
+
+	var roles []Role
+	if err := s.db.NewSelect().Model(&roles).ModelTableExpr(s.tableRole).
+		Where("id IN (?)", bun.In(roleIDs)).Where("namespace = ?", s.opts.Namespace).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// GetRolesByName implements Store
+func (s *BunStore) GetRolesByName(ctx context.Context, roleNames []string) ([]Role, error) {
+	if len(roleNames) == 0 {
+		return nil, nil
+	}
+
+	var roles []Role
+	if err := s.db.NewSelect().Model(&roles).ModelTableExpr(s.tableRole).
+		Where("name IN (?)", bun.In(roleNames)).Where("namespace = ?", s.opts.Namespace).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// ListRoles implements Store
+func (s *BunStore) ListRoles(ctx context.Context) ([]Role, error) {
+	var roles []Role
+	if err := s.db.NewSelect().Model(&roles).ModelTableExpr(s.tableRole).
+		Where("namespace = ?", s.opts.Namespace).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// FindRoles implements Store
+func (s *BunStore) FindRoles(ctx context.Context, opts ListOptions) ([]Role, int, error) {
+	roles := []Role{}
+	total, err := s.list(s.db.NewSelect().Model(&roles).ModelTableExpr(s.tableRole).
+		Where("namespace = ?", s.opts.Namespace), opts).ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return roles, total, nil
+}
+
+// CreateRole implements Store
+func (s *BunStore) CreateRole(ctx context.Context, role *Role) error {
+	role.Namespace = s.opts.Namespace
+	_, err := s.db.NewInsert().Model(role).ModelTableExpr(s.tableRole).Exec(ctx)
+
+	return pgConstraintError(err, EntityRole, role.Name)
+}
+
+// UpdateRole implements Store
+func (s *BunStore) UpdateRole(ctx context.Context, role *Role) error {
+	_, err := s.db.NewUpdate().Model(role).ModelTableExpr(s.tableRole).
+		Column("name", "title", "description", "metadata").WherePK().Where("namespace = ?", s.opts.Namespace).
+		Exec(ctx)
+
+	return pgConstraintError(err, EntityRole, role.Name)
+}
+
+// SetRoleState implements Store
+func (s *BunStore) SetRoleState(ctx context.Context, roleID uint, state string) error {
+	_, err := s.db.NewUpdate().Model((*Role)(nil)).ModelTableExpr(s.tableRole).
+		Set("state = ?", state).Where("id = ?", roleID).Where("namespace = ?", s.opts.Namespace).Exec(ctx)
+
+	return err
+}
+
+// SetRoleSegment implements Store
+func (s *BunStore) SetRoleSegment(ctx context.Context, roleID uint, percent int, seed string) error {
+	_, err := s.db.NewUpdate().Model((*Role)(nil)).ModelTableExpr(s.tableRole).
+		Set("segment_percent = ?", percent).Set("segment_seed = ?", seed).
+		Where("id = ?", roleID).Where("namespace = ?", s.opts.Namespace).Exec(ctx)
+
+	return err
+}
+
+// GetSegmentRoles implements Store
+func (s *BunStore) GetSegmentRoles(ctx context.Context) ([]Role, error) {
+	var roles []Role
+	err := s.db.NewSelect().Model(&roles).ModelTableExpr(s.tableRole).
+		Where("segment_percent > 0").Where("namespace = ?", s.opts.Namespace).Order("id").Scan(ctx)
+
+	return roles, err
+}
+
+// DeleteRole implements Store
+func (s *BunStore) DeleteRole(ctx context.Context, roleID uint) error {
+	_, err := s.db.NewDelete().Model((*Role)(nil)).ModelTableExpr(s.tableRole).
+		Where("id = ?", roleID).Where("namespace = ?", s.opts.Namespace).Exec(ctx)
+
+	return err
+}
+
+// RoleAssigned implements Store
+func (s *BunStore) RoleAssigned(ctx context.Context, roleID uint) (bool, error) {
+	return s.db.NewSelect().Model((*UserRole)(nil)).ModelTableExpr(s.tableUserRole).
+		Where("role_id = ?", roleID).Exists(ctx)
+}
+
+// CountRoleUsers implements Store
+func (s *BunStore) CountRoleUsers(ctx context.Context, roleID uint, tenant string, at time.Time) (int, error) {
+	return s.db.NewSelect().Model((*UserRole)(nil)).ModelTableExpr(s.tableUserRole).Distinct().Column("user_key").
+		Where("role_id = ?", roleID).Where("tenant = ?", tenant).Where("expires_at IS NULL OR expires_at > ?", at).
+		Count(ctx)
+}
+
+// GetPermission implements Store
+func (s *BunStore) GetPermission(ctx context.Context, permName string, tenant string) (*Permission, error) {
+	var perm Permission
+	if err := s.db.NewSelect().Model(&perm).Where("name = ?", permName).Where("namespace = ?", s.opts.Namespace).
+		Where("tenant IN (?)", bun.In([]string{"", tenant})).OrderExpr("tenant DESC").Limit(1).
+		ModelTableExpr(s.tablePerm).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrPermissionNotFound, permName)
+		}
+		return nil, err
+	}
+
+	return &perm, nil
+}
+
+// GetPermissionByAction implements Store
+func (s *BunStore) GetPermissionByAction(ctx context.Context, action string, resource string, tenant string) (
+	*Permission, error) {
+	var perm Permission
+	if err := s.db.NewSelect().Model(&perm).ModelTableExpr(s.tablePerm).
+		Where("action = ?", action).Where("resource = ?", resource).Where("namespace = ?", s.opts.Namespace).
+		Where("tenant IN (?)", bun.In([]string{"", tenant})).OrderExpr("tenant DESC, id").Limit(1).
+		Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s on %s", ErrPermissionNotFound, action, resource)
+		}
+		return nil, err
+	}
+
+	return &perm, nil
+}
+
+// GetPermissionsByID implements Store
+func (s *BunStore) GetPermissionsByID(ctx context.Context, permIDs []uint) ([]Permission, error) {
+	if len(permIDs) == 0 {
+		return nil, nil
+	}
+
+	var perms []Permission
+	if err := s.db.NewSelect().Model(&perms).ModelTableExpr(s.tablePerm).
+		Where("id IN (?)", bun.In(permIDs)).Where("namespace = ?", s.opts.Namespace).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return perms, nil
+}
+
+// ListPermissions implements Store
+func (s *BunStore) ListPermissions(ctx context.Context) ([]Permission, error) {
+	var perms []Permission
+	if err := s.db.NewSelect().Model(&perms).ModelTableExpr(s.tablePerm).
+		Where("namespace = ?", s.opts.Namespace).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return perms, nil
+}
+
+// FindPermissions implements Store
+func (s *BunStore) FindPermissions(ctx context.Context, opts ListOptions) ([]Permission, int, error) {
+	perms := []Permission{}
+	total, err := s.list(s.db.NewSelect().Model(&perms).ModelTableExpr(s.tablePerm).
+		Where("namespace = ?", s.opts.Namespace), opts).ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return perms, total, nil
+}
+
+// list applies the filter, the order and the page of the options to a query
+func (s *BunStore) list(q *bun.SelectQuery, opts ListOptions) *bun.SelectQuery {
+	if opts.Prefix != "" {
+		q = q.Where("name LIKE ?", likePrefix(opts.Prefix))
+	}
+	if opts.Limit > 0 {
+		q = q.Limit(opts.Limit)
+	}
+	if opts.Offset > 0 {
+		q = q.Offset(opts.Offset)
+	}
+
+	return q.OrderExpr(opts.order())
+}
+
+// CreatePermission implements Store
+func (s *BunStore) CreatePermission(ctx context.Context, perm *Permission) error {
+	perm.Namespace = s.opts.Namespace
+	_, err := s.db.NewInsert().Model(perm).ModelTableExpr(s.tablePerm).Exec(ctx)
+
+	return pgConstraintError(err, EntityPermission, perm.Name)
+}
+
+// UpdatePermission implements Store
+func (s *BunStore) UpdatePermission(ctx context.Context, perm *Permission) error {
+	_, err := s.db.NewUpdate().Model(perm).ModelTableExpr(s.tablePerm).
+		Column("name", "title", "condition", "description", "metadata", "action", "resource").WherePK().
+		Where("namespace = ?", s.opts.Namespace).Exec(ctx)
+
+	return pgConstraintError(err, EntityPermission, perm.Name)
+}
+
+// DeletePermission implements Store
+func (s *BunStore) DeletePermission(ctx context.Context, permID uint) error {
+	_, err := s.db.NewDelete().Model((*Permission)(nil)).ModelTableExpr(s.tablePerm).
+		Where("id = ?", permID).Where("namespace = ?", s.opts.Namespace).Exec(ctx)
+
+	return err
+}
+
+// PermissionAssigned implements Store
+func (s *BunStore) PermissionAssigned(ctx context.Context, permID uint) (bool, error) {
+	return s.db.NewSelect().Model((*RolePermission)(nil)).ModelTableExpr(s.tableRolePerm).
+		Where("permission_id = ?", permID).Exists(ctx)
+}
+
+// GetRolePermission implements Store
+func (s *BunStore) GetRolePermission(ctx context.Context, roleID, permID uint) (*RolePermission, error) {
+	var rolePerm RolePermission
+	if err := s.db.NewSelect().Model(&rolePerm).ModelTableExpr(s.tableRolePerm).
+		Where("role_id = ?", roleID).Where("permission_id =?", permID).
+		Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: permission %d of the role %d", ErrRolePermissionNotFound, permID, roleID)
+		}
+
+		return nil, err
+	}
+
+	return &rolePerm, nil
+}
+
+// GetRolePermissions implements Store
+func (s *BunStore) GetRolePermissions(ctx context.Context, roleIDs []uint) ([]RolePermission, error) {
+	if len(roleIDs) == 0 {
+		return nil, nil
+	}
+
+	var rolePerms []RolePermission
+	if err := s.db.NewSelect().Model(&rolePerms).ModelTableExpr(s.tableRolePerm).
+		Where("role_id IN (?)", bun.In(roleIDs)).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return rolePerms, nil
+}
+
+// RolesHavePermission implements Store
+func (s *BunStore) RolesHavePermission(ctx context.Context, roleIDs []uint, permID uint, at time.Time) (bool, error) {
+	if len(roleIDs) == 0 {
+		return false, nil
+	}
+
+	return s.db.NewSelect().Model((*RolePermission)(nil)).ModelTableExpr(s.tableRolePerm).
+		Where("role_id IN (?)", bun.In(roleIDs)).Where("permission_id = ?", permID).
+		Where("starts_at IS NULL OR starts_at <= ?", at).Where("expires_at IS NULL OR expires_at > ?", at).
+		Exists(ctx)
+}
+
+// CheckUserPermission implements PermissionChecker with a single query walking the composite and parent roles
+// of the assigned roles with a recursive cte
+func (s *BunStore) CheckUserPermission(ctx context.Context, userKey string, permName string, tenant string, at time.Time) (
+	bool, string, error) {
+	var result struct {
+		ID        uint
+		Condition string
+		Granted   bool
+	}
+
+	err := s.db.NewRaw(`WITH RECURSIVE perm AS (
+		SELECT id, condition FROM ? WHERE name = ? AND namespace = ? AND tenant IN (?) ORDER BY tenant DESC LIMIT 1
+	), roles (id) AS (
+		SELECT ur.role_id FROM ? AS ur JOIN ? AS r ON r.id = ur.role_id AND r.state = 'active' AND r.namespace = ?
+		WHERE ur.user_key = ? AND ur.tenant IN (?)
+			AND (ur.starts_at IS NULL OR ur.starts_at <= ?) AND (ur.expires_at IS NULL OR ur.expires_at > ?)
+		UNION
+		SELECT gr.role_id FROM ? AS gm JOIN ? AS gr ON gr.group_id = gm.group_id
+			JOIN ? AS r ON r.id = gr.role_id AND r.state = 'active' AND r.namespace = ?
+		WHERE gm.user_key = ?
+		UNION
+		SELECT id FROM (?) AS dr
+		UNION
+		SELECT r.id FROM ? AS r WHERE r.segment_percent > 0 AND r.state = 'active' AND r.namespace = ?
+			AND r.tenant IN (?) AND ('x' || substr(md5(r.segment_seed || ':' || ?), 1, 8))::bit(32)::bigint % 100
+				< r.segment_percent
+		UNION
+		SELECT edge.next_id FROM roles JOIN (
+			SELECT role_id, member_id AS next_id FROM ?
+			UNION ALL
+			SELECT role_id, parent_id AS next_id FROM ?
+		) AS edge ON edge.role_id = roles.id
+		JOIN ? AS r ON r.id = edge.next_id AND r.state = 'active' AND r.namespace = ?
+	), super_role (granted) AS (
+		SELECT ? <> '' AND EXISTS (
+			SELECT 1 FROM roles JOIN ? AS sr ON sr.id = roles.id WHERE sr.name = ? AND sr.namespace = ?
+		)
+	)
+	SELECT perm.id, CASE WHEN super_role.granted THEN '' ELSE perm.condition END AS condition,
+	super_role.granted OR EXISTS (
+		SELECT 1 FROM ? AS rp JOIN roles ON rp.role_id = roles.id
+		WHERE rp.permission_id = perm.id
+			AND (rp.starts_at IS NULL OR rp.starts_at <= ?) AND (rp.expires_at IS NULL OR rp.expires_at > ?)
+	) AND NOT EXISTS (
+		SELECT 1 FROM ? AS pd WHERE pd.permission_id = perm.id
+			AND (pd.role_id IN (SELECT id FROM roles) OR pd.user_key = ? AND pd.tenant IN (?))
+	) AS granted FROM perm, super_role`,
+		bun.Ident(s.prefix+"permissions"), permName, s.opts.Namespace, bun.In([]string{"", tenant}),
+		bun.Ident(s.prefix+"user_roles"), bun.Ident(s.prefix+"roles"), s.opts.Namespace, userKey,
+		bun.In([]string{"", tenant}), at, at,
+		bun.Ident(s.prefix+"group_members"), bun.Ident(s.prefix+"group_roles"), bun.Ident(s.prefix+"roles"),
+		s.opts.Namespace, userKey, s.defaultRoles(tenant),
+		bun.Ident(s.prefix+"roles"), s.opts.Namespace, bun.In([]string{"", tenant}), userKey,
+		bun.Ident(s.prefix+"role_composites"), bun.Ident(s.prefix+"role_parents"), bun.Ident(s.prefix+"roles"),
+		s.opts.Namespace, s.opts.SuperRole, bun.Ident(s.prefix+"roles"), s.opts.SuperRole, s.opts.Namespace,
+		bun.Ident(s.prefix+"role_permissions"), at, at,
+		bun.Ident(s.prefix+"permission_denials"), userKey, bun.In([]string{"", tenant}),
+	).Scan(ctx, &result)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, "", fmt.Errorf("%w: %s", ErrPermissionNotFound, permName)
+	}
+	if err != nil {
+		return false, "", err
+	}
+
+	return result.Granted, result.Condition, nil
+}
+
+// namespaceRoles selects the ids of the roles of the namespace of the store, to scope the assignments
+func (s *BunStore) namespaceRoles() *bun.SelectQuery {
+	return s.db.NewSelect().Model((*Role)(nil)).ModelTableExpr(s.tableRole).Column("id").
+		Where("namespace = ?", s.opts.Namespace)
+}
+
+// defaultRoles selects the ids of the active default roles in the tenant, a role of the tenant takes precedence
+// over the global role of the same name
+func (s *BunStore) defaultRoles(tenant string) *bun.SelectQuery {
+	q := s.db.NewSelect().Model((*Role)(nil)).ModelTableExpr(s.tableRole).
+		DistinctOn("name").Column("id").Where("state = 'active'").Where("namespace = ?", s.opts.Namespace).
+		OrderExpr("name, tenant DESC")
+	if len(s.opts.DefaultRoles) == 0 {
+		return q.Where("FALSE")
+	}
+
+	return q.Where("name IN (?)", bun.In(s.opts.DefaultRoles)).Where("tenant IN (?)", bun.In([]string{"", tenant}))
+}
+
+// CheckUserPermissions implements BatchPermissionChecker with the query of CheckUserPermission for all the
+// permissions at once
+func (s *BunStore) CheckUserPermissions(ctx context.Context, userKey string, permNames []string, tenant string,
+	at time.Time) (map[string]bool, map[string]string, error) {
+	granted, conditions := make(map[string]bool, len(permNames)), make(map[string]string, len(permNames))
+	if len(permNames) == 0 {
+		return granted, conditions, nil
+	}
+
+	var results []struct {
+		Name      string
+		Condition string
+		Granted   bool
+	}
+
+	err := s.db.NewRaw(`WITH RECURSIVE perm AS (
+		SELECT DISTINCT ON (name) id, name, condition FROM ? WHERE name IN (?) AND namespace = ? AND tenant IN (?)
+		ORDER BY name, tenant DESC
+	), roles (id) AS (
+		SELECT ur.role_id FROM ? AS ur JOIN ? AS r ON r.id = ur.role_id AND r.state = 'active' AND r.namespace = ?
+		WHERE ur.user_key = ? AND ur.tenant IN (?)
+			AND (ur.starts_at IS NULL OR ur.starts_at <= ?) AND (ur.expires_at IS NULL OR ur.expires_at > ?)
+		UNION
+		SELECT gr.role_id FROM ? AS gm JOIN ? AS gr ON gr.group_id = gm.group_id
+			JOIN ? AS r ON r.id = gr.role_id AND r.state = 'active' AND r.namespace = ?
+		WHERE gm.user_key = ?
+		UNION
+		SELECT id FROM (?) AS dr
+		UNION
+		SELECT r.id FROM ? AS r WHERE r.segment_percent > 0 AND r.state = 'active' AND r.namespace = ?
+			AND r.tenant IN (?) AND ('x' || substr(md5(r.segment_seed || ':' || ?), 1, 8))::bit(32)::bigint % 100
+				< r.segment_percent
+		UNION
+		SELECT edge.next_id FROM roles JOIN (
+			SELECT role_id, member_id AS next_id FROM ?
+			UNION ALL
+			SELECT role_id, parent_id AS next_id FROM ?
+		) AS edge ON edge.role_id = roles.id
+		JOIN ? AS r ON r.id = edge.next_id AND r.state = 'active' AND r.namespace = ?
+	), super_role (granted) AS (
+		SELECT ? <> '' AND EXISTS (
+			SELECT 1 FROM roles JOIN ? AS sr ON sr.id = roles.id WHERE sr.name = ? AND sr.namespace = ?
+		)
+	)
+	SELECT perm.name, CASE WHEN super_role.granted THEN '' ELSE perm.condition END AS condition,
+	super_role.granted OR EXISTS (
+		SELECT 1 FROM ? AS rp JOIN roles ON rp.role_id = roles.id
+		WHERE rp.permission_id = perm.id
+			AND (rp.starts_at IS NULL OR rp.starts_at <= ?) AND (rp.expires_at IS NULL OR rp.expires_at > ?)
+	) AND NOT EXISTS (
+		SELECT 1 FROM ? AS pd WHERE pd.permission_id = perm.id
+			AND (pd.role_id IN (SELECT id FROM roles) OR pd.user_key = ? AND pd.tenant IN (?))
+	) AS granted FROM perm, super_role`,
+		bun.Ident(s.prefix+"permissions"), bun.In(permNames), s.opts.Namespace, bun.In([]string{"", tenant}),
+		bun.Ident(s.prefix+"user_roles"), bun.Ident(s.prefix+"roles"), s.opts.Namespace, userKey,
+		bun.In([]string{"", tenant}), at, at,
+		bun.Ident(s.prefix+"group_members"), bun.Ident(s.prefix+"group_roles"), bun.Ident(s.prefix+"roles"),
+		s.opts.Namespace, userKey, s.defaultRoles(tenant),
+		bun.Ident(s.prefix+"roles"), s.opts.Namespace, bun.In([]string{"", tenant}), userKey,
+		bun.Ident(s.prefix+"role_composites"), bun.Ident(s.prefix+"role_parents"), bun.Ident(s.prefix+"roles"),
+		s.opts.Namespace, s.opts.SuperRole, bun.Ident(s.prefix+"roles"), s.opts.SuperRole, s.opts.Namespace,
+		bun.Ident(s.prefix+"role_permissions"), at, at,
+		bun.Ident(s.prefix+"permission_denials"), userKey, bun.In([]string{"", tenant}),
+	).Scan(ctx, &results)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, err
+	}
+
+	// the stored names may differ by case from the given ones on citext columns
+	requested := make(map[string][]string, len(permNames))
+	for _, permName := range permNames {
+		requested[s.nameKey(permName)] = append(requested[s.nameKey(permName)], permName)
+	}
+
+	for _, r := range results {
+		for _, permName := range requested[s.nameKey(r.Name)] {
+			granted[permName], conditions[permName] = r.Granted, r.Condition
+		}
+	}
+
+	return granted, conditions, nil
+}
+
+// AssignPermission implements Store
+func (s *BunStore) AssignPermission(ctx context.Context, rolePerm *RolePermission) error {
+	// a concurrent assignment of the same permission is not an error
+	res, err := s.db.NewInsert().Model(rolePerm).ModelTableExpr(s.tableRolePerm).
+		On("CONFLICT (role_id, permission_id) DO NOTHING").Exec(ctx)
+	if err != nil {
+		return pgConstraintError(err, EntityRolePermission, fmt.Sprintf("%d/%d", rolePerm.RoleID, rolePerm.PermissionID))
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrPermissionAlreadyAssigned
+	}
+
+	return nil
+}
+
+// RevokePermission implements Store
+func (s *BunStore) RevokePermission(ctx context.Context, roleID, permID uint) error {
+	_, err := s.db.NewDelete().Model((*RolePermission)(nil)).ModelTableExpr(s.tableRolePerm).
+		Where("role_id = ?", roleID).Where("permission_id = ?", permID).Exec(ctx)
+
+	return err
+}
+
+// RevokeRolePermissions implements Store
+func (s *BunStore) RevokeRolePermissions(ctx context.Context, roleID uint) error {
+	_, err := s.db.NewDelete().Model((*RolePermission)(nil)).ModelTableExpr(s.tableRolePerm).
+		Where("role_id = ?", roleID).Exec(ctx)
+
+	return err
+}
+
+// GetResourcePermissions implements Store
+func (s *BunStore) GetResourcePermissions(ctx context.Context, resourceType string, resourceID string) (
+	[]ResourcePermission, error) {
+	var grants []ResourcePermission
+	if err := s.db.NewSelect().Model(&grants).ModelTableExpr(s.tableResource).
+		Where("resource_type = ?", resourceType).Where("resource_id = ?", resourceID).Order("id").
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return grants, nil
+}
+
+// HasResourcePermission implements Store
+func (s *BunStore) HasResourcePermission(ctx context.Context, permID uint, resourceType string, resourceID string,
+	userKey string, tenant string, roleIDs []uint) (bool, error) {
+	return s.db.NewSelect().Model((*ResourcePermission)(nil)).ModelTableExpr(s.tableResource).
+		Where("permission_id = ?", permID).Where("resource_type = ?", resourceType).
+		Where("resource_id = ?", resourceID).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			q = q.Where("user_key = ? AND tenant IN (?)", userKey, bun.In([]string{"", tenant}))
+			if len(roleIDs) > 0 {
+				q = q.WhereOr("role_id IN (?)", bun.In(roleIDs))
+			}
+			return q
+		}).Exists(ctx)
+}
+
+// GetGroup implements Store
+func (s *BunStore) GetGroup(ctx context.Context, groupName string) (*Group, error) {
+	var group Group
+	err := s.db.NewSelect().Model(&group).ModelTableExpr(s.tableGroup).Where("name = ?", groupName).Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrGroupNotFound, groupName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// CreateGroup implements Store
+func (s *BunStore) CreateGroup(ctx context.Context, group *Group) error {
+	_, err := s.db.NewInsert().Model(group).ModelTableExpr(s.tableGroup).Exec(ctx)
+
+	return pgConstraintError(err, EntityGroup, group.Name)
+}
+
+// DeleteGroup implements Store
+func (s *BunStore) DeleteGroup(ctx context.Context, groupID uint) error {
+	_, err := s.db.NewDelete().Model((*Group)(nil)).ModelTableExpr(s.tableGroup).
+		Where("id = ?", groupID).Exec(ctx)
+
+	return err
+}
+
+// AddGroupMember implements Store
+func (s *BunStore) AddGroupMember(ctx context.Context, member *GroupMember) error {
+	_, err := s.db.NewInsert().Model(member).ModelTableExpr(s.tableMember).
+		On("CONFLICT (group_id, user_key) DO NOTHING").Exec(ctx)
+
+	return err
+}
+
+// RemoveGroupMember implements Store
+func (s *BunStore) RemoveGroupMember(ctx context.Context, groupID uint, userKey string) error {
+	_, err := s.db.NewDelete().Model((*GroupMember)(nil)).ModelTableExpr(s.tableMember).
+		Where("group_id = ?", groupID).Where("user_key = ?", userKey).Exec(ctx)
+
+	return err
+}
+
+// GetGroupMembers implements Store
+func (s *BunStore) GetGroupMembers(ctx context.Context, groupID uint) ([]string, error) {
+	var users []string
+	err := s.db.NewSelect().Model((*GroupMember)(nil)).ModelTableExpr(s.tableMember).Column("user_key").
+		Where("group_id = ?", groupID).Order("user_key").Scan(ctx, &users)
+
+	return users, err
+}
+
+// GetUserGroups implements Store
+func (s *BunStore) GetUserGroups(ctx context.Context, userKey string) ([]Group, error) {
+	var groups []Group
+	err := s.db.NewSelect().Model(&groups).ModelTableExpr(s.tableGroup).
+		Where("grp.id IN (?)", s.db.NewSelect().Model((*GroupMember)(nil)).ModelTableExpr(s.tableMember).
+			Column("group_id").Where("user_key = ?", userKey)).
+		Order("grp.name").Scan(ctx)
+
+	return groups, err
+}
+
+// AssignGroupRole implements Store
+func (s *BunStore) AssignGroupRole(ctx context.Context, groupRole *GroupRole) error {
+	_, err := s.db.NewInsert().Model(groupRole).ModelTableExpr(s.tableGroupRole).
+		On("CONFLICT (group_id, role_id) DO NOTHING").Exec(ctx)
+
+	return err
+}
+
+// RevokeGroupRole implements Store
+func (s *BunStore) RevokeGroupRole(ctx context.Context, groupID uint, roleID uint) error {
+	_, err := s.db.NewDelete().Model((*GroupRole)(nil)).ModelTableExpr(s.tableGroupRole).
+		Where("group_id = ?", groupID).Where("role_id = ?", roleID).Exec(ctx)
+
+	return err
+}
+
+// GetGroupRoleIDs implements Store
+func (s *BunStore) GetGroupRoleIDs(ctx context.Context, groupID uint) ([]uint, error) {
+	var roleIDs []uint
+	err := s.db.NewSelect().Model((*GroupRole)(nil)).ModelTableExpr(s.tableGroupRole).Column("role_id").
+		Where("group_id = ?", groupID).Order("role_id").Scan(ctx, &roleIDs)
+
+	return roleIDs, err
+}
+
+// GetUserGroupRoleIDs implements Store
+func (s *BunStore) GetUserGroupRoleIDs(ctx context.Context, userKey string) ([]uint, error) {
+	var roleIDs []uint
+	err := s.db.NewSelect().Model((*GroupRole)(nil)).ModelTableExpr(s.tableGroupRole).Distinct().
+		Column("gr.role_id").Join("JOIN ? AS gm ON gm.group_id = gr.group_id", bun.Ident(s.prefix+"group_members")).
+		Where("gm.user_key = ?", userKey).Scan(ctx, &roleIDs)
+
+	return roleIDs, err
+}
+
+// ForgetUser implements Store
+func (s *BunStore) ForgetUser(ctx context.Context, userKey string) error {
+	// the grants and the denials to the roles have an empty user key
+	if userKey == "" {
+		return nil
+	}
+
+	for _, table := range []string{
+		"user_roles", "assignment_claims", "resource_permissions", "permission_denials", "group_members",
+		"access_requests", "user_tombstones",
+	} {
+		if _, err := s.db.NewDelete().TableExpr("?", bun.Ident(s.prefix+table)).
+			Where("user_key = ?", userKey).Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.db.NewUpdate().Model((*AuditEntry)(nil)).ModelTableExpr(s.tableAudit).
+		Set("user_key = ''").Set("user_id = NULL").Where("user_key = ?", userKey).Exec(ctx)
+
+	return err
+}
+
+// GetPermissionDenials implements Store
+func (s *BunStore) GetPermissionDenials(ctx context.Context, permID uint) ([]PermissionDenial, error) {
+	var denials []PermissionDenial
+	err := s.db.NewSelect().Model(&denials).ModelTableExpr(s.tableDenial).
+		Where("permission_id = ?", permID).Order("id").Scan(ctx)
+
+	return denials, err
+}
+
+// GetUserPermissionDenials implements Store
+func (s *BunStore) GetUserPermissionDenials(ctx context.Context, userKey string) ([]PermissionDenial, error) {
+	var denials []PermissionDenial
+	err := s.db.NewSelect().Model(&denials).ModelTableExpr(s.tableDenial).
+		Where("user_key = ?", userKey).Order("id").Scan(ctx)
+
+	return denials, err
+}
+
+// IsPermissionDenied implements Store
+func (s *BunStore) IsPermissionDenied(ctx context.Context, permID uint, userKey string, tenant string,
+	roleIDs []uint) (bool, error) {
+	if userKey == "" && len(roleIDs) == 0 {
+		return false, nil
+	}
+
+	return s.db.NewSelect().Model((*PermissionDenial)(nil)).ModelTableExpr(s.tableDenial).
+		Where("permission_id = ?", permID).
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			if userKey != "" {
+				q = q.WhereOr("user_key = ? AND tenant IN (?)", userKey, bun.In([]string{"", tenant}))
+			}
+			if len(roleIDs) > 0 {
+				q = q.WhereOr("role_id IN (?)", bun.In(roleIDs))
+			}
+			return q
+		}).Exists(ctx)
+}
+
+// DenyPermission implements Store
+func (s *BunStore) DenyPermission(ctx context.Context, denial *PermissionDenial) error {
+	_, err := s.db.NewInsert().Model(denial).ModelTableExpr(s.tableDenial).
+		On("CONFLICT DO NOTHING").Exec(ctx)
+
+	return pgConstraintError(err, EntityPermissionDenial, fmt.Sprint(denial.PermissionID))
+}
+
+// RevokePermissionDenial implements Store
+func (s *BunStore) RevokePermissionDenial(ctx context.Context, denial *PermissionDenial) error {
+	q := s.db.NewDelete().Model((*PermissionDenial)(nil)).ModelTableExpr(s.tableDenial).
+		Where("permission_id = ?", denial.PermissionID).Where("user_key = ?", denial.UserKey).
+		Where("tenant = ?", denial.Tenant)
+	if denial.RoleID != 0 {
+		q = q.Where("role_id = ?", denial.RoleID)
+	} else {
+		q = q.Where("role_id IS NULL")
+	}
+
+	_, err := q.Exec(ctx)
+
+	return err
+}
+
+// GetUserResourcePermissions implements Store
+func (s *BunStore) GetUserResourcePermissions(ctx context.Context, userKey string) ([]ResourcePermission, error) {
+	var grants []ResourcePermission
+	err := s.db.NewSelect().Model(&grants).ModelTableExpr(s.tableResource).
+		Where("user_key = ?", userKey).Where("role_id IS NULL").
+		Where("permission_id IN (?)", s.db.NewSelect().Model((*Permission)(nil)).ModelTableExpr(s.tablePerm).
+			Column("id").Where("namespace = ?", s.opts.Namespace)).
+		Order("id").Scan(ctx)
+
+	return grants, err
+}
+
+// SaveUserTombstone implements Store
+func (s *BunStore) SaveUserTombstone(ctx context.Context, tombstone *UserTombstone) error {
+	_, err := s.db.NewInsert().Model(tombstone).ModelTableExpr(s.tableTombstone).Exec(ctx)
+
+	return err
+}
+
+// GetUserTombstones implements Store
+func (s *BunStore) GetUserTombstones(ctx context.Context, userKey string) ([]UserTombstone, error) {
+	var tombstones []UserTombstone
+	err := s.db.NewSelect().Model(&tombstones).ModelTableExpr(s.tableTombstone).
+		Where("user_key = ?", userKey).Order("offboarded_at", "id").Scan(ctx)
+
+	return tombstones, err
+}
+
+// GrantResourcePermission implements Store
+func (s *BunStore) GrantResourcePermission(ctx context.Context, grant *ResourcePermission) error {
+	_, err := s.db.NewInsert().Model(grant).ModelTableExpr(s.tableResource).
+		On("CONFLICT DO NOTHING").Exec(ctx)
+
+	return pgConstraintError(err, EntityResourcePermission, grant.ResourceType+":"+grant.ResourceID)
+}
+
+// RevokeResourcePermission implements Store
+func (s *BunStore) RevokeResourcePermission(ctx context.Context, grant *ResourcePermission) error {
+	q := s.db.NewDelete().Model((*ResourcePermission)(nil)).ModelTableExpr(s.tableResource).
+		Where("permission_id = ?", grant.PermissionID).Where("resource_type = ?", grant.ResourceType).
+		Where("resource_id = ?", grant.ResourceID).Where("user_key = ?", grant.UserKey).
+		Where("tenant = ?", grant.Tenant)
+	if grant.RoleID != 0 {
+		q = q.Where("role_id = ?", grant.RoleID)
+	} else {
+		q = q.Where("role_id IS NULL")
+	}
+
+	_, err := q.Exec(ctx)
+
+	return err
+}
+
+// GetUserRole implements Store
+func (s *BunStore) GetUserRole(ctx context.Context, userKey string, roleID uint, tenant string) (*UserRole, error) {
+	var userRole UserRole
+	if err := s.db.NewSelect().Model(&userRole).ModelTableExpr(s.tableUserRole).
+		Where("user_key = ?", userKey).Where("role_id = ?", roleID).Where("tenant = ?", tenant).
+		Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: role %d of the user %s", ErrUserRoleNotFound, roleID, userKey)
+		}
+		return nil, err
+	}
+
+	return &userRole, nil
+}
+
+// GetUserRoles implements Store
+func (s *BunStore) GetUserRoles(ctx context.Context, userKey string) ([]UserRole, error) {
+	var userRoles []UserRole
+	if err := s.db.NewSelect().Model(&userRoles).ModelTableExpr(s.tableUserRole).
+		Where("user_key = ?", userKey).Where("role_id IN (?)", s.namespaceRoles()).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return userRoles, nil
+}
+
+// GetAssignedRoles implements Store
+func (s *BunStore) GetAssignedRoles(ctx context.Context, userKey string, tenant string, at time.Time) ([]Role, error) {
+	var roles []Role
+	if err := s.db.NewSelect().Model(&roles).ModelTableExpr(s.tableRole).
+		Join("JOIN ? AS ur ON ur.role_id = role.id", bun.Ident(s.prefix+"user_roles")).
+		Where("role.namespace = ?", s.opts.Namespace).
+		Where("ur.user_key = ?", userKey).Where("ur.tenant IN (?)", bun.In([]string{"", tenant})).
+		Where("ur.starts_at IS NULL OR ur.starts_at <= ?", at).Where("ur.expires_at IS NULL OR ur.expires_at > ?", at).
+		OrderExpr("ur.id").Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// GetUsersRoles implements Store
+func (s *BunStore) GetUsersRoles(ctx context.Context, userKeys []string) ([]UserRole, error) {
+	if len(userKeys) == 0 {
+		return nil, nil
+	}
+
+	var userRoles []UserRole
+	if err := s.db.NewSelect().Model(&userRoles).ModelTableExpr(s.tableUserRole).
+		Where("user_key IN (?)", bun.In(userKeys)).Where("role_id IN (?)", s.namespaceRoles()).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return userRoles, nil
+}
+
+// GetRoleUsers implements Store
+func (s *BunStore) GetRoleUsers(ctx context.Context, roleID uint, tenant string, at time.Time, numeric bool,
+	page Page) ([]string, error) {
+	q := s.db.NewSelect().Model((*UserRole)(nil)).ModelTableExpr(s.tableUserRole).Distinct().
+		Column("user_id", "user_key").Where("role_id = ?", roleID).Where("tenant = ?", tenant).
+		Where("starts_at IS NULL OR starts_at <= ?", at).Where("expires_at IS NULL OR expires_at > ?", at).
+		OrderExpr("user_id NULLS LAST, user_key")
+	if numeric {
+		q = q.Where("user_id IS NOT NULL")
+	}
+	if page.Limit > 0 {
+		q = q.Limit(page.Limit)
+	}
+	if page.Offset > 0 {
+		q = q.Offset(page.Offset)
+	}
+
+	var userRoles []UserRole
+	if err := q.Scan(ctx, &userRoles); err != nil {
+		return nil, err
+	}
+
+	users := make([]string, 0, len(userRoles))
+	for _, ur := range userRoles {
+		users = append(users, ur.UserKey)
+	}
+
+	return users, nil
+}
+
+// ListUserRoles implements Store
+func (s *BunStore) ListUserRoles(ctx context.Context) ([]UserRole, error) {
+	var userRoles []UserRole
+	if err := s.db.NewSelect().Model(&userRoles).ModelTableExpr(s.tableUserRole).
+		Where("role_id IN (?)", s.namespaceRoles()).Order("user_id", "id").Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return userRoles, nil
+}
+
+// AssignRole implements Store
+func (s *BunStore) AssignRole(ctx context.Context, userRole *UserRole) error {
+	// a concurrent assignment of the same role is reported as already assigned
+	res, err := s.db.NewInsert().Model(userRole).ModelTableExpr(s.tableUserRole).
+		On("CONFLICT (user_key, role_id, tenant) DO NOTHING").Exec(ctx)
+	if err != nil {
+		return pgConstraintError(err, EntityUserRole, fmt.Sprintf("%s/%d", userRole.UserKey, userRole.RoleID))
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrRoleAlreadyAssigned
+	}
+
+	return nil
+}
+
+// AssignRoles implements Store, the assignments are inserted in batches of assignBatchSize rows. in the
+// transactions of InTx on a pgdriver connection, the large imports are streamed with COPY FROM instead
+func (s *BunStore) AssignRoles(ctx context.Context, userRoles []UserRole) error {
+	if len(userRoles) >= copyThreshold && s.copyable() {
+		return s.copyUserRoles(ctx, userRoles)
+	}
+
+	for start := 0; start < len(userRoles); start += assignBatchSize {
+		end := start + assignBatchSize
+		if end > len(userRoles) {
+			end = len(userRoles)
+		}
+
+		batch := userRoles[start:end]
+		if _, err := s.db.NewInsert().Model(&batch).ModelTableExpr(s.tableUserRole).
+			On("CONFLICT (user_key, role_id, tenant) DO NOTHING").Exec(ctx); err != nil {
+			return pgConstraintError(err, EntityUserRole, "")
+		}
+	}
+
+	return nil
+}
+
+// copyable reports whether the store can use COPY FROM, it needs the pgdriver connection of a transaction
+func (s *BunStore) copyable() bool {
+	if s.conn == nil {
+		return false
+	}
+
+	var ok bool
+	_ = s.conn.Raw(func(driverConn interface{}) error {
+		_, ok = driverConn.(*pgdriver.Conn)
+		return nil
+	})
+
+	return ok
+}
+
+// copyUserRoles streams the assignments with COPY FROM in the text format to a temporary table dropped at
+// the commit, then inserts them skipping the ones already assigned like the batches do
+func (s *BunStore) copyUserRoles(ctx context.Context, userRoles []UserRole) error {
+	columns := bun.Safe("user_id, user_key, role_id, tenant, starts_at, expires_at, granted_by")
+	staging := bun.Ident(s.prefix + "user_roles_copy")
+
+	// the table is created with the types of the columns, a previous import of the transaction left it empty
+	if _, err := s.db.ExecContext(ctx, "CREATE TEMP TABLE IF NOT EXISTS ? ON COMMIT DROP AS SELECT ? FROM ? WITH NO DATA",
+		staging, columns, bun.Ident(s.prefix+"user_roles")); err != nil {
+		return err
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		bw := bufio.NewWriter(w)
+		for _, ur := range userRoles {
+			userID := `\N`
+			if ur.UserID != 0 {
+				userID = strconv.FormatUint(uint64(ur.UserID), 10)
+			}
+
+			fields := []string{
+				userID, copyText(ur.UserKey), strconv.FormatUint(uint64(ur.RoleID), 10), copyText(ur.Tenant),
+				copyTime(ur.StartsAt), copyTime(ur.ExpiresAt), copyText(ur.GrantedBy),
+			}
+			if _, err := bw.WriteString(strings.Join(fields, "\t") + "\n"); err != nil {
+				_ = w.CloseWithError(err)
+				return
+			}
+		}
+		_ = w.CloseWithError(bw.Flush())
+	}()
+
+	_, err := pgdriver.CopyFrom(ctx, *s.conn, r, "COPY ? (?) FROM STDIN", staging, columns)
+	_ = r.Close()
+	if err != nil {
+		return err
+	}
+
+	if _, err = s.db.ExecContext(ctx,
+		"INSERT INTO ? (?) SELECT ? FROM ? ON CONFLICT (user_key, role_id, tenant) DO NOTHING",
+		bun.Ident(s.prefix+"user_roles"), columns, columns, staging); err != nil {
+		return pgConstraintError(err, EntityUserRole, "")
+	}
+
+	_, err = s.db.ExecContext(ctx, "TRUNCATE ?", staging)
+
+	return err
+}
+
+// copyEscaper escapes the values of the COPY text format
+var copyEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func copyText(v string) string {
+	return copyEscaper.Replace(v)
+}
+
+// copyTime formats a time of the COPY text format, the zero time is null
+func copyTime(t time.Time) string {
+	if t.IsZero() {
+		return `\N`
+	}
+
+	return t.Format("2006-01-02 15:04:05.999999-07:00")
+}
+
+// GetTimeBoundUserRoles implements Store
+func (s *BunStore) GetTimeBoundUserRoles(ctx context.Context, roleID uint, tenant string, userKeys []string) ([]UserRole, error) {
+	q := s.db.NewSelect().Model((*UserRole)(nil)).ModelTableExpr(s.tableUserRole).
+		Where("expires_at IS NOT NULL").Where("tenant = ?", tenant).Where("role_id IN (?)", s.namespaceRoles())
+	if roleID != 0 {
+		q = q.Where("role_id = ?", roleID)
+	}
+	if len(userKeys) > 0 {
+		q = q.Where("user_key IN (?)", bun.In(userKeys))
+	}
+
+	var userRoles []UserRole
+	if err := q.Order("id").Scan(ctx, &userRoles); err != nil {
+		return nil, err
+	}
+
+	return userRoles, nil
+}
+
+// GetExpiredUserRoles implements Store
+func (s *BunStore) GetExpiredUserRoles(ctx context.Context, at time.Time) ([]UserRole, error) {
+	var userRoles []UserRole
+	if err := s.db.NewSelect().Model(&userRoles).ModelTableExpr(s.tableUserRole).
+		Where("expires_at <= ?", at).Where("role_id IN (?)", s.namespaceRoles()).Order("id").Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return userRoles, nil
+}
+
+// SetUserRolesExpiry implements Store
+func (s *BunStore) SetUserRolesExpiry(ctx context.Context, userRoleIDs []uint, expiresAt time.Time) error {
+	if len(userRoleIDs) == 0 {
+		return nil
+	}
+
+	_, err := s.db.NewUpdate().Model((*UserRole)(nil)).ModelTableExpr(s.tableUserRole).
+		Set("expires_at = ?", expiresAt).Where("id IN (?)", bun.In(userRoleIDs)).Exec(ctx)
+
+	return err
+}
+
+// RevokeRole implements Store
+func (s *BunStore) RevokeRole(ctx context.Context, userKey string, roleID uint, tenant string) error {
+	_, err := s.db.NewDelete().Model((*UserRole)(nil)).ModelTableExpr(s.tableUserRole).
+		Where("user_key = ?", userKey).Where("role_id = ?", roleID).Where("tenant = ?", tenant).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.NewDelete().Model((*AssignmentClaim)(nil)).ModelTableExpr(s.tableClaim).
+		Where("user_key = ?", userKey).Where("role_id = ?", roleID).Where("tenant = ?", tenant).Exec(ctx)
+
+	return err
+}
+
+// ClaimAssignments implements Store
+func (s *BunStore) ClaimAssignments(ctx context.Context, claims []AssignmentClaim) error {
+	if len(claims) == 0 {
+		return nil
+	}
+
+	for start := 0; start < len(claims); start += assignBatchSize {
+		end := start + assignBatchSize
+		if end > len(claims) {
+			end = len(claims)
+		}
+
+		batch := claims[start:end]
+		if _, err := s.db.NewInsert().Model(&batch).ModelTableExpr(s.tableClaim).
+			On("CONFLICT (user_key, role_id, tenant, source) DO UPDATE").
+			Set("claimed_at = EXCLUDED.claimed_at").Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListConflictingClaims implements Store
+func (s *BunStore) ListConflictingClaims(ctx context.Context) ([]AssignmentClaim, error) {
+	conflicts := s.db.NewSelect().Model((*AssignmentClaim)(nil)).ModelTableExpr(s.prefix+"assignment_claims").
+		Column("user_key", "role_id", "tenant").Group("user_key", "role_id", "tenant").
+		Having("count(DISTINCT source) > 1")
+
+	var claims []AssignmentClaim
+	err := s.db.NewSelect().Model(&claims).ModelTableExpr(s.tableClaim).
+		Where("(ac.user_key, ac.role_id, ac.tenant) IN (?)", conflicts).Where("ac.role_id IN (?)", s.namespaceRoles()).
+		Order("ac.tenant", "ac.user_key", "ac.role_id", "ac.source").Scan(ctx)
+
+	return claims, err
+}
+
+// GetRoleComposites implements Store
+func (s *BunStore) GetRoleComposites(ctx context.Context, roleIDs []uint) ([]RoleComposite, error) {
+	if len(roleIDs) == 0 {
+		return nil, nil
+	}
+
+	var composites []RoleComposite
+	if err := s.db.NewSelect().Model(&composites).ModelTableExpr(s.tableComposite).
+		Where("role_id IN (?)", bun.In(roleIDs)).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return composites, nil
+}
+
+// AddRoleComposite implements Store
+func (s *BunStore) AddRoleComposite(ctx context.Context, roleID, memberID uint) error {
+	exists, err := s.db.NewSelect().Model((*RoleComposite)(nil)).ModelTableExpr(s.tableComposite).
+		Where("role_id = ?", roleID).Where("member_id = ?", memberID).Exists(ctx)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = s.db.NewInsert().Model(&RoleComposite{RoleID: roleID, MemberID: memberID}).
+		ModelTableExpr(s.tableComposite).Exec(ctx)
+
+	return pgConstraintError(err, EntityRoleComposite, fmt.Sprintf("%d/%d", roleID, memberID))
+}
+
+// RemoveRoleComposite implements Store
+func (s *BunStore) RemoveRoleComposite(ctx context.Context, roleID, memberID uint) error {
+	_, err := s.db.NewDelete().Model((*RoleComposite)(nil)).ModelTableExpr(s.tableComposite).
+		Where("role_id = ?", roleID).Where("member_id = ?", memberID).Exec(ctx)
+
+	return err
+}
+
+// GetRoleParents implements Store
+func (s *BunStore) GetRoleParents(ctx context.Context, roleIDs []uint) ([]RoleParent, error) {
+	if len(roleIDs) == 0 {
+		return nil, nil
+	}
+
+	var parents []RoleParent
+	if err := s.db.NewSelect().Model(&parents).ModelTableExpr(s.tableParent).
+		Where("role_id IN (?)", bun.In(roleIDs)).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return parents, nil
+}
+
+// GetRoleChildren implements Store
+func (s *BunStore) GetRoleChildren(ctx context.Context, parentID uint) ([]RoleParent, error) {
+	var children []RoleParent
+	if err := s.db.NewSelect().Model(&children).ModelTableExpr(s.tableParent).
+		Where("parent_id = ?", parentID).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return children, nil
+}
+
+// SetRoleParent implements Store
+func (s *BunStore) SetRoleParent(ctx context.Context, roleID, parentID uint) error {
+	_, err := s.db.NewInsert().Model(&RoleParent{RoleID: roleID, ParentID: parentID}).
+		ModelTableExpr(s.tableParent).On("CONFLICT (role_id) DO UPDATE").
+		Set("parent_id = EXCLUDED.parent_id").Exec(ctx)
+
+	return pgConstraintError(err, EntityRoleParent, fmt.Sprintf("%d/%d", roleID, parentID))
+}
+
+// RemoveRoleParent implements Store
+func (s *BunStore) RemoveRoleParent(ctx context.Context, roleID uint) error {
+	_, err := s.db.NewDelete().Model((*RoleParent)(nil)).ModelTableExpr(s.tableParent).
+		Where("role_id = ?", roleID).Exec(ctx)
+
+	return err
+}
+
+// GetPolicy implements Store
+func (s *BunStore) GetPolicy(ctx context.Context, name string) (*Policy, error) {
+	var policy Policy
+	if err := s.db.NewSelect().Model(&policy).ModelTableExpr(s.tablePolicy).
+		Where("name = ?", name).Limit(1).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrPolicyNotFound, name)
+		}
+
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// ListPolicies implements Store
+func (s *BunStore) ListPolicies(ctx context.Context) ([]Policy, error) {
+	var policies []Policy
+	if err := s.db.NewSelect().Model(&policies).ModelTableExpr(s.tablePolicy).
+		Order("name").Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// SavePolicy implements Store
+func (s *BunStore) SavePolicy(ctx context.Context, policy *Policy) error {
+	_, err := s.db.NewInsert().Model(policy).ModelTableExpr(s.tablePolicy).
+		On("CONFLICT (name) DO UPDATE").Set("expression = EXCLUDED.expression").Exec(ctx)
+
+	return err
+}
+
+// DeletePolicy implements Store
+func (s *BunStore) DeletePolicy(ctx context.Context, name string) error {
+	_, err := s.db.NewDelete().Model((*Policy)(nil)).ModelTableExpr(s.tablePolicy).
+		Where("name = ?", name).Exec(ctx)
+
+	return err
+}
+
+// policiesChannel is the channel the changes of the policies are notified on
+func (s *BunStore) policiesChannel() string {
+	return s.prefix + "authority_policies"
+}
+
+// NotifyPolicies implements PolicyNotifier
+func (s *BunStore) NotifyPolicies(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, "SELECT pg_notify(?, '')", s.policiesChannel())
+
+	return err
+}
+
+// ListenPolicies implements PolicyNotifier with a pgdriver listener, the store must use a *bun.DB opened with
+// pgdriver otherwise it returns ErrNotSupported
+func (s *BunStore) ListenPolicies(ctx context.Context, changed func()) error {
+	db, ok := s.db.(*bun.DB)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	ln := pgdriver.NewListener(db)
+	defer ln.Close()
+
+	if err := ln.Listen(ctx, s.policiesChannel()); err != nil {
+		return err
+	}
+
+	for {
+		if _, _, err := ln.Receive(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		changed()
+	}
+}
+
+// CreateAuditEntry implements Store
+func (s *BunStore) CreateAuditEntry(ctx context.Context, entry *AuditEntry) error {
+	_, err := s.db.NewInsert().Model(entry).ModelTableExpr(s.tableAudit).Exec(ctx)
+
+	return err
+}
+
+// ListChanges implements Store
+func (s *BunStore) ListChanges(ctx context.Context, afterID uint, before time.Time, limit int) (
+	[]AuditEntry, error) {
+	var entries []AuditEntry
+	if err := s.db.NewSelect().Model(&entries).ModelTableExpr(s.tableAudit).
+		Where("id > ?", afterID).Where("created_at < ?", before).Where("action <> ?", AuditPermissionChecked).
+		Order("id").Limit(limit).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// CreateAccessRequest implements Store
+func (s *BunStore) CreateAccessRequest(ctx context.Context, req *AccessRequest) error {
+	_, err := s.db.NewInsert().Model(req).ModelTableExpr(s.tableRequest).Exec(ctx)
+
+	return err
+}
+
+// GetAccessRequest implements Store
+func (s *BunStore) GetAccessRequest(ctx context.Context, id uint) (*AccessRequest, error) {
+	var req AccessRequest
+	if err := s.db.NewSelect().Model(&req).ModelTableExpr(s.tableRequest).Where("id = ?", id).Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %d", ErrAccessRequestNotFound, id)
+		}
+
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// ListAccessRequests implements Store
+func (s *BunStore) ListAccessRequests(ctx context.Context, userKey string, status string) ([]AccessRequest, error) {
+	q := s.db.NewSelect().Model((*AccessRequest)(nil)).ModelTableExpr(s.tableRequest).Order("id")
+	if userKey != "" {
+		q = q.Where("user_key = ?", userKey)
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+
+	var reqs []AccessRequest
+	if err := q.Scan(ctx, &reqs); err != nil {
+		return nil, err
+	}
+
+	return reqs, nil
+}
+
+// DecideAccessRequest implements Store
+func (s *BunStore) DecideAccessRequest(ctx context.Context, req *AccessRequest) error {
+	res, err := s.db.NewUpdate().Model(req).ModelTableExpr(s.tableRequest).
+		Column("status", "decided_by", "decided_at").WherePK().Where("status = ?", RequestPending).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrAccessRequestDecided
+	}
+
+	return nil
+}
+
+// AddRequestApproval implements Store, the request is locked until the end of the transaction
+func (s *BunStore) AddRequestApproval(ctx context.Context, approval *RequestApproval) (int, error) {
+	if _, err := s.db.NewSelect().Model((*AccessRequest)(nil)).ModelTableExpr(s.tableRequest).Column("id").
+		Where("id = ?", approval.RequestID).For("UPDATE").Exec(ctx); err != nil {
+		return 0, err
+	}
+
+	res, err := s.db.NewInsert().Model(approval).ModelTableExpr(s.tableApproval).
+		On("CONFLICT (request_id, approver) DO NOTHING").Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return 0, ErrApprovalAlreadyGiven
+	}
+
+	return s.db.NewSelect().Model((*RequestApproval)(nil)).ModelTableExpr(s.tableApproval).
+		Where("request_id = ?", approval.RequestID).Count(ctx)
+}
+
+// GetRequestApprovals implements Store
+func (s *BunStore) GetRequestApprovals(ctx context.Context, requestID uint) ([]RequestApproval, error) {
+	approvals := []RequestApproval{}
+	if err := s.db.NewSelect().Model(&approvals).ModelTableExpr(s.tableApproval).
+		Where("request_id = ?", requestID).Order("id").Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return approvals, nil
+}
+
+// CreateImportBatch implements Store
+func (s *BunStore) CreateImportBatch(ctx context.Context, batch *ImportBatch) (bool, error) {
+	res, err := s.db.NewInsert().Model(batch).ModelTableExpr(s.tableImport).
+		On("CONFLICT (key) DO NOTHING").Exec(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+
+	return n > 0, err
+}
+
+// GetImportBatch implements Store
+func (s *BunStore) GetImportBatch(ctx context.Context, key string) (*ImportBatch, error) {
+	var batch ImportBatch
+	if err := s.db.NewSelect().Model(&batch).ModelTableExpr(s.tableImport).Where("key = ?", key).
+		Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrImportNotFound, key)
+		}
+
+		return nil, err
+	}
+
+	return &batch, nil
+}
+
+// SaveImportBatch implements Store
+func (s *BunStore) SaveImportBatch(ctx context.Context, batch *ImportBatch) error {
+	_, err := s.db.NewInsert().Model(batch).ModelTableExpr(s.tableImport).
+		On("CONFLICT (key) DO UPDATE").Set("status = EXCLUDED.status").Set("items = EXCLUDED.items").
+		Set("error = EXCLUDED.error").Set("started_at = EXCLUDED.started_at").
+		Set("finished_at = EXCLUDED.finished_at").Exec(ctx)
+
+	return err
+}
+
+// schemaMigration is a version of the schema of the store
+type schemaMigration struct {
+	name    string
+	comment string
+	up      func(s *BunStore, ctx context.Context) error
+	down    func(s *BunStore, ctx context.Context) error
+}
+
+// schemaMigrations are the versions of the schema ordered by name, a change of the schema is a new migration
+// appended to the list, with its rollback. the applied migrations must not change
+var schemaMigrations = []schemaMigration{
+	{name: "00001", comment: "baseline", up: (*BunStore).migrateBaseline, down: (*BunStore).dropSchema},
+	{name: "00002", comment: "namespaces", up: (*BunStore).migrateNamespaces, down: (*BunStore).dropNamespaces},
+	{name: "00003", comment: "segments", up: (*BunStore).migrateSegments, down: (*BunStore).dropSegments},
+}
+
+// Migrate implements Store, it applies the versioned migrations of the schema that aren't applied yet, they are
+// recorded in the schema_migrations table prefixed like the others. the steps depending on the options, e.g.
+// the column types or the check functions, follow on every call. a store running on a transaction applies
+// the migrations without recording them, every migration is skipped when it's already applied
+func (s *BunStore) Migrate(ctx context.Context) error {
+	migrator, err := s.Migrator()
+	switch {
+	case err == nil:
+		if err = migrator.Init(ctx); err != nil {
+			return err
+		}
+		if _, err = migrator.Migrate(ctx); err != nil {
+			return err
+		}
+	case errors.Is(err, ErrNotSupported):
+		for _, m := range schemaMigrations {
+			if err = m.up(s, ctx); err != nil {
+				return err
+			}
+		}
+	default:
+		return err
+	}
+
+	if err = s.migrateColumnTypes(ctx); err != nil {
+		return err
+	}
+
+	if err = s.migrateNameColumns(ctx); err != nil {
+		return err
+	}
+
+	if err = s.createForeignKeyIndexes(ctx, false); err != nil {
+		return err
+	}
+
+	if s.opts.CheckFunctions {
+		if err = s.migrateCheckFunctions(ctx); err != nil {
+			return err
+		}
+	}
+
+	if s.opts.ReportingViews {
+		if err = s.migrateReportingViews(ctx); err != nil {
+			return err
+		}
+	}
+
+	if s.opts.Replication.Origin != "" {
+		return s.migrateReplication(ctx)
+	}
+
+	return nil
+}
+
+// Migrator returns the bun migrator of the versioned migrations of the schema, e.g. to report their status from
+// a command line tool. it returns ErrNotSupported when the store runs on a transaction
+func (s *BunStore) Migrator() (*migrate.Migrator, error) {
+	db, ok := s.db.(*bun.DB)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+
+	migrations := migrate.NewMigrations()
+	for _, m := range schemaMigrations {
+		m := m
+		migrations.Add(migrate.Migration{
+			Name: m.name, Comment: m.comment,
+			Up: func(ctx context.Context, db *bun.DB) error {
+				store := *s
+				store.db = db
+				return m.up(&store, ctx)
+			},
+			Down: func(ctx context.Context, db *bun.DB) error {
+				store := *s
+				store.db = db
+				return m.down(&store, ctx)
+			},
+		})
+	}
+
+	// a failed migration is not recorded so it runs again
+	return migrate.NewMigrator(db, migrations, migrate.WithTableName(s.prefix+"schema_migrations"),
+		migrate.WithLocksTableName(s.prefix+"schema_migration_locks"), migrate.WithMarkAppliedOnSuccess(true)), nil
+}
+
+// RollbackMigration rolls back the migrations applied by the last call of Migrate that applied any and returns
+// their names, rolling back the baseline drops every table of the store. it returns ErrNotSupported when
+// the store runs on a transaction
+func (s *BunStore) RollbackMigration(ctx context.Context) ([]string, error) {
+	migrator, err := s.Migrator()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = migrator.Init(ctx); err != nil {
+		return nil, err
+	}
+
+	var group *migrate.MigrationGroup
+	if group, err = migrator.Rollback(ctx); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(group.Migrations))
+	for _, m := range group.Migrations {
+		names = append(names, m.String())
+	}
+
+	return names, nil
+}
+
+// dropSchema drops the tables, the views and the functions of the store
+func (s *BunStore) dropSchema(ctx context.Context) error {
+	if err := s.dropReportingViews(ctx); err != nil {
+		return err
+	}
+
+	for _, function := range []string{s.checkFunction() + " (text, text, text)",
+		s.checkFunction() + " (bigint, text, text)", s.prefix + "authority_stamp ()"} {
+		if _, err := s.db.ExecContext(ctx, "DROP FUNCTION IF EXISTS "+function+" CASCADE"); err != nil {
+			return err
+		}
+	}
+
+	for i := len(storeTables) - 1; i >= 0; i-- {
+		if _, err := s.db.ExecContext(ctx, "DROP TABLE IF EXISTS ? CASCADE",
+			bun.Ident(s.prefix+storeTables[i])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateBaseline creates the tables of the first version of the schema. it brings up to date the tables
+// created before the migrations were versioned, every step is skipped when it's already applied
+func (s *BunStore) migrateBaseline(ctx context.Context) error {
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*Role)(nil)).
+		ModelTableExpr(s.prefix + "roles").Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*Permission)(nil)).
+		ModelTableExpr(s.prefix + "permissions").Exec(ctx); err != nil {
+		return err
+	}
+
+	roleFk1 := fmt.Sprintf(`("role_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"roles")
+	roleFk2 := fmt.Sprintf(`("permission_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"permissions")
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*RolePermission)(nil)).
+		ModelTableExpr(s.prefix + "role_permissions").
+		ForeignKey(roleFk1).ForeignKey(roleFk2).Exec(ctx); err != nil {
+		return err
+	}
+
+	compositeFk1 := fmt.Sprintf(`("role_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"roles")
+	compositeFk2 := fmt.Sprintf(`("member_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"roles")
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*RoleComposite)(nil)).
+		ModelTableExpr(s.prefix + "role_composites").
+		ForeignKey(compositeFk1).ForeignKey(compositeFk2).Exec(ctx); err != nil {
+		return err
+	}
+
+	parentFk1 := fmt.Sprintf(`("role_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"roles")
+	parentFk2 := fmt.Sprintf(`("parent_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"roles")
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*RoleParent)(nil)).
+		ModelTableExpr(s.prefix + "role_parents").
+		ForeignKey(parentFk1).ForeignKey(parentFk2).Exec(ctx); err != nil {
+		return err
+	}
+
+	userFk1 := fmt.Sprintf(`("role_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"roles")
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*UserRole)(nil)).
+		ModelTableExpr(s.prefix + "user_roles").
+		ForeignKey(userFk1).Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*AuditEntry)(nil)).
+		ModelTableExpr(s.prefix + "audit_entries").Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*Policy)(nil)).
+		ModelTableExpr(s.prefix + "policies").Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*AccessRequest)(nil)).
+		ModelTableExpr(s.prefix + "access_requests").Exec(ctx); err != nil {
+		return err
+	}
+
+	approvalFk := fmt.Sprintf(`("request_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"access_requests")
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*RequestApproval)(nil)).
+		ModelTableExpr(s.prefix + "request_approvals").ForeignKey(approvalFk).Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewCreateIndex().IfNotExists().Unique().ModelTableExpr(s.prefix+"request_approvals").
+		Index(s.prefix+"request_approvals_request_id_approver_key").Column("request_id", "approver").Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*ImportBatch)(nil)).
+		ModelTableExpr(s.prefix + "import_batches").Exec(ctx); err != nil {
+		return err
+	}
+
+	claimFk := fmt.Sprintf(`("role_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"roles")
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*AssignmentClaim)(nil)).
+		ModelTableExpr(s.prefix + "assignment_claims").ForeignKey(claimFk).Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewCreateIndex().IfNotExists().Unique().ModelTableExpr(s.prefix+"assignment_claims").
+		Index(s.prefix+"assignment_claims_user_key_role_id_tenant_source_key").
+		Column("user_key", "role_id", "tenant", "source").Exec(ctx); err != nil {
+		return err
+	}
+
+	resourceFk1 := fmt.Sprintf(`("permission_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"permissions")
+	resourceFk2 := fmt.Sprintf(`("role_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"roles")
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*ResourcePermission)(nil)).
+		ModelTableExpr(s.prefix + "resource_permissions").
+		ForeignKey(resourceFk1).ForeignKey(resourceFk2).Exec(ctx); err != nil {
+		return err
+	}
+
+	// a grant is stored once, the role of the grants to a user is null
+	if _, err := s.db.NewCreateIndex().IfNotExists().Unique().ModelTableExpr(s.prefix+"resource_permissions").
+		Index(s.prefix+"resource_permissions_grant_key").
+		Column("resource_type", "resource_id", "permission_id", "user_key", "tenant").
+		ColumnExpr("coalesce(role_id, 0)").Exec(ctx); err != nil {
+		return err
+	}
+
+	denialFk1 := fmt.Sprintf(`("permission_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"permissions")
+	denialFk2 := fmt.Sprintf(`("role_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"roles")
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*PermissionDenial)(nil)).
+		ModelTableExpr(s.prefix + "permission_denials").
+		ForeignKey(denialFk1).ForeignKey(denialFk2).Exec(ctx); err != nil {
+		return err
+	}
+
+	// a denial is stored once, the role of the denials to a user is null
+	if _, err := s.db.NewCreateIndex().IfNotExists().Unique().ModelTableExpr(s.prefix+"permission_denials").
+		Index(s.prefix+"permission_denials_denial_key").
+		Column("permission_id", "user_key", "tenant").ColumnExpr("coalesce(role_id, 0)").Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*Group)(nil)).
+		ModelTableExpr(s.prefix + "groups").Exec(ctx); err != nil {
+		return err
+	}
+
+	memberFk := fmt.Sprintf(`("group_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"groups")
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*GroupMember)(nil)).
+		ModelTableExpr(s.prefix + "group_members").ForeignKey(memberFk).Exec(ctx); err != nil {
+		return err
+	}
+
+	groupRoleFk1 := fmt.Sprintf(`("group_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"groups")
+	groupRoleFk2 := fmt.Sprintf(`("role_id") REFERENCES "%s" ("id") ON DELETE CASCADE`, s.prefix+"roles")
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*GroupRole)(nil)).
+		ModelTableExpr(s.prefix + "group_roles").ForeignKey(groupRoleFk1).ForeignKey(groupRoleFk2).Exec(ctx); err != nil {
+		return err
+	}
+
+	groupKeys := []struct {
+		table   string
+		columns []string
+	}{
+		{"group_members", []string{"group_id", "user_key"}},
+		{"group_roles", []string{"group_id", "role_id"}},
+	}
+	for _, key := range groupKeys {
+		if _, err := s.db.NewCreateIndex().IfNotExists().Unique().ModelTableExpr(s.prefix + key.table).
+			Index(s.prefix + key.table + "_" + strings.Join(key.columns, "_") + "_key").
+			Column(key.columns...).Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.db.NewCreateIndex().IfNotExists().ModelTableExpr(s.prefix + "group_members").
+		Index(s.prefix + "group_members_user_key_idx").Column("user_key").Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*UserTombstone)(nil)).
+		ModelTableExpr(s.prefix + "user_tombstones").Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewCreateIndex().IfNotExists().ModelTableExpr(s.prefix + "user_tombstones").
+		Index(s.prefix + "user_tombstones_user_key_idx").Column("user_key").Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewCreateTable().IfNotExists().Model((*PolicyVersion)(nil)).
+		ModelTableExpr(s.prefix + "policy_versions").Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewInsert().Model(&PolicyVersion{ID: 1}).ModelTableExpr(s.tableVersion).
+		On("CONFLICT (id) DO NOTHING").Exec(ctx); err != nil {
+		return err
+	}
+
+	// tables created before tenants were supported
+	for _, table := range []string{"roles", "permissions", "user_roles", "audit_entries"} {
+		if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(s.prefix + table).
+			ColumnExpr("tenant varchar NOT NULL DEFAULT ''").Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	// the date of the assignments, unknown for the ones made before it was recorded
+	if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(s.prefix + "user_roles").
+		ColumnExpr("created_at timestamptz DEFAULT current_timestamp").Exec(ctx); err != nil {
+		return err
+	}
+
+	// users identified by a string, the numeric ids of the existing assignments become their keys
+	if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(s.prefix + "user_roles").
+		ColumnExpr("user_key varchar NOT NULL DEFAULT ''").Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewUpdate().Model((*UserRole)(nil)).ModelTableExpr(s.prefix + "user_roles").
+		Set("user_key = user_id::text").Where("user_key = ''").Where("user_id IS NOT NULL").Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(ctx, "ALTER TABLE ? ALTER COLUMN user_id DROP NOT NULL",
+		bun.Ident(s.prefix+"user_roles")); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewCreateIndex().IfNotExists().ModelTableExpr(s.prefix + "user_roles").
+		Index(s.prefix + "user_roles_user_key_idx").Column("user_key").Exec(ctx); err != nil {
+		return err
+	}
+
+	if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(s.prefix + "audit_entries").
+		ColumnExpr("user_key varchar").Exec(ctx); err != nil {
+		return err
+	}
+
+	// permissions granted to roles for a period
+	for _, column := range []string{"starts_at timestamptz", "expires_at timestamptz"} {
+		if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(s.prefix + "role_permissions").
+			ColumnExpr(column).Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	// time-bound assignments
+	if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(s.prefix + "user_roles").
+		ColumnExpr("expires_at timestamptz").Exec(ctx); err != nil {
+		return err
+	}
+
+	// conditional permissions
+	if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(s.prefix + "permissions").
+		ColumnExpr("condition varchar NOT NULL DEFAULT ''").Exec(ctx); err != nil {
+		return err
+	}
+
+	// assignments scheduled to start later
+	if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(s.prefix + "user_roles").
+		ColumnExpr("starts_at timestamptz").Exec(ctx); err != nil {
+		return err
+	}
+
+	// the actor who assigned the role
+	if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(s.prefix + "user_roles").
+		ColumnExpr("granted_by varchar NOT NULL DEFAULT ''").Exec(ctx); err != nil {
+		return err
+	}
+
+	// the descriptions and the custom attributes
+	for _, table := range []string{"roles", "permissions"} {
+		for _, column := range []string{"description varchar NOT NULL DEFAULT ''", "metadata jsonb"} {
+			if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(s.prefix + table).
+				ColumnExpr(column).Exec(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	// the lifecycle of the roles, the existing roles are active
+	if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(s.prefix + "roles").
+		ColumnExpr("state varchar NOT NULL DEFAULT 'active'").Exec(ctx); err != nil {
+		return err
+	}
+
+	// the actions and the resources of the permissions
+	for _, column := range []string{"action varchar NOT NULL DEFAULT ''", "resource varchar NOT NULL DEFAULT ''"} {
+		if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(s.prefix + "permissions").
+			ColumnExpr(column).Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.db.NewCreateIndex().IfNotExists().ModelTableExpr(s.prefix+"permissions").
+		Index(s.prefix+"permissions_resource_action_idx").Column("resource", "action").Exec(ctx); err != nil {
+		return err
+	}
+
+	// the names are unique within a tenant
+	for _, table := range []string{"roles", "permissions"} {
+		if _, err := s.db.ExecContext(ctx, "ALTER TABLE ? DROP CONSTRAINT IF EXISTS ?",
+			bun.Ident(s.prefix+table), bun.Ident(s.prefix+table+"_name_key")); err != nil {
+			return err
+		}
+
+		if _, err := s.db.NewCreateIndex().IfNotExists().Unique().ModelTableExpr(s.prefix+table).
+			Index(s.prefix+table+"_name_tenant_key").Column("name", "tenant").Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	// an assignment is stored once, the duplicates left by concurrent inserts are dropped first
+	assignmentKeys := []struct {
+		table   string
+		columns []string
+	}{
+		{"user_roles", []string{"user_key", "role_id", "tenant"}},
+		{"role_permissions", []string{"role_id", "permission_id"}},
+	}
+	for _, key := range assignmentKeys {
+		table := s.prefix + key.table
+		index := table + "_" + strings.Join(key.columns, "_") + "_key"
+
+		var matches []string
+		for _, column := range key.columns {
+			matches = append(matches, fmt.Sprintf("dup.%[1]s = kept.%[1]s", column))
+		}
+
+		if _, err := s.db.ExecContext(ctx, "DELETE FROM ? AS dup USING ? AS kept WHERE dup.id > kept.id AND "+
+			strings.Join(matches, " AND ")+" AND NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = ?)",
+			bun.Ident(table), bun.Ident(table), index); err != nil {
+			return err
+		}
+
+		if _, err := s.db.NewCreateIndex().IfNotExists().Unique().ModelTableExpr(table).
+			Index(index).Column(key.columns...).Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateNamespaces adds the namespaces of the roles and the permissions, the names are unique within a namespace
+// and a tenant
+func (s *BunStore) migrateNamespaces(ctx context.Context) error {
+	for _, table := range []string{"roles", "permissions"} {
+		if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(s.prefix + table).
+			ColumnExpr("namespace varchar NOT NULL DEFAULT ''").Exec(ctx); err != nil {
+			return err
+		}
+
+		if _, err := s.db.NewCreateIndex().IfNotExists().Unique().ModelTableExpr(s.prefix+table).
+			Index(s.prefix+table+"_namespace_name_tenant_key").Column("namespace", "name", "tenant").
+			Exec(ctx); err != nil {
+			return err
+		}
+
+		if _, err := s.db.ExecContext(ctx, "DROP INDEX IF EXISTS ?",
+			bun.Ident(s.prefix+table+"_name_tenant_key")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dropNamespaces rolls back migrateNamespaces, it fails when the namespaces have roles or permissions of the
+// same name and tenant
+func (s *BunStore) dropNamespaces(ctx context.Context) error {
+	for _, table := range []string{"roles", "permissions"} {
+		if _, err := s.db.NewCreateIndex().IfNotExists().Unique().ModelTableExpr(s.prefix+table).
+			Index(s.prefix+table+"_name_tenant_key").Column("name", "tenant").Exec(ctx); err != nil {
+			return err
+		}
+
+		if _, err := s.db.ExecContext(ctx, "ALTER TABLE ? DROP COLUMN IF EXISTS namespace",
+			bun.Ident(s.prefix+table)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateSegments adds the segments of the users granted the roles, see AssignRoleToSegment
+func (s *BunStore) migrateSegments(ctx context.Context) error {
+	for _, column := range []string{
+		"segment_percent integer NOT NULL DEFAULT 0", "segment_seed varchar NOT NULL DEFAULT ''",
+	} {
+		if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(s.prefix + "roles").
+			ColumnExpr(column).Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dropSegments rolls back migrateSegments, the roles granted to segments keep their assignments only
+func (s *BunStore) dropSegments(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, "ALTER TABLE ? DROP COLUMN IF EXISTS segment_percent, "+
+		"DROP COLUMN IF EXISTS segment_seed", bun.Ident(s.prefix+"roles"))
+
+	return err
+}
+
+// GetPolicyVersion implements PolicyVersioner
+func (s *BunStore) GetPolicyVersion(ctx context.Context) (uint64, error) {
+	var version PolicyVersion
+	if err := s.db.NewSelect().Model(&version).ModelTableExpr(s.tableVersion).
+		Where("id = 1").Scan(ctx); err != nil {
+		return 0, err
+	}
+
+	return version.Version, nil
+}
+
+// BumpPolicyVersion implements PolicyVersioner
+func (s *BunStore) BumpPolicyVersion(ctx context.Context) error {
+	_, err := s.db.NewUpdate().Model((*PolicyVersion)(nil)).ModelTableExpr(s.tableVersion).
+		Set("version = version + 1").Where("id = 1").Exec(ctx)
+
+	return err
+}
+
+// migrateColumnTypes alters the columns whose type differs from the one set by the options
+func (s *BunStore) migrateColumnTypes(ctx context.Context) error {
+	types := s.opts.ColumnTypes
+	if types == (ColumnTypes{}) {
+		return nil
+	}
+
+	for _, t := range []string{types.Name, types.Title, types.ID} {
+		if t != "" && !columnTypePattern.MatchString(strings.ToLower(t)) {
+			return fmt.Errorf("authority: invalid column type %q", t)
+		}
+	}
+	if id := canonicalColumnType(types.ID); id != "" && id != "integer" && id != "bigint" {
+		return fmt.Errorf("authority: invalid id column type %q", types.ID)
+	}
+
+	tables := make([]string, 0, len(storeTables))
+	for _, table := range storeTables {
+		tables = append(tables, s.prefix+table)
+	}
+
+	var columns []struct {
+		TableName              string
+		ColumnName             string
+		UdtName                string
+		CharacterMaximumLength sql.NullInt64
+	}
+	if err := s.db.NewSelect().TableExpr("information_schema.columns").
+		Column("table_name", "column_name", "udt_name", "character_maximum_length").
+		Where("table_schema = current_schema()").Where("table_name IN (?)", bun.In(tables)).
+		Scan(ctx, &columns); err != nil {
+		return err
+	}
+
+	for _, c := range columns {
+		var want string
+		switch {
+		case c.ColumnName == "name" && (c.TableName == s.prefix+"roles" || c.TableName == s.prefix+"permissions"):
+			if !s.opts.CaseInsensitiveNames {
+				want = types.Name
+			}
+		case c.ColumnName == "title" && (c.TableName == s.prefix+"roles" || c.TableName == s.prefix+"permissions"):
+			want = types.Title
+		case c.ColumnName == "id" || strings.HasSuffix(c.ColumnName, "_id"):
+			// the ids of the resources are text
+			if canonicalColumnType(c.UdtName) == "integer" || canonicalColumnType(c.UdtName) == "bigint" {
+				want = types.ID
+			}
+		}
+
+		current := canonicalColumnType(c.UdtName)
+		if c.CharacterMaximumLength.Valid {
+			current += "(" + strconv.FormatInt(c.CharacterMaximumLength.Int64, 10) + ")"
+		}
+		if want == "" || canonicalColumnType(want) == current {
+			continue
+		}
+
+		if err := s.dropReportingViews(ctx); err != nil {
+			return err
+		}
+
+		if _, err := s.db.ExecContext(ctx, "ALTER TABLE ? ALTER COLUMN ? TYPE "+want,
+			bun.Ident(c.TableName), bun.Ident(c.ColumnName)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// canonicalColumnType returns the lowercase type with the same name information_schema uses
+func canonicalColumnType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	name, size := t, ""
+	if i := strings.IndexByte(t, '('); i >= 0 {
+		name, size = strings.TrimSpace(t[:i]), t[i:]
+	}
+	if alias, ok := columnTypeAliases[name]; ok {
+		name = alias
+	}
+
+	return name + size
+}
+
+// checkFunction returns the name of the functions checking the permissions within the namespace of the store,
+// every namespace sharing the tables has its own functions following its SuperRole and DefaultRoles
+func (s *BunStore) checkFunction() string {
+	if s.opts.Namespace == "" {
+		return s.prefix + "authority_check"
+	}
+
+	return s.prefix + "authority_check_" + s.opts.Namespace
+}
+
+// migrateCheckFunctions creates or replaces the functions checking the permissions with the query
+// of CheckUserPermission
+func (s *BunStore) migrateCheckFunctions(ctx context.Context) error {
+	defaults := s.db.NewSelect().Model((*Role)(nil)).ModelTableExpr(s.tableRole).
+		DistinctOn("name").Column("id").Where("state = 'active'").Where("namespace = ?", s.opts.Namespace).
+		Where("tenant IN ('', p_tenant)").OrderExpr("name, tenant DESC")
+	if len(s.opts.DefaultRoles) == 0 {
+		defaults = defaults.Where("FALSE")
+	} else {
+		defaults = defaults.Where("name IN (?)", bun.In(s.opts.DefaultRoles))
+	}
+
+	// the placeholders are followed by a space, bun drops a placeholder followed by a parenthesis
+	function := bun.Ident(s.checkFunction())
+	if _, err := s.db.ExecContext(ctx, `CREATE OR REPLACE FUNCTION ? (p_user text, p_perm text, p_tenant text DEFAULT '')
+	RETURNS boolean LANGUAGE sql STABLE AS $fn$
+	WITH RECURSIVE perm AS (
+		SELECT id, condition FROM ? WHERE name = p_perm AND namespace = ? AND tenant IN ('', p_tenant)
+		ORDER BY tenant DESC LIMIT 1
+	), roles (id) AS (
+		SELECT ur.role_id FROM ? AS ur JOIN ? AS r ON r.id = ur.role_id AND r.state = 'active' AND r.namespace = ?
+		WHERE ur.user_key = p_user AND ur.tenant IN ('', p_tenant)
+			AND (ur.starts_at IS NULL OR ur.starts_at <= now()) AND (ur.expires_at IS NULL OR ur.expires_at > now())
+		UNION
+		SELECT gr.role_id FROM ? AS gm JOIN ? AS gr ON gr.group_id = gm.group_id
+			JOIN ? AS r ON r.id = gr.role_id AND r.state = 'active' AND r.namespace = ?
+		WHERE gm.user_key = ?
+		UNION
+		SELECT id FROM (?) AS dr
+		UNION
+		SELECT r.id FROM ? AS r WHERE r.segment_percent > 0 AND r.state = 'active' AND r.namespace = ?
+			AND r.tenant IN (?) AND ('x' || substr(md5(r.segment_seed || ':' || ?), 1, 8))::bit(32)::bigint % 100
+				< r.segment_percent
+		UNION
+		SELECT edge.next_id FROM roles JOIN (
+			SELECT role_id, member_id AS next_id FROM ?
+			UNION ALL
+			SELECT role_id, parent_id AS next_id FROM ?
+		) AS edge ON edge.role_id = roles.id
+		JOIN ? AS r ON r.id = edge.next_id AND r.state = 'active' AND r.namespace = ?
+	)
+	SELECT coalesce((SELECT ? <> '' AND EXISTS (
+		SELECT 1 FROM roles JOIN ? AS sr ON sr.id = roles.id WHERE sr.name = ? AND sr.namespace = ?
+	) OR perm.condition = '' AND EXISTS (
+		SELECT 1 FROM ? AS rp JOIN roles ON rp.role_id = roles.id
+		WHERE rp.permission_id = perm.id
+			AND (rp.starts_at IS NULL OR rp.starts_at <= now()) AND (rp.expires_at IS NULL OR rp.expires_at > now())
+	) AND NOT EXISTS (
+		SELECT 1 FROM ? AS pd WHERE pd.permission_id = perm.id
+			AND (pd.role_id IN (SELECT id FROM roles) OR pd.user_key = p_user AND pd.tenant IN ('', p_tenant))
+	) FROM perm), false)
+	$fn$`,
+		function, bun.Ident(s.prefix+"permissions"), s.opts.Namespace,
+		bun.Ident(s.prefix+"user_roles"), bun.Ident(s.prefix+"roles"), s.opts.Namespace,
+		bun.Ident(s.prefix+"group_members"), bun.Ident(s.prefix+"group_roles"), bun.Ident(s.prefix+"roles"),
+		s.opts.Namespace, bun.Safe("p_user"), defaults,
+		bun.Ident(s.prefix+"roles"), s.opts.Namespace, bun.Safe("'', p_tenant"), bun.Safe("p_user"),
+		bun.Ident(s.prefix+"role_composites"), bun.Ident(s.prefix+"role_parents"), bun.Ident(s.prefix+"roles"),
+		s.opts.Namespace, s.opts.SuperRole, bun.Ident(s.prefix+"roles"), s.opts.SuperRole, s.opts.Namespace,
+		bun.Ident(s.prefix+"role_permissions"), bun.Ident(s.prefix+"permission_denials"),
+	); err != nil {
+		return err
+	}
+
+	// the numeric user ids are the decimal user keys
+	_, err := s.db.ExecContext(ctx, `CREATE OR REPLACE FUNCTION ? (p_user_id bigint, p_perm text, p_tenant text DEFAULT '')
+	RETURNS boolean LANGUAGE sql STABLE AS $fn$ SELECT ? (p_user_id::text, p_perm, p_tenant) $fn$`,
+		function, function)
+
+	return err
+}
+
+// MigrateIndexes implements IndexMigrator, the indexes are created concurrently so it must not run in a
+// transaction. an index left invalid by a failed creation is dropped first
+func (s *BunStore) MigrateIndexes(ctx context.Context) error {
+	return s.createForeignKeyIndexes(ctx, true)
+}
+
+// createForeignKeyIndexes creates the missing indexes of the foreign keys
+func (s *BunStore) createForeignKeyIndexes(ctx context.Context, concurrently bool) error {
+	for _, key := range foreignKeyIndexes {
+		table := s.prefix + key.table
+		index := table + "_" + strings.Join(key.columns, "_") + "_idx"
+		if s.opts.IndexName != nil {
+			index = s.opts.IndexName(table, key.columns)
+		}
+
+		if concurrently {
+			var invalid bool
+			if err := s.db.NewSelect().TableExpr("pg_index AS i").Join("JOIN pg_class AS c ON c.oid = i.indexrelid").
+				ColumnExpr("NOT i.indisvalid").Where("c.relname = ?", index).
+				Where("c.relnamespace = current_schema()::regnamespace").Scan(ctx, &invalid); err != nil &&
+				!errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+
+			if invalid {
+				if _, err := s.db.ExecContext(ctx, "DROP INDEX CONCURRENTLY IF EXISTS ?", bun.Ident(index)); err != nil {
+					return err
+				}
+			}
+		}
+
+		q := s.db.NewCreateIndex().IfNotExists().ModelTableExpr(table).Index(index).Column(key.columns...)
+		if concurrently {
+			q = q.Concurrently()
+		}
+		if _, err := q.Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replicatedTables are the tables replicated across regions, see ReplicationOptions
+var replicatedTables = []string{
+	"roles", "permissions", "role_permissions", "user_roles", "role_composites", "role_parents",
+	"permission_denials", "groups", "group_members", "group_roles", "policies",
+}
+
+// migrateReplication adds the replication versions to the replicated tables with the trigger stamping them
+// and interleaves the sequences of the ids between the regions
+func (s *BunStore) migrateReplication(ctx context.Context) error {
+	repl := s.opts.Replication
+	if repl.Regions < 0 || repl.Regions > 0 && (repl.Region < 0 || repl.Region >= repl.Regions) {
+		return fmt.Errorf("authority: invalid region %d of %d", repl.Region, repl.Regions)
+	}
+
+	// the rows written by the region are stamped unless the write sets their version, e.g. ApplyRole, the placeholders
+	// of the function are followed by a space like the ones of migrateCheckFunctions
+	function := bun.Ident(s.prefix + "authority_stamp")
+	if _, err := s.db.ExecContext(ctx, `CREATE OR REPLACE FUNCTION ? () RETURNS trigger LANGUAGE plpgsql AS $fn$
+	BEGIN
+		IF TG_OP = 'INSERT' AND NEW.origin = ''
+			OR TG_OP = 'UPDATE' AND NEW.updated_at = OLD.updated_at AND NEW.origin = OLD.origin THEN
+			NEW.updated_at := now();
+			NEW.origin := ?;
+		END IF;
+		RETURN NEW;
+	END
+	$fn$`, function, repl.Origin); err != nil {
+		return err
+	}
+
+	for _, name := range replicatedTables {
+		table := s.prefix + name
+		columns := []string{"updated_at timestamptz NOT NULL DEFAULT now()", "origin varchar NOT NULL DEFAULT ''"}
+		for _, column := range columns {
+			if _, err := s.db.NewAddColumn().IfNotExists().ModelTableExpr(table).
+				ColumnExpr(column).Exec(ctx); err != nil {
+				return err
+			}
+		}
+
+		trigger := bun.Ident(table + "_stamp")
+		if _, err := s.db.ExecContext(ctx, "DROP TRIGGER IF EXISTS ? ON ?", trigger, bun.Ident(table)); err != nil {
+			return err
+		}
+		if _, err := s.db.ExecContext(ctx, "CREATE TRIGGER ? BEFORE INSERT OR UPDATE ON ? FOR EACH ROW EXECUTE FUNCTION ? ()",
+			trigger, bun.Ident(table), function); err != nil {
+			return err
+		}
+
+		if repl.Regions == 0 {
+			continue
+		}
+
+		var seq sql.NullString
+		if err := s.db.NewSelect().ColumnExpr("pg_get_serial_sequence(?, 'id')", table).Scan(ctx, &seq); err != nil {
+			return err
+		}
+		if !seq.Valid {
+			continue
+		}
+
+		// the next id of the region is the first one above the ids of every region
+		var maxID int64
+		if err := s.db.NewSelect().TableExpr("?", bun.Ident(table)).ColumnExpr("coalesce(max(id), 0)").
+			Scan(ctx, &maxID); err != nil {
+			return err
+		}
+
+		regions, offset := int64(repl.Regions), int64(repl.Region+1)%int64(repl.Regions)
+		next := maxID + 1
+		next += ((offset-next)%regions + regions) % regions
+
+		if _, err := s.db.ExecContext(ctx, "ALTER SEQUENCE "+seq.String+" INCREMENT BY ?", regions); err != nil {
+			return err
+		}
+		if _, err := s.db.ExecContext(ctx, "SELECT setval(?, ?, false)", seq.String, next); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListRoleVersions implements Replicator
+func (s *BunStore) ListRoleVersions(ctx context.Context) ([]Role, error) {
+	var roles []Role
+	if err := s.db.NewSelect().Model(&roles).ModelTableExpr(s.tableRole).ColumnExpr("role.*").
+		Where("namespace = ?", s.opts.Namespace).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// ListPermissionVersions implements Replicator
+func (s *BunStore) ListPermissionVersions(ctx context.Context) ([]Permission, error) {
+	var perms []Permission
+	if err := s.db.NewSelect().Model(&perms).ModelTableExpr(s.tablePerm).ColumnExpr("perm.*").
+		Where("namespace = ?", s.opts.Namespace).Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	return perms, nil
+}
+
+// ApplyRole implements Replicator
+func (s *BunStore) ApplyRole(ctx context.Context, role *Role) error {
+	metadata, err := json.Marshal(role.Metadata)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.NewUpdate().Model((*Role)(nil)).ModelTableExpr(s.tableRole).
+		Set("title = ?", role.Title).Set("description = ?", role.Description).
+		Set("metadata = nullif(?::jsonb, 'null')", string(metadata)).Set("state = ?", role.stateOrDefault()).
+		Set("updated_at = ?", role.UpdatedAt).Set("origin = ?", role.Origin).
+		Where("namespace = ?", s.opts.Namespace).Where("name = ?", role.Name).Where("tenant = ?", role.Tenant).
+		Exec(ctx)
+
+	return err
+}
+
+// ApplyPermission implements Replicator
+func (s *BunStore) ApplyPermission(ctx context.Context, perm *Permission) error {
+	metadata, err := json.Marshal(perm.Metadata)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.NewUpdate().Model((*Permission)(nil)).ModelTableExpr(s.tablePerm).
+		Set("title = ?", perm.Title).Set("condition = ?", perm.Condition).Set("action = ?", perm.Action).
+		Set("resource = ?", perm.Resource).Set("description = ?", perm.Description).
+		Set("metadata = nullif(?::jsonb, 'null')", string(metadata)).
+		Set("updated_at = ?", perm.UpdatedAt).Set("origin = ?", perm.Origin).
+		Where("namespace = ?", s.opts.Namespace).Where("name = ?", perm.Name).Where("tenant = ?", perm.Tenant).
+		Exec(ctx)
+
+	return err
+}
+
+// reportingViews are the views created with ReportingViews, each one after the views it selects from
+var reportingViews = []string{"role_closure_v", "role_permission_matrix_v", "user_effective_permissions_v"}
+
+// migrateReportingViews creates or replaces the views for the reporting tools
+func (s *BunStore) migrateReportingViews(ctx context.Context) error {
+	table := func(name string) bun.Ident {
+		return bun.Ident(s.prefix + name)
+	}
+
+	// role_closure_v pairs every active role with itself and the active roles of its namespace it includes and
+	// inherits from
+	if _, err := s.db.ExecContext(ctx, `CREATE OR REPLACE VIEW ? AS
+	WITH RECURSIVE closure (role_id, included_role_id, namespace) AS (
+		SELECT id, id, namespace FROM ? WHERE state = 'active'
+		UNION
+		SELECT closure.role_id, edge.next_id, closure.namespace FROM closure JOIN (
+			SELECT role_id, member_id AS next_id FROM ?
+			UNION ALL
+			SELECT role_id, parent_id AS next_id FROM ?
+		) AS edge ON edge.role_id = closure.included_role_id
+		JOIN ? AS r ON r.id = edge.next_id AND r.state = 'active' AND r.namespace = closure.namespace
+	)
+	SELECT role_id, included_role_id, namespace FROM closure`,
+		table("role_closure_v"), table("roles"), table("role_composites"), table("role_parents"), table("roles"),
+	); err != nil {
+		return err
+	}
+
+	// the permissions denied to one of the roles a role includes are left out
+	if _, err := s.db.ExecContext(ctx, `CREATE OR REPLACE VIEW ? AS
+	SELECT r.id AS role_id, r.name AS role, r.tenant AS role_tenant, p.id AS permission_id, p.name AS permission,
+		p.tenant AS permission_tenant, via.name AS via_role, p.condition <> '' AS conditional, r.namespace
+	FROM ? AS c JOIN ? AS r ON r.id = c.role_id JOIN ? AS via ON via.id = c.included_role_id
+		JOIN ? AS rp ON rp.role_id = c.included_role_id JOIN ? AS p ON p.id = rp.permission_id
+	WHERE p.namespace = r.namespace AND (rp.starts_at IS NULL OR rp.starts_at <= now()) AND (rp.expires_at IS NULL OR rp.expires_at > now())
+		AND NOT EXISTS (
+			SELECT 1 FROM ? AS pd JOIN ? AS dc ON dc.included_role_id = pd.role_id
+			WHERE dc.role_id = c.role_id AND pd.permission_id = p.id
+		)`,
+		table("role_permission_matrix_v"), table("role_closure_v"), table("roles"), table("roles"),
+		table("role_permissions"), table("permissions"), table("permission_denials"), table("role_closure_v"),
+	); err != nil {
+		return err
+	}
+
+	// the permissions denied to the user or to one of its other roles in the tenant are left out
+	_, err := s.db.ExecContext(ctx, `CREATE OR REPLACE VIEW ? AS
+	WITH assigned (user_key, tenant, role_id) AS (
+		SELECT user_key, tenant, role_id FROM ?
+		WHERE (starts_at IS NULL OR starts_at <= now()) AND (expires_at IS NULL OR expires_at > now())
+		UNION
+		SELECT gm.user_key, '', gr.role_id FROM ? AS gm JOIN ? AS gr ON gr.group_id = gm.group_id
+	)
+	SELECT DISTINCT a.user_key, a.tenant, m.permission_id, m.permission, m.role AS granted_by_role, m.conditional,
+		m.namespace
+	FROM assigned AS a JOIN ? AS m ON m.role_id = a.role_id
+	WHERE NOT EXISTS (
+		SELECT 1 FROM ? AS pd WHERE pd.permission_id = m.permission_id
+			AND (pd.user_key = a.user_key AND pd.tenant IN ('', a.tenant) OR pd.role_id IN (
+				SELECT c.included_role_id FROM assigned AS other JOIN ? AS c ON c.role_id = other.role_id
+				WHERE other.user_key = a.user_key AND other.tenant IN ('', a.tenant)
+			))
+	)`,
+		table("user_effective_permissions_v"), table("user_roles"), table("group_members"), table("group_roles"),
+		table("role_permission_matrix_v"), table("permission_denials"), table("role_closure_v"),
+	)
+
+	return err
+}
+
+// dropReportingViews drops the views for the reporting tools, the columns they select can't be altered.
+// Migrate creates them again after the columns
+func (s *BunStore) dropReportingViews(ctx context.Context) error {
+	for i := len(reportingViews) - 1; i >= 0; i-- {
+		if _, err := s.db.ExecContext(ctx, "DROP VIEW IF EXISTS ?", bun.Ident(s.prefix+reportingViews[i])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateNameColumns makes the name columns citext when the options ask for it and finds whether they are
+func (s *BunStore) migrateNameColumns(ctx context.Context) error {
+	if s.opts.CaseInsensitiveNames {
+		if _, err := s.db.ExecContext(ctx, "CREATE EXTENSION IF NOT EXISTS citext"); err != nil {
+			return err
+		}
+	}
+
+	s.caseInsensitive = true
+	for _, table := range []string{"roles", "permissions"} {
+		var udt string
+		if err := s.db.NewSelect().TableExpr("information_schema.columns").Column("udt_name").
+			Where("table_schema = current_schema()").Where("table_name = ?", s.prefix+table).
+			Where("column_name = 'name'").Scan(ctx, &udt); err != nil {
+			return err
+		}
+
+		if udt != "citext" && s.opts.CaseInsensitiveNames {
+			if err := s.dropReportingViews(ctx); err != nil {
+				return err
+			}
+
+			if _, err := s.db.ExecContext(ctx, "ALTER TABLE ? ALTER COLUMN name TYPE citext",
+				bun.Ident(s.prefix+table)); err != nil {
+				return err
+			}
+			udt = "citext"
+		}
+
+		s.caseInsensitive = s.caseInsensitive && udt == "citext"
+	}
+
+	return nil
+}
+
+// nameKey returns the key matching the names the database considers equal
+func (s *BunStore) nameKey(name string) string {
+	if s.caseInsensitive {
+		return strings.ToLower(name)
+	}
+
+	return name
+}
+
+// InTx implements Transactor, fn gets a store running its queries in the transaction
+func (s *BunStore) InTx(ctx context.Context, fn func(ctx context.Context, store Store) error) error {
+	db, ok := s.db.(*bun.DB)
+	if !ok {
+		return s.db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			txStore := *s
+			txStore.db = tx
+
+			return fn(ctx, &txStore)
+		})
+	}
+
+	// the transaction runs on a pinned connection so the store can use it directly, e.g. for COPY FROM
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		txStore := *s
+		txStore.db, txStore.conn = tx, &conn
+
+		return fn(ctx, &txStore)
+	})
+}
+
+// TryLock implements Locker with a postgres session advisory lock, the key is prefixed with the tables prefix
+// so instances using different prefixes don't block each other. a store running in a transaction takes a
+// transaction advisory lock instead, released when the transaction ends even if it aborts
+func (s *BunStore) TryLock(ctx context.Context, key string) (func() error, bool, error) {
+	key = s.prefix + key
+
+	db, ok := s.db.(*bun.DB)
+	if !ok {
+		var locked bool
+		if err := s.db.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock(hashtext(?))", key).
+			Scan(&locked); err != nil {
+			return nil, false, err
+		}
+
+		return func() error { return nil }, locked, nil
+	}
+
+	// the session advisory lock must be released on the connection that took it
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var locked bool
+	if err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext(?))", key).Scan(&locked); err != nil {
+		_ = conn.Close()
+		return nil, false, err
+	}
+
+	if !locked {
+		return nil, false, conn.Close()
+	}
+
+	unlock := func() error {
+		// unlock even when the context of the operation is canceled, the pooled connection would keep the lock
+		_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext(?))", key)
+		if cerr := conn.Close(); err == nil {
+			err = cerr
+		}
+
+		return err
+	}
+
+	return unlock, true, nil
+}
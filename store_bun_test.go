@@ -0,0 +1,332 @@
+package authority_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/driver/pgdriver"
+
+	"authority"
+)
+
+// newBunDB returns a database of the AUTHORITY_TEST_DSN environment variable, the test is skipped without it
+func newBunDB(t *testing.T) *bun.DB {
+	t.Helper()
+
+	dsn := os.Getenv("AUTHORITY_TEST_DSN")
+	if dsn == "" {
+		t.Skip("AUTHORITY_TEST_DSN is not set")
+	}
+
+	db := bun.NewDB(sql.OpenDB(pgdriver.NewConnector(pgdriver.WithDSN(dsn))), pgdialect.New())
+	t.Cleanup(func() { _ = db.Close() })
+
+	return db
+}
+
+// newBunStore returns a migrated bun store on the database with tables prefixed for the test and dropped
+// after it
+func newBunStore(t *testing.T, db *bun.DB, opts authority.BunStoreOptions) *authority.BunStore {
+	t.Helper()
+
+	if opts.TablesPrefix == "" {
+		opts.TablesPrefix = testTablesPrefix()
+	}
+
+	store := authority.NewBunStoreWith(db, opts)
+	dropAfter(t, db, opts)
+	must(t, store.Migrate(context.Background()))
+
+	return store
+}
+
+// newBunAuthority returns an instance using the bun store on a database of AUTHORITY_TEST_DSN, with tables
+// prefixed for the test and dropped after it. the options may set everything but the store and the database
+func newBunAuthority(t *testing.T, opts authority.Options) *authority.Authority {
+	t.Helper()
+
+	db := newBunDB(t)
+	if opts.TablesPrefix == "" {
+		opts.TablesPrefix = testTablesPrefix()
+	}
+
+	opts.DB = db
+	dropAfter(t, db, authority.BunStoreOptions{TablesPrefix: opts.TablesPrefix, Namespace: opts.Namespace})
+
+	a, err := authority.NewE(opts)
+	must(t, err)
+
+	return a
+}
+
+// testTablesPrefix returns a prefix of tables unique to the test
+func testTablesPrefix() string {
+	return fmt.Sprintf("t%d_", time.Now().UnixNano())
+}
+
+// dropAfter drops the tables of the store with the options after the test, with the tables of the migrator
+func dropAfter(t *testing.T, db *bun.DB, opts authority.BunStoreOptions) {
+	store := authority.NewBunStoreWith(db, opts)
+
+	t.Cleanup(func() {
+		ctx := context.Background()
+
+		// rolling back the baseline drops the tables of the store
+		for {
+			names, err := store.RollbackMigration(ctx)
+			if err != nil {
+				t.Errorf("RollbackMigration: %v", err)
+				return
+			}
+			if len(names) == 0 {
+				break
+			}
+		}
+
+		for _, table := range []string{"schema_migrations", "schema_migration_locks"} {
+			if _, err := db.ExecContext(ctx, "DROP TABLE IF EXISTS ?", bun.Ident(opts.TablesPrefix+table)); err != nil {
+				t.Errorf("dropping %s: %v", table, err)
+			}
+		}
+	})
+}
+
+func TestBunStoreCopyImport(t *testing.T) {
+	store := newBunStore(t, newBunDB(t), authority.BunStoreOptions{})
+	ctx := context.Background()
+
+	role := &authority.Role{Name: "reader"}
+	must(t, store.CreateRole(ctx, role))
+	must(t, store.AssignRole(ctx, &authority.UserRole{UserKey: "0", RoleID: role.ID}))
+
+	// more assignments than the threshold of COPY FROM, the first user already has the role
+	userRoles := make([]authority.UserRole, 12000)
+	for i := range userRoles {
+		userRoles[i] = authority.UserRole{UserKey: strconv.Itoa(i), RoleID: role.ID}
+	}
+
+	for i := 0; i < 2; i++ {
+		must(t, store.InTx(ctx, func(ctx context.Context, tx authority.Store) error {
+			return tx.AssignRoles(ctx, userRoles)
+		}))
+	}
+
+	assigned, err := store.ListUserRoles(ctx)
+	must(t, err)
+	if len(assigned) != len(userRoles) {
+		t.Fatalf("%d assignments, want %d", len(assigned), len(userRoles))
+	}
+}
+
+func TestBunStoreCheckFunctions(t *testing.T) {
+	prefix := testTablesPrefix()
+	a := newBunAuthority(t, authority.Options{
+		TablesPrefix: prefix, Namespace: "billing", SuperRole: "root", CheckFunctions: true,
+	})
+	setupRole(t, a, "editor", "invoices.write")
+	must(t, a.CreatePermission("invoices.delete"))
+	must(t, a.CreateRole("root"))
+	must(t, a.AssignRole(1, "editor"))
+	must(t, a.AssignRole(2, "root"))
+
+	ctx := context.Background()
+	function := bun.Ident(prefix + "authority_check_billing")
+	for _, tc := range []struct {
+		user    int64
+		perm    string
+		granted bool
+	}{
+		{1, "invoices.write", true},
+		{1, "invoices.delete", false},
+		{2, "invoices.delete", true},
+		{3, "invoices.write", false},
+		{1, "missing", false},
+	} {
+		var byKey, byID bool
+		must(t, a.DB.NewSelect().ColumnExpr("? (?, ?)", function, strconv.FormatInt(tc.user, 10), tc.perm).
+			Scan(ctx, &byKey))
+		must(t, a.DB.NewSelect().ColumnExpr("? (?::bigint, ?)", function, tc.user, tc.perm).Scan(ctx, &byID))
+
+		if byKey != tc.granted || byID != tc.granted {
+			t.Errorf("check of %s for %d = %t by key and %t by id, want %t", tc.perm, tc.user, byKey, byID,
+				tc.granted)
+		}
+	}
+}
+
+func TestBunStoreDefaultRoles(t *testing.T) {
+	a := newBunAuthority(t, authority.Options{DefaultRoles: []string{"member"}})
+	setupRole(t, a, "member", "comments.write")
+	must(t, a.CreatePermission("comments.delete"))
+
+	ok, err := a.CheckPermission(1, "comments.write")
+	must(t, err)
+	if !ok {
+		t.Fatal("the default role is not considered assigned")
+	}
+
+	if ok, err = a.CheckPermission(1, "comments.delete"); err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Fatal("granted a permission of no default role")
+	}
+
+	must(t, a.MaterializeDefaultRoles([]uint{1}))
+	roles, err := a.GetUserRoles(1)
+	must(t, err)
+	if !equalStrings(roles, []string{"member"}) {
+		t.Fatalf("GetUserRoles = %v after MaterializeDefaultRoles", roles)
+	}
+}
+
+func TestBunStoreSegments(t *testing.T) {
+	prefix := testTablesPrefix()
+	a := newBunAuthority(t, authority.Options{TablesPrefix: prefix, CheckFunctions: true})
+	setupRole(t, a, "beta", "reports.v2")
+	must(t, a.AssignRoleToSegment("beta", 30, "reports"))
+
+	// the query of the store and the check function select the users of the segment like InSegment
+	ctx := context.Background()
+	for id := uint(1); id <= 100; id++ {
+		granted, err := a.CheckPermission(id, "reports.v2")
+		must(t, err)
+
+		var checked bool
+		must(t, a.DB.NewSelect().ColumnExpr("? (?::bigint, ?)", bun.Ident(prefix+"authority_check"), id,
+			"reports.v2").Scan(ctx, &checked))
+
+		if want := authority.InSegment(id, 30, "reports"); granted != want || checked != want {
+			t.Fatalf("check of the user %d = %t by the store and %t by the function, want %t", id, granted,
+				checked, want)
+		}
+	}
+}
+
+func TestBunStoreLastWriterWins(t *testing.T) {
+	db := newBunDB(t)
+	eu := newBunStore(t, db, authority.BunStoreOptions{Replication: authority.ReplicationOptions{Origin: "eu"}})
+	us := newBunStore(t, db, authority.BunStoreOptions{Replication: authority.ReplicationOptions{Origin: "us"}})
+
+	local, err := authority.NewE(authority.Options{Store: eu})
+	must(t, err)
+	remote, err := authority.NewE(authority.Options{Store: us})
+	must(t, err)
+
+	must(t, local.CreateRole("editor"))
+	must(t, remote.CreateRole("editor"))
+	must(t, remote.SetRoleDescription("editor", "edits the articles"))
+
+	ctx := context.Background()
+	roles, err := eu.ListRoleVersions(ctx)
+	must(t, err)
+	if len(roles) != 1 || roles[0].Origin != "eu" || roles[0].UpdatedAt.IsZero() {
+		t.Fatalf("ListRoleVersions = %+v, want the role stamped by eu", roles)
+	}
+
+	conflicts, err := local.ResolveConflicts(us)
+	must(t, err)
+	if len(conflicts) != 1 || !conflicts[0].RemoteWon {
+		t.Fatalf("ResolveConflicts = %+v, want the later remote version", conflicts)
+	}
+
+	// the applied row keeps the version of the remote region instead of being stamped again
+	if roles, err = eu.ListRoleVersions(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if roles[0].Description != "edits the articles" || roles[0].Origin != "us" ||
+		!roles[0].UpdatedAt.Equal(conflicts[0].Remote.UpdatedAt) {
+		t.Fatalf("the local role is %+v after the resolution", roles[0])
+	}
+}
+
+func TestBunStoreMigrations(t *testing.T) {
+	db := newBunDB(t)
+	opts := authority.BunStoreOptions{
+		TablesPrefix: testTablesPrefix(), Namespace: "billing", CheckFunctions: true, ReportingViews: true,
+	}
+	store := newBunStore(t, db, opts)
+
+	ctx := context.Background()
+	exists := func(kind string, name string) bool {
+		t.Helper()
+
+		query := "SELECT to_regclass(?) IS NOT NULL"
+		if kind == "function" {
+			query = "SELECT EXISTS (SELECT 1 FROM pg_proc WHERE proname = ?)"
+		}
+
+		var found bool
+		must(t, db.QueryRowContext(ctx, query, name).Scan(&found))
+
+		return found
+	}
+
+	// migrating again applies nothing
+	must(t, store.Migrate(ctx))
+	if !exists("table", opts.TablesPrefix+"roles") || !exists("table", opts.TablesPrefix+"role_permission_matrix_v") ||
+		!exists("function", opts.TablesPrefix+"authority_check_billing") {
+		t.Fatal("the schema is not migrated")
+	}
+
+	names, err := store.RollbackMigration(ctx)
+	must(t, err)
+	sort.Strings(names)
+	if !equalStrings(names, []string{"00001_baseline", "00002_namespaces", "00003_segments"}) {
+		t.Fatalf("RollbackMigration = %v, want the baseline, the namespaces and the segments", names)
+	}
+
+	for _, name := range []string{"roles", "user_roles", "role_permission_matrix_v", "authority_check_billing"} {
+		kind := "table"
+		if name == "authority_check_billing" {
+			kind = "function"
+		}
+		if exists(kind, opts.TablesPrefix+name) {
+			t.Errorf("the %s %s is left after the rollback", kind, name)
+		}
+	}
+
+	// the rolled back schema migrates again
+	must(t, store.Migrate(ctx))
+	must(t, store.CreateRole(ctx, &authority.Role{Name: "editor"}))
+}
+
+var errRollback = errors.New("rollback")
+
+func TestBunStoreTryLockInTx(t *testing.T) {
+	db := newBunDB(t)
+	store := newBunStore(t, db, authority.BunStoreOptions{})
+	ctx := context.Background()
+
+	err := store.InTx(ctx, func(ctx context.Context, tx authority.Store) error {
+		_, locked, err := tx.(authority.Locker).TryLock(ctx, "test")
+		if err != nil || !locked {
+			t.Fatalf("TryLock in the transaction = %v, %v, want locked", locked, err)
+		}
+
+		if _, locked, err = store.TryLock(ctx, "test"); err != nil || locked {
+			t.Fatalf("TryLock outside of the transaction = %v, %v, want not locked", locked, err)
+		}
+
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("InTx = %v, want the error rolling back", err)
+	}
+
+	// the rolled back transaction released the lock
+	unlock, locked, err := store.TryLock(ctx, "test")
+	if err != nil || !locked {
+		t.Fatalf("TryLock after the rollback = %v, %v, want locked", locked, err)
+	}
+	must(t, unlock())
+}
@@ -0,0 +1,58 @@
+package authority_test
+
+import (
+	"context"
+	"testing"
+
+	"authority"
+	"authority/authoritytest"
+)
+
+func TestMemoryStore(t *testing.T) {
+	authoritytest.TestStore(t, func(t *testing.T) authority.Store {
+		return authoritytest.NewMemoryStore()
+	})
+}
+
+func TestBunStore(t *testing.T) {
+	db := newBunDB(t)
+
+	authoritytest.TestStore(t, func(t *testing.T) authority.Store {
+		return newBunStore(t, db, authority.BunStoreOptions{})
+	})
+}
+
+// migrationCounter counts the migrations of a store
+type migrationCounter struct {
+	authority.Store
+	migrations int
+}
+
+func (s *migrationCounter) Migrate(ctx context.Context) error {
+	s.migrations++
+	return s.Store.Migrate(ctx)
+}
+
+func TestCustomStore(t *testing.T) {
+	store := &migrationCounter{Store: authoritytest.NewMemoryStore()}
+	a, err := authority.NewE(authority.Options{Store: store})
+	must(t, err)
+	if store.migrations != 1 || a.DB != nil {
+		t.Fatalf("%d migrations and the database %v, want one migration of the custom store", store.migrations, a.DB)
+	}
+
+	setupRole(t, a, "editor", "articles.write")
+	must(t, a.AssignRole(1, "editor"))
+	ok, err := a.CheckPermission(1, "articles.write")
+	must(t, err)
+	if !ok {
+		t.Fatal("the permission of the custom store is not granted")
+	}
+
+	if _, err = authority.NewE(authority.Options{Store: store, SkipMigration: true}); err != nil {
+		t.Fatal(err)
+	}
+	if store.migrations != 1 {
+		t.Fatalf("%d migrations with SkipMigration, want none", store.migrations-1)
+	}
+}
@@ -0,0 +1,30 @@
+package authority
+
+import (
+	"context"
+	"errors"
+)
+
+// holdsSuperRole reports whether the super role is among the roles, which are the granting roles of a user
+// or of a role
+func (a *Authority) holdsSuperRole(ctx context.Context, roleIDs []uint, tenant string) (bool, error) {
+	if a.superRole == "" {
+		return false, nil
+	}
+
+	role, err := a.getTenantRole(ctx, a.superRole, tenant)
+	if errors.Is(err, ErrRoleNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	for _, id := range roleIDs {
+		if id == role.ID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
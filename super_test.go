@@ -0,0 +1,57 @@
+package authority_test
+
+import (
+	"testing"
+
+	"authority"
+)
+
+func TestSuperRole(t *testing.T) {
+	a := newAuthority(t, authority.Options{SuperRole: "root"})
+	must(t, a.CreateRole("root"))
+	setupRole(t, a, "editor", "articles.read")
+	must(t, a.CreatePermission("billing.refund"))
+	must(t, a.CreatePermissionInTenant("tenant.only", "acme"))
+	must(t, a.DefinePolicy("can_refund", "billing.refund AND editor"))
+	must(t, a.AssignRole(1, "root"))
+
+	// the denials don't apply to the super role
+	must(t, a.DenyPermission(1, "billing.refund"))
+
+	for _, perm := range []string{"articles.read", "billing.refund"} {
+		granted, err := a.CheckPermission(1, perm)
+		must(t, err)
+		if !granted {
+			t.Errorf("CheckPermission(%q) of the super role = false", perm)
+		}
+	}
+
+	if granted, err := a.CheckRole(1, "editor"); err != nil || !granted {
+		t.Errorf("CheckRole of the super role = %v, %v, want true", granted, err)
+	}
+
+	if granted, err := a.CheckPolicy(1, "can_refund"); err != nil || !granted {
+		t.Errorf("CheckPolicy of the super role = %v, %v, want true", granted, err)
+	}
+
+	perms, err := a.GetUserPermissions(1)
+	must(t, err)
+	if want := []string{"articles.read", "billing.refund"}; !equalStrings(perms, want) {
+		t.Errorf("GetUserPermissions = %v, want %v", perms, want)
+	}
+
+	if perms, err = a.GetUserPermissionsInTenant(1, "acme"); err != nil || len(perms) != 3 {
+		t.Errorf("GetUserPermissionsInTenant = %v, %v, want the global and the tenant permissions", perms, err)
+	}
+
+	manifest, err := a.BuildCapabilityManifest(1)
+	must(t, err)
+	if !manifest.Capabilities["billing.refund"] || !manifest.Capabilities["articles.read"] {
+		t.Errorf("BuildCapabilityManifest = %v, want every permission", manifest.Capabilities)
+	}
+
+	// the other users need the permissions
+	if granted, err := a.CheckPolicy(2, "can_refund"); err != nil || granted {
+		t.Errorf("CheckPolicy of a user without roles = %v, %v, want false", granted, err)
+	}
+}
@@ -0,0 +1,174 @@
+package authority
+
+import (
+	"context"
+	"sort"
+)
+
+// Changes lists the names added and removed by a sync
+type Changes struct {
+	Added   []string
+	Removed []string
+}
+
+// ReplaceRolePermissions sets the exact permissions of a role, e.g. from a configuration file. the missing
+// permissions are assigned and the others revoked in a single transaction, it returns the names of the
+// assigned and the revoked permissions sorted by name
+func (a *Authority) ReplaceRolePermissions(roleName string, permNames []string) (Changes, error) {
+	return a.ReplaceRolePermissionsCtx(context.Background(), roleName, permNames)
+}
+
+// ReplaceRolePermissionsCtx is the context-aware variant of ReplaceRolePermissions
+func (a *Authority) ReplaceRolePermissionsCtx(ctx context.Context, roleName string, permNames []string) (Changes, error) {
+	var changes Changes
+
+	err := a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		changes = Changes{}
+
+		role, err := tx.getRole(ctx, roleName)
+		if err != nil {
+			return err
+		}
+
+		rolePerms, err := tx.store.GetRolePermissions(ctx, []uint{role.ID})
+		if err != nil {
+			return err
+		}
+
+		permIDs := make([]uint, 0, len(rolePerms))
+		for _, rp := range rolePerms {
+			permIDs = append(permIDs, rp.PermissionID)
+		}
+
+		current, err := tx.store.GetPermissionsByID(ctx, permIDs)
+		if err != nil {
+			return err
+		}
+
+		target := make(map[string]bool, len(permNames))
+		for _, permName := range permNames {
+			target[permName] = true
+		}
+
+		assigned := make(map[string]bool, len(current))
+		for _, perm := range current {
+			assigned[perm.Name] = true
+
+			if target[perm.Name] {
+				continue
+			}
+
+			entry := AuditEntry{Action: AuditPermissionRevoked, Role: roleName, Permission: perm.Name}
+			if err = tx.validate(ctx, entry); err != nil {
+				return err
+			}
+
+			if err = tx.store.RevokePermission(ctx, role.ID, perm.ID); err != nil {
+				return err
+			}
+
+			if err = tx.changed(ctx, entry); err != nil {
+				return err
+			}
+
+			changes.Removed = append(changes.Removed, perm.Name)
+		}
+
+		for permName := range target {
+			if !assigned[permName] {
+				changes.Added = append(changes.Added, permName)
+			}
+		}
+
+		sort.Strings(changes.Added)
+		sort.Strings(changes.Removed)
+
+		return tx.assignPermissions(ctx, roleName, changes.Added, RolePermission{}, "")
+	})
+	if err != nil {
+		return Changes{}, err
+	}
+
+	return changes, nil
+}
+
+// SyncUserRoles sets the exact global roles of a user, the missing roles are assigned and the others revoked
+// in a single transaction. the scheduled and time-bound assignments of the target roles are kept as they are.
+// it returns the names of the assigned and the revoked roles sorted by name
+func (a *Authority) SyncUserRoles(userID uint, roleNames []string) (Changes, error) {
+	return a.SyncUserRolesCtx(context.Background(), userID, roleNames)
+}
+
+// SyncUserRolesCtx is the context-aware variant of SyncUserRoles
+func (a *Authority) SyncUserRolesCtx(ctx context.Context, userID uint, roleNames []string) (Changes, error) {
+	user := userKey(userID)
+
+	var changes Changes
+	err := a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		changes = Changes{}
+
+		userRoles, err := tx.store.GetUserRoles(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		global := userRoles[:0]
+		for _, ur := range userRoles {
+			if ur.Tenant == "" {
+				global = append(global, ur)
+			}
+		}
+
+		names, err := tx.roleNames(ctx, global)
+		if err != nil {
+			return err
+		}
+
+		target := make(map[string]bool, len(roleNames))
+		for _, roleName := range roleNames {
+			target[roleName] = true
+		}
+
+		assigned := make(map[string]bool, len(global))
+		for _, ur := range global {
+			roleName := names[ur.RoleID]
+			assigned[roleName] = true
+
+			if target[roleName] {
+				continue
+			}
+
+			entry := AuditEntry{Action: AuditRoleRevoked, UserKey: user, Role: roleName}
+			if err = tx.validate(ctx, entry); err != nil {
+				return err
+			}
+
+			if err = tx.store.RevokeRole(ctx, user, ur.RoleID, ""); err != nil {
+				return err
+			}
+
+			if err = tx.changed(ctx, entry); err != nil {
+				return err
+			}
+
+			changes.Removed = append(changes.Removed, roleName)
+		}
+
+		for roleName := range target {
+			if !assigned[roleName] {
+				changes.Added = append(changes.Added, roleName)
+			}
+		}
+
+		sort.Strings(changes.Added)
+		sort.Strings(changes.Removed)
+
+		// the assignments are part of the sync, an idempotency key of the context must not skip them
+		return tx.assignRoles(withoutIdempotencyKey(ctx), changes.Added, []string{user}, "")
+	})
+	if err != nil {
+		return Changes{}, err
+	}
+
+	return changes, nil
+}
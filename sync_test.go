@@ -0,0 +1,50 @@
+package authority_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"authority"
+)
+
+func TestSyncUserRoles(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	for _, roleName := range []string{"reader", "editor", "admin"} {
+		must(t, a.CreateRole(roleName))
+	}
+	must(t, a.AssignRoles(1, []string{"reader", "admin"}))
+
+	changes, err := a.SyncUserRoles(1, []string{"reader", "editor"})
+	must(t, err)
+	if !equalStrings(changes.Added, []string{"editor"}) || !equalStrings(changes.Removed, []string{"admin"}) {
+		t.Fatalf("SyncUserRoles = %+v", changes)
+	}
+
+	roles, err := a.GetUserRoles(1)
+	must(t, err)
+	sort.Strings(roles)
+	if !equalStrings(roles, []string{"editor", "reader"}) {
+		t.Fatalf("GetUserRoles = %v", roles)
+	}
+}
+
+func TestSyncUserRolesIgnoresIdempotencyKey(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	for _, roleName := range []string{"reader", "editor"} {
+		must(t, a.CreateRole(roleName))
+	}
+
+	// the key of a completed import must not skip the roles added by the sync
+	ctx := authority.WithIdempotencyKey(context.Background(), "job-1")
+	must(t, a.AssignRolesCtx(ctx, 1, []string{"reader"}))
+
+	_, err := a.SyncUserRolesCtx(ctx, 1, []string{"reader", "editor"})
+	must(t, err)
+
+	ok, err := a.CheckRole(1, "editor")
+	must(t, err)
+	if !ok {
+		t.Fatal("the sync skipped the added role")
+	}
+}
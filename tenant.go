@@ -0,0 +1,123 @@
+package authority
+
+import "context"
+
+// the InTenant variants scope roles, permissions and assignments to a tenant, e.g. an organization.
+// the global roles and permissions (created without a tenant) are visible in every tenant and a role defined in
+// a tenant takes precedence over a global role with the same name. the global assignments apply in every tenant
+// while the assignments made in a tenant apply only within it
+
+// CreateRoleInTenant stores a role that exists only in the given tenant
+func (a *Authority) CreateRoleInTenant(roleName string, tenant string) error {
+	return a.CreateRoleInTenantCtx(context.Background(), roleName, tenant)
+}
+
+// CreateRoleInTenantCtx is the context-aware variant of CreateRoleInTenant
+func (a *Authority) CreateRoleInTenantCtx(ctx context.Context, roleName string, tenant string) error {
+	return a.createRole(ctx, Role{Name: roleName, Tenant: tenant})
+}
+
+// CreatePermissionInTenant stores a permission that exists only in the given tenant
+func (a *Authority) CreatePermissionInTenant(permName string, tenant string) error {
+	return a.CreatePermissionInTenantCtx(context.Background(), permName, tenant)
+}
+
+// CreatePermissionInTenantCtx is the context-aware variant of CreatePermissionInTenant
+func (a *Authority) CreatePermissionInTenantCtx(ctx context.Context, permName string, tenant string) error {
+	return a.createPermission(ctx, permName, tenant)
+}
+
+// AssignPermissionsInTenant assigns permissions to a role, the role and the permissions are looked up
+// in the tenant first and then among the global ones
+func (a *Authority) AssignPermissionsInTenant(roleName string, permNames []string, tenant string) error {
+	return a.AssignPermissionsInTenantCtx(context.Background(), roleName, permNames, tenant)
+}
+
+// AssignPermissionsInTenantCtx is the context-aware variant of AssignPermissionsInTenant
+func (a *Authority) AssignPermissionsInTenantCtx(ctx context.Context, roleName string, permNames []string, tenant string) error {
+	return a.assignPermissions(ctx, roleName, permNames, RolePermission{}, tenant)
+}
+
+// AssignRoleInTenant assigns a role to a user within the given tenant only
+func (a *Authority) AssignRoleInTenant(userID uint, roleName string, tenant string) error {
+	return a.AssignRoleInTenantCtx(context.Background(), userID, roleName, tenant)
+}
+
+// AssignRoleInTenantCtx is the context-aware variant of AssignRoleInTenant
+func (a *Authority) AssignRoleInTenantCtx(ctx context.Context, userID uint, roleName string, tenant string) error {
+	return a.assignRole(ctx, roleName, UserRole{UserKey: userKey(userID), Tenant: tenant})
+}
+
+// RevokeRoleInTenant revokes a role assigned to a user within the given tenant,
+// the global assignment of the role is not affected
+func (a *Authority) RevokeRoleInTenant(userID uint, roleName string, tenant string) error {
+	return a.RevokeRoleInTenantCtx(context.Background(), userID, roleName, tenant)
+}
+
+// RevokeRoleInTenantCtx is the context-aware variant of RevokeRoleInTenant
+func (a *Authority) RevokeRoleInTenantCtx(ctx context.Context, userID uint, roleName string, tenant string) error {
+	return a.revokeRole(ctx, userKey(userID), roleName, tenant)
+}
+
+// CheckRoleInTenant checks if a user has a role within the given tenant
+func (a *Authority) CheckRoleInTenant(userID uint, roleName string, tenant string) (bool, error) {
+	return a.CheckRoleInTenantCtx(context.Background(), userID, roleName, tenant)
+}
+
+// CheckRoleInTenantCtx is the context-aware variant of CheckRoleInTenant
+func (a *Authority) CheckRoleInTenantCtx(ctx context.Context, userID uint, roleName string, tenant string) (bool, error) {
+	return a.checkRole(ctx, userKey(userID), roleName, tenant)
+}
+
+// CheckPermissionInTenant checks if a user has a permission within the given tenant
+func (a *Authority) CheckPermissionInTenant(userID uint, permName string, tenant string) (bool, error) {
+	return a.CheckPermissionInTenantCtx(context.Background(), userID, permName, tenant)
+}
+
+// CheckPermissionInTenantCtx is the context-aware variant of CheckPermissionInTenant
+func (a *Authority) CheckPermissionInTenantCtx(ctx context.Context, userID uint, permName string, tenant string) (bool, error) {
+	return a.checkPermission(ctx, userKey(userID), permName, tenant)
+}
+
+// CheckRolePermissionInTenant checks if a role has a permission within the given tenant, the role and the
+// permission are looked up in the tenant first and then among the global ones like CheckPermissionInTenant
+func (a *Authority) CheckRolePermissionInTenant(roleName string, permName string, tenant string) (bool, error) {
+	return a.CheckRolePermissionInTenantCtx(context.Background(), roleName, permName, tenant)
+}
+
+// CheckRolePermissionInTenantCtx is the context-aware variant of CheckRolePermissionInTenant
+func (a *Authority) CheckRolePermissionInTenantCtx(ctx context.Context, roleName string, permName string,
+	tenant string) (bool, error) {
+	return a.checkRolePermission(ctx, roleName, permName, tenant)
+}
+
+// GetUserRolesInTenant returns the names of the roles a user has within the given tenant,
+// including the globally assigned ones
+func (a *Authority) GetUserRolesInTenant(userID uint, tenant string) ([]string, error) {
+	return a.GetUserRolesInTenantCtx(context.Background(), userID, tenant)
+}
+
+// GetUserRolesInTenantCtx is the context-aware variant of GetUserRolesInTenant
+func (a *Authority) GetUserRolesInTenantCtx(ctx context.Context, userID uint, tenant string) ([]string, error) {
+	return a.getUserRoles(ctx, userKey(userID), tenant)
+}
+
+// GetUserPermissionsInTenant returns the names of the permissions a user has within the given tenant
+func (a *Authority) GetUserPermissionsInTenant(userID uint, tenant string) ([]string, error) {
+	return a.GetUserPermissionsInTenantCtx(context.Background(), userID, tenant)
+}
+
+// GetUserPermissionsInTenantCtx is the context-aware variant of GetUserPermissionsInTenant
+func (a *Authority) GetUserPermissionsInTenantCtx(ctx context.Context, userID uint, tenant string) ([]string, error) {
+	perms, err := a.userPermissions(ctx, userKey(userID), tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(perms))
+	for _, perm := range perms {
+		result = append(result, perm.Name)
+	}
+
+	return result, nil
+}
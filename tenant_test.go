@@ -0,0 +1,90 @@
+package authority_test
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"authority"
+)
+
+func TestTenants(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	setupRole(t, a, "editor", "articles.write")
+	must(t, a.CreateRoleInTenant("editor", "acme"))
+	must(t, a.CreatePermissionInTenant("articles.publish", "acme"))
+	must(t, a.AssignPermissionsInTenant("editor", []string{"articles.write", "articles.publish"}, "acme"))
+
+	// the first user is an editor of acme only, the second one a global editor
+	must(t, a.AssignRoleInTenant(1, "editor", "acme"))
+	must(t, a.AssignRole(2, "editor"))
+
+	for _, tc := range []struct {
+		user    uint
+		perm    string
+		tenant  string
+		granted bool
+	}{
+		{1, "articles.publish", "acme", true},
+		{1, "articles.write", "acme", true},
+		{1, "articles.write", "", false},
+		{1, "articles.write", "globex", false},
+		{2, "articles.write", "globex", true},
+		{2, "articles.write", "", true},
+		// the global editor is another role than the editor of acme
+		{2, "articles.publish", "acme", false},
+	} {
+		granted, err := a.CheckPermissionInTenant(tc.user, tc.perm, tc.tenant)
+		must(t, err)
+		if granted != tc.granted {
+			t.Errorf("CheckPermissionInTenant(%d, %s, %q) = %t, want %t", tc.user, tc.perm, tc.tenant, granted,
+				tc.granted)
+		}
+	}
+
+	perms, err := a.GetUserPermissionsInTenant(1, "acme")
+	must(t, err)
+	sort.Strings(perms)
+	if !equalStrings(perms, []string{"articles.publish", "articles.write"}) {
+		t.Fatalf("GetUserPermissionsInTenant = %v", perms)
+	}
+
+	must(t, a.RevokeRoleInTenant(1, "editor", "acme"))
+	ok, err := a.CheckRoleInTenant(1, "editor", "acme")
+	must(t, err)
+	if ok {
+		t.Fatal("the role is kept after RevokeRoleInTenant")
+	}
+}
+
+func TestCheckRolePermissionInTenant(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+	setupRole(t, a, "editor", "articles.write")
+	must(t, a.CreateRoleInTenant("editor", "acme"))
+	must(t, a.CreatePermissionInTenant("articles.publish", "acme"))
+	must(t, a.AssignPermissionsInTenant("editor", []string{"articles.publish"}, "acme"))
+
+	for _, tc := range []struct {
+		perm    string
+		tenant  string
+		granted bool
+	}{
+		{"articles.publish", "acme", true},
+		// the editor of acme is another role than the global editor
+		{"articles.write", "acme", false},
+		{"articles.write", "globex", true},
+		{"articles.write", "", true},
+	} {
+		granted, err := a.CheckRolePermissionInTenant("editor", tc.perm, tc.tenant)
+		must(t, err)
+		if granted != tc.granted {
+			t.Errorf("CheckRolePermissionInTenant(editor, %s, %q) = %t, want %t", tc.perm, tc.tenant, granted,
+				tc.granted)
+		}
+	}
+
+	// the permission of acme is unknown outside of the tenant
+	if _, err := a.CheckRolePermission("editor", "articles.publish"); !errors.Is(err, authority.ErrPermissionNotFound) {
+		t.Fatalf("CheckRolePermission = %v, want ErrPermissionNotFound", err)
+	}
+}
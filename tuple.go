@@ -0,0 +1,341 @@
+package authority
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// the relation tuples describe the global roles, permissions and assignments in the object#relation@subject
+// notation of Zanzibar-style systems like SpiceDB and OpenFGA, so both can be fed from the same data:
+//
+//	role:editor#member@user:42                    the user 42 is assigned the role editor
+//	role:editor#member@role:admin#member          the composite role admin includes the role editor
+//	role:editor#parent@role:viewer                the role editor inherits the permissions of the role viewer
+//	permission:articles.write#granted@role:editor#member   the role editor has the permission articles.write
+//
+// the tenant scoped roles and assignments and the conditions and validity windows are not represented
+
+// the types of the objects and subjects of the relation tuples
+const (
+	TupleTypeUser       = "user"
+	TupleTypeRole       = "role"
+	TupleTypePermission = "permission"
+)
+
+// the relations of the relation tuples
+const (
+	TupleRelationMember  = "member"
+	TupleRelationParent  = "parent"
+	TupleRelationGranted = "granted"
+)
+
+// ErrInvalidTuple is returned when a relation tuple can't be parsed
+var ErrInvalidTuple = errors.New("invalid relation tuple")
+
+// ErrUnsupportedTuple is returned when a relation tuple doesn't map onto a role, permission or assignment
+var ErrUnsupportedTuple = errors.New("unsupported relation tuple")
+
+// RelationTuple states that a subject has a relation to an object, e.g. role:editor#member@user:42.
+// the subject is a user set when SubjectRelation is set, e.g. role:admin#member
+type RelationTuple struct {
+	ObjectType      string
+	ObjectID        string
+	Relation        string
+	SubjectType     string
+	SubjectID       string
+	SubjectRelation string
+}
+
+// ParseRelationTuple parses a relation tuple written as object#relation@subject
+func ParseRelationTuple(s string) (RelationTuple, error) {
+	var t RelationTuple
+
+	object, subject, ok := strings.Cut(s, "@")
+	if !ok {
+		return t, fmt.Errorf("%w: %q", ErrInvalidTuple, s)
+	}
+
+	if object, t.Relation, ok = strings.Cut(object, "#"); !ok {
+		return t, fmt.Errorf("%w: %q", ErrInvalidTuple, s)
+	}
+
+	if subject, t.SubjectRelation, ok = strings.Cut(subject, "#"); ok && t.SubjectRelation == "" {
+		return t, fmt.Errorf("%w: %q", ErrInvalidTuple, s)
+	}
+
+	t.ObjectType, t.ObjectID, ok = strings.Cut(object, ":")
+	if !ok || t.ObjectType == "" || t.ObjectID == "" || t.Relation == "" {
+		return t, fmt.Errorf("%w: %q", ErrInvalidTuple, s)
+	}
+
+	if t.SubjectType, t.SubjectID, ok = strings.Cut(subject, ":"); !ok || t.SubjectType == "" || t.SubjectID == "" {
+		return t, fmt.Errorf("%w: %q", ErrInvalidTuple, s)
+	}
+
+	return t, nil
+}
+
+// String returns the tuple written as object#relation@subject
+func (t RelationTuple) String() string {
+	s := t.ObjectType + ":" + t.ObjectID + "#" + t.Relation + "@" + t.SubjectType + ":" + t.SubjectID
+	if t.SubjectRelation != "" {
+		s += "#" + t.SubjectRelation
+	}
+
+	return s
+}
+
+// the kinds of the tuples mapped onto the tables
+const (
+	tupleUnsupported = iota
+	tupleUserRole
+	tupleComposite
+	tupleParent
+	tupleRolePermission
+)
+
+// kind returns what the tuple maps onto
+func (t RelationTuple) kind() int {
+	switch {
+	case t.ObjectType == TupleTypeRole && t.Relation == TupleRelationMember &&
+		t.SubjectType == TupleTypeUser && t.SubjectRelation == "":
+		return tupleUserRole
+	case t.ObjectType == TupleTypeRole && t.Relation == TupleRelationMember &&
+		t.SubjectType == TupleTypeRole && t.SubjectRelation == TupleRelationMember:
+		return tupleComposite
+	case t.ObjectType == TupleTypeRole && t.Relation == TupleRelationParent &&
+		t.SubjectType == TupleTypeRole && t.SubjectRelation == "":
+		return tupleParent
+	case t.ObjectType == TupleTypePermission && t.Relation == TupleRelationGranted &&
+		t.SubjectType == TupleTypeRole && t.SubjectRelation == TupleRelationMember:
+		return tupleRolePermission
+	}
+
+	return tupleUnsupported
+}
+
+// ReadTuples returns the relation tuples of the global roles, permissions and assignments, sorted
+func (a *Authority) ReadTuples() ([]RelationTuple, error) {
+	return a.ReadTuplesCtx(context.Background())
+}
+
+// ReadTuplesCtx is the context-aware variant of ReadTuples
+func (a *Authority) ReadTuplesCtx(ctx context.Context) ([]RelationTuple, error) {
+	roles, err := a.store.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[uint]string, len(roles))
+	roleIDs := make([]uint, 0, len(roles))
+	for _, role := range roles {
+		if role.Tenant == "" {
+			names[role.ID] = role.Name
+			roleIDs = append(roleIDs, role.ID)
+		}
+	}
+
+	var perms []Permission
+	if perms, err = a.store.ListPermissions(ctx); err != nil {
+		return nil, err
+	}
+
+	permNames := make(map[uint]string, len(perms))
+	for _, perm := range perms {
+		if perm.Tenant == "" {
+			permNames[perm.ID] = perm.Name
+		}
+	}
+
+	var tuples []RelationTuple
+
+	var userRoles []UserRole
+	if userRoles, err = a.store.ListUserRoles(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, ur := range userRoles {
+		if name, ok := names[ur.RoleID]; ok && ur.Tenant == "" {
+			tuples = append(tuples, RelationTuple{
+				ObjectType: TupleTypeRole, ObjectID: name, Relation: TupleRelationMember,
+				SubjectType: TupleTypeUser, SubjectID: ur.UserKey,
+			})
+		}
+	}
+
+	var composites []RoleComposite
+	if composites, err = a.store.GetRoleComposites(ctx, roleIDs); err != nil {
+		return nil, err
+	}
+
+	for _, rc := range composites {
+		if member, ok := names[rc.MemberID]; ok {
+			tuples = append(tuples, RelationTuple{
+				ObjectType: TupleTypeRole, ObjectID: member, Relation: TupleRelationMember,
+				SubjectType: TupleTypeRole, SubjectID: names[rc.RoleID], SubjectRelation: TupleRelationMember,
+			})
+		}
+	}
+
+	var parents []RoleParent
+	if parents, err = a.store.GetRoleParents(ctx, roleIDs); err != nil {
+		return nil, err
+	}
+
+	for _, rp := range parents {
+		if parent, ok := names[rp.ParentID]; ok {
+			tuples = append(tuples, RelationTuple{
+				ObjectType: TupleTypeRole, ObjectID: names[rp.RoleID], Relation: TupleRelationParent,
+				SubjectType: TupleTypeRole, SubjectID: parent,
+			})
+		}
+	}
+
+	var rolePerms []RolePermission
+	if rolePerms, err = a.store.GetRolePermissions(ctx, roleIDs); err != nil {
+		return nil, err
+	}
+
+	for _, rp := range rolePerms {
+		if perm, ok := permNames[rp.PermissionID]; ok {
+			tuples = append(tuples, RelationTuple{
+				ObjectType: TupleTypePermission, ObjectID: perm, Relation: TupleRelationGranted,
+				SubjectType: TupleTypeRole, SubjectID: names[rp.RoleID], SubjectRelation: TupleRelationMember,
+			})
+		}
+	}
+
+	sort.Slice(tuples, func(i, j int) bool {
+		return tuples[i].String() < tuples[j].String()
+	})
+
+	return tuples, nil
+}
+
+// WriteTuples applies the relation tuples, assigning the roles and permissions and adding the composite roles
+// and the parents they describe. the tuples already applied are skipped and the changes are made atomically.
+// it returns ErrUnsupportedTuple if a tuple doesn't map onto a role, permission or assignment
+func (a *Authority) WriteTuples(tuples []RelationTuple) error {
+	return a.WriteTuplesCtx(context.Background(), tuples)
+}
+
+// WriteTuplesCtx is the context-aware variant of WriteTuples
+func (a *Authority) WriteTuplesCtx(ctx context.Context, tuples []RelationTuple) error {
+	return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		for _, t := range tuples {
+			if err := tx.writeTuple(ctx, t); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (a *Authority) writeTuple(ctx context.Context, t RelationTuple) error {
+	switch t.kind() {
+	case tupleUserRole:
+		err := a.assignRole(ctx, t.ObjectID, UserRole{UserKey: t.SubjectID})
+		if errors.Is(err, ErrRoleAlreadyAssigned) {
+			return nil
+		}
+		return err
+	case tupleComposite:
+		members, err := a.GetCompositeRolesCtx(ctx, t.SubjectID)
+		if err != nil {
+			return err
+		}
+		for _, member := range members {
+			if member == t.ObjectID {
+				return nil
+			}
+		}
+		return a.addCompositeRoles(ctx, t.SubjectID, []string{t.ObjectID})
+	case tupleParent:
+		return a.SetRoleParentCtx(ctx, t.ObjectID, t.SubjectID)
+	case tupleRolePermission:
+		return a.assignRolePermissions(ctx, t.SubjectID, []string{t.ObjectID}, RolePermission{}, "")
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnsupportedTuple, t)
+}
+
+// DeleteTuples removes the roles and permissions assignments, the composite roles and the parents described by
+// the relation tuples, the changes are made atomically.
+// it returns ErrUnsupportedTuple if a tuple doesn't map onto a role, permission or assignment
+func (a *Authority) DeleteTuples(tuples []RelationTuple) error {
+	return a.DeleteTuplesCtx(context.Background(), tuples)
+}
+
+// DeleteTuplesCtx is the context-aware variant of DeleteTuples
+func (a *Authority) DeleteTuplesCtx(ctx context.Context, tuples []RelationTuple) error {
+	return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		for _, t := range tuples {
+			if err := tx.deleteTuple(ctx, t); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (a *Authority) deleteTuple(ctx context.Context, t RelationTuple) error {
+	switch t.kind() {
+	case tupleUserRole:
+		return a.revokeRole(ctx, t.SubjectID, t.ObjectID, "")
+	case tupleComposite:
+		return a.RemoveCompositeRoleCtx(ctx, t.SubjectID, t.ObjectID)
+	case tupleParent:
+		role, err := a.getRole(ctx, t.ObjectID)
+		if err != nil {
+			return err
+		}
+
+		var parent *Role
+		if parent, err = a.getRole(ctx, t.SubjectID); err != nil {
+			return err
+		}
+
+		// the role may have a different parent
+		var parents []RoleParent
+		if parents, err = a.store.GetRoleParents(ctx, []uint{role.ID}); err != nil {
+			return err
+		}
+		if len(parents) == 0 || parents[0].ParentID != parent.ID {
+			return nil
+		}
+
+		return a.RemoveRoleParentCtx(ctx, t.ObjectID)
+	case tupleRolePermission:
+		return a.RevokeRolePermissionCtx(ctx, t.SubjectID, t.ObjectID)
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnsupportedTuple, t)
+}
+
+// CheckTuple checks a relation of a user, role:editor#member@user:42 checks the user 42 has the role editor
+// and permission:articles.write#granted@user:42 checks the user 42 has the permission articles.write,
+// including the roles and permissions granted through the composite roles and the parents
+func (a *Authority) CheckTuple(t RelationTuple) (bool, error) {
+	return a.CheckTupleCtx(context.Background(), t)
+}
+
+// CheckTupleCtx is the context-aware variant of CheckTuple
+func (a *Authority) CheckTupleCtx(ctx context.Context, t RelationTuple) (bool, error) {
+	if t.SubjectType != TupleTypeUser || t.SubjectRelation != "" {
+		return false, fmt.Errorf("%w: %s", ErrUnsupportedTuple, t)
+	}
+
+	switch {
+	case t.ObjectType == TupleTypeRole && t.Relation == TupleRelationMember:
+		return a.checkRole(ctx, t.SubjectID, t.ObjectID, "")
+	case t.ObjectType == TupleTypePermission && t.Relation == TupleRelationGranted:
+		return a.checkPermission(ctx, t.SubjectID, t.ObjectID, "")
+	}
+
+	return false, fmt.Errorf("%w: %s", ErrUnsupportedTuple, t)
+}
@@ -0,0 +1,68 @@
+package authority
+
+import (
+	"context"
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// WithTx returns a copy of the instance that runs its queries in the transaction of the caller, e.g. to create
+// a user and assign its roles atomically. the changes are committed or rolled back with the transaction and the
+// internal transactions of the copy become savepoints. the copy doesn't read the cache since it may see
+// uncommitted changes, and it keeps the changes for the hooks until Committed is called once the transaction is
+// committed. it returns ErrNotSupported when the instance doesn't use the bun store
+func (a *Authority) WithTx(tx bun.Tx) (*Authority, error) {
+	s, ok := a.store.(*BunStore)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+
+	txStore := *s
+	txStore.db, txStore.conn = tx, nil
+
+	c := *a
+	c.store = &txStore
+	c.pending = &[]AuditEntry{}
+	if c.cache != nil {
+		c.cache = purgeOnlyCache{a.cache}
+	}
+
+	return &c, nil
+}
+
+// Committed is called on an instance returned by WithTx once its transaction is committed, it drops the lookups
+// cached meanwhile and notifies the hooks of the changes made with the instance. the changes of a transaction
+// rolled back are never notified, the instance must not be used after
+func (a *Authority) Committed(ctx context.Context) error {
+	if a.pending == nil {
+		return nil
+	}
+
+	pending := *a.pending
+	a.pending = nil
+
+	err := a.purgeCache(ctx)
+	for _, entry := range pending {
+		a.notify(ctx, entry)
+	}
+
+	return err
+}
+
+// purgeOnlyCache forwards only the purges to the cache, the entries stay out of reach of a transaction
+type purgeOnlyCache struct {
+	cache CacheBackend
+}
+
+func (c purgeOnlyCache) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, nil
+}
+
+func (c purgeOnlyCache) Set(context.Context, string, []byte, time.Duration) error {
+	return nil
+}
+
+func (c purgeOnlyCache) Purge(ctx context.Context) error {
+	return c.cache.Purge(ctx)
+}
@@ -0,0 +1,53 @@
+package authority_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/uptrace/bun"
+
+	"authority"
+)
+
+// changeRecorder records the actions of the changes it's notified of
+type changeRecorder struct {
+	authority.NopHooks
+	actions []string
+}
+
+func (r *changeRecorder) OnChange(_ context.Context, change authority.AuditEntry) {
+	r.actions = append(r.actions, change.Action)
+}
+
+func TestWithTxNeedsBunStore(t *testing.T) {
+	a := newAuthority(t, authority.Options{})
+
+	if _, err := a.WithTx(bun.Tx{}); !errors.Is(err, authority.ErrNotSupported) {
+		t.Fatalf("WithTx = %v, want ErrNotSupported", err)
+	}
+}
+
+func TestWithTxNotifiesOnCommit(t *testing.T) {
+	db := newBunDB(t)
+	hooks := &changeRecorder{}
+	a, err := authority.NewE(authority.Options{Store: newBunStore(t, db, authority.BunStoreOptions{}), Hooks: hooks})
+	must(t, err)
+
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	must(t, err)
+
+	txa, err := a.WithTx(tx)
+	must(t, err)
+	must(t, txa.CreateRoleCtx(ctx, "editor"))
+	if len(hooks.actions) != 0 {
+		t.Fatalf("notified %v before the commit", hooks.actions)
+	}
+
+	must(t, tx.Commit())
+	must(t, txa.Committed(ctx))
+	if !equalStrings(hooks.actions, []string{authority.AuditRoleCreated}) {
+		t.Fatalf("notified %v, want the created role", hooks.actions)
+	}
+}
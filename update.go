@@ -0,0 +1,223 @@
+package authority
+
+import (
+	"context"
+	"errors"
+)
+
+// the actions recorded on the audit entries of the updates
+const (
+	AuditRoleUpdated       = "role.updated"
+	AuditPermissionUpdated = "permission.updated"
+)
+
+// RoleUpdate has the changes UpdateRole makes, the nil fields are left unchanged
+type RoleUpdate struct {
+	Name        *string
+	Title       *string
+	Description *string
+	// Metadata replaces the custom attributes, an empty map removes them
+	Metadata map[string]interface{}
+}
+
+// PermissionUpdate has the changes UpdatePermission makes, the nil fields are left unchanged
+type PermissionUpdate struct {
+	Name        *string
+	Title       *string
+	Description *string
+	// Action and Resource are the action and the resource the permission allows
+	Action   *string
+	Resource *string
+	// Metadata replaces the custom attributes, an empty map removes them
+	Metadata map[string]interface{}
+}
+
+// UpdateRole renames a role or sets its title, its description or its metadata, the assignments follow
+// the renamed role. it returns a ConstraintError matching ErrDuplicate and ErrRoleExists if another role has
+// the new name. the names kept outside of the tables, e.g. in the policies, the declarations or the notification
+// options, are not updated
+func (a *Authority) UpdateRole(roleName string, update RoleUpdate) error {
+	return a.UpdateRoleCtx(context.Background(), roleName, update)
+}
+
+// UpdateRoleCtx is the context-aware variant of UpdateRole
+func (a *Authority) UpdateRoleCtx(ctx context.Context, roleName string, update RoleUpdate) error {
+	return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		role, err := tx.getRole(ctx, roleName)
+		if err != nil {
+			return err
+		}
+
+		detail := ""
+		if update.Name != nil && *update.Name != role.Name {
+			var existing *Role
+			existing, err = tx.store.GetRole(ctx, *update.Name, role.Tenant)
+			if err != nil && !errors.Is(err, ErrRoleNotFound) {
+				return err
+			}
+			if err == nil && existing.Tenant == role.Tenant {
+				return &ConstraintError{Kind: ErrDuplicate, Entity: EntityRole, Name: *update.Name}
+			}
+			detail = "renamed from " + role.Name
+			role.Name = *update.Name
+		}
+		if update.Title != nil {
+			role.Title = *update.Title
+		}
+		if update.Description != nil {
+			role.Description = *update.Description
+		}
+		if update.Metadata != nil {
+			role.Metadata = nullMetadata(update.Metadata)
+		}
+
+		if err = tx.store.UpdateRole(ctx, role); err != nil {
+			return err
+		}
+
+		return tx.changed(ctx, AuditEntry{Action: AuditRoleUpdated, Role: role.Name, Detail: detail})
+	})
+}
+
+// UpdatePermission renames a permission or sets its title, its description or its metadata, the assignments
+// follow the renamed permission.
+// it returns a ConstraintError matching ErrDuplicate if another permission has the new name. the names kept
+// outside of the tables, e.g. in the declarations, the sensitivity classes or the code checking
+// the permission, are not updated
+func (a *Authority) UpdatePermission(permName string, update PermissionUpdate) error {
+	return a.UpdatePermissionCtx(context.Background(), permName, update)
+}
+
+// UpdatePermissionCtx is the context-aware variant of UpdatePermission
+func (a *Authority) UpdatePermissionCtx(ctx context.Context, permName string, update PermissionUpdate) error {
+	return a.inTx(ctx, func(ctx context.Context, tx *Authority) error {
+		perm, err := tx.getPermission(ctx, permName)
+		if err != nil {
+			return err
+		}
+
+		detail := ""
+		if update.Name != nil && *update.Name != perm.Name {
+			var existing *Permission
+			existing, err = tx.store.GetPermission(ctx, *update.Name, perm.Tenant)
+			if err != nil && !errors.Is(err, ErrPermissionNotFound) {
+				return err
+			}
+			if err == nil && existing.Tenant == perm.Tenant {
+				return &ConstraintError{Kind: ErrDuplicate, Entity: EntityPermission, Name: *update.Name}
+			}
+			detail = "renamed from " + perm.Name
+			perm.Name = *update.Name
+		}
+		if update.Title != nil {
+			perm.Title = *update.Title
+		}
+		if update.Description != nil {
+			perm.Description = *update.Description
+		}
+		if update.Metadata != nil {
+			perm.Metadata = nullMetadata(update.Metadata)
+		}
+		if update.Action != nil {
+			perm.Action = *update.Action
+		}
+		if update.Resource != nil {
+			perm.Resource = *update.Resource
+		}
+
+		if err = tx.store.UpdatePermission(ctx, perm); err != nil {
+			return err
+		}
+
+		return tx.changed(ctx, AuditEntry{Action: AuditPermissionUpdated, Permission: perm.Name, Detail: detail})
+	})
+}
+
+// SetRoleDescription sets the description of a role
+func (a *Authority) SetRoleDescription(roleName string, description string) error {
+	return a.SetRoleDescriptionCtx(context.Background(), roleName, description)
+}
+
+// SetRoleDescriptionCtx is the context-aware variant of SetRoleDescription
+func (a *Authority) SetRoleDescriptionCtx(ctx context.Context, roleName string, description string) error {
+	return a.UpdateRoleCtx(ctx, roleName, RoleUpdate{Description: &description})
+}
+
+// SetRoleMetadata replaces the custom attributes of a role, they must be encodable as JSON
+func (a *Authority) SetRoleMetadata(roleName string, metadata map[string]interface{}) error {
+	return a.SetRoleMetadataCtx(context.Background(), roleName, metadata)
+}
+
+// SetRoleMetadataCtx is the context-aware variant of SetRoleMetadata
+func (a *Authority) SetRoleMetadataCtx(ctx context.Context, roleName string, metadata map[string]interface{}) error {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+
+	return a.UpdateRoleCtx(ctx, roleName, RoleUpdate{Metadata: metadata})
+}
+
+// GetRoleMetadata returns the custom attributes of a role, nil when it has none
+func (a *Authority) GetRoleMetadata(roleName string) (map[string]interface{}, error) {
+	return a.GetRoleMetadataCtx(context.Background(), roleName)
+}
+
+// GetRoleMetadataCtx is the context-aware variant of GetRoleMetadata
+func (a *Authority) GetRoleMetadataCtx(ctx context.Context, roleName string) (map[string]interface{}, error) {
+	role, err := a.getRole(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	return role.Metadata, nil
+}
+
+// SetPermissionDescription sets the description of a permission
+func (a *Authority) SetPermissionDescription(permName string, description string) error {
+	return a.SetPermissionDescriptionCtx(context.Background(), permName, description)
+}
+
+// SetPermissionDescriptionCtx is the context-aware variant of SetPermissionDescription
+func (a *Authority) SetPermissionDescriptionCtx(ctx context.Context, permName string, description string) error {
+	return a.UpdatePermissionCtx(ctx, permName, PermissionUpdate{Description: &description})
+}
+
+// SetPermissionMetadata replaces the custom attributes of a permission, they must be encodable as JSON
+func (a *Authority) SetPermissionMetadata(permName string, metadata map[string]interface{}) error {
+	return a.SetPermissionMetadataCtx(context.Background(), permName, metadata)
+}
+
+// SetPermissionMetadataCtx is the context-aware variant of SetPermissionMetadata
+func (a *Authority) SetPermissionMetadataCtx(ctx context.Context, permName string,
+	metadata map[string]interface{}) error {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+
+	return a.UpdatePermissionCtx(ctx, permName, PermissionUpdate{Metadata: metadata})
+}
+
+// GetPermissionMetadata returns the custom attributes of a permission, nil when it has none
+func (a *Authority) GetPermissionMetadata(permName string) (map[string]interface{}, error) {
+	return a.GetPermissionMetadataCtx(context.Background(), permName)
+}
+
+// GetPermissionMetadataCtx is the context-aware variant of GetPermissionMetadata
+func (a *Authority) GetPermissionMetadataCtx(ctx context.Context, permName string) (map[string]interface{},
+	error) {
+	perm, err := a.getPermission(ctx, permName)
+	if err != nil {
+		return nil, err
+	}
+
+	return perm.Metadata, nil
+}
+
+// nullMetadata returns nil for the empty metadata so that it's stored as NULL
+func nullMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	return metadata
+}
@@ -0,0 +1,137 @@
+package authority
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// userKey returns the key of a user identified by a number
+func userKey(userID uint) string {
+	return strconv.FormatUint(uint64(userID), 10)
+}
+
+// userIDOf returns the numeric id of a user key, 0 when the user is identified by a string
+func userIDOf(key string) uint {
+	id, err := strconv.ParseUint(key, 10, 0)
+	if err != nil {
+		return 0
+	}
+
+	return uint(id)
+}
+
+// User acts on the roles and permissions of a user identified by a string, e.g. a UUID or an email.
+// the methods taking a numeric user id are equivalent to the ones of the User with the decimal id as key,
+// e.g. AssignRole(42, "admin") and User("42").AssignRole("admin")
+type User struct {
+	a      *Authority
+	key    string
+	tenant string
+}
+
+// User returns the user identified by the key
+func (a *Authority) User(key string) User {
+	return User{a: a, key: key}
+}
+
+// InTenant returns the same user acting within the given tenant, like the InTenant variants of the methods
+func (u User) InTenant(tenant string) User {
+	u.tenant = tenant
+	return u
+}
+
+// Key returns the key identifying the user
+func (u User) Key() string {
+	return u.key
+}
+
+// AssignRole assigns a role to the user
+func (u User) AssignRole(roleName string) error {
+	return u.AssignRoleCtx(context.Background(), roleName)
+}
+
+// AssignRoleCtx is the context-aware variant of AssignRole
+func (u User) AssignRoleCtx(ctx context.Context, roleName string) error {
+	return u.a.assignRole(ctx, roleName, UserRole{UserKey: u.key, Tenant: u.tenant})
+}
+
+// AssignRoleFrom assigns a role to the user starting at the given time
+func (u User) AssignRoleFrom(roleName string, startAt time.Time) error {
+	return u.AssignRoleFromCtx(context.Background(), roleName, startAt)
+}
+
+// AssignRoleFromCtx is the context-aware variant of AssignRoleFrom
+func (u User) AssignRoleFromCtx(ctx context.Context, roleName string, startAt time.Time) error {
+	return u.a.assignRole(ctx, roleName, UserRole{UserKey: u.key, Tenant: u.tenant, StartsAt: startAt})
+}
+
+// AssignRoleUntil assigns a role to the user until the given time
+func (u User) AssignRoleUntil(roleName string, expiresAt time.Time) error {
+	return u.AssignRoleUntilCtx(context.Background(), roleName, expiresAt)
+}
+
+// AssignRoleUntilCtx is the context-aware variant of AssignRoleUntil
+func (u User) AssignRoleUntilCtx(ctx context.Context, roleName string, expiresAt time.Time) error {
+	return u.a.assignRole(ctx, roleName, UserRole{UserKey: u.key, Tenant: u.tenant, ExpiresAt: expiresAt})
+}
+
+// RevokeRole revokes a role from the user
+func (u User) RevokeRole(roleName string) error {
+	return u.RevokeRoleCtx(context.Background(), roleName)
+}
+
+// RevokeRoleCtx is the context-aware variant of RevokeRole
+func (u User) RevokeRoleCtx(ctx context.Context, roleName string) error {
+	return u.a.revokeRole(ctx, u.key, roleName, u.tenant)
+}
+
+// CheckRole checks if the user has a role
+func (u User) CheckRole(roleName string) (bool, error) {
+	return u.CheckRoleCtx(context.Background(), roleName)
+}
+
+// CheckRoleCtx is the context-aware variant of CheckRole
+func (u User) CheckRoleCtx(ctx context.Context, roleName string) (bool, error) {
+	return u.a.checkRole(ctx, u.key, roleName, u.tenant)
+}
+
+// CheckPermission checks if the user has a permission
+func (u User) CheckPermission(permName string) (bool, error) {
+	return u.CheckPermissionCtx(context.Background(), permName)
+}
+
+// CheckPermissionCtx is the context-aware variant of CheckPermission
+func (u User) CheckPermissionCtx(ctx context.Context, permName string) (bool, error) {
+	return u.a.checkPermission(ctx, u.key, permName, u.tenant)
+}
+
+// GetRoles returns the names of the roles of the user
+func (u User) GetRoles() ([]string, error) {
+	return u.GetRolesCtx(context.Background())
+}
+
+// GetRolesCtx is the context-aware variant of GetRoles
+func (u User) GetRolesCtx(ctx context.Context) ([]string, error) {
+	return u.a.getUserRoles(ctx, u.key, u.tenant)
+}
+
+// GetPermissions returns the names of the effective permissions of the user
+func (u User) GetPermissions() ([]string, error) {
+	return u.GetPermissionsCtx(context.Background())
+}
+
+// GetPermissionsCtx is the context-aware variant of GetPermissions
+func (u User) GetPermissionsCtx(ctx context.Context) ([]string, error) {
+	perms, err := u.a.userPermissions(ctx, u.key, u.tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(perms))
+	for _, perm := range perms {
+		result = append(result, perm.Name)
+	}
+
+	return result, nil
+}
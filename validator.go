@@ -0,0 +1,92 @@
+package authority
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrChangeRejected is matched by the ValidationError returned when a validator vetoes a change
+var ErrChangeRejected = errors.New("change rejected")
+
+// Validator enforces the rules of an organization on the changes, e.g. the naming of the roles, the separation
+// of duties or quotas, without forking the package. the change is described by the entry auditing records,
+// with the request metadata of the context. the validators of Options.Validators are called in order and the
+// first error vetoes the change
+type Validator interface {
+	// BeforeChange is called with the proposed change before it's made, it's called for the creations and the
+	// deletions of the roles and the permissions and for their assignments and revocations
+	BeforeChange(ctx context.Context, change AuditEntry) error
+	// AfterChange is called with every change once it's made and before it's audited. the change is rolled
+	// back when it's made in a transaction, e.g. by AssignPermissions, the bulk assignments, the syncs and the
+	// instances returned by WithTx, otherwise it's kept while its error is returned
+	AfterChange(ctx context.Context, change AuditEntry) error
+}
+
+// NopValidator implements Validator accepting every change, embed it to implement only one of the methods
+type NopValidator struct{}
+
+func (NopValidator) BeforeChange(context.Context, AuditEntry) error { return nil }
+func (NopValidator) AfterChange(context.Context, AuditEntry) error  { return nil }
+
+// ValidatorFunc adapts a function to a Validator called before the changes
+type ValidatorFunc func(ctx context.Context, change AuditEntry) error
+
+// BeforeChange implements Validator
+func (f ValidatorFunc) BeforeChange(ctx context.Context, change AuditEntry) error {
+	return f(ctx, change)
+}
+
+// AfterChange implements Validator
+func (f ValidatorFunc) AfterChange(context.Context, AuditEntry) error {
+	return nil
+}
+
+// ValidationError is returned when a validator vetoes a change, it unwraps to the error of the validator so
+// that its typed errors can be matched with errors.As
+type ValidationError struct {
+	// Change is the vetoed change
+	Change AuditEntry
+	// Err is the error of the validator
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s %s: %v", ErrChangeRejected, e.Change.Action, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrChangeRejected
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrChangeRejected
+}
+
+// validate calls the validators before a change
+func (a *Authority) validate(ctx context.Context, entry AuditEntry) error {
+	if len(a.validators) == 0 {
+		return nil
+	}
+
+	entry = a.describe(ctx, entry)
+	for _, v := range a.validators {
+		if err := v.BeforeChange(ctx, entry); err != nil {
+			return &ValidationError{Change: entry, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// validated calls the validators after a change, the entry is described already
+func (a *Authority) validated(ctx context.Context, entry AuditEntry) error {
+	for _, v := range a.validators {
+		if err := v.AfterChange(ctx, entry); err != nil {
+			return &ValidationError{Change: entry, Err: err}
+		}
+	}
+
+	return nil
+}
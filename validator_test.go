@@ -0,0 +1,67 @@
+package authority_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"authority"
+)
+
+// namingError is the typed error of the naming rule
+type namingError struct {
+	Name string
+}
+
+func (e *namingError) Error() string {
+	return "role " + e.Name + " is not prefixed with app."
+}
+
+// segregation rejects the permissions of the auditors granted to the other roles once they are assigned
+type segregation struct {
+	authority.NopValidator
+}
+
+func (segregation) AfterChange(_ context.Context, change authority.AuditEntry) error {
+	if change.Action == authority.AuditPermissionAssigned && strings.HasPrefix(change.Permission, "audit.") &&
+		change.Role != "app.auditor" {
+		return errors.New("the audit permissions are reserved to the auditors")
+	}
+
+	return nil
+}
+
+func TestValidators(t *testing.T) {
+	naming := authority.ValidatorFunc(func(_ context.Context, change authority.AuditEntry) error {
+		if change.Action == authority.AuditRoleCreated && !strings.HasPrefix(change.Role, "app.") {
+			return &namingError{Name: change.Role}
+		}
+		return nil
+	})
+	a := newAuthority(t, authority.Options{Validators: []authority.Validator{naming, segregation{}}})
+
+	err := a.CreateRole("editor")
+	var namingErr *namingError
+	if !errors.Is(err, authority.ErrChangeRejected) || !errors.As(err, &namingErr) || namingErr.Name != "editor" {
+		t.Fatalf("CreateRole = %v, want the naming error", err)
+	}
+	if _, err = a.GetRole("editor"); !errors.Is(err, authority.ErrRoleNotFound) {
+		t.Fatalf("GetRole = %v, the rejected role was created", err)
+	}
+
+	setupRole(t, a, "app.auditor", "audit.read")
+	must(t, a.CreateRole("app.editor"))
+
+	// the assignment rejected after it's made is rolled back with the others
+	must(t, a.CreatePermission("articles.write"))
+	err = a.AssignPermissions("app.editor", []string{"articles.write", "audit.read"})
+	if !errors.Is(err, authority.ErrChangeRejected) {
+		t.Fatalf("AssignPermissions = %v, want ErrChangeRejected", err)
+	}
+	ok, err := a.CheckRolePermission("app.editor", "articles.write")
+	must(t, err)
+	if ok {
+		t.Fatal("the permissions are assigned after the rejected assignment")
+	}
+}
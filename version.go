@@ -0,0 +1,40 @@
+package authority
+
+import "context"
+
+// PolicyVersioner is implemented by the stores that keep a version of the definitions of the roles and
+// permissions shared by all the instances, e.g. to compute the ETags of the lists of an admin API
+type PolicyVersioner interface {
+	// GetPolicyVersion returns the current version
+	GetPolicyVersion(ctx context.Context) (uint64, error)
+	// BumpPolicyVersion increments the version, with the change when it runs in its transaction
+	BumpPolicyVersion(ctx context.Context) error
+}
+
+// PolicyVersion returns the version of the definitions of the roles, the permissions, their assignments to the
+// roles, the composite and parent roles and the policies. it changes with every change of the definitions made
+// by any instance, the assignments of the users don't change it. it returns ErrNotSupported if the store
+// doesn't implement PolicyVersioner
+func (a *Authority) PolicyVersion() (uint64, error) {
+	return a.PolicyVersionCtx(context.Background())
+}
+
+// PolicyVersionCtx is the context-aware variant of PolicyVersion
+func (a *Authority) PolicyVersionCtx(ctx context.Context) (uint64, error) {
+	versioner, ok := a.store.(PolicyVersioner)
+	if !ok {
+		return 0, ErrNotSupported
+	}
+
+	return versioner.GetPolicyVersion(ctx)
+}
+
+// bumpPolicyVersion increments the version when a change is about the definitions rather than a user
+func (a *Authority) bumpPolicyVersion(ctx context.Context, entry AuditEntry) error {
+	versioner, ok := a.store.(PolicyVersioner)
+	if !ok || entry.UserKey != "" || entry.UserID != 0 {
+		return nil
+	}
+
+	return versioner.BumpPolicyVersion(ctx)
+}